@@ -2,6 +2,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/stashapp/stash/pkg/api"
 	"github.com/stashapp/stash/pkg/database"
 	"github.com/stashapp/stash/pkg/manager"
@@ -12,6 +14,16 @@ import (
 )
 
 func main() {
+	// run subcommands without initialising the server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "scraper":
+			os.Exit(runScraperCommand(os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrateCommand(os.Args[2:]))
+		}
+	}
+
 	manager.Initialize()
 
 	// perform the post-migration for new databases
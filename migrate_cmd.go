@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/stashapp/stash/pkg/database"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/manager/paths"
+)
+
+const migrateUsage = `usage: stash migrate --to <version> [flags]
+
+Migrates the database up or down to the provided schema version. The
+database is backed up before it is migrated. Migrating down refuses to run
+if data would be lost, unless --allow-data-loss is set.
+
+Stash must not be running while the database is migrated.
+`
+
+// runMigrateCommand runs the migrate subcommand with the provided arguments.
+// Returns the exit code of the command.
+func runMigrateCommand(args []string) int {
+	flags := pflag.NewFlagSet("migrate", pflag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		flags.PrintDefaults()
+	}
+
+	var options database.MigrateOptions
+	var configPath string
+	flags.UintVar(&options.Version, "to", 0, "schema version to migrate to")
+	flags.BoolVar(&options.AllowDataLoss, "allow-data-loss", false, "migrate down even if data would be lost")
+	flags.StringVarP(&configPath, "config", "c", "", "config file to use")
+	flags.StringVar(&options.DatabasePath, "database", "", "database to migrate instead of the configured database")
+
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if options.Version == 0 || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if err := readMigrateConfig(configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "error reading config: %s\n", err.Error())
+		return 1
+	}
+
+	if options.DatabasePath != "" {
+		config.Set(config.Database, options.DatabasePath)
+	}
+	options.DatabasePath = config.GetDatabasePath()
+	options.BackupDirectory = config.GetBackupDirectory()
+
+	if err := database.MigrateTo(options); err != nil {
+		if _, ok := err.(*database.DataLossError); ok {
+			fmt.Fprintf(os.Stderr, "%s\nrun with --allow-data-loss to migrate anyway\n", err.Error())
+		} else {
+			fmt.Fprintf(os.Stderr, "error migrating database: %s\n", err.Error())
+		}
+		return 1
+	}
+
+	fmt.Printf("database %s is at schema version %d\n", options.DatabasePath, database.Version())
+	return 0
+}
+
+// readMigrateConfig reads the config file in the same locations as the
+// server, without creating a config file if none exists.
+func readMigrateConfig(configPath string) error {
+	viper.SetConfigName("config")
+	if configPath != "" {
+		viper.SetConfigFile(configPath)
+	}
+	viper.AddConfigPath(".")
+	viper.AddConfigPath("$HOME/.stash")
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return err
+		}
+	}
+
+	viper.SetDefault(config.Database, paths.GetDefaultDatabaseFilePath())
+	return nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/manager"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// isImageURL returns true if the image input is a URL to fetch the image
+// from, rather than the image data.
+func isImageURL(imageInput string) bool {
+	lower := strings.ToLower(imageInput)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// getImageProcessOptions returns the process options of the provided input.
+func getImageProcessOptions(input *models.ImageProcessInput) (*image.ProcessOptions, error) {
+	ret := &image.ProcessOptions{}
+	if input == nil {
+		return ret, nil
+	}
+
+	if input.Crop != nil {
+		if input.Crop.Width <= 0 || input.Crop.Height <= 0 {
+			return nil, errors.New("crop width and height must be greater than zero")
+		}
+
+		ret.Crop = &image.Crop{
+			X:      input.Crop.X,
+			Y:      input.Crop.Y,
+			Width:  input.Crop.Width,
+			Height: input.Crop.Height,
+		}
+	}
+
+	if input.MaxWidth != nil {
+		if *input.MaxWidth < 0 {
+			return nil, errors.New("max width must not be negative")
+		}
+		ret.MaxWidth = *input.MaxWidth
+	}
+
+	if input.MaxHeight != nil {
+		if *input.MaxHeight < 0 {
+			return nil, errors.New("max height must not be negative")
+		}
+		ret.MaxHeight = *input.MaxHeight
+	}
+
+	return ret, nil
+}
+
+// processImageInput returns the data of a performer, studio or tag image
+// input, processed with the provided options before it is stored. The input
+// may be a URL, which is fetched using the scraper user agent, a data URI or
+// base64 encoded data.
+func processImageInput(imageInput string, options *models.ImageProcessInput) ([]byte, error) {
+	processOptions, err := getImageProcessOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if isImageURL(imageInput) {
+		data, err = manager.GetInstance().ScraperCache.FetchImage(imageInput)
+	} else {
+		_, data, err = utils.ProcessBase64Image(imageInput)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return manager.ProcessStoredImage(data, *processOptions)
+}
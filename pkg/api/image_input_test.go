@@ -0,0 +1,52 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsImageURL(t *testing.T) {
+	assert.True(t, isImageURL("https://example.com/image.jpg"))
+	assert.True(t, isImageURL("HTTP://example.com/image.jpg"))
+	assert.False(t, isImageURL("data:image/png;base64,aW1hZ2U="))
+	assert.False(t, isImageURL("aW1hZ2U="))
+}
+
+func TestGetImageProcessOptions(t *testing.T) {
+	width := 100
+	negative := -1
+
+	tests := []struct {
+		name    string
+		input   *models.ImageProcessInput
+		want    *image.ProcessOptions
+		wantErr bool
+	}{
+		{"nil", nil, &image.ProcessOptions{}, false},
+		{
+			"crop",
+			&models.ImageProcessInput{
+				Crop:     &models.ImageCropInput{X: 1, Y: 2, Width: 3, Height: 4},
+				MaxWidth: &width,
+			},
+			&image.ProcessOptions{
+				Crop:     &image.Crop{X: 1, Y: 2, Width: 3, Height: 4},
+				MaxWidth: 100,
+			},
+			false,
+		},
+		{"empty crop", &models.ImageProcessInput{Crop: &models.ImageCropInput{Width: 0, Height: 4}}, nil, true},
+		{"negative max height", &models.ImageProcessInput{MaxHeight: &negative}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getImageProcessOptions(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
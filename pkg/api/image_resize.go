@@ -0,0 +1,191 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// negotiatedResizeFormats are the formats that are used when accepted by the
+// client and no format is requested, in order of preference. WebP is
+// preferred because it is much faster to encode than AVIF.
+var negotiatedResizeFormats = []image.ResizeFormat{
+	image.ResizeFormatWebP,
+	image.ResizeFormatAVIF,
+}
+
+// hasResizeParams returns true if the request has any of the width, height
+// or format query parameters.
+func hasResizeParams(r *http.Request) bool {
+	query := r.URL.Query()
+	return query.Get("width") != "" || query.Get("height") != "" || query.Get("format") != ""
+}
+
+// getResizeOptions returns the resize options of the width, height, fit and
+// format query parameters of the request, or nil if none of width, height
+// or format are provided. The format is negotiated from the Accept header
+// if it is not provided.
+func getResizeOptions(r *http.Request) (*image.ResizeOptions, error) {
+	if !hasResizeParams(r) {
+		return nil, nil
+	}
+
+	query := r.URL.Query()
+	widthParam := query.Get("width")
+	heightParam := query.Get("height")
+	formatParam := query.Get("format")
+
+	ret := &image.ResizeOptions{
+		Fit:    image.ResizeFitContain,
+		Format: image.ResizeFormat(formatParam),
+	}
+
+	var err error
+	if widthParam != "" {
+		if ret.Width, err = strconv.Atoi(widthParam); err != nil || ret.Width < 0 {
+			return nil, fmt.Errorf("invalid width %q", widthParam)
+		}
+	}
+	if heightParam != "" {
+		if ret.Height, err = strconv.Atoi(heightParam); err != nil || ret.Height < 0 {
+			return nil, fmt.Errorf("invalid height %q", heightParam)
+		}
+	}
+
+	// the fit is only used when both the width and height are provided
+	if fitParam := query.Get("fit"); fitParam != "" && ret.Width > 0 && ret.Height > 0 {
+		ret.Fit = image.ResizeFit(fitParam)
+		if !ret.Fit.IsValid() {
+			return nil, fmt.Errorf("invalid fit %q", fitParam)
+		}
+	}
+
+	if formatParam == "" {
+		ret.Format = negotiateResizeFormat(r.Header.Get("Accept"), manager.ResizeFormatSupported)
+	} else if !ret.Format.IsValid() {
+		return nil, fmt.Errorf("invalid format %q", formatParam)
+	} else if !manager.ResizeFormatSupported(ret.Format) {
+		return nil, fmt.Errorf("%s images are not supported by ffmpeg", formatParam)
+	}
+
+	return ret, nil
+}
+
+// negotiateResizeFormat returns the preferred supported format that is
+// explicitly accepted by the provided Accept header. Returns JPEG if none
+// are accepted.
+func negotiateResizeFormat(accept string, supported func(format image.ResizeFormat) bool) image.ResizeFormat {
+	accepted := make(map[string]bool)
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && kv[0] == "q" {
+				quality, _ = strconv.ParseFloat(kv[1], 64)
+			}
+		}
+
+		accepted[mediaType] = quality > 0
+	}
+
+	for _, format := range negotiatedResizeFormats {
+		if accepted[format.ContentType()] && supported(format) {
+			return format
+		}
+	}
+
+	return image.ResizeFormatJPEG
+}
+
+// serveResizeRequest serves the image resized with the options of the
+// request, using the provided function to resize it. Returns false if the
+// image should not be resized, in which case the original image should be
+// served instead.
+func serveResizeRequest(w http.ResponseWriter, r *http.Request, resize func(options image.ResizeOptions) ([]byte, error)) bool {
+	options, err := getResizeOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return true
+	}
+
+	if options == nil {
+		return false
+	}
+
+	if r.URL.Query().Get("format") == "" {
+		w.Header().Add("Vary", "Accept")
+	}
+
+	data, err := resize(*options)
+	if err != nil {
+		// images that cannot be decoded, such as svg images, are served as is
+		if err != image.ErrNotResizable {
+			logger.Warnf("error resizing image: %s", err.Error())
+		}
+		return false
+	}
+
+	w.Header().Set("Content-Type", options.Format.ContentType())
+	utils.ServeImage(data, w, r)
+	return true
+}
+
+// serveStoredImage serves a performer, studio, tag, movie or scene image,
+// resized if requested by the request. The MD5 checksum of the image is its
+// ETag, so the image is only read if the client does not have it cached,
+// unless it is resized. Returns false if there is no image, in which case
+// the default image should be served instead.
+func serveStoredImage(w http.ResponseWriter, r *http.Request, txnManager models.TransactionManager, getChecksum func(repo models.ReaderRepository) (string, error), getImage func(repo models.ReaderRepository) ([]byte, error)) bool {
+	var checksum string
+	if err := txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
+		var err error
+		checksum, err = getChecksum(repo)
+		return err
+	}); err != nil || checksum == "" {
+		return false
+	}
+
+	if !hasResizeParams(r) && utils.MatchesETag(r, checksum) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	var image []byte
+	if err := txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
+		var err error
+		image, err = getImage(repo)
+		return err
+	}); err != nil {
+		logger.Warnf("error reading image %s: %s", checksum, err.Error())
+		return false
+	}
+
+	if len(image) == 0 {
+		return false
+	}
+
+	serveImageData(image, w, r)
+	return true
+}
+
+// serveImageData serves the image data, resized if requested by the
+// request.
+func serveImageData(data []byte, w http.ResponseWriter, r *http.Request) {
+	if serveResizeRequest(w, r, func(options image.ResizeOptions) ([]byte, error) {
+		return manager.GetResizedImageData(data, options)
+	}) {
+		return
+	}
+
+	utils.ServeImage(data, w, r)
+}
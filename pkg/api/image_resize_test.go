@@ -0,0 +1,35 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateResizeFormat(t *testing.T) {
+	all := func(format image.ResizeFormat) bool {
+		return true
+	}
+	noWebP := func(format image.ResizeFormat) bool {
+		return format != image.ResizeFormatWebP
+	}
+
+	tests := []struct {
+		accept    string
+		supported func(format image.ResizeFormat) bool
+		want      image.ResizeFormat
+	}{
+		{"", all, image.ResizeFormatJPEG},
+		{"*/*", all, image.ResizeFormatJPEG},
+		{"image/avif,image/webp,image/apng,image/*,*/*;q=0.8", all, image.ResizeFormatWebP},
+		{"image/avif,image/webp,*/*", noWebP, image.ResizeFormatAVIF},
+		{"image/webp;q=0, image/avif", all, image.ResizeFormatAVIF},
+		{"IMAGE/WEBP", all, image.ResizeFormatWebP},
+		{"image/webp", noWebP, image.ResizeFormatJPEG},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, negotiateResizeFormat(tt.accept, tt.supported), tt.accept)
+	}
+}
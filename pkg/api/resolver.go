@@ -47,6 +47,14 @@ func (r *Resolver) Tag() models.TagResolver {
 	return &tagResolver{r}
 }
 
+func (r *Resolver) SavedFilter() models.SavedFilterResolver {
+	return &savedFilterResolver{r}
+}
+
+func (r *Resolver) AuditLogEntry() models.AuditLogEntryResolver {
+	return &auditLogEntryResolver{r}
+}
+
 func (r *Resolver) ScrapedSceneTag() models.ScrapedSceneTagResolver {
 	return &scrapedSceneTagResolver{r}
 }
@@ -75,6 +83,8 @@ type imageResolver struct{ *Resolver }
 type studioResolver struct{ *Resolver }
 type movieResolver struct{ *Resolver }
 type tagResolver struct{ *Resolver }
+type savedFilterResolver struct{ *Resolver }
+type auditLogEntryResolver struct{ *Resolver }
 type scrapedSceneTagResolver struct{ *Resolver }
 type scrapedSceneMovieResolver struct{ *Resolver }
 type scrapedScenePerformerResolver struct{ *Resolver }
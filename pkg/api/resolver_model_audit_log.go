@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *auditLogEntryResolver) Field(ctx context.Context, obj *models.AuditLogEntry) (*string, error) {
+	if obj.Field.Valid {
+		return &obj.Field.String, nil
+	}
+	return nil, nil
+}
+
+func (r *auditLogEntryResolver) OldValue(ctx context.Context, obj *models.AuditLogEntry) (*string, error) {
+	if obj.OldValue.Valid {
+		return &obj.OldValue.String, nil
+	}
+	return nil, nil
+}
+
+func (r *auditLogEntryResolver) NewValue(ctx context.Context, obj *models.AuditLogEntry) (*string, error) {
+	if obj.NewValue.Valid {
+		return &obj.NewValue.String, nil
+	}
+	return nil, nil
+}
+
+func (r *auditLogEntryResolver) CreatedAt(ctx context.Context, obj *models.AuditLogEntry) (*time.Time, error) {
+	return &obj.CreatedAt.Timestamp, nil
+}
@@ -45,6 +45,16 @@ func (r *galleryResolver) Cover(ctx context.Context, obj *models.Gallery) (ret *
 			ret = imgs[0]
 		}
 
+		// the chosen cover may no longer be in the gallery
+		if obj.CoverImageID.Valid {
+			for _, img := range imgs {
+				if int64(img.ID) == obj.CoverImageID.Int64 {
+					ret = img
+					return nil
+				}
+			}
+		}
+
 		for _, img := range imgs {
 			if image.IsCover(img) {
 				ret = img
@@ -60,6 +70,18 @@ func (r *galleryResolver) Cover(ctx context.Context, obj *models.Gallery) (ret *
 	return ret, nil
 }
 
+func (r *galleryResolver) Chapters(ctx context.Context, obj *models.Gallery) (ret []*models.GalleryChapter, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		ret, err = repo.Gallery().GetChapters(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
 func (r *galleryResolver) Date(ctx context.Context, obj *models.Gallery) (*string, error) {
 	if obj.Date.Valid {
 		result := utils.GetYMDFromDatabaseDate(obj.Date.String)
@@ -153,3 +175,14 @@ func (r *galleryResolver) ImageCount(ctx context.Context, obj *models.Gallery) (
 
 	return ret, nil
 }
+
+func (r *galleryResolver) CustomFields(ctx context.Context, obj *models.Gallery) (ret map[string]interface{}, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Gallery().GetCustomFields(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -2,10 +2,12 @@ package api
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/stashapp/stash/pkg/api/urlbuilders"
 	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 func (r *imageResolver) Title(ctx context.Context, obj *models.Image) (*string, error) {
@@ -21,15 +23,70 @@ func (r *imageResolver) Rating(ctx context.Context, obj *models.Image) (*int, er
 	return nil, nil
 }
 
+func (r *imageResolver) Date(ctx context.Context, obj *models.Image) (*string, error) {
+	if obj.Date.Valid {
+		result := utils.GetYMDFromDatabaseDate(obj.Date.String)
+		return &result, nil
+	}
+	return nil, nil
+}
+
+func (r *imageResolver) Orientation(ctx context.Context, obj *models.Image) (*int, error) {
+	if obj.Orientation.Valid {
+		orientation := int(obj.Orientation.Int64)
+		return &orientation, nil
+	}
+	return nil, nil
+}
+
+func (r *imageResolver) Camera(ctx context.Context, obj *models.Image) (*string, error) {
+	if obj.Camera.Valid {
+		return &obj.Camera.String, nil
+	}
+	return nil, nil
+}
+
+func (r *imageResolver) Keywords(ctx context.Context, obj *models.Image) (ret []string, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Image().GetKeywords(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *imageResolver) Format(ctx context.Context, obj *models.Image) (*string, error) {
+	if obj.Format.Valid && obj.Format.String != "" {
+		return &obj.Format.String, nil
+	}
+	return nil, nil
+}
+
+func (r *imageResolver) Phash(ctx context.Context, obj *models.Image) (*string, error) {
+	if obj.Phash.Valid {
+		phash := fmt.Sprintf("%016x", uint64(obj.Phash.Int64))
+		return &phash, nil
+	}
+	return nil, nil
+}
+
 func (r *imageResolver) File(ctx context.Context, obj *models.Image) (*models.ImageFileType, error) {
 	width := int(obj.Width.Int64)
 	height := int(obj.Height.Int64)
 	size := int(obj.Size.Int64)
-	return &models.ImageFileType{
+	ret := &models.ImageFileType{
 		Size:   &size,
 		Width:  &width,
 		Height: &height,
-	}, nil
+	}
+
+	if obj.Duration.Valid {
+		ret.Duration = &obj.Duration.Float64
+	}
+
+	return ret, nil
 }
 
 func (r *imageResolver) Paths(ctx context.Context, obj *models.Image) (*models.ImagePathsType, error) {
@@ -91,3 +148,14 @@ func (r *imageResolver) Performers(ctx context.Context, obj *models.Image) (ret
 
 	return ret, nil
 }
+
+func (r *imageResolver) CustomFields(ctx context.Context, obj *models.Image) (ret map[string]interface{}, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Image().GetCustomFields(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
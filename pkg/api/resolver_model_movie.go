@@ -105,3 +105,14 @@ func (r *movieResolver) SceneCount(ctx context.Context, obj *models.Movie) (ret
 
 	return &res, err
 }
+
+func (r *movieResolver) CustomFields(ctx context.Context, obj *models.Movie) (ret map[string]interface{}, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Movie().GetCustomFields(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
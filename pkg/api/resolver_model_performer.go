@@ -138,6 +138,27 @@ func (r *performerResolver) ImagePath(ctx context.Context, obj *models.Performer
 	return &imagePath, nil
 }
 
+func (r *performerResolver) ImagePaths(ctx context.Context, obj *models.Performer) ([]string, error) {
+	var count int
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		count, err = repo.Performer().GetImageCount(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
+	builder := urlbuilders.NewPerformerURLBuilder(baseURL, obj.ID)
+
+	ret := []string{}
+	for i := 0; i < count; i++ {
+		ret = append(ret, builder.GetPerformerImageURLByIndex(i))
+	}
+
+	return ret, nil
+}
+
 func (r *performerResolver) SceneCount(ctx context.Context, obj *models.Performer) (ret *int, err error) {
 	var res int
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
@@ -171,3 +192,14 @@ func (r *performerResolver) StashIds(ctx context.Context, obj *models.Performer)
 
 	return ret, nil
 }
+
+func (r *performerResolver) CustomFields(ctx context.Context, obj *models.Performer) (ret map[string]interface{}, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Performer().GetCustomFields(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *savedFilterResolver) FindFilter(ctx context.Context, obj *models.SavedFilter) (*models.SavedFindFilterType, error) {
+	if !obj.FindFilter.Valid {
+		return nil, nil
+	}
+
+	var ret models.SavedFindFilterType
+	if err := json.Unmarshal([]byte(obj.FindFilter.String), &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (r *savedFilterResolver) ObjectFilter(ctx context.Context, obj *models.SavedFilter) (*string, error) {
+	if obj.ObjectFilter.Valid {
+		return &obj.ObjectFilter.String, nil
+	}
+	return nil, nil
+}
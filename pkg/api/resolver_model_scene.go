@@ -200,3 +200,14 @@ func (r *sceneResolver) StashIds(ctx context.Context, obj *models.Scene) (ret []
 
 	return ret, nil
 }
+
+func (r *sceneResolver) CustomFields(ctx context.Context, obj *models.Scene) (ret map[string]interface{}, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Scene().GetCustomFields(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -90,3 +90,14 @@ func (r *studioResolver) StashIds(ctx context.Context, obj *models.Studio) (ret
 
 	return ret, nil
 }
+
+func (r *studioResolver) CustomFields(ctx context.Context, obj *models.Studio) (ret map[string]interface{}, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Studio().GetCustomFields(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/audit"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *mutationResolver) RevertChange(ctx context.Context, id string) (ret *models.AuditLogEntry, err error) {
+	entryID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		ret, err = audit.Revert(repo, changeSource(ctx), entryID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
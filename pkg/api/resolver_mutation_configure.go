@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/manager/config"
@@ -32,6 +33,23 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input models.Co
 		config.Set(config.Database, input.DatabasePath)
 	}
 
+	if input.BackupInterval != nil {
+		config.Set(config.BackupInterval, *input.BackupInterval)
+	}
+
+	if input.BackupDirectory != nil {
+		if *input.BackupDirectory != "" {
+			if err := utils.EnsureDir(*input.BackupDirectory); err != nil {
+				return makeConfigGeneralResult(), err
+			}
+		}
+		config.Set(config.BackupDirectory, input.BackupDirectory)
+	}
+
+	if input.BackupCount != nil {
+		config.Set(config.BackupCount, *input.BackupCount)
+	}
+
 	if input.GeneratedPath != nil {
 		if err := utils.EnsureDir(*input.GeneratedPath); err != nil {
 			return makeConfigGeneralResult(), err
@@ -140,6 +158,73 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input models.Co
 
 	config.Set(config.CreateGalleriesFromFolders, input.CreateGalleriesFromFolders)
 
+	if input.GalleryFolderRules != nil {
+		if err := config.ValidateGalleryFolderRules(input.GalleryFolderRules); err != nil {
+			return makeConfigGeneralResult(), err
+		}
+		config.Set(config.GalleryFolderRules, input.GalleryFolderRules)
+	}
+
+	if input.ImageKeywordsAsTags != nil {
+		config.Set(config.ImageKeywordsAsTags, *input.ImageKeywordsAsTags)
+	}
+
+	if input.ImageClips != nil {
+		config.Set(config.ImageClips, *input.ImageClips)
+	}
+
+	if input.ImageClipMaxDuration != nil {
+		config.Set(config.ImageClipMaxDuration, *input.ImageClipMaxDuration)
+	}
+
+	if input.ImageThumbnailSizes != nil {
+		for _, size := range input.ImageThumbnailSizes {
+			if size <= 0 {
+				return makeConfigGeneralResult(), fmt.Errorf("invalid image thumbnail size %d", size)
+			}
+		}
+		config.Set(config.ImageThumbnailSizes, input.ImageThumbnailSizes)
+	}
+
+	if input.ImageThumbnailQuality != nil {
+		if *input.ImageThumbnailQuality < 1 || *input.ImageThumbnailQuality > 100 {
+			return makeConfigGeneralResult(), errors.New("image thumbnail quality must be from 1 to 100")
+		}
+		config.Set(config.ImageThumbnailQuality, *input.ImageThumbnailQuality)
+	}
+
+	if input.ImageThumbnailFormat != nil {
+		config.Set(config.ImageThumbnailFormat, input.ImageThumbnailFormat.String())
+	}
+
+	if input.ImageThumbnailFilter != nil {
+		config.Set(config.ImageThumbnailFilter, input.ImageThumbnailFilter.String())
+	}
+
+	if input.ResizedImageCacheSize != nil {
+		config.Set(config.ResizedImageCacheSize, *input.ResizedImageCacheSize)
+	}
+
+	if input.StoredImageFormat != nil {
+		if *input.StoredImageFormat == models.StoredImageFormatWebp && !manager.ResizeFormatSupported(image.ResizeFormatWebP) {
+			return makeConfigGeneralResult(), errors.New("webp images are not supported by ffmpeg")
+		}
+		config.Set(config.StoredImageFormat, input.StoredImageFormat.String())
+	}
+
+	if input.BlobsPath != nil {
+		if *input.BlobsPath != "" {
+			if err := utils.EnsureDir(*input.BlobsPath); err != nil {
+				return makeConfigGeneralResult(), err
+			}
+		}
+		config.Set(config.BlobsPath, input.BlobsPath)
+	}
+
+	if input.BlobsStorage != nil {
+		config.Set(config.BlobsStorage, input.BlobsStorage.String())
+	}
+
 	refreshScraperCache := false
 	if input.ScraperUserAgent != nil {
 		config.Set(config.ScraperUserAgent, input.ScraperUserAgent)
@@ -153,6 +238,14 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input models.Co
 
 	config.Set(config.ScraperCertCheck, input.ScraperCertCheck)
 
+	if input.ScraperPackageIndex != nil {
+		config.Set(config.ScraperPackageIndex, input.ScraperPackageIndex)
+	}
+
+	if input.PluginPackageIndex != nil {
+		config.Set(config.PluginPackageIndex, input.PluginPackageIndex)
+	}
+
 	if input.StashBoxes != nil {
 		if err := config.ValidateStashBoxes(input.StashBoxes); err != nil {
 			return nil, err
@@ -8,6 +8,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stashapp/stash/pkg/audit"
+	"github.com/stashapp/stash/pkg/gallery"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
@@ -63,29 +65,38 @@ func (r *mutationResolver) GalleryCreate(ctx context.Context, input models.Galle
 	// Start the transaction and save the gallery
 	var gallery *models.Gallery
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Gallery()
-		var err error
-		gallery, err = qb.Create(newGallery)
-		if err != nil {
-			return err
-		}
+		return audit.Create(repo, changeSource(ctx), models.AuditEntityEnumGallery, func() (int, error) {
+			qb := repo.Gallery()
+			var err error
+			gallery, err = qb.Create(newGallery)
+			if err != nil {
+				return 0, err
+			}
 
-		// Save the performers
-		if err := r.updateGalleryPerformers(qb, gallery.ID, input.PerformerIds); err != nil {
-			return err
-		}
+			// Save the performers
+			if err := r.updateGalleryPerformers(qb, gallery.ID, input.PerformerIds); err != nil {
+				return 0, err
+			}
 
-		// Save the tags
-		if err := r.updateGalleryTags(qb, gallery.ID, input.TagIds); err != nil {
-			return err
-		}
+			// Save the tags
+			if err := r.updateGalleryTags(qb, gallery.ID, input.TagIds); err != nil {
+				return 0, err
+			}
 
-		// Save the scenes
-		if err := r.updateGalleryScenes(qb, gallery.ID, input.SceneIds); err != nil {
-			return err
-		}
+			// Save the scenes
+			if err := r.updateGalleryScenes(qb, gallery.ID, input.SceneIds); err != nil {
+				return 0, err
+			}
 
-		return nil
+			// Save the custom fields
+			if input.CustomFields != nil {
+				if err := qb.UpdateCustomFields(gallery.ID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+					return 0, err
+				}
+			}
+
+			return gallery.ID, nil
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -122,10 +133,17 @@ func (r *mutationResolver) GalleryUpdate(ctx context.Context, input models.Galle
 		inputMap: getUpdateInputMap(ctx),
 	}
 
+	galleryID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start the transaction and save the gallery
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		ret, err = r.galleryUpdate(input, translator, repo)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumGallery, galleryID, func() error {
+			ret, err = r.galleryUpdate(input, translator, repo)
+			return err
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -143,11 +161,19 @@ func (r *mutationResolver) GalleriesUpdate(ctx context.Context, input []*models.
 				inputMap: inputMaps[i],
 			}
 
-			thisGallery, err := r.galleryUpdate(*gallery, translator, repo)
+			galleryID, err := strconv.Atoi(gallery.ID)
 			if err != nil {
 				return err
 			}
 
+			var thisGallery *models.Gallery
+			if err := audit.Update(repo, changeSource(ctx), models.AuditEntityEnumGallery, galleryID, func() error {
+				thisGallery, err = r.galleryUpdate(*gallery, translator, repo)
+				return err
+			}); err != nil {
+				return err
+			}
+
 			ret = append(ret, thisGallery)
 		}
 
@@ -207,7 +233,7 @@ func (r *mutationResolver) galleryUpdate(input models.GalleryUpdateInput, transl
 
 	// gallery scene is set from the scene only
 
-	gallery, err := qb.UpdatePartial(updatedGallery)
+	updated, err := qb.UpdatePartial(updatedGallery)
 	if err != nil {
 		return nil, err
 	}
@@ -233,7 +259,26 @@ func (r *mutationResolver) galleryUpdate(input models.GalleryUpdateInput, transl
 		}
 	}
 
-	return gallery, nil
+	// Save the chapters
+	if translator.hasField("chapters") {
+		chapters, err := gallery.ChaptersFromInput(input.Chapters)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qb.UpdateChapters(galleryID, chapters); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save the custom fields
+	if translator.hasField("custom_fields") {
+		if err := qb.UpdateCustomFields(galleryID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+			return nil, err
+		}
+	}
+
+	return updated, nil
 }
 
 func (r *mutationResolver) BulkGalleryUpdate(ctx context.Context, input models.BulkGalleryUpdateInput) ([]*models.Gallery, error) {
@@ -259,53 +304,19 @@ func (r *mutationResolver) BulkGalleryUpdate(ctx context.Context, input models.B
 
 	// Start the transaction and save the galleries
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Gallery()
-
 		for _, galleryIDStr := range input.Ids {
 			galleryID, _ := strconv.Atoi(galleryIDStr)
-			updatedGallery.ID = galleryID
-
-			gallery, err := qb.UpdatePartial(updatedGallery)
-			if err != nil {
-				return err
-			}
-
-			ret = append(ret, gallery)
-
-			// Save the performers
-			if translator.hasField("performer_ids") {
-				performerIDs, err := adjustGalleryPerformerIDs(qb, galleryID, *input.PerformerIds)
-				if err != nil {
-					return err
-				}
-
-				if err := qb.UpdatePerformers(galleryID, performerIDs); err != nil {
-					return err
-				}
-			}
 
-			// Save the tags
-			if translator.hasField("tag_ids") {
-				tagIDs, err := adjustGalleryTagIDs(qb, galleryID, *input.TagIds)
+			if err := audit.Update(repo, changeSource(ctx), models.AuditEntityEnumGallery, galleryID, func() error {
+				gallery, err := bulkGalleryUpdate(repo.Gallery(), galleryID, updatedGallery, input, translator)
 				if err != nil {
 					return err
 				}
 
-				if err := qb.UpdateTags(galleryID, tagIDs); err != nil {
-					return err
-				}
-			}
-
-			// Save the scenes
-			if translator.hasField("scene_ids") {
-				sceneIDs, err := adjustGallerySceneIDs(qb, galleryID, *input.SceneIds)
-				if err != nil {
-					return err
-				}
-
-				if err := qb.UpdateScenes(galleryID, sceneIDs); err != nil {
-					return err
-				}
+				ret = append(ret, gallery)
+				return nil
+			}); err != nil {
+				return err
 			}
 		}
 
@@ -317,6 +328,60 @@ func (r *mutationResolver) BulkGalleryUpdate(ctx context.Context, input models.B
 	return ret, nil
 }
 
+func bulkGalleryUpdate(qb models.GalleryReaderWriter, galleryID int, updatedGallery models.GalleryPartial, input models.BulkGalleryUpdateInput, translator changesetTranslator) (*models.Gallery, error) {
+	updatedGallery.ID = galleryID
+
+	gallery, err := qb.UpdatePartial(updatedGallery)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the performers
+	if translator.hasField("performer_ids") {
+		performerIDs, err := adjustGalleryPerformerIDs(qb, galleryID, *input.PerformerIds)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qb.UpdatePerformers(galleryID, performerIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save the tags
+	if translator.hasField("tag_ids") {
+		tagIDs, err := adjustGalleryTagIDs(qb, galleryID, *input.TagIds)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qb.UpdateTags(galleryID, tagIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save the scenes
+	if translator.hasField("scene_ids") {
+		sceneIDs, err := adjustGallerySceneIDs(qb, galleryID, *input.SceneIds)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qb.UpdateScenes(galleryID, sceneIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save the custom fields
+	if translator.hasField("custom_fields") {
+		if err := updateCustomFields(qb, galleryID, input.CustomFields); err != nil {
+			return nil, err
+		}
+	}
+
+	return gallery, nil
+}
+
 func adjustGalleryPerformerIDs(qb models.GalleryReader, galleryID int, ids models.BulkUpdateIds) (ret []int, err error) {
 	ret, err = qb.GetPerformerIDs(galleryID)
 	if err != nil {
@@ -378,7 +443,9 @@ func (r *mutationResolver) GalleryDestroy(ctx context.Context, input models.Gall
 				}
 
 				for _, img := range imgs {
-					if err := iqb.Destroy(img.ID); err != nil {
+					if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumImage, img.ID, func() error {
+						return iqb.Destroy(img.ID)
+					}); err != nil {
 						return err
 					}
 
@@ -398,7 +465,9 @@ func (r *mutationResolver) GalleryDestroy(ctx context.Context, input models.Gall
 					}
 
 					if len(imgGalleries) == 0 {
-						if err := iqb.Destroy(img.ID); err != nil {
+						if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumImage, img.ID, func() error {
+							return iqb.Destroy(img.ID)
+						}); err != nil {
 							return err
 						}
 
@@ -408,7 +477,9 @@ func (r *mutationResolver) GalleryDestroy(ctx context.Context, input models.Gall
 				}
 			}
 
-			if err := qb.Destroy(id); err != nil {
+			if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumGallery, id, func() error {
+				return qb.Destroy(id)
+			}); err != nil {
 				return err
 			}
 		}
@@ -473,7 +544,9 @@ func (r *mutationResolver) AddGalleryImages(ctx context.Context, input models.Ga
 		}
 
 		newIDs = utils.IntAppendUniques(newIDs, imageIDs)
-		return qb.UpdateImages(galleryID, newIDs)
+		return audit.UpdateAll(repo, changeSource(ctx), models.AuditEntityEnumImage, imageIDs, func() error {
+			return qb.UpdateImages(galleryID, newIDs)
+		})
 	}); err != nil {
 		return false, err
 	}
@@ -513,7 +586,78 @@ func (r *mutationResolver) RemoveGalleryImages(ctx context.Context, input models
 		}
 
 		newIDs = utils.IntExclude(newIDs, imageIDs)
-		return qb.UpdateImages(galleryID, newIDs)
+		return audit.UpdateAll(repo, changeSource(ctx), models.AuditEntityEnumImage, imageIDs, func() error {
+			return qb.UpdateImages(galleryID, newIDs)
+		})
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) GalleryReorderImages(ctx context.Context, galleryID string, imageIds []string) (bool, error) {
+	id, err := strconv.Atoi(galleryID)
+	if err != nil {
+		return false, err
+	}
+
+	imageIDs, err := utils.StringSliceToIntSlice(imageIds)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Gallery()
+		g, err := qb.Find(id)
+		if err != nil {
+			return err
+		}
+
+		if g == nil {
+			return errors.New("gallery not found")
+		}
+
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumGallery, id, func() error {
+			return gallery.SetImageOrder(qb, id, imageIDs)
+		})
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) GallerySetCover(ctx context.Context, galleryID string, imageID *string) (bool, error) {
+	id, err := strconv.Atoi(galleryID)
+	if err != nil {
+		return false, err
+	}
+
+	var coverID *int
+	if imageID != nil {
+		v, err := strconv.Atoi(*imageID)
+		if err != nil {
+			return false, err
+		}
+		coverID = &v
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Gallery()
+		g, err := qb.Find(id)
+		if err != nil {
+			return err
+		}
+
+		if g == nil {
+			return errors.New("gallery not found")
+		}
+
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumGallery, id, func() error {
+			_, err := gallery.SetCover(qb, id, coverID)
+			return err
+		})
 	}); err != nil {
 		return false, err
 	}
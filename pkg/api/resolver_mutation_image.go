@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stashapp/stash/pkg/audit"
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
@@ -16,10 +18,17 @@ func (r *mutationResolver) ImageUpdate(ctx context.Context, input models.ImageUp
 		inputMap: getUpdateInputMap(ctx),
 	}
 
+	imageID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start the transaction and save the image
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		ret, err = r.imageUpdate(input, translator, repo)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumImage, imageID, func() error {
+			ret, err = r.imageUpdate(input, translator, repo)
+			return err
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -37,11 +46,19 @@ func (r *mutationResolver) ImagesUpdate(ctx context.Context, input []*models.Ima
 				inputMap: inputMaps[i],
 			}
 
-			thisImage, err := r.imageUpdate(*image, translator, repo)
+			imageID, err := strconv.Atoi(image.ID)
 			if err != nil {
 				return err
 			}
 
+			var thisImage *models.Image
+			if err := audit.Update(repo, changeSource(ctx), models.AuditEntityEnumImage, imageID, func() error {
+				thisImage, err = r.imageUpdate(*image, translator, repo)
+				return err
+			}); err != nil {
+				return err
+			}
+
 			ret = append(ret, thisImage)
 		}
 
@@ -97,6 +114,13 @@ func (r *mutationResolver) imageUpdate(input models.ImageUpdateInput, translator
 		}
 	}
 
+	// Save the custom fields
+	if translator.hasField("custom_fields") {
+		if err := qb.UpdateCustomFields(imageID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+			return nil, err
+		}
+	}
+
 	return image, nil
 }
 
@@ -148,61 +172,80 @@ func (r *mutationResolver) BulkImageUpdate(ctx context.Context, input models.Bul
 
 	// Start the transaction and save the image marker
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Image()
-
 		for _, imageID := range imageIDs {
-			updatedImage.ID = imageID
+			if err := audit.Update(repo, changeSource(ctx), models.AuditEntityEnumImage, imageID, func() error {
+				image, err := bulkImageUpdate(repo.Image(), imageID, updatedImage, input, translator)
+				if err != nil {
+					return err
+				}
 
-			image, err := qb.Update(updatedImage)
-			if err != nil {
+				ret = append(ret, image)
+				return nil
+			}); err != nil {
 				return err
 			}
+		}
 
-			ret = append(ret, image)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
-			// Save the galleries
-			if translator.hasField("gallery_ids") {
-				galleryIDs, err := adjustImageGalleryIDs(qb, imageID, *input.GalleryIds)
-				if err != nil {
-					return err
-				}
+	return ret, nil
+}
 
-				if err := qb.UpdateGalleries(imageID, galleryIDs); err != nil {
-					return err
-				}
-			}
+func bulkImageUpdate(qb models.ImageReaderWriter, imageID int, updatedImage models.ImagePartial, input models.BulkImageUpdateInput, translator changesetTranslator) (*models.Image, error) {
+	updatedImage.ID = imageID
 
-			// Save the performers
-			if translator.hasField("performer_ids") {
-				performerIDs, err := adjustImagePerformerIDs(qb, imageID, *input.PerformerIds)
-				if err != nil {
-					return err
-				}
+	image, err := qb.Update(updatedImage)
+	if err != nil {
+		return nil, err
+	}
 
-				if err := qb.UpdatePerformers(imageID, performerIDs); err != nil {
-					return err
-				}
-			}
+	// Save the galleries
+	if translator.hasField("gallery_ids") {
+		galleryIDs, err := adjustImageGalleryIDs(qb, imageID, *input.GalleryIds)
+		if err != nil {
+			return nil, err
+		}
 
-			// Save the tags
-			if translator.hasField("tag_ids") {
-				tagIDs, err := adjustImageTagIDs(qb, imageID, *input.TagIds)
-				if err != nil {
-					return err
-				}
+		if err := qb.UpdateGalleries(imageID, galleryIDs); err != nil {
+			return nil, err
+		}
+	}
 
-				if err := qb.UpdateTags(imageID, tagIDs); err != nil {
-					return err
-				}
-			}
+	// Save the performers
+	if translator.hasField("performer_ids") {
+		performerIDs, err := adjustImagePerformerIDs(qb, imageID, *input.PerformerIds)
+		if err != nil {
+			return nil, err
 		}
 
-		return nil
-	}); err != nil {
-		return nil, err
+		if err := qb.UpdatePerformers(imageID, performerIDs); err != nil {
+			return nil, err
+		}
 	}
 
-	return ret, nil
+	// Save the tags
+	if translator.hasField("tag_ids") {
+		tagIDs, err := adjustImageTagIDs(qb, imageID, *input.TagIds)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qb.UpdateTags(imageID, tagIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save the custom fields
+	if translator.hasField("custom_fields") {
+		if err := updateCustomFields(qb, imageID, input.CustomFields); err != nil {
+			return nil, err
+		}
+	}
+
+	return image, nil
 }
 
 func adjustImageGalleryIDs(qb models.ImageReader, imageID int, ids models.BulkUpdateIds) (ret []int, err error) {
@@ -251,7 +294,9 @@ func (r *mutationResolver) ImageDestroy(ctx context.Context, input models.ImageD
 			return fmt.Errorf("image with id %d not found", imageID)
 		}
 
-		return qb.Destroy(imageID)
+		return audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumImage, imageID, func() error {
+			return qb.Destroy(imageID)
+		})
 	}); err != nil {
 		return false, err
 	}
@@ -293,7 +338,9 @@ func (r *mutationResolver) ImagesDestroy(ctx context.Context, input models.Image
 			}
 
 			images = append(images, image)
-			if err := qb.Destroy(imageID); err != nil {
+			if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumImage, imageID, func() error {
+				return qb.Destroy(imageID)
+			}); err != nil {
 				return err
 			}
 		}
@@ -320,6 +367,86 @@ func (r *mutationResolver) ImagesDestroy(ctx context.Context, input models.Image
 	return true, nil
 }
 
+func (r *mutationResolver) ImagesMerge(ctx context.Context, input models.ImagesMergeInput) (ret *models.Image, err error) {
+	destinationID, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceIDs, err := utils.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []*models.Image
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Image()
+
+		destination, err := qb.Find(destinationID)
+		if err != nil {
+			return err
+		}
+
+		if destination == nil {
+			return fmt.Errorf("image with id %d not found", destinationID)
+		}
+
+		for _, sourceID := range sourceIDs {
+			if sourceID == destinationID {
+				return fmt.Errorf("cannot merge image %d into itself", sourceID)
+			}
+
+			source, err := qb.Find(sourceID)
+			if err != nil {
+				return err
+			}
+
+			if source == nil {
+				return fmt.Errorf("image with id %d not found", sourceID)
+			}
+
+			sources = append(sources, source)
+		}
+
+		if err := audit.Update(repo, changeSource(ctx), models.AuditEntityEnumImage, destinationID, func() error {
+			ret, err = image.Merge(qb, destination, sources)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		// the gallery covers and order of the sources are lost when they are
+		// destroyed
+		if err := image.MergeGalleries(repo.Gallery(), destinationID, sources); err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumImage, source.ID, func() error {
+				return qb.Destroy(source.ID)
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, source := range sources {
+		manager.DeleteGeneratedImageFiles(source)
+
+		// source files that are not deleted are skipped by the scan, since
+		// their checksums are recorded against the destination
+		if input.DeleteFile != nil && *input.DeleteFile {
+			manager.DeleteImageFile(source)
+		}
+	}
+
+	return ret, nil
+}
+
 func (r *mutationResolver) ImageIncrementO(ctx context.Context, id string) (ret int, err error) {
 	imageID, err := strconv.Atoi(id)
 	if err != nil {
@@ -327,10 +454,10 @@ func (r *mutationResolver) ImageIncrementO(ctx context.Context, id string) (ret
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Image()
-
-		ret, err = qb.IncrementOCounter(imageID)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumImage, imageID, func() error {
+			ret, err = repo.Image().IncrementOCounter(imageID)
+			return err
+		})
 	}); err != nil {
 		return 0, err
 	}
@@ -345,10 +472,10 @@ func (r *mutationResolver) ImageDecrementO(ctx context.Context, id string) (ret
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Image()
-
-		ret, err = qb.DecrementOCounter(imageID)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumImage, imageID, func() error {
+			ret, err = repo.Image().DecrementOCounter(imageID)
+			return err
+		})
 	}); err != nil {
 		return 0, err
 	}
@@ -363,10 +490,10 @@ func (r *mutationResolver) ImageResetO(ctx context.Context, id string) (ret int,
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Image()
-
-		ret, err = qb.ResetOCounter(imageID)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumImage, imageID, func() error {
+			ret, err = repo.Image().ResetOCounter(imageID)
+			return err
+		})
 	}); err != nil {
 		return 0, err
 	}
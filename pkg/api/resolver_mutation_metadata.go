@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"time"
@@ -79,11 +80,28 @@ func (r *mutationResolver) MetadataClean(ctx context.Context, input models.Clean
 	return "todo", nil
 }
 
+func (r *mutationResolver) MetadataCheckIntegrity(ctx context.Context, repair *bool) (string, error) {
+	manager.GetInstance().CheckIntegrity(repair != nil && *repair)
+	return "todo", nil
+}
+
 func (r *mutationResolver) MigrateHashNaming(ctx context.Context) (string, error) {
 	manager.GetInstance().MigrateHash()
 	return "todo", nil
 }
 
+func (r *mutationResolver) MigrateBlobs(ctx context.Context) (string, error) {
+	manager.GetInstance().MigrateBlobs()
+	return "todo", nil
+}
+
+func (r *mutationResolver) ApplyGalleryFolderRules(ctx context.Context) (string, error) {
+	if err := manager.GetInstance().ApplyGalleryFolderRules(); err != nil {
+		return "", err
+	}
+	return "todo", nil
+}
+
 func (r *mutationResolver) JobStatus(ctx context.Context) (*models.MetadataUpdateStatus, error) {
 	status := manager.GetInstance().Status
 	ret := models.MetadataUpdateStatus{
@@ -114,7 +132,11 @@ func (r *mutationResolver) BackupDatabase(ctx context.Context, input models.Back
 		backupPath = f.Name()
 		f.Close()
 	} else {
-		backupPath = database.DatabaseBackupPath()
+		backupDir := config.GetBackupDirectory()
+		if err := utils.EnsureDir(backupDir); err != nil {
+			return nil, err
+		}
+		backupPath = database.DatabaseBackupPathInDir(backupDir)
 	}
 
 	err := database.Backup(database.DB, backupPath)
@@ -137,3 +159,30 @@ func (r *mutationResolver) BackupDatabase(ctx context.Context, input models.Back
 
 	return nil, nil
 }
+
+func (r *mutationResolver) RestoreDatabase(ctx context.Context, backup string) (bool, error) {
+	// only allow restoring backups listed in the backup directory
+	backups, err := database.ListBackups(config.GetBackupDirectory())
+	if err != nil {
+		return false, err
+	}
+
+	var backupPath string
+	for _, b := range backups {
+		if filepath.Base(b.Path) == backup {
+			backupPath = b.Path
+			break
+		}
+	}
+
+	if backupPath == "" {
+		return false, fmt.Errorf("backup %s not found", backup)
+	}
+
+	if err := manager.GetInstance().RestoreDatabase(backupPath); err != nil {
+		return false, err
+	}
+
+	logger.Infof("Restored database from backup: %s", backupPath)
+	return true, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stashapp/stash/pkg/audit"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
 )
@@ -86,20 +87,29 @@ func (r *mutationResolver) MovieCreate(ctx context.Context, input models.MovieCr
 	// Start the transaction and save the movie
 	var movie *models.Movie
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Movie()
-		movie, err = qb.Create(newMovie)
-		if err != nil {
-			return err
-		}
+		return audit.Create(repo, changeSource(ctx), models.AuditEntityEnumMovie, func() (int, error) {
+			qb := repo.Movie()
+			movie, err = qb.Create(newMovie)
+			if err != nil {
+				return 0, err
+			}
 
-		// update image table
-		if len(frontimageData) > 0 {
-			if err := qb.UpdateImages(movie.ID, frontimageData, backimageData); err != nil {
-				return err
+			// update image table
+			if len(frontimageData) > 0 {
+				if err := qb.UpdateImages(movie.ID, frontimageData, backimageData); err != nil {
+					return 0, err
+				}
 			}
-		}
 
-		return nil
+			// Save the custom fields
+			if input.CustomFields != nil {
+				if err := qb.UpdateCustomFields(movie.ID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+					return 0, err
+				}
+			}
+
+			return movie.ID, nil
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -159,46 +169,55 @@ func (r *mutationResolver) MovieUpdate(ctx context.Context, input models.MovieUp
 	// Start the transaction and save the movie
 	var movie *models.Movie
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Movie()
-		movie, err = qb.Update(updatedMovie)
-		if err != nil {
-			return err
-		}
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumMovie, movieID, func() error {
+			qb := repo.Movie()
+			movie, err = qb.Update(updatedMovie)
+			if err != nil {
+				return err
+			}
 
-		// update image table
-		if frontImageIncluded || backImageIncluded {
-			if !frontImageIncluded {
-				frontimageData, err = qb.GetFrontImage(updatedMovie.ID)
-				if err != nil {
-					return err
+			// update image table
+			if frontImageIncluded || backImageIncluded {
+				if !frontImageIncluded {
+					frontimageData, err = qb.GetFrontImage(updatedMovie.ID)
+					if err != nil {
+						return err
+					}
 				}
-			}
-			if !backImageIncluded {
-				backimageData, err = qb.GetBackImage(updatedMovie.ID)
-				if err != nil {
-					return err
+				if !backImageIncluded {
+					backimageData, err = qb.GetBackImage(updatedMovie.ID)
+					if err != nil {
+						return err
+					}
 				}
-			}
 
-			if len(frontimageData) == 0 && len(backimageData) == 0 {
-				// both images are being nulled. Destroy them.
-				if err := qb.DestroyImages(movie.ID); err != nil {
-					return err
-				}
-			} else {
-				// HACK - if front image is null and back image is not null, then set the front image
-				// to the default image since we can't have a null front image and a non-null back image
-				if frontimageData == nil && backimageData != nil {
-					_, frontimageData, _ = utils.ProcessBase64Image(models.DefaultMovieImage)
+				if len(frontimageData) == 0 && len(backimageData) == 0 {
+					// both images are being nulled. Destroy them.
+					if err := qb.DestroyImages(movie.ID); err != nil {
+						return err
+					}
+				} else {
+					// HACK - if front image is null and back image is not null, then set the front image
+					// to the default image since we can't have a null front image and a non-null back image
+					if frontimageData == nil && backimageData != nil {
+						_, frontimageData, _ = utils.ProcessBase64Image(models.DefaultMovieImage)
+					}
+
+					if err := qb.UpdateImages(movie.ID, frontimageData, backimageData); err != nil {
+						return err
+					}
 				}
+			}
 
-				if err := qb.UpdateImages(movie.ID, frontimageData, backimageData); err != nil {
+			// Save the custom fields
+			if translator.hasField("custom_fields") {
+				if err := qb.UpdateCustomFields(movie.ID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
 					return err
 				}
 			}
-		}
 
-		return nil
+			return nil
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -213,7 +232,9 @@ func (r *mutationResolver) MovieDestroy(ctx context.Context, input models.MovieD
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		return repo.Movie().Destroy(id)
+		return audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumMovie, id, func() error {
+			return repo.Movie().Destroy(id)
+		})
 	}); err != nil {
 		return false, err
 	}
@@ -229,7 +250,9 @@ func (r *mutationResolver) MoviesDestroy(ctx context.Context, movieIDs []string)
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Movie()
 		for _, id := range ids {
-			if err := qb.Destroy(id); err != nil {
+			if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumMovie, id, func() error {
+				return qb.Destroy(id)
+			}); err != nil {
 				return err
 			}
 		}
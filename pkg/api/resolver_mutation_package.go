@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/manager"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/pkgmgr"
+)
+
+func changePackages(packageType models.PackageType, fn func(pm *pkgmgr.Manager) error) (bool, error) {
+	pm, err := manager.GetInstance().GetPackageManager(packageType)
+	if err != nil {
+		return false, err
+	}
+
+	err = fn(pm)
+
+	// reload even if there was an error, since some packages may have
+	// been changed
+	if reloadErr := manager.GetInstance().ReloadPackages(packageType); reloadErr != nil && err == nil {
+		err = reloadErr
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) InstallPackages(ctx context.Context, typeArg models.PackageType, ids []string) (bool, error) {
+	return changePackages(typeArg, func(pm *pkgmgr.Manager) error {
+		return pm.Install(ids)
+	})
+}
+
+func (r *mutationResolver) UpdatePackages(ctx context.Context, typeArg models.PackageType, ids []string) (bool, error) {
+	return changePackages(typeArg, func(pm *pkgmgr.Manager) error {
+		return pm.Update(ids)
+	})
+}
+
+func (r *mutationResolver) UninstallPackages(ctx context.Context, typeArg models.PackageType, ids []string) (bool, error) {
+	return changePackages(typeArg, func(pm *pkgmgr.Manager) error {
+		return pm.Uninstall(ids)
+	})
+}
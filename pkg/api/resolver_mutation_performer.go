@@ -3,9 +3,11 @@ package api
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/stashapp/stash/pkg/audit"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
 )
@@ -18,7 +20,7 @@ func (r *mutationResolver) PerformerCreate(ctx context.Context, input models.Per
 	var err error
 
 	if input.Image != nil {
-		_, imageData, err = utils.ProcessBase64Image(*input.Image)
+		imageData, err = processImageInput(*input.Image, input.ImageOptions)
 	}
 
 	if err != nil {
@@ -87,29 +89,38 @@ func (r *mutationResolver) PerformerCreate(ctx context.Context, input models.Per
 	// Start the transaction and save the performer
 	var performer *models.Performer
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Performer()
+		return audit.Create(repo, changeSource(ctx), models.AuditEntityEnumPerformer, func() (int, error) {
+			qb := repo.Performer()
 
-		performer, err = qb.Create(newPerformer)
-		if err != nil {
-			return err
-		}
+			performer, err = qb.Create(newPerformer)
+			if err != nil {
+				return 0, err
+			}
 
-		// update image table
-		if len(imageData) > 0 {
-			if err := qb.UpdateImage(performer.ID, imageData); err != nil {
-				return err
+			// update image table
+			if len(imageData) > 0 {
+				if err := qb.UpdateImage(performer.ID, imageData); err != nil {
+					return 0, err
+				}
 			}
-		}
 
-		// Save the stash_ids
-		if input.StashIds != nil {
-			stashIDJoins := models.StashIDsFromInput(input.StashIds)
-			if err := qb.UpdateStashIDs(performer.ID, stashIDJoins); err != nil {
-				return err
+			// Save the stash_ids
+			if input.StashIds != nil {
+				stashIDJoins := models.StashIDsFromInput(input.StashIds)
+				if err := qb.UpdateStashIDs(performer.ID, stashIDJoins); err != nil {
+					return 0, err
+				}
 			}
-		}
 
-		return nil
+			// Save the custom fields
+			if input.CustomFields != nil {
+				if err := qb.UpdateCustomFields(performer.ID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+					return 0, err
+				}
+			}
+
+			return performer.ID, nil
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -133,7 +144,7 @@ func (r *mutationResolver) PerformerUpdate(ctx context.Context, input models.Per
 	var err error
 	imageIncluded := translator.hasField("image")
 	if input.Image != nil {
-		_, imageData, err = utils.ProcessBase64Image(*input.Image)
+		imageData, err = processImageInput(*input.Image, input.ImageOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -175,35 +186,133 @@ func (r *mutationResolver) PerformerUpdate(ctx context.Context, input models.Per
 	// Start the transaction and save the performer
 	var performer *models.Performer
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Performer()
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumPerformer, performerID, func() error {
+			qb := repo.Performer()
 
-		var err error
-		performer, err = qb.Update(updatedPerformer)
-		if err != nil {
-			return err
+			var err error
+			performer, err = qb.Update(updatedPerformer)
+			if err != nil {
+				return err
+			}
+
+			// update image table
+			if len(imageData) > 0 {
+				if err := qb.UpdateImage(performer.ID, imageData); err != nil {
+					return err
+				}
+			} else if imageIncluded {
+				// must be unsetting
+				if err := qb.DestroyImage(performer.ID); err != nil {
+					return err
+				}
+			}
+
+			// Save the stash_ids
+			if translator.hasField("stash_ids") {
+				stashIDJoins := models.StashIDsFromInput(input.StashIds)
+				if err := qb.UpdateStashIDs(performerID, stashIDJoins); err != nil {
+					return err
+				}
+			}
+
+			// Save the custom fields
+			if translator.hasField("custom_fields") {
+				if err := qb.UpdateCustomFields(performerID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return performer, nil
+}
+
+func (r *mutationResolver) PerformerImageAdd(ctx context.Context, input models.PerformerImageAddInput) (*models.Performer, error) {
+	imageData, err := processImageInput(input.Image, input.ImageOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.changePerformerImages(ctx, input.PerformerID, func(images [][]byte) ([][]byte, error) {
+		if input.Primary != nil && *input.Primary {
+			return append([][]byte{imageData}, images...), nil
+		}
+
+		return append(images, imageData), nil
+	})
+}
+
+func (r *mutationResolver) PerformerImageRemove(ctx context.Context, input models.PerformerImageRemoveInput) (*models.Performer, error) {
+	return r.changePerformerImages(ctx, input.PerformerID, func(images [][]byte) ([][]byte, error) {
+		if input.Index < 0 || input.Index >= len(images) {
+			return nil, fmt.Errorf("image index %d out of range", input.Index)
+		}
+
+		return append(images[:input.Index], images[input.Index+1:]...), nil
+	})
+}
+
+func (r *mutationResolver) PerformerImagesReorder(ctx context.Context, input models.PerformerImagesReorderInput) (*models.Performer, error) {
+	return r.changePerformerImages(ctx, input.PerformerID, func(images [][]byte) ([][]byte, error) {
+		if len(input.Indexes) != len(images) {
+			return nil, fmt.Errorf("expected %d image indexes, got %d", len(images), len(input.Indexes))
+		}
+
+		var ret [][]byte
+		used := make(map[int]bool)
+		for _, index := range input.Indexes {
+			if index < 0 || index >= len(images) {
+				return nil, fmt.Errorf("image index %d out of range", index)
+			}
+			if used[index] {
+				return nil, fmt.Errorf("image index %d included more than once", index)
+			}
+
+			used[index] = true
+			ret = append(ret, images[index])
 		}
 
-		// update image table
-		if len(imageData) > 0 {
-			if err := qb.UpdateImage(performer.ID, imageData); err != nil {
+		return ret, nil
+	})
+}
+
+// changePerformerImages replaces the images of the performer with the images
+// returned by the change function.
+func (r *mutationResolver) changePerformerImages(ctx context.Context, id string, change func(images [][]byte) ([][]byte, error)) (*models.Performer, error) {
+	performerID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var performer *models.Performer
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Performer()
+
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumPerformer, performerID, func() error {
+			images, err := qb.GetImages(performerID)
+			if err != nil {
 				return err
 			}
-		} else if imageIncluded {
-			// must be unsetting
-			if err := qb.DestroyImage(performer.ID); err != nil {
+
+			images, err = change(images)
+			if err != nil {
 				return err
 			}
-		}
 
-		// Save the stash_ids
-		if translator.hasField("stash_ids") {
-			stashIDJoins := models.StashIDsFromInput(input.StashIds)
-			if err := qb.UpdateStashIDs(performerID, stashIDJoins); err != nil {
+			if err := qb.UpdateImages(performerID, images); err != nil {
 				return err
 			}
-		}
 
-		return nil
+			performer, err = qb.Update(models.PerformerPartial{
+				ID:        performerID,
+				UpdatedAt: &models.SQLiteTimestamp{Timestamp: time.Now()},
+			})
+			return err
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -218,7 +327,9 @@ func (r *mutationResolver) PerformerDestroy(ctx context.Context, input models.Pe
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		return repo.Performer().Destroy(id)
+		return audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumPerformer, id, func() error {
+			return repo.Performer().Destroy(id)
+		})
 	}); err != nil {
 		return false, err
 	}
@@ -234,7 +345,9 @@ func (r *mutationResolver) PerformersDestroy(ctx context.Context, performerIDs [
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Performer()
 		for _, id := range ids {
-			if err := qb.Destroy(id); err != nil {
+			if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumPerformer, id, func() error {
+				return qb.Destroy(id)
+			}); err != nil {
 				return err
 			}
 		}
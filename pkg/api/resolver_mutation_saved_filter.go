@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *mutationResolver) SaveFilter(ctx context.Context, input models.SaveFilterInput) (ret *models.SavedFilter, err error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, errors.New("name must not be empty")
+	}
+
+	newFilter, err := newSavedFilter(input.Mode, input.FindFilter, input.ObjectFilter)
+	if err != nil {
+		return nil, err
+	}
+	newFilter.Name = input.Name
+
+	if input.ID != nil {
+		newFilter.ID, err = strconv.Atoi(*input.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.SavedFilter()
+
+		// ensure name is unique for the mode
+		existing, err := qb.FindByMode(newFilter.Mode)
+		if err != nil {
+			return err
+		}
+		for _, f := range existing {
+			if f.ID != newFilter.ID && strings.EqualFold(f.Name, newFilter.Name) {
+				return fmt.Errorf("filter with name '%s' already exists", newFilter.Name)
+			}
+		}
+
+		if newFilter.ID == 0 {
+			ret, err = qb.Create(*newFilter)
+			return err
+		}
+
+		current, err := qb.Find(newFilter.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return errors.New("saved filter not found")
+		}
+
+		ret, err = qb.Update(*newFilter)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) DestroySavedFilter(ctx context.Context, input models.DestroyFilterInput) (bool, error) {
+	id, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		return repo.SavedFilter().Destroy(id)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) SetDefaultFilter(ctx context.Context, input models.SetDefaultFilterInput) (bool, error) {
+	newFilter, err := newSavedFilter(input.Mode, input.FindFilter, input.ObjectFilter)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.SavedFilter()
+
+		if input.FindFilter != nil || input.ObjectFilter != nil {
+			_, err := qb.SetDefault(*newFilter)
+			return err
+		}
+
+		// clear the default filter
+		existing, err := qb.FindDefault(input.Mode)
+		if err != nil || existing == nil {
+			return err
+		}
+
+		return qb.Destroy(existing.ID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// newSavedFilter returns a saved filter with the filters encoded as JSON.
+// Returns an error if the object filter is not a valid filter for the mode.
+func newSavedFilter(mode models.FilterMode, findFilter *models.FindFilterType, objectFilter *string) (*models.SavedFilter, error) {
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("invalid filter mode: %s", mode)
+	}
+
+	ret := &models.SavedFilter{
+		Mode: mode,
+	}
+
+	if findFilter != nil {
+		data, err := json.Marshal(findFilter)
+		if err != nil {
+			return nil, err
+		}
+		ret.FindFilter = sql.NullString{String: string(data), Valid: true}
+	}
+
+	if objectFilter != nil {
+		if err := validateObjectFilter(mode, *objectFilter); err != nil {
+			return nil, err
+		}
+		ret.ObjectFilter = sql.NullString{String: *objectFilter, Valid: true}
+	}
+
+	return ret, nil
+}
+
+func validateObjectFilter(mode models.FilterMode, objectFilter string) error {
+	var filter interface{}
+	switch mode {
+	case models.FilterModeScenes:
+		filter = &models.SceneFilterType{}
+	case models.FilterModePerformers:
+		filter = &models.PerformerFilterType{}
+	case models.FilterModeStudios:
+		filter = &models.StudioFilterType{}
+	case models.FilterModeGalleries:
+		filter = &models.GalleryFilterType{}
+	case models.FilterModeSceneMarkers:
+		filter = &models.SceneMarkerFilterType{}
+	case models.FilterModeMovies:
+		filter = &models.MovieFilterType{}
+	case models.FilterModeTags:
+		filter = &models.TagFilterType{}
+	case models.FilterModeImages:
+		filter = &models.ImageFilterType{}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(objectFilter)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(filter); err != nil {
+		return fmt.Errorf("invalid object filter for %s: %s", mode, err.Error())
+	}
+
+	return nil
+}
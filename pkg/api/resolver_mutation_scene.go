@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stashapp/stash/pkg/audit"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
@@ -18,10 +19,17 @@ func (r *mutationResolver) SceneUpdate(ctx context.Context, input models.SceneUp
 		inputMap: getUpdateInputMap(ctx),
 	}
 
+	sceneID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start the transaction and save the scene
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		ret, err = r.sceneUpdate(input, translator, repo)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumScene, sceneID, func() error {
+			ret, err = r.sceneUpdate(input, translator, repo)
+			return err
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -39,12 +47,20 @@ func (r *mutationResolver) ScenesUpdate(ctx context.Context, input []*models.Sce
 				inputMap: inputMaps[i],
 			}
 
-			thisScene, err := r.sceneUpdate(*scene, translator, repo)
-			ret = append(ret, thisScene)
-
+			sceneID, err := strconv.Atoi(scene.ID)
 			if err != nil {
 				return err
 			}
+
+			var thisScene *models.Scene
+			if err := audit.Update(repo, changeSource(ctx), models.AuditEntityEnumScene, sceneID, func() error {
+				thisScene, err = r.sceneUpdate(*scene, translator, repo)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			ret = append(ret, thisScene)
 		}
 
 		return nil
@@ -137,6 +153,13 @@ func (r *mutationResolver) sceneUpdate(input models.SceneUpdateInput, translator
 		}
 	}
 
+	// Save the custom fields
+	if translator.hasField("custom_fields") {
+		if err := qb.UpdateCustomFields(sceneID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+			return nil, err
+		}
+	}
+
 	// only update the cover image if provided and everything else was successful
 	if coverImageData != nil {
 		err = manager.SetSceneScreenshot(scene.GetHash(config.GetVideoFileNamingAlgorithm()), coverImageData)
@@ -227,61 +250,80 @@ func (r *mutationResolver) BulkSceneUpdate(ctx context.Context, input models.Bul
 
 	// Start the transaction and save the scene marker
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Scene()
-
 		for _, sceneID := range sceneIDs {
-			updatedScene.ID = sceneID
+			if err := audit.Update(repo, changeSource(ctx), models.AuditEntityEnumScene, sceneID, func() error {
+				scene, err := bulkSceneUpdate(repo.Scene(), sceneID, updatedScene, input, translator)
+				if err != nil {
+					return err
+				}
 
-			scene, err := qb.Update(updatedScene)
-			if err != nil {
+				ret = append(ret, scene)
+				return nil
+			}); err != nil {
 				return err
 			}
+		}
 
-			ret = append(ret, scene)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
-			// Save the performers
-			if translator.hasField("performer_ids") {
-				performerIDs, err := adjustScenePerformerIDs(qb, sceneID, *input.PerformerIds)
-				if err != nil {
-					return err
-				}
+	return ret, nil
+}
 
-				if err := qb.UpdatePerformers(sceneID, performerIDs); err != nil {
-					return err
-				}
-			}
+func bulkSceneUpdate(qb models.SceneReaderWriter, sceneID int, updatedScene models.ScenePartial, input models.BulkSceneUpdateInput, translator changesetTranslator) (*models.Scene, error) {
+	updatedScene.ID = sceneID
 
-			// Save the tags
-			if translator.hasField("tag_ids") {
-				tagIDs, err := adjustSceneTagIDs(qb, sceneID, *input.TagIds)
-				if err != nil {
-					return err
-				}
+	scene, err := qb.Update(updatedScene)
+	if err != nil {
+		return nil, err
+	}
 
-				if err := qb.UpdateTags(sceneID, tagIDs); err != nil {
-					return err
-				}
-			}
+	// Save the performers
+	if translator.hasField("performer_ids") {
+		performerIDs, err := adjustScenePerformerIDs(qb, sceneID, *input.PerformerIds)
+		if err != nil {
+			return nil, err
+		}
 
-			// Save the galleries
-			if translator.hasField("gallery_ids") {
-				galleryIDs, err := adjustSceneGalleryIDs(qb, sceneID, *input.GalleryIds)
-				if err != nil {
-					return err
-				}
+		if err := qb.UpdatePerformers(sceneID, performerIDs); err != nil {
+			return nil, err
+		}
+	}
 
-				if err := qb.UpdateGalleries(sceneID, galleryIDs); err != nil {
-					return err
-				}
-			}
+	// Save the tags
+	if translator.hasField("tag_ids") {
+		tagIDs, err := adjustSceneTagIDs(qb, sceneID, *input.TagIds)
+		if err != nil {
+			return nil, err
 		}
 
-		return nil
-	}); err != nil {
-		return nil, err
+		if err := qb.UpdateTags(sceneID, tagIDs); err != nil {
+			return nil, err
+		}
 	}
 
-	return ret, nil
+	// Save the galleries
+	if translator.hasField("gallery_ids") {
+		galleryIDs, err := adjustSceneGalleryIDs(qb, sceneID, *input.GalleryIds)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qb.UpdateGalleries(sceneID, galleryIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save the custom fields
+	if translator.hasField("custom_fields") {
+		if err := updateCustomFields(qb, sceneID, input.CustomFields); err != nil {
+			return nil, err
+		}
+	}
+
+	return scene, nil
 }
 
 func adjustIDs(existingIDs []int, updateIDs models.BulkUpdateIds) []int {
@@ -321,6 +363,17 @@ func adjustIDs(existingIDs []int, updateIDs models.BulkUpdateIds) []int {
 	return existingIDs
 }
 
+// updateCustomFields sets the provided custom fields on the object with the
+// provided id, removing fields with a nil value and keeping the others.
+func updateCustomFields(qb models.CustomFieldsReaderWriter, id int, fields map[string]interface{}) error {
+	existing, err := qb.GetCustomFields(id)
+	if err != nil {
+		return err
+	}
+
+	return qb.UpdateCustomFields(id, existing.Merge(fields))
+}
+
 func adjustScenePerformerIDs(qb models.SceneReader, sceneID int, ids models.BulkUpdateIds) (ret []int, err error) {
 	ret, err = qb.GetPerformerIDs(sceneID)
 	if err != nil {
@@ -368,8 +421,10 @@ func (r *mutationResolver) SceneDestroy(ctx context.Context, input models.SceneD
 			return fmt.Errorf("scene with id %d not found", sceneID)
 		}
 
-		postCommitFunc, err = manager.DestroyScene(scene, repo)
-		return err
+		return audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumScene, sceneID, func() error {
+			postCommitFunc, err = manager.DestroyScene(scene, repo)
+			return err
+		})
 	}); err != nil {
 		return false, err
 	}
@@ -405,8 +460,11 @@ func (r *mutationResolver) ScenesDestroy(ctx context.Context, input models.Scene
 			if scene != nil {
 				scenes = append(scenes, scene)
 			}
-			f, err := manager.DestroyScene(scene, repo)
-			if err != nil {
+			var f func()
+			if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumScene, sceneID, func() error {
+				f, err = manager.DestroyScene(scene, repo)
+				return err
+			}); err != nil {
 				return err
 			}
 
@@ -529,8 +587,10 @@ func (r *mutationResolver) SceneMarkerDestroy(ctx context.Context, id string) (b
 			return err
 		}
 
-		postCommitFunc, err = manager.DestroySceneMarker(scene, marker, qb)
-		return err
+		return audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumSceneMarker, markerID, func() error {
+			postCommitFunc, err = manager.DestroySceneMarker(scene, marker, qb)
+			return err
+		})
 	}); err != nil {
 		return false, err
 	}
@@ -550,31 +610,45 @@ func (r *mutationResolver) changeMarker(ctx context.Context, changeType int, cha
 		qb := repo.SceneMarker()
 		sqb := repo.Scene()
 
-		var err error
+		// Save the marker tags
+		// If this tag is the primary tag, then let's not add it.
+		tagIDs = utils.IntExclude(tagIDs, []int{changedMarker.PrimaryTagID})
+
 		switch changeType {
 		case create:
-			sceneMarker, err = qb.Create(changedMarker)
+			return audit.Create(repo, changeSource(ctx), models.AuditEntityEnumSceneMarker, func() (int, error) {
+				var err error
+				sceneMarker, err = qb.Create(changedMarker)
+				if err != nil {
+					return 0, err
+				}
+
+				return sceneMarker.ID, qb.UpdateTags(sceneMarker.ID, tagIDs)
+			})
 		case update:
 			// check to see if timestamp was changed
+			var err error
 			existingMarker, err = qb.Find(changedMarker.ID)
 			if err != nil {
 				return err
 			}
-			sceneMarker, err = qb.Update(changedMarker)
-			if err != nil {
+
+			if err := audit.Update(repo, changeSource(ctx), models.AuditEntityEnumSceneMarker, changedMarker.ID, func() error {
+				sceneMarker, err = qb.Update(changedMarker)
+				if err != nil {
+					return err
+				}
+
+				return qb.UpdateTags(sceneMarker.ID, tagIDs)
+			}); err != nil {
 				return err
 			}
 
 			scene, err = sqb.Find(int(existingMarker.SceneID.Int64))
-		}
-		if err != nil {
 			return err
 		}
 
-		// Save the marker tags
-		// If this tag is the primary tag, then let's not add it.
-		tagIDs = utils.IntExclude(tagIDs, []int{changedMarker.PrimaryTagID})
-		return qb.UpdateTags(sceneMarker.ID, tagIDs)
+		return nil
 	}); err != nil {
 		return nil, err
 	}
@@ -595,10 +669,10 @@ func (r *mutationResolver) SceneIncrementO(ctx context.Context, id string) (ret
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Scene()
-
-		ret, err = qb.IncrementOCounter(sceneID)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumScene, sceneID, func() error {
+			ret, err = repo.Scene().IncrementOCounter(sceneID)
+			return err
+		})
 	}); err != nil {
 		return 0, err
 	}
@@ -613,10 +687,10 @@ func (r *mutationResolver) SceneDecrementO(ctx context.Context, id string) (ret
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Scene()
-
-		ret, err = qb.DecrementOCounter(sceneID)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumScene, sceneID, func() error {
+			ret, err = repo.Scene().DecrementOCounter(sceneID)
+			return err
+		})
 	}); err != nil {
 		return 0, err
 	}
@@ -631,10 +705,10 @@ func (r *mutationResolver) SceneResetO(ctx context.Context, id string) (ret int,
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Scene()
-
-		ret, err = qb.ResetOCounter(sceneID)
-		return err
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumScene, sceneID, func() error {
+			ret, err = repo.Scene().ResetOCounter(sceneID)
+			return err
+		})
 	}); err != nil {
 		return 0, err
 	}
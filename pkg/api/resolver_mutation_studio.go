@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stashapp/stash/pkg/audit"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
@@ -20,7 +21,7 @@ func (r *mutationResolver) StudioCreate(ctx context.Context, input models.Studio
 
 	// Process the base 64 encoded image string
 	if input.Image != nil {
-		_, imageData, err = utils.ProcessBase64Image(*input.Image)
+		imageData, err = processImageInput(*input.Image, input.ImageOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -45,30 +46,39 @@ func (r *mutationResolver) StudioCreate(ctx context.Context, input models.Studio
 	// Start the transaction and save the studio
 	var studio *models.Studio
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Studio()
+		return audit.Create(repo, changeSource(ctx), models.AuditEntityEnumStudio, func() (int, error) {
+			qb := repo.Studio()
 
-		var err error
-		studio, err = qb.Create(newStudio)
-		if err != nil {
-			return err
-		}
+			var err error
+			studio, err = qb.Create(newStudio)
+			if err != nil {
+				return 0, err
+			}
 
-		// update image table
-		if len(imageData) > 0 {
-			if err := qb.UpdateImage(studio.ID, imageData); err != nil {
-				return err
+			// update image table
+			if len(imageData) > 0 {
+				if err := qb.UpdateImage(studio.ID, imageData); err != nil {
+					return 0, err
+				}
 			}
-		}
 
-		// Save the stash_ids
-		if input.StashIds != nil {
-			stashIDJoins := models.StashIDsFromInput(input.StashIds)
-			if err := qb.UpdateStashIDs(studio.ID, stashIDJoins); err != nil {
-				return err
+			// Save the stash_ids
+			if input.StashIds != nil {
+				stashIDJoins := models.StashIDsFromInput(input.StashIds)
+				if err := qb.UpdateStashIDs(studio.ID, stashIDJoins); err != nil {
+					return 0, err
+				}
 			}
-		}
 
-		return nil
+			// Save the custom fields
+			if input.CustomFields != nil {
+				if err := qb.UpdateCustomFields(studio.ID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+					return 0, err
+				}
+			}
+
+			return studio.ID, nil
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -96,7 +106,7 @@ func (r *mutationResolver) StudioUpdate(ctx context.Context, input models.Studio
 	imageIncluded := translator.hasField("image")
 	if input.Image != nil {
 		var err error
-		_, imageData, err = utils.ProcessBase64Image(*input.Image)
+		imageData, err = processImageInput(*input.Image, input.ImageOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -114,39 +124,48 @@ func (r *mutationResolver) StudioUpdate(ctx context.Context, input models.Studio
 	// Start the transaction and save the studio
 	var studio *models.Studio
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Studio()
-
-		if err := manager.ValidateModifyStudio(updatedStudio, qb); err != nil {
-			return err
-		}
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumStudio, studioID, func() error {
+			qb := repo.Studio()
 
-		var err error
-		studio, err = qb.Update(updatedStudio)
-		if err != nil {
-			return err
-		}
-
-		// update image table
-		if len(imageData) > 0 {
-			if err := qb.UpdateImage(studio.ID, imageData); err != nil {
+			if err := manager.ValidateModifyStudio(updatedStudio, qb); err != nil {
 				return err
 			}
-		} else if imageIncluded {
-			// must be unsetting
-			if err := qb.DestroyImage(studio.ID); err != nil {
+
+			var err error
+			studio, err = qb.Update(updatedStudio)
+			if err != nil {
 				return err
 			}
-		}
 
-		// Save the stash_ids
-		if translator.hasField("stash_ids") {
-			stashIDJoins := models.StashIDsFromInput(input.StashIds)
-			if err := qb.UpdateStashIDs(studioID, stashIDJoins); err != nil {
-				return err
+			// update image table
+			if len(imageData) > 0 {
+				if err := qb.UpdateImage(studio.ID, imageData); err != nil {
+					return err
+				}
+			} else if imageIncluded {
+				// must be unsetting
+				if err := qb.DestroyImage(studio.ID); err != nil {
+					return err
+				}
 			}
-		}
 
-		return nil
+			// Save the stash_ids
+			if translator.hasField("stash_ids") {
+				stashIDJoins := models.StashIDsFromInput(input.StashIds)
+				if err := qb.UpdateStashIDs(studioID, stashIDJoins); err != nil {
+					return err
+				}
+			}
+
+			// Save the custom fields
+			if translator.hasField("custom_fields") {
+				if err := qb.UpdateCustomFields(studioID, models.CustomFieldsFromInput(input.CustomFields)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -161,7 +180,9 @@ func (r *mutationResolver) StudioDestroy(ctx context.Context, input models.Studi
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		return repo.Studio().Destroy(id)
+		return audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumStudio, id, func() error {
+			return repo.Studio().Destroy(id)
+		})
 	}); err != nil {
 		return false, err
 	}
@@ -177,7 +198,9 @@ func (r *mutationResolver) StudiosDestroy(ctx context.Context, studioIDs []strin
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Studio()
 		for _, id := range ids {
-			if err := qb.Destroy(id); err != nil {
+			if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumStudio, id, func() error {
+				return qb.Destroy(id)
+			}); err != nil {
 				return err
 			}
 		}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stashapp/stash/pkg/audit"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
@@ -24,7 +25,7 @@ func (r *mutationResolver) TagCreate(ctx context.Context, input models.TagCreate
 	var err error
 
 	if input.Image != nil {
-		_, imageData, err = utils.ProcessBase64Image(*input.Image)
+		imageData, err = processImageInput(*input.Image, input.ImageOptions)
 
 		if err != nil {
 			return nil, err
@@ -34,26 +35,28 @@ func (r *mutationResolver) TagCreate(ctx context.Context, input models.TagCreate
 	// Start the transaction and save the tag
 	var tag *models.Tag
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Tag()
+		return audit.Create(repo, changeSource(ctx), models.AuditEntityEnumTag, func() (int, error) {
+			qb := repo.Tag()
 
-		// ensure name is unique
-		if err := manager.EnsureTagNameUnique(newTag, qb); err != nil {
-			return err
-		}
+			// ensure name is unique
+			if err := manager.EnsureTagNameUnique(newTag, qb); err != nil {
+				return 0, err
+			}
 
-		tag, err = qb.Create(newTag)
-		if err != nil {
-			return err
-		}
+			tag, err = qb.Create(newTag)
+			if err != nil {
+				return 0, err
+			}
 
-		// update image table
-		if len(imageData) > 0 {
-			if err := qb.UpdateImage(tag.ID, imageData); err != nil {
-				return err
+			// update image table
+			if len(imageData) > 0 {
+				if err := qb.UpdateImage(tag.ID, imageData); err != nil {
+					return 0, err
+				}
 			}
-		}
 
-		return nil
+			return tag.ID, nil
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -82,7 +85,7 @@ func (r *mutationResolver) TagUpdate(ctx context.Context, input models.TagUpdate
 
 	imageIncluded := translator.hasField("image")
 	if input.Image != nil {
-		_, imageData, err = utils.ProcessBase64Image(*input.Image)
+		imageData, err = processImageInput(*input.Image, input.ImageOptions)
 
 		if err != nil {
 			return nil, err
@@ -92,42 +95,44 @@ func (r *mutationResolver) TagUpdate(ctx context.Context, input models.TagUpdate
 	// Start the transaction and save the tag
 	var tag *models.Tag
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Tag()
-
-		// ensure name is unique
-		existing, err := qb.Find(tagID)
-		if err != nil {
-			return err
-		}
+		return audit.Update(repo, changeSource(ctx), models.AuditEntityEnumTag, tagID, func() error {
+			qb := repo.Tag()
 
-		if existing == nil {
-			return fmt.Errorf("Tag with ID %d not found", tagID)
-		}
-
-		if existing.Name != updatedTag.Name {
-			if err := manager.EnsureTagNameUnique(updatedTag, qb); err != nil {
+			// ensure name is unique
+			existing, err := qb.Find(tagID)
+			if err != nil {
 				return err
 			}
-		}
 
-		tag, err = qb.Update(updatedTag)
-		if err != nil {
-			return err
-		}
+			if existing == nil {
+				return fmt.Errorf("Tag with ID %d not found", tagID)
+			}
 
-		// update image table
-		if len(imageData) > 0 {
-			if err := qb.UpdateImage(tag.ID, imageData); err != nil {
-				return err
+			if existing.Name != updatedTag.Name {
+				if err := manager.EnsureTagNameUnique(updatedTag, qb); err != nil {
+					return err
+				}
 			}
-		} else if imageIncluded {
-			// must be unsetting
-			if err := qb.DestroyImage(tag.ID); err != nil {
+
+			tag, err = qb.Update(updatedTag)
+			if err != nil {
 				return err
 			}
-		}
 
-		return nil
+			// update image table
+			if len(imageData) > 0 {
+				if err := qb.UpdateImage(tag.ID, imageData); err != nil {
+					return err
+				}
+			} else if imageIncluded {
+				// must be unsetting
+				if err := qb.DestroyImage(tag.ID); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -142,7 +147,9 @@ func (r *mutationResolver) TagDestroy(ctx context.Context, input models.TagDestr
 	}
 
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		return repo.Tag().Destroy(tagID)
+		return audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumTag, tagID, func() error {
+			return repo.Tag().Destroy(tagID)
+		})
 	}); err != nil {
 		return false, err
 	}
@@ -158,7 +165,9 @@ func (r *mutationResolver) TagsDestroy(ctx context.Context, tagIDs []string) (bo
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Tag()
 		for _, id := range ids {
-			if err := qb.Destroy(id); err != nil {
+			if err := audit.Destroy(repo, changeSource(ctx), models.AuditEntityEnumTag, id, func() error {
+				return qb.Destroy(id)
+			}); err != nil {
 				return err
 			}
 		}
@@ -60,6 +60,13 @@ func TestTagCreate(t *testing.T) {
 		ID:   newTagID,
 		Name: tagName,
 	}, nil)
+	tagRW.On("Find", newTagID).Return(&models.Tag{
+		ID:   newTagID,
+		Name: tagName,
+	}, nil).Once()
+
+	auditLogRW := r.txnManager.(*mocks.TransactionManager).AuditLog().(*mocks.AuditLogReaderWriter)
+	auditLogRW.On("Create", mock.AnythingOfType("models.AuditLogEntry")).Return(&models.AuditLogEntry{}, nil).Once()
 
 	tag, err := r.Mutation().TagCreate(context.TODO(), models.TagCreateInput{
 		Name: tagName,
@@ -67,4 +74,5 @@ func TestTagCreate(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.NotNil(t, tag)
+	auditLogRW.AssertExpectations(t)
 }
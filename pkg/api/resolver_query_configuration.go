@@ -41,10 +41,15 @@ func makeConfigGeneralResult() *models.ConfigGeneralResult {
 
 	scraperUserAgent := config.GetScraperUserAgent()
 	scraperCDPPath := config.GetScraperCDPPath()
+	scraperPackageIndex := config.GetScraperPackageIndex()
+	pluginPackageIndex := config.GetPluginPackageIndex()
 
 	return &models.ConfigGeneralResult{
 		Stashes:                    config.GetStashPaths(),
 		DatabasePath:               config.GetDatabasePath(),
+		BackupInterval:             config.GetBackupInterval(),
+		BackupDirectory:            config.GetBackupDirectory(),
+		BackupCount:                config.GetBackupCount(),
 		GeneratedPath:              config.GetGeneratedPath(),
 		CachePath:                  config.GetCachePath(),
 		CalculateMd5:               config.IsCalculateMD5(),
@@ -68,11 +73,25 @@ func makeConfigGeneralResult() *models.ConfigGeneralResult {
 		ImageExtensions:            config.GetImageExtensions(),
 		GalleryExtensions:          config.GetGalleryExtensions(),
 		CreateGalleriesFromFolders: config.GetCreateGalleriesFromFolders(),
+		GalleryFolderRules:         config.GetGalleryFolderRules(),
+		ImageKeywordsAsTags:        config.GetImageKeywordsAsTags(),
+		ImageClips:                 config.GetImageClips(),
+		ImageClipMaxDuration:       config.GetImageClipMaxDuration(),
+		ImageThumbnailSizes:        config.GetImageThumbnailSizes(),
+		ImageThumbnailQuality:      config.GetImageThumbnailQuality(),
+		ImageThumbnailFormat:       config.GetImageThumbnailFormat(),
+		ImageThumbnailFilter:       config.GetImageThumbnailFilter(),
+		ResizedImageCacheSize:      config.GetResizedImageCacheSize(),
+		StoredImageFormat:          config.GetStoredImageFormat(),
+		BlobsStorage:               config.GetBlobsStorage(),
+		BlobsPath:                  config.GetBlobsPath(),
 		Excludes:                   config.GetExcludes(),
 		ImageExcludes:              config.GetImageExcludes(),
 		ScraperUserAgent:           &scraperUserAgent,
 		ScraperCertCheck:           config.GetScraperCertCheck(),
 		ScraperCDPPath:             &scraperCDPPath,
+		ScraperPackageIndex:        &scraperPackageIndex,
+		PluginPackageIndex:         &pluginPackageIndex,
 		StashBoxes:                 config.GetStashBoxes(),
 	}
 }
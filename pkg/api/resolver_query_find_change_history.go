@@ -0,0 +1,24 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *queryResolver) FindChangeHistory(ctx context.Context, entity models.AuditEntityEnum, id string) (ret []*models.AuditLogEntry, err error) {
+	entityID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.AuditLog().FindByEntity(entity, entityID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *queryResolver) FindSavedFilters(ctx context.Context, mode *models.FilterMode) (ret []*models.SavedFilter, err error) {
+	modes := models.AllFilterMode
+	if mode != nil {
+		modes = []models.FilterMode{*mode}
+	}
+
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		for _, m := range modes {
+			filters, err := repo.SavedFilter().FindByMode(m)
+			if err != nil {
+				return err
+			}
+			ret = append(ret, filters...)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) FindDefaultFilter(ctx context.Context, mode models.FilterMode) (ret *models.SavedFilter, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.SavedFilter().FindDefault(mode)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"path/filepath"
 
+	"github.com/stashapp/stash/pkg/database"
 	"github.com/stashapp/stash/pkg/manager"
+	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
 )
 
@@ -17,3 +20,22 @@ func (r *queryResolver) JobStatus(ctx context.Context) (*models.MetadataUpdateSt
 
 	return &ret, nil
 }
+
+func (r *queryResolver) ListBackups(ctx context.Context) ([]*models.DatabaseBackup, error) {
+	backups, err := database.ListBackups(config.GetBackupDirectory())
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []*models.DatabaseBackup{}
+	for _, b := range backups {
+		ret = append(ret, &models.DatabaseBackup{
+			Name:          filepath.Base(b.Path),
+			SchemaVersion: int(b.SchemaVersion),
+			CreatedAt:     b.Time,
+			Size:          int(b.Size),
+		})
+	}
+
+	return ret, nil
+}
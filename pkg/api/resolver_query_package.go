@@ -0,0 +1,26 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/manager"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *queryResolver) AvailablePackages(ctx context.Context, typeArg models.PackageType) ([]*models.Package, error) {
+	pm, err := manager.GetInstance().GetPackageManager(typeArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.ListAvailable()
+}
+
+func (r *queryResolver) InstalledPackages(ctx context.Context, typeArg models.PackageType) ([]*models.InstalledPackage, error) {
+	pm, err := manager.GetInstance().GetPackageManager(typeArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.ListInstalled()
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
@@ -33,10 +34,26 @@ func (rs imageRoutes) Routes() chi.Router {
 
 func (rs imageRoutes) Thumbnail(w http.ResponseWriter, r *http.Request) {
 	image := r.Context().Value(imageKey).(*models.Image)
-	filepath := manager.GetInstance().Paths.Generated.GetThumbnailPath(image.Checksum, models.DefaultGthumbWidth)
 
-	// if the thumbnail doesn't exist, fall back to the original file
+	if rs.serveResized(w, r, image) {
+		return
+	}
+	// the size parameter selects the closest configured thumbnail size
+	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+	size = manager.GetImageThumbnailSize(size)
+	filepath := manager.GetImageThumbnailPath(image, size)
+
+	// if the thumbnail doesn't exist, fall back to the original file, unless
+	// it must be transformed to display the right way up or is a video clip
 	exists, _ := utils.FileExists(filepath)
+	if !exists && (image.Orientation.Int64 > 1 || image.Duration.Valid) {
+		var err error
+		exists, err = manager.GenerateImageThumbnail(image, size, false)
+		if err != nil {
+			logger.Error(err.Error())
+		}
+	}
+
 	if exists {
 		http.ServeFile(w, r, filepath)
 	} else {
@@ -47,10 +64,27 @@ func (rs imageRoutes) Thumbnail(w http.ResponseWriter, r *http.Request) {
 func (rs imageRoutes) Image(w http.ResponseWriter, r *http.Request) {
 	i := r.Context().Value(imageKey).(*models.Image)
 
+	if rs.serveResized(w, r, i) {
+		return
+	}
+
 	// if image is in a zip file, we need to serve it specifically
 	image.Serve(w, r, i.Path)
 }
 
+// serveResized serves the image resized if requested by the width, height,
+// fit and format query parameters. Video clips and animated images are not
+// resized. Returns false if the image was not served.
+func (rs imageRoutes) serveResized(w http.ResponseWriter, r *http.Request, i *models.Image) bool {
+	if i.IsAnimated || i.Duration.Valid {
+		return false
+	}
+
+	return serveResizeRequest(w, r, func(options image.ResizeOptions) ([]byte, error) {
+		return manager.GetResizedImage(i, options)
+	})
+}
+
 // endregion
 
 func ImageCtx(next http.Handler) http.Handler {
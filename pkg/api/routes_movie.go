@@ -30,37 +30,33 @@ func (rs movieRoutes) Routes() chi.Router {
 func (rs movieRoutes) FrontImage(w http.ResponseWriter, r *http.Request) {
 	movie := r.Context().Value(movieKey).(*models.Movie)
 	defaultParam := r.URL.Query().Get("default")
-	var image []byte
-	if defaultParam != "true" {
-		rs.txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
-			image, _ = repo.Movie().GetFrontImage(movie.ID)
-			return nil
-		})
-	}
 
-	if len(image) == 0 {
-		_, image, _ = utils.ProcessBase64Image(models.DefaultMovieImage)
+	if defaultParam != "true" && serveStoredImage(w, r, rs.txnManager, func(repo models.ReaderRepository) (string, error) {
+		return repo.Movie().GetFrontImageChecksum(movie.ID)
+	}, func(repo models.ReaderRepository) ([]byte, error) {
+		return repo.Movie().GetFrontImage(movie.ID)
+	}) {
+		return
 	}
 
-	utils.ServeImage(image, w, r)
+	_, image, _ := utils.ProcessBase64Image(models.DefaultMovieImage)
+	serveImageData(image, w, r)
 }
 
 func (rs movieRoutes) BackImage(w http.ResponseWriter, r *http.Request) {
 	movie := r.Context().Value(movieKey).(*models.Movie)
 	defaultParam := r.URL.Query().Get("default")
-	var image []byte
-	if defaultParam != "true" {
-		rs.txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
-			image, _ = repo.Movie().GetBackImage(movie.ID)
-			return nil
-		})
-	}
 
-	if len(image) == 0 {
-		_, image, _ = utils.ProcessBase64Image(models.DefaultMovieImage)
+	if defaultParam != "true" && serveStoredImage(w, r, rs.txnManager, func(repo models.ReaderRepository) (string, error) {
+		return repo.Movie().GetBackImageChecksum(movie.ID)
+	}, func(repo models.ReaderRepository) ([]byte, error) {
+		return repo.Movie().GetBackImage(movie.ID)
+	}) {
+		return
 	}
 
-	utils.ServeImage(image, w, r)
+	_, image, _ := utils.ProcessBase64Image(models.DefaultMovieImage)
+	serveImageData(image, w, r)
 }
 
 func MovieCtx(next http.Handler) http.Handler {
@@ -8,7 +8,6 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
-	"github.com/stashapp/stash/pkg/utils"
 )
 
 type performerRoutes struct {
@@ -30,19 +29,34 @@ func (rs performerRoutes) Image(w http.ResponseWriter, r *http.Request) {
 	performer := r.Context().Value(performerKey).(*models.Performer)
 	defaultParam := r.URL.Query().Get("default")
 
-	var image []byte
-	if defaultParam != "true" {
-		rs.txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
-			image, _ = repo.Performer().GetImage(performer.ID)
-			return nil
-		})
+	// the index parameter selects one of the other images of the performer
+	index := 0
+	if indexParam := r.URL.Query().Get("index"); indexParam != "" {
+		var err error
+		index, err = strconv.Atoi(indexParam)
+		if err != nil || index < 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
 	}
 
-	if len(image) == 0 || defaultParam == "true" {
-		image, _ = getRandomPerformerImageUsingName(performer.Name.String, performer.Gender.String)
+	if defaultParam != "true" {
+		if serveStoredImage(w, r, rs.txnManager, func(repo models.ReaderRepository) (string, error) {
+			return repo.Performer().GetImageChecksumByIndex(performer.ID, index)
+		}, func(repo models.ReaderRepository) ([]byte, error) {
+			return repo.Performer().GetImageByIndex(performer.ID, index)
+		}) {
+			return
+		}
+
+		if index > 0 {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
 	}
 
-	utils.ServeImage(image, w, r)
+	image, _ := getRandomPerformerImageUsingName(performer.Name.String, performer.Gender.String)
+	serveImageData(image, w, r)
 }
 
 func PerformerCtx(next http.Handler) http.Handler {
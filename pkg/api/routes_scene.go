@@ -184,13 +184,12 @@ func (rs sceneRoutes) Screenshot(w http.ResponseWriter, r *http.Request) {
 	screenshotExists, _ := utils.FileExists(filepath)
 	if screenshotExists {
 		http.ServeFile(w, r, filepath)
-	} else {
-		var cover []byte
-		rs.txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
-			cover, _ = repo.Scene().GetCover(scene.ID)
-			return nil
-		})
-		utils.ServeImage(cover, w, r)
+	} else if !serveStoredImage(w, r, rs.txnManager, func(repo models.ReaderRepository) (string, error) {
+		return repo.Scene().GetCoverChecksum(scene.ID)
+	}, func(repo models.ReaderRepository) ([]byte, error) {
+		return repo.Scene().GetCover(scene.ID)
+	}) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 	}
 }
 
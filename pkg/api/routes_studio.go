@@ -30,19 +30,16 @@ func (rs studioRoutes) Image(w http.ResponseWriter, r *http.Request) {
 	studio := r.Context().Value(studioKey).(*models.Studio)
 	defaultParam := r.URL.Query().Get("default")
 
-	var image []byte
-	if defaultParam != "true" {
-		rs.txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
-			image, _ = repo.Studio().GetImage(studio.ID)
-			return nil
-		})
+	if defaultParam != "true" && serveStoredImage(w, r, rs.txnManager, func(repo models.ReaderRepository) (string, error) {
+		return repo.Studio().GetImageChecksum(studio.ID)
+	}, func(repo models.ReaderRepository) ([]byte, error) {
+		return repo.Studio().GetImage(studio.ID)
+	}) {
+		return
 	}
 
-	if len(image) == 0 {
-		_, image, _ = utils.ProcessBase64Image(models.DefaultStudioImage)
-	}
-
-	utils.ServeImage(image, w, r)
+	_, image, _ := utils.ProcessBase64Image(models.DefaultStudioImage)
+	serveImageData(image, w, r)
 }
 
 func StudioCtx(next http.Handler) http.Handler {
@@ -8,7 +8,6 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
-	"github.com/stashapp/stash/pkg/utils"
 )
 
 type tagRoutes struct {
@@ -30,19 +29,15 @@ func (rs tagRoutes) Image(w http.ResponseWriter, r *http.Request) {
 	tag := r.Context().Value(tagKey).(*models.Tag)
 	defaultParam := r.URL.Query().Get("default")
 
-	var image []byte
-	if defaultParam != "true" {
-		rs.txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
-			image, _ = repo.Tag().GetImage(tag.ID)
-			return nil
-		})
+	if defaultParam != "true" && serveStoredImage(w, r, rs.txnManager, func(repo models.ReaderRepository) (string, error) {
+		return repo.Tag().GetImageChecksum(tag.ID)
+	}, func(repo models.ReaderRepository) ([]byte, error) {
+		return repo.Tag().GetImage(tag.ID)
+	}) {
+		return
 	}
 
-	if len(image) == 0 {
-		image = models.DefaultTagImage
-	}
-
-	utils.ServeImage(image, w, r)
+	serveImageData(models.DefaultTagImage, w, r)
 }
 
 func TagCtx(next http.Handler) http.Handler {
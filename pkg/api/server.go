@@ -120,6 +120,7 @@ func Start() {
 	r.Use(middleware.StripSlashes)
 	r.Use(cors.AllowAll().Handler)
 	r.Use(BaseURLMiddleware)
+	r.Use(ChangeSourceMiddleware)
 	r.Use(ConfigCheckMiddleware)
 	r.Use(DatabaseCheckMiddleware)
 
@@ -414,6 +415,29 @@ func BaseURLMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// ChangeSourceMiddleware sets the source of changes made by the request from
+// the X-Stash-Change-Source header. Changes are attributed to the user if the
+// header is not set or is invalid.
+func ChangeSourceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source := models.AuditSourceEnum(r.Header.Get(models.ChangeSourceHeader))
+		if !source.IsValid() {
+			source = models.AuditSourceEnumUser
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), changeSourceKey, source))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// changeSource returns the source of changes made in the provided context.
+func changeSource(ctx context.Context) models.AuditSourceEnum {
+	if source, ok := ctx.Value(changeSourceKey).(models.AuditSourceEnum); ok {
+		return source
+	}
+	return models.AuditSourceEnumUser
+}
+
 func ConfigCheckMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ext := path.Ext(r.URL.Path)
@@ -17,3 +17,7 @@ func NewPerformerURLBuilder(baseURL string, performerID int) PerformerURLBuilder
 func (b PerformerURLBuilder) GetPerformerImageURL() string {
 	return b.BaseURL + "/performer/" + b.PerformerID + "/image"
 }
+
+func (b PerformerURLBuilder) GetPerformerImageURLByIndex(index int) string {
+	return b.GetPerformerImageURL() + "?index=" + strconv.Itoa(index)
+}
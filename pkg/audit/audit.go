@@ -0,0 +1,362 @@
+// Package audit records the history of changes made to object metadata and
+// allows individual changes to be reverted.
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// Snapshot is the tracked values of an object, keyed by field name. Null
+// values are nil.
+type Snapshot map[string]*string
+
+func getEntity(entityType models.AuditEntityEnum) (entity, error) {
+	e, found := entities[entityType]
+	if !found {
+		return entity{}, fmt.Errorf("unsupported entity type %s", entityType)
+	}
+	return e, nil
+}
+
+// fieldNames returns the tracked fields of the entity, with the object
+// fields first, followed by the relations in name order.
+func (e entity) fieldNames() []string {
+	var relations []string
+	for name := range e.relations {
+		relations = append(relations, name)
+	}
+	sort.Strings(relations)
+
+	return append(append([]string(nil), e.fields...), relations...)
+}
+
+// snapshot returns the tracked values of the object with the provided id.
+// Returns nil if the object does not exist.
+func (e entity) snapshot(r models.Repository, id int) (Snapshot, error) {
+	obj, err := e.find(r, id)
+	if err != nil || obj == nil {
+		return nil, err
+	}
+
+	ret := make(Snapshot)
+	for _, field := range e.fields {
+		v, err := getField(obj, field)
+		if err != nil {
+			return nil, err
+		}
+		ret[field] = v
+	}
+
+	for name, rel := range e.relations {
+		v, err := rel.get(r, id)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s: %s", name, err.Error())
+		}
+		ret[name] = &v
+	}
+
+	return ret, nil
+}
+
+func (s Snapshot) json() (sql.NullString, error) {
+	v, err := json.Marshal(s)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(v), Valid: true}, nil
+}
+
+func nullString(v *string) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *v, Valid: true}
+}
+
+func valuesEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func newEntry(source models.AuditSourceEnum, entityType models.AuditEntityEnum, id int, action models.AuditActionEnum) models.AuditLogEntry {
+	return models.AuditLogEntry{
+		EntityType: entityType,
+		EntityID:   id,
+		Action:     action,
+		Source:     source,
+		CreatedAt:  models.SQLiteTimestamp{Timestamp: time.Now()},
+	}
+}
+
+// diff records an update entry for each field that differs between before
+// and after. Returns the created entries.
+func diff(r models.Repository, source models.AuditSourceEnum, entityType models.AuditEntityEnum, id int, before, after Snapshot) ([]*models.AuditLogEntry, error) {
+	e, err := getEntity(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*models.AuditLogEntry
+	for _, field := range e.fieldNames() {
+		if valuesEqual(before[field], after[field]) {
+			continue
+		}
+
+		entry := newEntry(source, entityType, id, models.AuditActionEnumUpdate)
+		entry.Field = sql.NullString{String: field, Valid: true}
+		entry.OldValue = nullString(before[field])
+		entry.NewValue = nullString(after[field])
+
+		created, err := r.AuditLog().Create(entry)
+		if err != nil {
+			return nil, fmt.Errorf("error creating audit log entry: %s", err.Error())
+		}
+		ret = append(ret, created)
+	}
+
+	return ret, nil
+}
+
+// Update calls fn and records an entry for each tracked field of the object
+// that was changed by it. Nothing is recorded if the object does not exist
+// before and after fn is called.
+func Update(r models.Repository, source models.AuditSourceEnum, entityType models.AuditEntityEnum, id int, fn func() error) error {
+	e, err := getEntity(entityType)
+	if err != nil {
+		return err
+	}
+
+	before, err := e.snapshot(r, id)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	if before == nil {
+		return nil
+	}
+
+	after, err := e.snapshot(r, id)
+	if err != nil || after == nil {
+		return err
+	}
+
+	_, err = diff(r, source, entityType, id, before, after)
+	return err
+}
+
+// UpdateAll calls fn and records an entry for each tracked field of the
+// objects with the provided ids that was changed by it.
+func UpdateAll(r models.Repository, source models.AuditSourceEnum, entityType models.AuditEntityEnum, ids []int, fn func() error) error {
+	e, err := getEntity(entityType)
+	if err != nil {
+		return err
+	}
+
+	ids = utils.IntAppendUniques(nil, ids)
+
+	before := make([]Snapshot, len(ids))
+	for i, id := range ids {
+		before[i], err = e.snapshot(r, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		if before[i] == nil {
+			continue
+		}
+
+		after, err := e.snapshot(r, id)
+		if err != nil {
+			return err
+		}
+		if after == nil {
+			continue
+		}
+
+		if _, err := diff(r, source, entityType, id, before[i], after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Create calls fn, which returns the id of the created object, and records
+// the tracked values of the created object.
+func Create(r models.Repository, source models.AuditSourceEnum, entityType models.AuditEntityEnum, fn func() (int, error)) error {
+	e, err := getEntity(entityType)
+	if err != nil {
+		return err
+	}
+
+	id, err := fn()
+	if err != nil {
+		return err
+	}
+
+	after, err := e.snapshot(r, id)
+	if err != nil || after == nil {
+		return err
+	}
+
+	entry := newEntry(source, entityType, id, models.AuditActionEnumCreate)
+	if entry.NewValue, err = after.json(); err != nil {
+		return err
+	}
+
+	if _, err := r.AuditLog().Create(entry); err != nil {
+		return fmt.Errorf("error creating audit log entry: %s", err.Error())
+	}
+
+	return nil
+}
+
+// Destroy records the tracked values of the object with the provided id and
+// then calls fn to destroy it.
+func Destroy(r models.Repository, source models.AuditSourceEnum, entityType models.AuditEntityEnum, id int, fn func() error) error {
+	e, err := getEntity(entityType)
+	if err != nil {
+		return err
+	}
+
+	before, err := e.snapshot(r, id)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	if before == nil {
+		return nil
+	}
+
+	entry := newEntry(source, entityType, id, models.AuditActionEnumDestroy)
+	if entry.OldValue, err = before.json(); err != nil {
+		return err
+	}
+
+	if _, err := r.AuditLog().Create(entry); err != nil {
+		return fmt.Errorf("error creating audit log entry: %s", err.Error())
+	}
+
+	return nil
+}
+
+// Revert restores the previous value of the field changed by the update
+// entry with the provided id. The field must not have been changed since.
+// The revert is itself recorded, and the new entry is returned.
+func Revert(r models.Repository, source models.AuditSourceEnum, entryID int) (*models.AuditLogEntry, error) {
+	entry, err := r.AuditLog().Find(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("change history entry with id %d not found", entryID)
+	}
+
+	if entry.Action != models.AuditActionEnumUpdate || !entry.Field.Valid {
+		return nil, errors.New("only updates can be reverted")
+	}
+
+	e, err := getEntity(entry.EntityType)
+	if err != nil {
+		return nil, err
+	}
+
+	field := entry.Field.String
+	before, err := e.snapshot(r, entry.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, fmt.Errorf("%s with id %d no longer exists", entry.EntityType, entry.EntityID)
+	}
+
+	current, tracked := before[field]
+	if !tracked {
+		return nil, fmt.Errorf("field %s is not tracked", field)
+	}
+
+	var newValue *string
+	if entry.NewValue.Valid {
+		newValue = &entry.NewValue.String
+	}
+	if !valuesEqual(current, newValue) {
+		return nil, fmt.Errorf("%s has been changed since", field)
+	}
+
+	var oldValue *string
+	if entry.OldValue.Valid {
+		oldValue = &entry.OldValue.String
+	}
+
+	obj, err := e.find(r, entry.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rel, isRelation := e.relations[field]; isRelation {
+		if rel.set == nil {
+			return nil, fmt.Errorf("changes to %s cannot be reverted", field)
+		}
+		if oldValue == nil {
+			return nil, fmt.Errorf("invalid value for %s", field)
+		}
+		if err := rel.set(r, entry.EntityID, *oldValue); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := setField(obj, field, oldValue); err != nil {
+			return nil, err
+		}
+		if e.prepare != nil {
+			e.prepare(obj)
+		}
+	}
+
+	if err := touch(obj); err != nil {
+		return nil, err
+	}
+	if err := e.update(r, obj); err != nil {
+		return nil, err
+	}
+
+	after, err := e.snapshot(r, entry.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := diff(r, source, entry.EntityType, entry.EntityID, before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	// the reverted field is always first since only one field is changed
+	if len(created) == 0 {
+		return nil, fmt.Errorf("reverting %s made no changes", field)
+	}
+
+	return created[0], nil
+}
@@ -0,0 +1,380 @@
+package audit
+
+import (
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// relation is a tracked value that is not stored in the object table, such
+// as a list of joined ids. Changes to relations without set are recorded but
+// cannot be reverted.
+type relation struct {
+	get func(r models.Repository, id int) (string, error)
+	set func(r models.Repository, id int, value string) error
+}
+
+// entity describes how the history of an object type is tracked.
+type entity struct {
+	// find returns the object with the provided id, or nil if it does not
+	// exist.
+	find func(r models.Repository, id int) (interface{}, error)
+	// update writes all the fields of the object returned by find.
+	update func(r models.Repository, obj interface{}) error
+	// prepare updates derived fields before the object is written.
+	prepare func(obj interface{})
+
+	// fields are the tracked columns of the object table.
+	fields    []string
+	relations map[string]relation
+}
+
+type idsGetter func(id int) ([]int, error)
+type idsSetter func(id int, ids []int) error
+
+func idsRelation(get func(r models.Repository) idsGetter, set func(r models.Repository) idsSetter) relation {
+	return relation{
+		get: func(r models.Repository, id int) (string, error) {
+			ids, err := get(r)(id)
+			if err != nil {
+				return "", err
+			}
+			return idList(ids), nil
+		},
+		set: func(r models.Repository, id int, value string) error {
+			ids, err := parseIDList(value)
+			if err != nil {
+				return err
+			}
+			return set(r)(id, ids)
+		},
+	}
+}
+
+type stashIDsGetter func(id int) ([]*models.StashID, error)
+type stashIDsSetter func(id int, stashIDs []models.StashID) error
+
+func stashIDsRelation(get func(r models.Repository) stashIDsGetter, set func(r models.Repository) stashIDsSetter) relation {
+	return relation{
+		get: func(r models.Repository, id int) (string, error) {
+			stashIDs, err := get(r)(id)
+			if err != nil {
+				return "", err
+			}
+			return stashIDsValue(stashIDs)
+		},
+		set: func(r models.Repository, id int, value string) error {
+			stashIDs, err := parseStashIDs(value)
+			if err != nil {
+				return err
+			}
+			return set(r)(id, stashIDs)
+		},
+	}
+}
+
+func customFieldsRelation(qb func(r models.Repository) models.CustomFieldsReaderWriter) relation {
+	return relation{
+		get: func(r models.Repository, id int) (string, error) {
+			fields, err := qb(r).GetCustomFields(id)
+			if err != nil {
+				return "", err
+			}
+			return customFieldsValue(fields)
+		},
+		set: func(r models.Repository, id int, value string) error {
+			fields, err := parseCustomFields(value)
+			if err != nil {
+				return err
+			}
+			return qb(r).UpdateCustomFields(id, fields)
+		},
+	}
+}
+
+func galleryChaptersRelation() relation {
+	return relation{
+		get: func(r models.Repository, id int) (string, error) {
+			chapters, err := r.Gallery().GetChapters(id)
+			if err != nil {
+				return "", err
+			}
+			return galleryChaptersValue(chapters)
+		},
+		set: func(r models.Repository, id int, value string) error {
+			chapters, err := parseGalleryChapters(value)
+			if err != nil {
+				return err
+			}
+			return r.Gallery().UpdateChapters(id, chapters)
+		},
+	}
+}
+
+func galleryImageOrderRelation() relation {
+	return relation{
+		get: func(r models.Repository, id int) (string, error) {
+			ids, err := r.Gallery().GetImageOrder(id)
+			if err != nil {
+				return "", err
+			}
+			return orderedIDList(ids), nil
+		},
+		set: func(r models.Repository, id int, value string) error {
+			ids, err := parseIDList(value)
+			if err != nil {
+				return err
+			}
+			return r.Gallery().UpdateImageOrder(id, ids)
+		},
+	}
+}
+
+// performerImagesRelation tracks the checksums of the performer images in
+// order. Images are deleted when they are no longer used, so changes cannot
+// be reverted.
+func performerImagesRelation() relation {
+	return relation{
+		get: func(r models.Repository, id int) (string, error) {
+			qb := r.Performer()
+			count, err := qb.GetImageCount(id)
+			if err != nil {
+				return "", err
+			}
+
+			var checksums []string
+			for i := 0; i < count; i++ {
+				checksum, err := qb.GetImageChecksumByIndex(id, i)
+				if err != nil {
+					return "", err
+				}
+				checksums = append(checksums, checksum)
+			}
+			return strings.Join(checksums, ","), nil
+		},
+	}
+}
+
+// nameChecksum sets the checksum of performers, studios and movies, which is
+// derived from the name.
+func nameChecksum(obj interface{}) {
+	switch o := obj.(type) {
+	case *models.Performer:
+		o.Checksum = utils.MD5FromString(o.Name.String)
+	case *models.Studio:
+		o.Checksum = utils.MD5FromString(o.Name.String)
+	case *models.Movie:
+		o.Checksum = utils.MD5FromString(o.Name.String)
+	}
+}
+
+var entities = map[models.AuditEntityEnum]entity{
+	models.AuditEntityEnumScene: {
+		find: func(r models.Repository, id int) (interface{}, error) {
+			ret, err := r.Scene().Find(id)
+			if ret == nil {
+				return nil, err
+			}
+			return ret, err
+		},
+		update: func(r models.Repository, obj interface{}) error {
+			_, err := r.Scene().UpdateFull(*obj.(*models.Scene))
+			return err
+		},
+		fields: []string{"title", "details", "url", "date", "rating", "organized", "o_counter", "studio_id"},
+		relations: map[string]relation{
+			"gallery_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Scene().GetGalleryIDs },
+				func(r models.Repository) idsSetter { return r.Scene().UpdateGalleries },
+			),
+			"performer_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Scene().GetPerformerIDs },
+				func(r models.Repository) idsSetter { return r.Scene().UpdatePerformers },
+			),
+			"tag_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Scene().GetTagIDs },
+				func(r models.Repository) idsSetter { return r.Scene().UpdateTags },
+			),
+			"movies": {
+				get: func(r models.Repository, id int) (string, error) {
+					movies, err := r.Scene().GetMovies(id)
+					if err != nil {
+						return "", err
+					}
+					return sceneMoviesValue(movies)
+				},
+				set: func(r models.Repository, id int, value string) error {
+					movies, err := parseSceneMovies(id, value)
+					if err != nil {
+						return err
+					}
+					return r.Scene().UpdateMovies(id, movies)
+				},
+			},
+			"stash_ids": stashIDsRelation(
+				func(r models.Repository) stashIDsGetter { return r.Scene().GetStashIDs },
+				func(r models.Repository) stashIDsSetter { return r.Scene().UpdateStashIDs },
+			),
+			"custom_fields": customFieldsRelation(func(r models.Repository) models.CustomFieldsReaderWriter { return r.Scene() }),
+		},
+	},
+	models.AuditEntityEnumSceneMarker: {
+		find: func(r models.Repository, id int) (interface{}, error) {
+			ret, err := r.SceneMarker().Find(id)
+			if ret == nil {
+				return nil, err
+			}
+			return ret, err
+		},
+		update: func(r models.Repository, obj interface{}) error {
+			_, err := r.SceneMarker().Update(*obj.(*models.SceneMarker))
+			return err
+		},
+		fields: []string{"title", "seconds", "primary_tag_id", "scene_id"},
+		relations: map[string]relation{
+			"tag_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.SceneMarker().GetTagIDs },
+				func(r models.Repository) idsSetter { return r.SceneMarker().UpdateTags },
+			),
+		},
+	},
+	models.AuditEntityEnumImage: {
+		find: func(r models.Repository, id int) (interface{}, error) {
+			ret, err := r.Image().Find(id)
+			if ret == nil {
+				return nil, err
+			}
+			return ret, err
+		},
+		update: func(r models.Repository, obj interface{}) error {
+			_, err := r.Image().UpdateFull(*obj.(*models.Image))
+			return err
+		},
+		fields: []string{"title", "rating", "organized", "o_counter", "studio_id"},
+		relations: map[string]relation{
+			"gallery_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Image().GetGalleryIDs },
+				func(r models.Repository) idsSetter { return r.Image().UpdateGalleries },
+			),
+			"performer_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Image().GetPerformerIDs },
+				func(r models.Repository) idsSetter { return r.Image().UpdatePerformers },
+			),
+			"tag_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Image().GetTagIDs },
+				func(r models.Repository) idsSetter { return r.Image().UpdateTags },
+			),
+			"custom_fields": customFieldsRelation(func(r models.Repository) models.CustomFieldsReaderWriter { return r.Image() }),
+		},
+	},
+	models.AuditEntityEnumGallery: {
+		find: func(r models.Repository, id int) (interface{}, error) {
+			ret, err := r.Gallery().Find(id)
+			if ret == nil {
+				return nil, err
+			}
+			return ret, err
+		},
+		update: func(r models.Repository, obj interface{}) error {
+			_, err := r.Gallery().Update(*obj.(*models.Gallery))
+			return err
+		},
+		fields: []string{"title", "url", "date", "details", "rating", "organized", "studio_id", "cover_image_id"},
+		relations: map[string]relation{
+			"chapters":    galleryChaptersRelation(),
+			"image_order": galleryImageOrderRelation(),
+			"scene_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Gallery().GetSceneIDs },
+				func(r models.Repository) idsSetter { return r.Gallery().UpdateScenes },
+			),
+			"performer_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Gallery().GetPerformerIDs },
+				func(r models.Repository) idsSetter { return r.Gallery().UpdatePerformers },
+			),
+			"tag_ids": idsRelation(
+				func(r models.Repository) idsGetter { return r.Gallery().GetTagIDs },
+				func(r models.Repository) idsSetter { return r.Gallery().UpdateTags },
+			),
+			"custom_fields": customFieldsRelation(func(r models.Repository) models.CustomFieldsReaderWriter { return r.Gallery() }),
+		},
+	},
+	models.AuditEntityEnumPerformer: {
+		find: func(r models.Repository, id int) (interface{}, error) {
+			ret, err := r.Performer().Find(id)
+			if ret == nil {
+				return nil, err
+			}
+			return ret, err
+		},
+		update: func(r models.Repository, obj interface{}) error {
+			_, err := r.Performer().UpdateFull(*obj.(*models.Performer))
+			return err
+		},
+		prepare: nameChecksum,
+		fields: []string{"name", "gender", "url", "twitter", "instagram", "birthdate", "ethnicity", "country", "eye_color",
+			"height", "measurements", "fake_tits", "career_length", "tattoos", "piercings", "aliases", "favorite"},
+		relations: map[string]relation{
+			"images": performerImagesRelation(),
+			"stash_ids": stashIDsRelation(
+				func(r models.Repository) stashIDsGetter { return r.Performer().GetStashIDs },
+				func(r models.Repository) stashIDsSetter { return r.Performer().UpdateStashIDs },
+			),
+			"custom_fields": customFieldsRelation(func(r models.Repository) models.CustomFieldsReaderWriter { return r.Performer() }),
+		},
+	},
+	models.AuditEntityEnumStudio: {
+		find: func(r models.Repository, id int) (interface{}, error) {
+			ret, err := r.Studio().Find(id)
+			if ret == nil {
+				return nil, err
+			}
+			return ret, err
+		},
+		update: func(r models.Repository, obj interface{}) error {
+			_, err := r.Studio().UpdateFull(*obj.(*models.Studio))
+			return err
+		},
+		prepare: nameChecksum,
+		fields:  []string{"name", "url", "parent_id"},
+		relations: map[string]relation{
+			"stash_ids": stashIDsRelation(
+				func(r models.Repository) stashIDsGetter { return r.Studio().GetStashIDs },
+				func(r models.Repository) stashIDsSetter { return r.Studio().UpdateStashIDs },
+			),
+			"custom_fields": customFieldsRelation(func(r models.Repository) models.CustomFieldsReaderWriter { return r.Studio() }),
+		},
+	},
+	models.AuditEntityEnumMovie: {
+		find: func(r models.Repository, id int) (interface{}, error) {
+			ret, err := r.Movie().Find(id)
+			if ret == nil {
+				return nil, err
+			}
+			return ret, err
+		},
+		update: func(r models.Repository, obj interface{}) error {
+			_, err := r.Movie().UpdateFull(*obj.(*models.Movie))
+			return err
+		},
+		prepare: nameChecksum,
+		fields:  []string{"name", "aliases", "duration", "date", "rating", "studio_id", "director", "synopsis", "url"},
+		relations: map[string]relation{
+			"custom_fields": customFieldsRelation(func(r models.Repository) models.CustomFieldsReaderWriter { return r.Movie() }),
+		},
+	},
+	models.AuditEntityEnumTag: {
+		find: func(r models.Repository, id int) (interface{}, error) {
+			ret, err := r.Tag().Find(id)
+			if ret == nil {
+				return nil, err
+			}
+			return ret, err
+		},
+		update: func(r models.Repository, obj interface{}) error {
+			_, err := r.Tag().Update(*obj.(*models.Tag))
+			return err
+		},
+		fields: []string{"name"},
+	},
+}
@@ -0,0 +1,292 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// structField returns the addressable field of the object pointed to by obj
+// with the provided db column name.
+func structField(obj interface{}, column string) (reflect.Value, error) {
+	v := reflect.ValueOf(obj).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("db"), ",")[0] == column {
+			return v.Field(i), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s has no field %s", t.Name(), column)
+}
+
+// getField returns the value of the field of obj with the provided db column
+// name. Returns nil if the value is null.
+func getField(obj interface{}, column string) (*string, error) {
+	f, err := structField(obj, column)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret string
+	switch v := f.Interface().(type) {
+	case sql.NullString:
+		if !v.Valid {
+			return nil, nil
+		}
+		ret = v.String
+	case sql.NullInt64:
+		if !v.Valid {
+			return nil, nil
+		}
+		ret = strconv.FormatInt(v.Int64, 10)
+	case sql.NullFloat64:
+		if !v.Valid {
+			return nil, nil
+		}
+		ret = strconv.FormatFloat(v.Float64, 'f', -1, 64)
+	case sql.NullBool:
+		if !v.Valid {
+			return nil, nil
+		}
+		ret = strconv.FormatBool(v.Bool)
+	case models.SQLiteDate:
+		if !v.Valid {
+			return nil, nil
+		}
+		ret = v.String
+	case string:
+		ret = v
+	case bool:
+		ret = strconv.FormatBool(v)
+	case int:
+		ret = strconv.Itoa(v)
+	case float64:
+		ret = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, fmt.Errorf("unsupported type %T for field %s", v, column)
+	}
+
+	return &ret, nil
+}
+
+// setField sets the field of obj with the provided db column name to the
+// provided value, as returned by getField.
+func setField(obj interface{}, column string, value *string) error {
+	f, err := structField(obj, column)
+	if err != nil {
+		return err
+	}
+
+	var s string
+	if value != nil {
+		s = *value
+	}
+
+	var newValue interface{}
+	switch f.Interface().(type) {
+	case sql.NullString:
+		newValue = sql.NullString{String: s, Valid: value != nil}
+	case sql.NullInt64:
+		ret := sql.NullInt64{Valid: value != nil}
+		if ret.Valid {
+			if ret.Int64, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return err
+			}
+		}
+		newValue = ret
+	case sql.NullFloat64:
+		ret := sql.NullFloat64{Valid: value != nil}
+		if ret.Valid {
+			if ret.Float64, err = strconv.ParseFloat(s, 64); err != nil {
+				return err
+			}
+		}
+		newValue = ret
+	case sql.NullBool:
+		ret := sql.NullBool{Valid: value != nil}
+		if ret.Valid {
+			if ret.Bool, err = strconv.ParseBool(s); err != nil {
+				return err
+			}
+		}
+		newValue = ret
+	case models.SQLiteDate:
+		newValue = models.SQLiteDate{String: s, Valid: value != nil}
+	case string:
+		newValue = s
+	case bool:
+		if newValue, err = strconv.ParseBool(s); err != nil {
+			return err
+		}
+	case int:
+		if newValue, err = strconv.Atoi(s); err != nil {
+			return err
+		}
+	case float64:
+		if newValue, err = strconv.ParseFloat(s, 64); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported type %T for field %s", f.Interface(), column)
+	}
+
+	f.Set(reflect.ValueOf(newValue))
+	return nil
+}
+
+// touch sets the updated time of obj to now.
+func touch(obj interface{}) error {
+	f, err := structField(obj, "updated_at")
+	if err != nil {
+		return err
+	}
+
+	f.Set(reflect.ValueOf(models.SQLiteTimestamp{Timestamp: time.Now()}))
+	return nil
+}
+
+// idList returns the sorted ids as a comma-separated string.
+func idList(ids []int) string {
+	sorted := append([]int(nil), ids...)
+	sort.Ints(sorted)
+
+	var s []string
+	for _, id := range sorted {
+		s = append(s, strconv.Itoa(id))
+	}
+	return strings.Join(s, ",")
+}
+
+// orderedIDList returns the ids in their order as a comma-separated string.
+func orderedIDList(ids []int) string {
+	var s []string
+	for _, id := range ids {
+		s = append(s, strconv.Itoa(id))
+	}
+	return strings.Join(s, ",")
+}
+
+// parseIDList returns the ids of a string returned by idList or
+// orderedIDList.
+func parseIDList(s string) ([]int, error) {
+	var ret []int
+	if s == "" {
+		return ret, nil
+	}
+
+	for _, idStr := range strings.Split(s, ",") {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id list %q", s)
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
+
+func jsonValue(v interface{}) (string, error) {
+	ret, err := json.Marshal(v)
+	return string(ret), err
+}
+
+type sceneMovie struct {
+	MovieID    int    `json:"movie_id"`
+	SceneIndex *int64 `json:"scene_index,omitempty"`
+}
+
+func sceneMoviesValue(movies []models.MoviesScenes) (string, error) {
+	ret := []sceneMovie{}
+	for _, m := range movies {
+		sm := sceneMovie{MovieID: m.MovieID}
+		if m.SceneIndex.Valid {
+			index := m.SceneIndex.Int64
+			sm.SceneIndex = &index
+		}
+		ret = append(ret, sm)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].MovieID < ret[j].MovieID
+	})
+
+	return jsonValue(ret)
+}
+
+func parseSceneMovies(sceneID int, s string) ([]models.MoviesScenes, error) {
+	var movies []sceneMovie
+	if err := json.Unmarshal([]byte(s), &movies); err != nil {
+		return nil, err
+	}
+
+	var ret []models.MoviesScenes
+	for _, m := range movies {
+		ms := models.MoviesScenes{MovieID: m.MovieID, SceneID: sceneID}
+		if m.SceneIndex != nil {
+			ms.SceneIndex = sql.NullInt64{Int64: *m.SceneIndex, Valid: true}
+		}
+		ret = append(ret, ms)
+	}
+	return ret, nil
+}
+
+func stashIDsValue(stashIDs []*models.StashID) (string, error) {
+	ret := []models.StashID{}
+	for _, s := range stashIDs {
+		ret = append(ret, *s)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Endpoint != ret[j].Endpoint {
+			return ret[i].Endpoint < ret[j].Endpoint
+		}
+		return ret[i].StashID < ret[j].StashID
+	})
+
+	return jsonValue(ret)
+}
+
+func parseStashIDs(s string) ([]models.StashID, error) {
+	var ret []models.StashID
+	err := json.Unmarshal([]byte(s), &ret)
+	return ret, err
+}
+
+func galleryChaptersValue(chapters []*models.GalleryChapter) (string, error) {
+	ret := []models.GalleryChapter{}
+	for _, c := range chapters {
+		ret = append(ret, *c)
+	}
+
+	return jsonValue(ret)
+}
+
+func parseGalleryChapters(s string) ([]models.GalleryChapter, error) {
+	var ret []models.GalleryChapter
+	err := json.Unmarshal([]byte(s), &ret)
+	return ret, err
+}
+
+func customFieldsValue(fields models.CustomFieldMap) (string, error) {
+	if fields == nil {
+		fields = models.CustomFieldMap{}
+	}
+	// map keys are sorted when encoded
+	return jsonValue(fields)
+}
+
+func parseCustomFields(s string) (models.CustomFieldMap, error) {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+
+	var ret models.CustomFieldMap
+	err := decoder.Decode(&ret)
+	return ret, err
+}
@@ -0,0 +1,111 @@
+// Package blob stores blobs of data addressed by their checksum.
+package blob
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+var checksumRE = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// FilesystemStore stores blobs in files named by their MD5 checksum. Files
+// are kept in two levels of subdirectories named by the first four
+// characters of the checksum, so that directories don't grow too large.
+type FilesystemStore struct {
+	Path string
+}
+
+// NewFilesystemStore returns a store of blobs in the provided directory.
+func NewFilesystemStore(path string) *FilesystemStore {
+	return &FilesystemStore{
+		Path: path,
+	}
+}
+
+func (s *FilesystemStore) path(checksum string) string {
+	if len(checksum) < 4 {
+		return filepath.Join(s.Path, checksum)
+	}
+	return filepath.Join(s.Path, checksum[0:2], checksum[2:4], checksum)
+}
+
+// Read returns the data of the blob with the provided checksum.
+func (s *FilesystemStore) Read(checksum string) ([]byte, error) {
+	return ioutil.ReadFile(s.path(checksum))
+}
+
+// Exists returns true if the blob with the provided checksum is stored.
+func (s *FilesystemStore) Exists(checksum string) bool {
+	_, err := os.Stat(s.path(checksum))
+	return err == nil
+}
+
+// Write stores the data of the blob with the provided checksum. The file is
+// not written if it already exists, since its contents are the same. The
+// data is written to a temporary file first, so that a partially written
+// blob is never read.
+func (s *FilesystemStore) Write(checksum string, data []byte) error {
+	path := s.path(checksum)
+	if s.Exists(checksum) {
+		return nil
+	}
+
+	dir := filepath.Dir(path)
+	if err := utils.EnsureDirAll(dir); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, checksum+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes the blob with the provided checksum. Deleting a blob that
+// is not stored is not an error.
+func (s *FilesystemStore) Delete(checksum string) error {
+	err := os.Remove(s.path(checksum))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Walk calls fn with the checksum of each stored blob. Files that are not
+// named by a checksum, such as temporary files, are skipped.
+func (s *FilesystemStore) Walk(fn func(checksum string) error) error {
+	err := filepath.Walk(s.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !checksumRE.MatchString(info.Name()) {
+			return nil
+		}
+
+		return fn(info.Name())
+	})
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package blob
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilesystemStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewFilesystemStore(filepath.Join(dir, "blobs"))
+
+	// walking a store that has not been written to is not an error
+	assert.Nil(t, s.Walk(func(checksum string) error {
+		t.Errorf("unexpected blob %s", checksum)
+		return nil
+	}))
+
+	const checksum = "5d41402abc4b2a76b9719d911017c592"
+	data := []byte("hello")
+
+	assert.False(t, s.Exists(checksum))
+	assert.Nil(t, s.Write(checksum, data))
+	assert.True(t, s.Exists(checksum))
+	assert.FileExists(t, filepath.Join(dir, "blobs", "5d", "41", checksum))
+
+	// writing an existing blob leaves it as is
+	assert.Nil(t, s.Write(checksum, data))
+
+	got, err := s.Read(checksum)
+	assert.Nil(t, err)
+	assert.Equal(t, data, got)
+
+	// files that are not blobs are skipped
+	if err := ioutil.WriteFile(filepath.Join(dir, "blobs", "5d", "41", checksum+".1.tmp"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var walked []string
+	assert.Nil(t, s.Walk(func(checksum string) error {
+		walked = append(walked, checksum)
+		return nil
+	}))
+	assert.Equal(t, []string{checksum}, walked)
+
+	assert.Nil(t, s.Delete(checksum))
+	assert.False(t, s.Exists(checksum))
+	assert.Nil(t, s.Delete(checksum))
+
+	_, err = s.Read(checksum)
+	assert.True(t, os.IsNotExist(err))
+}
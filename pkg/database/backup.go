@@ -0,0 +1,301 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/stashapp/stash/pkg/logger"
+)
+
+const backupTimeFormat = "20060102_150405"
+
+// scheduledBackupExtension is appended to the names of scheduled backups, so
+// that rotating them does not remove other backups, such as those made
+// before migrating.
+const scheduledBackupExtension = ".scheduled"
+
+// BackupFile describes a database backup file.
+type BackupFile struct {
+	Path          string
+	SchemaVersion uint
+	Time          time.Time
+	Size          int64
+	// Scheduled is true if the backup was made by a scheduled backup.
+	Scheduled bool
+}
+
+// DatabaseBackupPathInDir returns the path of a new backup file for the
+// current database in the provided directory.
+func DatabaseBackupPathInDir(dir string) string {
+	fn := fmt.Sprintf("%s.%d.%s", filepath.Base(dbPath), databaseSchemaVersion, time.Now().Format(backupTimeFormat))
+	return filepath.Join(dir, fn)
+}
+
+// ScheduledBackupPathInDir returns the path of a new scheduled backup file
+// for the current database in the provided directory.
+func ScheduledBackupPathInDir(dir string) string {
+	return DatabaseBackupPathInDir(dir) + scheduledBackupExtension
+}
+
+func backupFileRegex() *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(filepath.Base(dbPath)) + `\.(\d+)\.(\d{8}_\d{6})(` + regexp.QuoteMeta(scheduledBackupExtension) + `)?$`)
+}
+
+// ListBackups returns the backups of the current database in the provided
+// directory, ordered from newest to oldest.
+func ListBackups(dir string) ([]BackupFile, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	re := backupFileRegex()
+
+	var ret []BackupFile
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		match := re.FindStringSubmatch(f.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			continue
+		}
+
+		t, err := time.ParseInLocation(backupTimeFormat, match[2], time.Local)
+		if err != nil {
+			continue
+		}
+
+		ret = append(ret, BackupFile{
+			Path:          filepath.Join(dir, f.Name()),
+			SchemaVersion: uint(version),
+			Time:          t,
+			Size:          f.Size(),
+			Scheduled:     match[3] != "",
+		})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Time.After(ret[j].Time)
+	})
+
+	return ret, nil
+}
+
+// RotateBackups removes the oldest scheduled backups in the provided
+// directory so that no more than keep scheduled backups remain. Other
+// backups are not removed. Does nothing if keep is zero or less.
+func RotateBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return err
+	}
+
+	var scheduled []BackupFile
+	for _, b := range backups {
+		if b.Scheduled {
+			scheduled = append(scheduled, b)
+		}
+	}
+
+	if len(scheduled) <= keep {
+		return nil
+	}
+
+	for _, b := range scheduled[keep:] {
+		logger.Infof("Removing old database backup: %s", b.Path)
+		if err := os.Remove(b.Path); err != nil {
+			return fmt.Errorf("error removing database backup %s: %s", b.Path, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// backupSchemaVersion returns the schema version of the database at the
+// provided path. Returns an error if the database is in a dirty state.
+func backupSchemaVersion(backupPath string) (uint, error) {
+	db, err := sqlx.Connect(sqlite3Driver, "file:"+backupPath+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("error opening backup %s: %s", backupPath, err.Error())
+	}
+	defer db.Close()
+
+	var version uint
+	var dirty bool
+	row := db.QueryRowx("SELECT version, dirty FROM schema_migrations LIMIT 1")
+	if err := row.Scan(&version, &dirty); err != nil {
+		return 0, fmt.Errorf("error reading schema version of backup %s: %s", backupPath, err.Error())
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("backup %s is in a dirty migration state", backupPath)
+	}
+
+	return version, nil
+}
+
+// Restore replaces the current database with a copy of the backup at
+// backupPath. The backup file itself is left in place. The current database
+// is first copied to a file next to it with the extension .pre-restore. The
+// database connection is closed while the file is replaced and then
+// reopened, running migrations if the backup has an older schema version.
+// If the restored database cannot be opened or migrated, the copy of the
+// current database is moved back and reopened. Returns true if migrations
+// were run.
+func Restore(backupPath string) (bool, error) {
+	version, err := backupSchemaVersion(backupPath)
+	if err != nil {
+		return false, err
+	}
+
+	if version > appSchemaVersion {
+		return false, fmt.Errorf("backup schema version %d is incompatible with required schema version %d", version, appSchemaVersion)
+	}
+
+	// copy the backup next to the database so that it can be renamed into
+	// place
+	restorePath := dbPath + ".restore"
+	if err := copyDatabase(backupPath, nil, restorePath); err != nil {
+		return false, err
+	}
+
+	preRestorePath := dbPath + ".pre-restore"
+	if err := copyDatabase(dbPath, DB, preRestorePath); err != nil {
+		os.Remove(restorePath)
+		return false, fmt.Errorf("error copying the current database: %s", err.Error())
+	}
+
+	if err := closeForRestore(); err != nil {
+		return false, err
+	}
+
+	migrated, err := openRestored(restorePath)
+	if err != nil {
+		logger.Errorf("Error restoring database backup %s: %s", backupPath, err.Error())
+
+		if rollbackErr := rollbackRestore(preRestorePath); rollbackErr != nil {
+			return false, fmt.Errorf("error restoring backup: %s. Error reverting to the previous database %s: %s", err.Error(), preRestorePath, rollbackErr.Error())
+		}
+		return false, err
+	}
+
+	return migrated, nil
+}
+
+// copyDatabase copies the database at srcPath, using db if it is already
+// open, to destPath, replacing any existing file.
+func copyDatabase(srcPath string, db *sqlx.DB, destPath string) error {
+	if err := os.Remove(destPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if db == nil {
+		var err error
+		db, err = sqlx.Connect(sqlite3Driver, "file:"+srcPath+"?mode=ro")
+		if err != nil {
+			return fmt.Errorf("error opening database %s: %s", srcPath, err.Error())
+		}
+		defer db.Close()
+	}
+
+	return Backup(db, destPath)
+}
+
+func closeForRestore() error {
+	if DB != nil {
+		if err := DB.Close(); err != nil {
+			return errors.New("Error closing database: " + err.Error())
+		}
+		DB = nil
+	}
+
+	if err := removeWALFiles(dbPath); err != nil {
+		DB = open(dbPath, false)
+		return err
+	}
+
+	return nil
+}
+
+// openRestored moves the restored database into place and opens it,
+// running migrations if needed. Returns true if migrations were run.
+func openRestored(restorePath string) (migrated bool, err error) {
+	// opening and migrating the database panics on some errors
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	if err := RestoreFromBackup(restorePath); err != nil {
+		return false, err
+	}
+
+	if err := getDatabaseSchemaVersion(); err != nil {
+		return false, err
+	}
+
+	if NeedsMigration() {
+		if err := RunMigrations(); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	const disableForeignKeys = false
+	DB = open(dbPath, disableForeignKeys)
+
+	return false, nil
+}
+
+// rollbackRestore moves the copy of the database made before restoring
+// back into place and reopens it.
+func rollbackRestore(preRestorePath string) error {
+	if DB != nil {
+		DB.Close()
+		DB = nil
+	}
+
+	if err := removeWALFiles(dbPath); err != nil {
+		return err
+	}
+
+	if err := RestoreFromBackup(preRestorePath); err != nil {
+		return err
+	}
+
+	if err := getDatabaseSchemaVersion(); err != nil {
+		return err
+	}
+
+	// a database that needed migrating was not open before restoring
+	if !NeedsMigration() {
+		const disableForeignKeys = false
+		DB = open(dbPath, disableForeignKeys)
+	}
+
+	return nil
+}
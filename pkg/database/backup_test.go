@@ -0,0 +1,80 @@
+package database
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAndRotateBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-backup-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	origPath := dbPath
+	dbPath = filepath.Join(dir, "stash-go.sqlite")
+	defer func() {
+		dbPath = origPath
+	}()
+
+	files := []string{
+		"stash-go.sqlite.20.20210101_100000.scheduled",
+		"stash-go.sqlite.21.20210301_100000.scheduled",
+		"stash-go.sqlite.21.20210201_100000.scheduled",
+		// not a scheduled backup
+		"stash-go.sqlite.19.20201201_100000",
+		// not backups of this database
+		"stash-go.sqlite",
+		"stash-go.sqlite.21.invalid",
+		"other.sqlite.21.20210401_100000.scheduled",
+	}
+
+	for _, f := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), []byte("test"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := ListBackups(dir)
+	assert.Nil(t, err)
+	if assert.Len(t, backups, 4) {
+		assert.Equal(t, filepath.Join(dir, files[1]), backups[0].Path)
+		assert.Equal(t, uint(21), backups[0].SchemaVersion)
+		assert.Equal(t, int64(4), backups[0].Size)
+		assert.True(t, backups[0].Scheduled)
+		assert.Equal(t, filepath.Join(dir, files[2]), backups[1].Path)
+		assert.Equal(t, filepath.Join(dir, files[0]), backups[2].Path)
+		assert.Equal(t, uint(20), backups[2].SchemaVersion)
+		assert.Equal(t, filepath.Join(dir, files[3]), backups[3].Path)
+		assert.False(t, backups[3].Scheduled)
+	}
+
+	// keeping zero backups keeps all of them
+	assert.Nil(t, RotateBackups(dir, 0))
+	backups, _ = ListBackups(dir)
+	assert.Len(t, backups, 4)
+
+	// only scheduled backups are removed
+	assert.Nil(t, RotateBackups(dir, 1))
+	backups, _ = ListBackups(dir)
+	if assert.Len(t, backups, 2) {
+		assert.Equal(t, filepath.Join(dir, files[1]), backups[0].Path)
+		assert.Equal(t, filepath.Join(dir, files[3]), backups[1].Path)
+	}
+
+	// non-backup files are untouched
+	for _, f := range files[4:] {
+		_, err := os.Stat(filepath.Join(dir, f))
+		assert.Nil(t, err)
+	}
+
+	// missing directory returns no backups
+	backups, err = ListBackups(filepath.Join(dir, "missing"))
+	assert.Nil(t, err)
+	assert.Len(t, backups, 0)
+}
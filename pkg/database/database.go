@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"time"
+	"path/filepath"
 
 	"github.com/fvbommel/sortorder"
 	"github.com/gobuffalo/packr/v2"
@@ -21,7 +21,7 @@ import (
 
 var DB *sqlx.DB
 var dbPath string
-var appSchemaVersion uint = 18
+var appSchemaVersion uint = 29
 var databaseSchemaVersion uint
 
 const sqlite3Driver = "sqlite3ex"
@@ -38,6 +38,10 @@ func init() {
 func Initialize(databasePath string) bool {
 	dbPath = databasePath
 
+	if err := checkFTS5(); err != nil {
+		panic(err)
+	}
+
 	if err := getDatabaseSchemaVersion(); err != nil {
 		panic(err)
 	}
@@ -96,18 +100,27 @@ func Reset(databasePath string) error {
 		return errors.New("Error removing database: " + err.Error())
 	}
 
-	// remove the -shm, -wal files ( if they exist )
+	if err := removeWALFiles(databasePath); err != nil {
+		return err
+	}
+
+	Initialize(databasePath)
+	return nil
+}
+
+// removeWALFiles removes the -shm and -wal files of the database, if they
+// exist.
+func removeWALFiles(databasePath string) error {
 	walFiles := []string{databasePath + "-shm", databasePath + "-wal"}
 	for _, wf := range walFiles {
 		if exists, _ := utils.FileExists(wf); exists {
-			err = os.Remove(wf)
+			err := os.Remove(wf)
 			if err != nil {
 				return errors.New("Error removing database: " + err.Error())
 			}
 		}
 	}
 
-	Initialize(databasePath)
 	return nil
 }
 
@@ -147,7 +160,7 @@ func AppSchemaVersion() uint {
 }
 
 func DatabaseBackupPath() string {
-	return fmt.Sprintf("%s.%d.%s", dbPath, databaseSchemaVersion, time.Now().Format("20060102_150405"))
+	return DatabaseBackupPathInDir(filepath.Dir(dbPath))
 }
 
 func Version() uint {
@@ -155,13 +168,19 @@ func Version() uint {
 }
 
 func getMigrate() (*migrate.Migrate, error) {
+	return getMigrateForPath(dbPath)
+}
+
+// getMigrateForPath returns a migrate instance for the database at the
+// provided path. Foreign keys are disabled for the migration connection.
+func getMigrateForPath(databasePath string) (*migrate.Migrate, error) {
 	migrationsBox := packr.New("Migrations Box", "./migrations")
 	packrSource := &Packr2Source{
 		Box:        migrationsBox,
 		Migrations: source.NewMigrations(),
 	}
 
-	databasePath := utils.FixWindowsPath(dbPath)
+	databasePath = utils.FixWindowsPath(databasePath)
 	s, _ := WithInstance(packrSource)
 
 	const disableForeignKeys = true
@@ -192,8 +211,35 @@ func getDatabaseSchemaVersion() error {
 	return nil
 }
 
+// errFTS5Unsupported is returned if SQLite was built without full-text
+// search, which is required by the database schema since version 20.
+var errFTS5Unsupported = errors.New("SQLite full-text search (FTS5) is not supported by this build. Build with the sqlite_fts5 tag")
+
+// checkFTS5 returns an error if SQLite was built without FTS5 support.
+func checkFTS5() error {
+	db, err := sql.Open(sqlite3Driver, ":memory:")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var enabled bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return err
+	}
+
+	if !enabled {
+		return errFTS5Unsupported
+	}
+	return nil
+}
+
 // Migrate the database
 func RunMigrations() error {
+	if err := checkFTS5(); err != nil {
+		return err
+	}
+
 	m, err := getMigrate()
 	if err != nil {
 		panic(err.Error())
@@ -234,6 +280,7 @@ func registerCustomDriver() {
 				funcs := map[string]interface{}{
 					"regexp":            regexFn,
 					"durationToTinyInt": durationToTinyIntFn,
+					"md5":               md5Fn,
 				}
 
 				for name, fn := range funcs {
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"crypto/md5"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -35,3 +37,9 @@ func durationToTinyIntFn(str string) (int64, error) {
 
 	return int64(seconds), nil
 }
+
+// md5Fn returns the hex encoded MD5 checksum of the data. It is used to
+// calculate the checksums of blobs in migrations.
+func md5Fn(data []byte) string {
+	return fmt.Sprintf("%x", md5.Sum(data))
+}
@@ -0,0 +1,269 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/stashapp/stash/pkg/blob"
+)
+
+// orphanCheck finds rows of table whose column references a missing row of
+// parent. The referenced column of parent is id unless parentColumn is set.
+// Orphaned rows are deleted when repaired, unless setNull is true, in which
+// case the column is cleared instead. Rows with a NULL column are only
+// orphaned if the column is required.
+type orphanCheck struct {
+	table        string
+	column       string
+	parent       string
+	parentColumn string
+	required     bool
+	setNull      bool
+}
+
+func (c orphanCheck) where() string {
+	parentColumn := c.parentColumn
+	if parentColumn == "" {
+		parentColumn = "id"
+	}
+
+	ret := fmt.Sprintf("`%s` NOT IN (SELECT `%s` FROM `%s`)", c.column, parentColumn, c.parent)
+	if c.required {
+		return fmt.Sprintf("(`%s` IS NULL OR %s)", c.column, ret)
+	}
+	return fmt.Sprintf("(`%s` IS NOT NULL AND %s)", c.column, ret)
+}
+
+func (c orphanCheck) description() string {
+	if c.setNull {
+		return fmt.Sprintf("%s with missing %s.%s", c.table, c.parent, c.column)
+	}
+	return fmt.Sprintf("%s orphaned by missing %s", c.table, c.parent)
+}
+
+// orphanChecks are ordered so that rows removed by a check are included in
+// the checks of the tables that reference them.
+var orphanChecks = []orphanCheck{
+	{table: "scene_markers", column: "scene_id", parent: "scenes", required: true},
+	{table: "scene_markers", column: "primary_tag_id", parent: "tags", required: true},
+	{table: "scene_markers_tags", column: "scene_marker_id", parent: "scene_markers", required: true},
+	{table: "scene_markers_tags", column: "tag_id", parent: "tags", required: true},
+
+	{table: "scenes_tags", column: "scene_id", parent: "scenes", required: true},
+	{table: "scenes_tags", column: "tag_id", parent: "tags", required: true},
+	{table: "performers_scenes", column: "scene_id", parent: "scenes", required: true},
+	{table: "performers_scenes", column: "performer_id", parent: "performers", required: true},
+	{table: "movies_scenes", column: "scene_id", parent: "scenes", required: true},
+	{table: "movies_scenes", column: "movie_id", parent: "movies", required: true},
+	{table: "scenes_galleries", column: "scene_id", parent: "scenes", required: true},
+	{table: "scenes_galleries", column: "gallery_id", parent: "galleries", required: true},
+	{table: "scene_stash_ids", column: "scene_id", parent: "scenes", required: true},
+	{table: "scenes_cover", column: "scene_id", parent: "scenes", required: true},
+
+	{table: "galleries_images", column: "gallery_id", parent: "galleries", required: true},
+	{table: "galleries_images", column: "image_id", parent: "images", required: true},
+	{table: "galleries_tags", column: "gallery_id", parent: "galleries", required: true},
+	{table: "galleries_chapters", column: "gallery_id", parent: "galleries", required: true},
+	{table: "galleries_tags", column: "tag_id", parent: "tags", required: true},
+	{table: "images_tags", column: "image_id", parent: "images", required: true},
+	{table: "images_tags", column: "tag_id", parent: "tags", required: true},
+	{table: "images_keywords", column: "image_id", parent: "images", required: true},
+	{table: "performers_images", column: "image_id", parent: "images", required: true},
+	{table: "performers_images", column: "performer_id", parent: "performers", required: true},
+	{table: "performers_galleries", column: "gallery_id", parent: "galleries", required: true},
+	{table: "performers_galleries", column: "performer_id", parent: "performers", required: true},
+
+	{table: "performers_image", column: "performer_id", parent: "performers", required: true},
+	{table: "performer_stash_ids", column: "performer_id", parent: "performers", required: true},
+	{table: "studios_image", column: "studio_id", parent: "studios", required: true},
+	{table: "studio_stash_ids", column: "studio_id", parent: "studios", required: true},
+	{table: "movies_images", column: "movie_id", parent: "movies", required: true},
+	{table: "tags_image", column: "tag_id", parent: "tags", required: true},
+
+	{table: "performers_image", column: "image_blob", parent: "blobs", parentColumn: "checksum", required: true},
+	{table: "studios_image", column: "image_blob", parent: "blobs", parentColumn: "checksum", required: true},
+	{table: "tags_image", column: "image_blob", parent: "blobs", parentColumn: "checksum", required: true},
+	{table: "movies_images", column: "front_image_blob", parent: "blobs", parentColumn: "checksum", required: true},
+	{table: "movies_images", column: "back_image_blob", parent: "blobs", parentColumn: "checksum", setNull: true},
+	{table: "scenes_cover", column: "cover_blob", parent: "blobs", parentColumn: "checksum", required: true},
+
+	{table: "scene_custom_fields", column: "scene_id", parent: "scenes", required: true},
+	{table: "performer_custom_fields", column: "performer_id", parent: "performers", required: true},
+	{table: "studio_custom_fields", column: "studio_id", parent: "studios", required: true},
+	{table: "movie_custom_fields", column: "movie_id", parent: "movies", required: true},
+	{table: "gallery_custom_fields", column: "gallery_id", parent: "galleries", required: true},
+	{table: "image_custom_fields", column: "image_id", parent: "images", required: true},
+
+	{table: "scenes", column: "studio_id", parent: "studios", setNull: true},
+	{table: "images", column: "studio_id", parent: "studios", setNull: true},
+	{table: "galleries", column: "studio_id", parent: "studios", setNull: true},
+	{table: "galleries", column: "cover_image_id", parent: "images", setNull: true},
+	{table: "movies", column: "studio_id", parent: "studios", setNull: true},
+	{table: "studios", column: "parent_id", parent: "studios", setNull: true},
+}
+
+// IntegrityProblem is a problem found by an integrity check.
+type IntegrityProblem struct {
+	Description string
+	Count       int
+}
+
+// IntegrityReport is the result of checking the integrity of the database.
+type IntegrityReport struct {
+	// Errors are the errors reported by PRAGMA integrity_check. These
+	// cannot be repaired.
+	Errors []string
+	// ForeignKeyViolations are the foreign key violations reported by
+	// PRAGMA foreign_key_check, grouped by table and parent table.
+	ForeignKeyViolations []IntegrityProblem
+	// Orphans are the orphaned rows found, or repaired if the check
+	// repaired the database.
+	Orphans []IntegrityProblem
+	// MissingBlobs are the checksums of the blobs stored in the filesystem
+	// whose files are missing. These cannot be repaired.
+	MissingBlobs []string
+}
+
+// OK returns true if no problems were found.
+func (r IntegrityReport) OK() bool {
+	return len(r.Errors) == 0 && len(r.ForeignKeyViolations) == 0 && len(r.Orphans) == 0 && len(r.MissingBlobs) == 0
+}
+
+// CheckIntegrity checks the integrity of the database, and looks for rows
+// that reference rows that no longer exist. If repair is true, the orphaned
+// rows are deleted, or their references cleared, in a single transaction.
+// The foreign key violations in the returned report are those remaining
+// after the repair. If blobsPath is not empty, the files of the blobs
+// stored in the filesystem are checked to exist in blobsPath.
+func CheckIntegrity(repair bool, blobsPath string) (*IntegrityReport, error) {
+	ret := &IntegrityReport{}
+
+	var results []string
+	if err := DB.Select(&results, "PRAGMA integrity_check"); err != nil {
+		return nil, fmt.Errorf("error running integrity check: %s", err.Error())
+	}
+	for _, r := range results {
+		if r != "ok" {
+			ret.Errors = append(ret.Errors, r)
+		}
+	}
+
+	if repair {
+		if err := WithTxn(func(tx *sqlx.Tx) error {
+			var err error
+			ret.Orphans, err = repairOrphans(tx)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		ret.Orphans, err = countOrphans(DB)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	ret.ForeignKeyViolations, err = foreignKeyViolations(DB)
+	if err != nil {
+		return nil, err
+	}
+
+	if blobsPath != "" {
+		ret.MissingBlobs, err = missingBlobs(DB, blob.NewFilesystemStore(blobsPath))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func countOrphans(db *sqlx.DB) ([]IntegrityProblem, error) {
+	var ret []IntegrityProblem
+	for _, c := range orphanChecks {
+		var count int
+		query := fmt.Sprintf("SELECT COUNT(*) FROM `%s` WHERE %s", c.table, c.where())
+		if err := db.Get(&count, query); err != nil {
+			return nil, fmt.Errorf("error checking %s: %s", c.description(), err.Error())
+		}
+
+		if count > 0 {
+			ret = append(ret, IntegrityProblem{Description: c.description(), Count: count})
+		}
+	}
+
+	return ret, nil
+}
+
+func repairOrphans(tx *sqlx.Tx) ([]IntegrityProblem, error) {
+	var ret []IntegrityProblem
+	for _, c := range orphanChecks {
+		query := fmt.Sprintf("DELETE FROM `%s` WHERE %s", c.table, c.where())
+		if c.setNull {
+			query = fmt.Sprintf("UPDATE `%s` SET `%s` = NULL WHERE %s", c.table, c.column, c.where())
+		}
+
+		result, err := tx.Exec(query)
+		if err != nil {
+			return nil, fmt.Errorf("error repairing %s: %s", c.description(), err.Error())
+		}
+
+		count, _ := result.RowsAffected()
+		if count > 0 {
+			ret = append(ret, IntegrityProblem{Description: c.description(), Count: int(count)})
+		}
+	}
+
+	return ret, nil
+}
+
+func foreignKeyViolations(db *sqlx.DB) ([]IntegrityProblem, error) {
+	rows, err := db.Queryx("PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("error running foreign key check: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var ret []IntegrityProblem
+	counts := make(map[string]int)
+	for rows.Next() {
+		var table, parent string
+		var rowID, fkID interface{}
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, err
+		}
+
+		desc := fmt.Sprintf("%s referencing missing %s", table, parent)
+		if _, found := counts[desc]; !found {
+			ret = append(ret, IntegrityProblem{Description: desc})
+		}
+		counts[desc]++
+	}
+
+	for i := range ret {
+		ret[i].Count = counts[ret[i].Description]
+	}
+
+	return ret, rows.Err()
+}
+
+// missingBlobs returns the checksums of the blobs stored in the filesystem
+// that are missing from store.
+func missingBlobs(db *sqlx.DB, store *blob.FilesystemStore) ([]string, error) {
+	var checksums []string
+	if err := db.Select(&checksums, "SELECT `checksum` FROM `blobs` WHERE `blob` IS NULL"); err != nil {
+		return nil, fmt.Errorf("error checking blobs: %s", err.Error())
+	}
+
+	var ret []string
+	for _, checksum := range checksums {
+		if !store.Exists(checksum) {
+			ret = append(ret, checksum)
+		}
+	}
+
+	return ret, nil
+}
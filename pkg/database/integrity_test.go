@@ -0,0 +1,99 @@
+// +build integration
+
+package database
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stashapp/stash/pkg/blob"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-integrity-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	origDB := DB
+	defer func() {
+		DB = origDB
+	}()
+
+	path := filepath.Join(dir, "stash-go.sqlite")
+	schemaAtVersion(t, path, appSchemaVersion)
+
+	// foreign keys are disabled so that orphaned rows can be inserted
+	DB = open(path, true)
+	defer DB.Close()
+
+	for _, q := range []string{
+		"INSERT INTO tags (id, name, created_at, updated_at) VALUES (1, 'tag', '', '')",
+		"INSERT INTO scenes (id, path, checksum, studio_id, created_at, updated_at) VALUES (1, 'scene.mp4', 'md5', 99, '', '')",
+		"INSERT INTO scenes_tags (scene_id, tag_id) VALUES (1, 1), (1, 99), (99, 1)",
+		"INSERT INTO scene_markers (id, title, seconds, primary_tag_id, scene_id, created_at, updated_at) VALUES (1, 'marker', 0, 1, 1, '', ''), (2, 'orphan', 0, 1, 99, '', '')",
+		"INSERT INTO scene_markers_tags (scene_marker_id, tag_id) VALUES (1, 1), (2, 1)",
+		"INSERT INTO tags_image (tag_id, image_blob) VALUES (1, 'gone')",
+		"INSERT INTO blobs (checksum, blob) VALUES ('present', NULL), ('missing', NULL), ('stored', 'data')",
+	} {
+		if _, err := DB.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	blobsPath := filepath.Join(dir, "blobs")
+	if err := blob.NewFilesystemStore(blobsPath).Write("present", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []IntegrityProblem{
+		{"scene_markers orphaned by missing scenes", 1},
+		{"scenes_tags orphaned by missing scenes", 1},
+		{"scenes_tags orphaned by missing tags", 1},
+		{"tags_image orphaned by missing blobs", 1},
+		{"scenes with missing studios.studio_id", 1},
+	}
+
+	report, err := CheckIntegrity(false, blobsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, report.Errors, 0)
+	assert.Equal(t, expected, report.Orphans)
+	assert.NotEmpty(t, report.ForeignKeyViolations)
+	assert.Equal(t, []string{"missing"}, report.MissingBlobs)
+	assert.False(t, report.OK())
+
+	// the marker tags of the removed marker are removed with it
+	report, err = CheckIntegrity(true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []IntegrityProblem{
+		expected[0],
+		{"scene_markers_tags orphaned by missing scene_markers", 1},
+		expected[1],
+		expected[2],
+		expected[3],
+		expected[4],
+	}, report.Orphans)
+	assert.Len(t, report.ForeignKeyViolations, 0)
+
+	// missing blob files are not checked without a blobs path
+	report, err = CheckIntegrity(false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, report.OK())
+
+	var count int
+	assert.Nil(t, DB.Get(&count, "SELECT COUNT(*) FROM scene_markers_tags"))
+	assert.Equal(t, 1, count)
+	assert.Nil(t, DB.Get(&count, "SELECT COUNT(*) FROM scenes WHERE studio_id IS NULL"))
+	assert.Equal(t, 1, count)
+}
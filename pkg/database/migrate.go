@@ -0,0 +1,268 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// dataLossCheck is a query returning the number of rows whose data is lost
+// when a schema version is reverted.
+type dataLossCheck struct {
+	description string
+	query       string
+}
+
+// downDataLossChecks are the data loss checks for each schema version,
+// keyed by the version whose down migration loses the data. Data that is
+// regenerated by scanning, such as file modification times and the full
+// text search index, is not checked.
+var downDataLossChecks = map[uint][]dataLossCheck{
+	2: {
+		{"scene covers", "SELECT COUNT(*) FROM `scenes` WHERE `cover` IS NOT NULL"},
+	},
+	3: {
+		{"scene o-counters", "SELECT COUNT(*) FROM `scenes` WHERE `o_counter` > 0"},
+	},
+	4: {
+		{"movies", "SELECT COUNT(*) FROM `movies`"},
+	},
+	5: {
+		{"performer genders", "SELECT COUNT(*) FROM `performers` WHERE `gender` IS NOT NULL"},
+	},
+	6: {
+		{"scene formats", "SELECT COUNT(*) FROM `scenes` WHERE `format` IS NOT NULL"},
+	},
+	8: {
+		{"movie studios", "SELECT COUNT(*) FROM `movies` WHERE `studio_id` IS NOT NULL"},
+		{"duplicate movie scene numbers", "SELECT COUNT(*) FROM `movies_scenes` AS `m` WHERE `scene_index` IS NOT NULL AND `rowid` != (SELECT MIN(`rowid`) FROM `movies_scenes` WHERE `movie_id` = `m`.`movie_id` AND `scene_index` = `m`.`scene_index`)"},
+	},
+	9: {
+		{"parent studios", "SELECT COUNT(*) FROM `studios` WHERE `parent_id` IS NOT NULL"},
+	},
+	11: {
+		{"tag images", "SELECT COUNT(*) FROM `tags_image`"},
+	},
+	12: {
+		{"scenes without an MD5 checksum", "SELECT COUNT(*) FROM `scenes` WHERE `checksum` IS NULL"},
+	},
+	13: {
+		{"images", "SELECT COUNT(*) FROM `images`"},
+		{"galleries without a path", "SELECT COUNT(*) FROM `galleries` WHERE `path` IS NULL"},
+		{"gallery metadata", "SELECT COUNT(*) FROM `galleries` WHERE `path` IS NOT NULL AND COALESCE(`title`, `url`, `date`, `details`, `studio_id`, `rating`) IS NOT NULL"},
+		{"gallery tags", "SELECT COUNT(*) FROM `galleries_tags`"},
+		{"gallery performers", "SELECT COUNT(*) FROM `performers_galleries`"},
+	},
+	14: {
+		{"scene stash ids", "SELECT COUNT(*) FROM `scene_stash_ids`"},
+		{"performer stash ids", "SELECT COUNT(*) FROM `performer_stash_ids`"},
+		{"studio stash ids", "SELECT COUNT(*) FROM `studio_stash_ids`"},
+	},
+	16: {
+		{"organized scenes", "SELECT COUNT(*) FROM `scenes` WHERE `organized` = 1"},
+		{"organized images", "SELECT COUNT(*) FROM `images` WHERE `organized` = 1"},
+		{"organized galleries", "SELECT COUNT(*) FROM `galleries` WHERE `organized` = 1"},
+	},
+	18: {
+		{"galleries with multiple scenes", "SELECT COUNT(*) FROM (SELECT `gallery_id` FROM `scenes_galleries` GROUP BY `gallery_id` HAVING COUNT(*) > 1)"},
+	},
+	19: {
+		{"additional performer images", "SELECT COUNT(*) FROM `performers_image` WHERE `position` != 0"},
+	},
+	21: {
+		{"saved filters", "SELECT COUNT(*) FROM `saved_filters`"},
+	},
+	22: {
+		{"scene custom fields", "SELECT COUNT(*) FROM `scene_custom_fields`"},
+		{"performer custom fields", "SELECT COUNT(*) FROM `performer_custom_fields`"},
+		{"studio custom fields", "SELECT COUNT(*) FROM `studio_custom_fields`"},
+		{"movie custom fields", "SELECT COUNT(*) FROM `movie_custom_fields`"},
+		{"gallery custom fields", "SELECT COUNT(*) FROM `gallery_custom_fields`"},
+		{"image custom fields", "SELECT COUNT(*) FROM `image_custom_fields`"},
+	},
+	23: {
+		{"change history entries", "SELECT COUNT(*) FROM `audit_log`"},
+	},
+	26: {
+		{"gallery image positions", "SELECT COUNT(*) FROM `galleries_images` WHERE `position` IS NOT NULL"},
+		{"gallery covers", "SELECT COUNT(*) FROM `galleries` WHERE `cover_image_id` IS NOT NULL"},
+		{"gallery chapters", "SELECT COUNT(*) FROM `galleries_chapters`"},
+	},
+	28: {
+		{"images stored in the filesystem", "SELECT COUNT(*) FROM `blobs` WHERE `blob` IS NULL"},
+	},
+}
+
+// DataLoss describes data that is lost when a schema version is reverted.
+type DataLoss struct {
+	SchemaVersion uint
+	Description   string
+	Count         int
+}
+
+// DataLossError is returned by MigrateTo when migrating down would lose data
+// and data loss was not allowed.
+type DataLossError struct {
+	Losses []DataLoss
+}
+
+func (e *DataLossError) Error() string {
+	var lines []string
+	for _, l := range e.Losses {
+		lines = append(lines, fmt.Sprintf("reverting schema version %d would lose %d %s", l.SchemaVersion, l.Count, l.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// MigrateOptions are the options for MigrateTo.
+type MigrateOptions struct {
+	// DatabasePath is the path of the database to migrate.
+	DatabasePath string
+	// Version is the schema version to migrate to.
+	Version uint
+	// BackupDirectory is the directory the database is backed up into
+	// before it is migrated.
+	BackupDirectory string
+	// AllowDataLoss allows migrating down when data would be lost.
+	AllowDataLoss bool
+}
+
+// MigrateTo migrates an existing database up or down to the provided schema
+// version. The database is backed up before it is migrated. The migration
+// is performed on a copy of the database, which replaces the database once
+// all migrations have succeeded. If migrating down would lose data, a
+// *DataLossError is returned and the database is left unchanged unless
+// AllowDataLoss is set.
+//
+// The database must not be in use while it is migrated.
+func MigrateTo(options MigrateOptions) error {
+	if options.Version < 1 || options.Version > appSchemaVersion {
+		return fmt.Errorf("schema version must be between 1 and %d", appSchemaVersion)
+	}
+
+	if exists, _ := utils.FileExists(options.DatabasePath); !exists {
+		return fmt.Errorf("database %s does not exist", options.DatabasePath)
+	}
+
+	dbPath = options.DatabasePath
+	if err := getDatabaseSchemaVersion(); err != nil {
+		return err
+	}
+
+	from := databaseSchemaVersion
+	if from == 0 || from > appSchemaVersion {
+		return fmt.Errorf("database schema version %d is not supported", from)
+	}
+
+	if from == options.Version {
+		logger.Infof("Database is already at schema version %d", from)
+		return nil
+	}
+
+	if err := utils.EnsureDir(options.BackupDirectory); err != nil {
+		return fmt.Errorf("error creating backup directory %s: %s", options.BackupDirectory, err.Error())
+	}
+
+	// a backup made in the same second by a previous attempt is reused
+	backupPath := DatabaseBackupPathInDir(options.BackupDirectory)
+	if exists, _ := utils.FileExists(backupPath); !exists {
+		if err := Backup(nil, backupPath); err != nil {
+			return err
+		}
+	}
+
+	workPath := dbPath + ".migrate"
+	if err := os.Remove(workPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(workPath)
+		_ = removeWALFiles(workPath)
+	}()
+
+	if err := Backup(nil, workPath); err != nil {
+		return err
+	}
+
+	losses, err := migrateCopy(workPath, from, options.Version)
+	if err != nil {
+		return err
+	}
+
+	if len(losses) > 0 && !options.AllowDataLoss {
+		return &DataLossError{Losses: losses}
+	}
+
+	for _, l := range losses {
+		logger.Warnf("Reverting schema version %d removed %d %s", l.SchemaVersion, l.Count, l.Description)
+	}
+
+	if DB != nil {
+		if err := DB.Close(); err != nil {
+			return errors.New("Error closing database: " + err.Error())
+		}
+		DB = nil
+	}
+
+	if err := removeWALFiles(dbPath); err != nil {
+		return err
+	}
+
+	if err := os.Rename(workPath, dbPath); err != nil {
+		return fmt.Errorf("error replacing database: %s", err.Error())
+	}
+
+	return getDatabaseSchemaVersion()
+}
+
+// migrateCopy migrates the database at path from one schema version to
+// another, returning the data lost by any down migrations.
+func migrateCopy(path string, from uint, to uint) ([]DataLoss, error) {
+	m, err := getMigrateForPath(path)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+
+	if to > from {
+		logger.Infof("Migrating database from version %d to %d", from, to)
+		return nil, m.Migrate(to)
+	}
+
+	conn, err := sqlx.Connect(sqlite3Driver, "file:"+path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var losses []DataLoss
+	for v := from; v > to; v-- {
+		for _, c := range downDataLossChecks[v] {
+			var count int
+			if err := conn.Get(&count, c.query); err != nil {
+				return nil, fmt.Errorf("error checking %s: %s", c.description, err.Error())
+			}
+
+			if count > 0 {
+				losses = append(losses, DataLoss{
+					SchemaVersion: v,
+					Description:   c.description,
+					Count:         count,
+				})
+			}
+		}
+
+		logger.Infof("Migrating database from version %d to %d", v, v-1)
+		if err := m.Steps(-1); err != nil {
+			return nil, fmt.Errorf("error reverting schema version %d: %s", v, err.Error())
+		}
+	}
+
+	return losses, nil
+}
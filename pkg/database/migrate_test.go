@@ -0,0 +1,197 @@
+// +build integration
+
+package database
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// describeSchema returns a description of the tables, columns, indexes,
+// foreign keys and triggers of the database, keyed by object.
+func describeSchema(t *testing.T, db *sqlx.DB) map[string][]string {
+	ret := make(map[string][]string)
+
+	var tables []string
+	if err := db.Select(&tables, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_migrations'"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, table := range tables {
+		var lines []string
+		var columns []string
+		if err := db.Select(&columns, `SELECT name || ' ' || type || ' ' || "notnull" || ' ' || COALESCE(dflt_value, 'NULL') || ' ' || pk FROM pragma_table_info(?) ORDER BY cid`, table); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, columns...)
+
+		var fks []string
+		if err := db.Select(&fks, `SELECT 'fk ' || "from" || ' ' || "table" || '.' || COALESCE("to", '') || ' ' || on_delete FROM pragma_foreign_key_list(?) ORDER BY "from"`, table); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, fks...)
+
+		var indexes []struct {
+			Name   string `db:"name"`
+			Unique bool   `db:"unique"`
+		}
+		if err := db.Select(&indexes, `SELECT name, "unique" FROM pragma_index_list(?) WHERE origin = 'c' ORDER BY name`, table); err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range indexes {
+			var cols []string
+			if err := db.Select(&cols, "SELECT name FROM pragma_index_info(?) ORDER BY seqno", i.Name); err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, fmt.Sprintf("index %s %v %v", i.Name, i.Unique, cols))
+		}
+
+		ret["table "+table] = lines
+	}
+
+	var triggers []string
+	if err := db.Select(&triggers, "SELECT name FROM sqlite_master WHERE type = 'trigger' ORDER BY name"); err != nil {
+		t.Fatal(err)
+	}
+	ret["triggers"] = triggers
+
+	return ret
+}
+
+func schemaAtVersion(t *testing.T, path string, version uint) map[string][]string {
+	m, err := getMigrateForPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if err := m.Migrate(version); err != nil {
+		t.Fatalf("migrating to version %d: %s", version, err.Error())
+	}
+
+	conn := open(path, true)
+	defer conn.Close()
+	return describeSchema(t, conn)
+}
+
+func TestDownMigrationSchema(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-migrate-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for v := appSchemaVersion; v > 1; v-- {
+		expected := schemaAtVersion(t, filepath.Join(dir, fmt.Sprintf("fresh-%d.sqlite", v)), v-1)
+
+		path := filepath.Join(dir, fmt.Sprintf("down-%d.sqlite", v))
+		schemaAtVersion(t, path, v)
+		actual := schemaAtVersion(t, path, v-1)
+
+		assert.Equal(t, expected, actual, "schema after reverting version %d", v)
+	}
+}
+
+func TestMigrateTo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-migrate-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	origPath := dbPath
+	defer func() {
+		dbPath = origPath
+	}()
+
+	path := filepath.Join(dir, "stash-go.sqlite")
+	schemaAtVersion(t, path, appSchemaVersion)
+
+	conn := open(path, true)
+	for _, q := range []string{
+		"INSERT INTO studios (id, checksum, name, created_at, updated_at) VALUES (1, 'studio', 'studio', '', '')",
+		"INSERT INTO scenes (id, path, checksum, oshash, studio_id, created_at, updated_at) VALUES (1, 'scene.mp4', 'md5', 'oshash', 1, '', '')",
+		"INSERT INTO performers (id, checksum, name, created_at, updated_at) VALUES (1, 'performer', 'performer', '', '')",
+		"INSERT INTO blobs (checksum, blob) VALUES ('55a54008ad1ba589aa210d2629c1df41', X'01'), ('9e688c58a5487b8eaf69c9e1005ad0bf', X'02')",
+		"INSERT INTO performers_image (performer_id, position, image_blob) VALUES (1, 0, '55a54008ad1ba589aa210d2629c1df41'), (1, 1, '9e688c58a5487b8eaf69c9e1005ad0bf')",
+		"INSERT INTO performers_scenes (performer_id, scene_id) VALUES (1, 1)",
+		"INSERT INTO galleries (id, path, checksum, created_at, updated_at) VALUES (1, 'gallery.zip', 'gallery', '', '')",
+		"INSERT INTO scenes_galleries (scene_id, gallery_id) VALUES (1, 1)",
+		"INSERT INTO galleries_chapters (gallery_id, title, image_index) VALUES (1, 'chapter', 0)",
+		"INSERT INTO saved_filters (mode, name) VALUES ('SCENES', 'filter')",
+	} {
+		if _, err := conn.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn.Close()
+
+	options := MigrateOptions{
+		DatabasePath:    path,
+		Version:         20,
+		BackupDirectory: filepath.Join(dir, "backups1"),
+	}
+
+	// gallery chapters and saved filters are lost reverting schema versions
+	// 26 and 21
+	err = MigrateTo(options)
+	if assert.IsType(t, &DataLossError{}, err) {
+		losses := err.(*DataLossError).Losses
+		assert.Equal(t, []DataLoss{{26, "gallery chapters", 1}, {21, "saved filters", 1}}, losses)
+	}
+	assert.Equal(t, appSchemaVersion, Version())
+
+	backups, _ := ListBackups(options.BackupDirectory)
+	assert.Len(t, backups, 1)
+
+	// revert to the first version
+	options.Version = 1
+	options.AllowDataLoss = true
+	options.BackupDirectory = filepath.Join(dir, "backups2")
+	if err := MigrateTo(options); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint(1), Version())
+
+	conn = open(path, true)
+	var count int
+	assert.Nil(t, conn.Get(&count, "SELECT COUNT(*) FROM scenes WHERE checksum = 'md5' AND studio_id = 1"))
+	assert.Equal(t, 1, count)
+	assert.Nil(t, conn.Get(&count, "SELECT COUNT(*) FROM galleries WHERE scene_id = 1"))
+	assert.Equal(t, 1, count)
+	assert.Nil(t, conn.Get(&count, "SELECT COUNT(*) FROM performers WHERE image = X'01'"))
+	assert.Equal(t, 1, count)
+	conn.Close()
+
+	// and back to the latest version
+	options.Version = appSchemaVersion
+	options.AllowDataLoss = false
+	options.BackupDirectory = filepath.Join(dir, "backups3")
+	if err := MigrateTo(options); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, appSchemaVersion, Version())
+
+	conn = open(path, true)
+	defer conn.Close()
+	assert.Nil(t, conn.Get(&count, "SELECT COUNT(*) FROM scenes_galleries WHERE scene_id = 1 AND gallery_id = 1"))
+	assert.Equal(t, 1, count)
+	assert.Nil(t, conn.Get(&count, "SELECT COUNT(*) FROM performers_scenes WHERE scene_id = 1 AND performer_id = 1"))
+	assert.Equal(t, 1, count)
+	assert.Nil(t, conn.Get(&count, "SELECT COUNT(*) FROM performers_image WHERE performer_id = 1"))
+	assert.Equal(t, 1, count)
+
+	// the image is moved back into the blobs table
+	assert.Nil(t, conn.Get(&count, "SELECT COUNT(*) FROM performers_image INNER JOIN blobs ON checksum = image_blob WHERE performer_id = 1 AND blob = X'01'"))
+	assert.Equal(t, 1, count)
+
+	// migrating to an unknown version fails
+	options.Version = appSchemaVersion + 1
+	assert.NotNil(t, MigrateTo(options))
+}
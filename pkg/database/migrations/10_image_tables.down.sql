@@ -0,0 +1,335 @@
+-- move the images back into the scenes, studios, performers and movies tables.
+-- Objects without an image are given an empty image.
+
+CREATE TABLE `_studios_new` (
+  `id` integer not null primary key autoincrement,
+  `image` blob not null,
+  `checksum` varchar(255) not null,
+  `name` varchar(255),
+  `url` varchar(255),
+  `created_at` datetime not null,
+  `updated_at` datetime not null
+, parent_id INTEGER DEFAULT NULL CHECK ( id IS NOT parent_id ) REFERENCES studios(id) on delete set null);
+
+INSERT INTO `_studios_new`
+  (
+    `id`,
+    `image`,
+    `checksum`,
+    `name`,
+    `url`,
+    `created_at`,
+    `updated_at`,
+    `parent_id`
+  )
+  SELECT
+    `id`,
+    COALESCE((SELECT `image` FROM `studios_image` WHERE `studio_id` = `studios`.`id`), X''),
+    `checksum`,
+    `name`,
+    `url`,
+    `created_at`,
+    `updated_at`,
+    `parent_id`
+  FROM `studios`;
+
+DROP TABLE `studios`;
+ALTER TABLE `_studios_new` rename to `studios`;
+
+CREATE INDEX `index_studios_on_checksum` on `studios` (`checksum`);
+CREATE INDEX `index_studios_on_name` on `studios` (`name`);
+CREATE INDEX index_studios_on_parent_id on studios (parent_id);
+CREATE UNIQUE INDEX `studios_checksum_unique` on `studios` (`checksum`);
+
+CREATE TABLE `_scenes_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510) not null,
+  `checksum` varchar(255) not null,
+  `title` varchar(255),
+  `details` text,
+  `url` varchar(255),
+  `date` date,
+  `rating` tinyint,
+  `size` varchar(255),
+  `duration` float,
+  `video_codec` varchar(255),
+  `audio_codec` varchar(255),
+  `width` tinyint,
+  `height` tinyint,
+  `framerate` float,
+  `bitrate` integer,
+  `studio_id` integer,
+  `created_at` datetime not null,
+  `updated_at` datetime not null, `cover` blob, `o_counter` tinyint not null default 0, `format` varchar(255),
+  foreign key(`studio_id`) references `studios`(`id`) on delete CASCADE
+);
+
+INSERT INTO `_scenes_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `title`,
+    `details`,
+    `url`,
+    `date`,
+    `rating`,
+    `size`,
+    `duration`,
+    `video_codec`,
+    `audio_codec`,
+    `width`,
+    `height`,
+    `framerate`,
+    `bitrate`,
+    `studio_id`,
+    `created_at`,
+    `updated_at`,
+    `cover`,
+    `o_counter`,
+    `format`
+  )
+  SELECT
+    `id`,
+    `path`,
+    `checksum`,
+    `title`,
+    `details`,
+    `url`,
+    `date`,
+    `rating`,
+    `size`,
+    `duration`,
+    `video_codec`,
+    `audio_codec`,
+    `width`,
+    `height`,
+    `framerate`,
+    `bitrate`,
+    `studio_id`,
+    `created_at`,
+    `updated_at`,
+    (SELECT `cover` FROM `scenes_cover` WHERE `scene_id` = `scenes`.`id`),
+    `o_counter`,
+    `format`
+  FROM `scenes`;
+
+DROP TABLE `scenes`;
+ALTER TABLE `_scenes_new` rename to `scenes`;
+
+CREATE INDEX `index_scenes_on_studio_id` on `scenes` (`studio_id`);
+CREATE UNIQUE INDEX `scenes_checksum_unique` on `scenes` (`checksum`);
+CREATE UNIQUE INDEX `scenes_path_unique` on `scenes` (`path`);
+
+CREATE TABLE `_performers_new` (
+  `id` integer not null primary key autoincrement,
+  `checksum` varchar(255) not null,
+  `name` varchar(255),
+  `gender` varchar(20),
+  `url` varchar(255),
+  `twitter` varchar(255),
+  `instagram` varchar(255),
+  `birthdate` date,
+  `ethnicity` varchar(255),
+  `country` varchar(255),
+  `eye_color` varchar(255),
+  `height` varchar(255),
+  `measurements` varchar(255),
+  `fake_tits` varchar(255),
+  `career_length` varchar(255),
+  `tattoos` varchar(255),
+  `piercings` varchar(255),
+  `aliases` varchar(255),
+  `favorite` boolean not null default '0',
+  `created_at` datetime not null,
+  `updated_at` datetime not null,
+  `image` blob not null
+);
+
+INSERT INTO `_performers_new`
+  (
+    `id`,
+    `checksum`,
+    `name`,
+    `gender`,
+    `url`,
+    `twitter`,
+    `instagram`,
+    `birthdate`,
+    `ethnicity`,
+    `country`,
+    `eye_color`,
+    `height`,
+    `measurements`,
+    `fake_tits`,
+    `career_length`,
+    `tattoos`,
+    `piercings`,
+    `aliases`,
+    `favorite`,
+    `created_at`,
+    `updated_at`,
+    `image`
+  )
+  SELECT
+    `id`,
+    `checksum`,
+    `name`,
+    `gender`,
+    `url`,
+    `twitter`,
+    `instagram`,
+    `birthdate`,
+    `ethnicity`,
+    `country`,
+    `eye_color`,
+    `height`,
+    `measurements`,
+    `fake_tits`,
+    `career_length`,
+    `tattoos`,
+    `piercings`,
+    `aliases`,
+    `favorite`,
+    `created_at`,
+    `updated_at`,
+    COALESCE((SELECT `image` FROM `performers_image` WHERE `performer_id` = `performers`.`id`), X'')
+  FROM `performers`;
+
+DROP TABLE `performers`;
+ALTER TABLE `_performers_new` rename to `performers`;
+
+CREATE INDEX `index_performers_on_name` on `performers` (`name`);
+CREATE UNIQUE INDEX `performers_checksum_unique` on `performers` (`checksum`);
+
+CREATE TABLE `_movies_new` (
+  `id` integer not null primary key autoincrement,
+  `name` varchar(255) not null,
+  `aliases` varchar(255),
+  `duration` integer,
+  `date` date,
+  `rating` tinyint,
+  `studio_id` integer,
+  `director` varchar(255),
+  `synopsis` text,
+  `checksum` varchar(255) not null,
+  `url` varchar(255),
+  `created_at` datetime not null,
+  `updated_at` datetime not null,
+  `front_image` blob not null,
+  `back_image` blob,
+  foreign key(`studio_id`) references `studios`(`id`) on delete set null
+);
+
+INSERT INTO `_movies_new`
+  (
+    `id`,
+    `name`,
+    `aliases`,
+    `duration`,
+    `date`,
+    `rating`,
+    `studio_id`,
+    `director`,
+    `synopsis`,
+    `checksum`,
+    `url`,
+    `created_at`,
+    `updated_at`,
+    `front_image`,
+    `back_image`
+  )
+  SELECT
+    `id`,
+    `name`,
+    `aliases`,
+    `duration`,
+    `date`,
+    `rating`,
+    `studio_id`,
+    `director`,
+    `synopsis`,
+    `checksum`,
+    `url`,
+    `created_at`,
+    `updated_at`,
+    COALESCE((SELECT `front_image` FROM `movies_images` WHERE `movie_id` = `movies`.`id`), X''),
+    (SELECT `back_image` FROM `movies_images` WHERE `movie_id` = `movies`.`id`)
+  FROM `movies`;
+
+DROP TABLE `movies`;
+ALTER TABLE `_movies_new` rename to `movies`;
+
+CREATE INDEX `index_movies_on_studio_id` on `movies` (`studio_id`);
+CREATE UNIQUE INDEX `movies_checksum_unique` on `movies` (`checksum`);
+CREATE UNIQUE INDEX `movies_name_unique` on `movies` (`name`);
+
+CREATE TABLE `_scraped_items_new` (
+  `id` integer not null primary key autoincrement,
+  `title` varchar(255),
+  `description` text,
+  `url` varchar(255),
+  `date` date,
+  `rating` varchar(255),
+  `tags` varchar(510),
+  `models` varchar(510),
+  `episode` integer,
+  `gallery_filename` varchar(255),
+  `gallery_url` varchar(510),
+  `video_filename` varchar(255),
+  `video_url` varchar(255),
+  `studio_id` integer,
+  `created_at` datetime not null,
+  `updated_at` datetime not null, `movie_id` integer,
+  foreign key(`studio_id`) references `studios`(`id`)
+);
+
+INSERT INTO `_scraped_items_new`
+  (
+    `id`,
+    `title`,
+    `description`,
+    `url`,
+    `date`,
+    `rating`,
+    `tags`,
+    `models`,
+    `episode`,
+    `gallery_filename`,
+    `gallery_url`,
+    `video_filename`,
+    `video_url`,
+    `studio_id`,
+    `created_at`,
+    `updated_at`,
+    `movie_id`
+  )
+  SELECT
+    `id`,
+    `title`,
+    `description`,
+    `url`,
+    `date`,
+    `rating`,
+    `tags`,
+    `models`,
+    `episode`,
+    `gallery_filename`,
+    `gallery_url`,
+    `video_filename`,
+    `video_url`,
+    `studio_id`,
+    `created_at`,
+    `updated_at`,
+    NULL
+  FROM `scraped_items`;
+
+DROP TABLE `scraped_items`;
+ALTER TABLE `_scraped_items_new` rename to `scraped_items`;
+
+CREATE INDEX `index_scraped_items_on_studio_id` on `scraped_items` (`studio_id`);
+
+DROP TABLE IF EXISTS `scenes_cover`;
+DROP TABLE IF EXISTS `performers_image`;
+DROP TABLE IF EXISTS `studios_image`;
+DROP TABLE IF EXISTS `movies_images`;
@@ -0,0 +1,81 @@
+-- remove the oshash column. Scenes without an MD5 checksum use their oshash
+-- as a checksum, and are updated on the next scan.
+CREATE TABLE `_scenes_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510) not null,
+  `checksum` varchar(255) not null,
+  `title` varchar(255),
+  `details` text,
+  `url` varchar(255),
+  `date` date,
+  `rating` tinyint,
+  `size` varchar(255),
+  `duration` float,
+  `video_codec` varchar(255),
+  `audio_codec` varchar(255),
+  `width` tinyint,
+  `height` tinyint,
+  `framerate` float,
+  `bitrate` integer,
+  `studio_id` integer,
+  `o_counter` tinyint not null default 0,
+  `format` varchar(255),
+  `created_at` datetime not null,
+  `updated_at` datetime not null,
+  foreign key(`studio_id`) references `studios`(`id`) on delete SET NULL
+);
+
+INSERT INTO `_scenes_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `title`,
+    `details`,
+    `url`,
+    `date`,
+    `rating`,
+    `size`,
+    `duration`,
+    `video_codec`,
+    `audio_codec`,
+    `width`,
+    `height`,
+    `framerate`,
+    `bitrate`,
+    `studio_id`,
+    `o_counter`,
+    `format`,
+    `created_at`,
+    `updated_at`
+  )
+  SELECT
+    `id`,
+    `path`,
+    COALESCE(`checksum`, `oshash`),
+    `title`,
+    `details`,
+    `url`,
+    `date`,
+    `rating`,
+    `size`,
+    `duration`,
+    `video_codec`,
+    `audio_codec`,
+    `width`,
+    `height`,
+    `framerate`,
+    `bitrate`,
+    `studio_id`,
+    `o_counter`,
+    `format`,
+    `created_at`,
+    `updated_at`
+  FROM `scenes`;
+
+DROP TABLE `scenes`;
+ALTER TABLE `_scenes_new` rename to `scenes`;
+
+CREATE INDEX `index_scenes_on_studio_id` on `scenes` (`studio_id`);
+CREATE UNIQUE INDEX `scenes_checksum_unique` on `scenes` (`checksum`);
+CREATE UNIQUE INDEX `scenes_path_unique` on `scenes` (`path`);
@@ -0,0 +1,43 @@
+DROP TABLE IF EXISTS `performers_images`;
+DROP TABLE IF EXISTS `performers_galleries`;
+DROP TABLE IF EXISTS `galleries_tags`;
+DROP TABLE IF EXISTS `galleries_images`;
+DROP TABLE IF EXISTS `images_tags`;
+DROP TABLE IF EXISTS `images`;
+
+-- remove the gallery metadata columns. Galleries without a path are removed.
+CREATE TABLE `_galleries_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510) not null,
+  `checksum` varchar(255) not null,
+  `scene_id` integer,
+  `created_at` datetime not null,
+  `updated_at` datetime not null,
+  foreign key(`scene_id`) references `scenes`(`id`)
+);
+
+INSERT INTO `_galleries_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `scene_id`,
+    `created_at`,
+    `updated_at`
+  )
+  SELECT
+    `id`,
+    `path`,
+    `checksum`,
+    `scene_id`,
+    `created_at`,
+    `updated_at`
+  FROM `galleries`
+  WHERE `path` IS NOT NULL;
+
+DROP TABLE `galleries`;
+ALTER TABLE `_galleries_new` rename to `galleries`;
+
+CREATE UNIQUE INDEX `galleries_checksum_unique` on `galleries` (`checksum`);
+CREATE UNIQUE INDEX `galleries_path_unique` on `galleries` (`path`);
+CREATE INDEX `index_galleries_on_scene_id` on `galleries` (`scene_id`);
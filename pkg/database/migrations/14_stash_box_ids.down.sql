@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `scene_stash_ids`;
+DROP TABLE IF EXISTS `performer_stash_ids`;
+DROP TABLE IF EXISTS `studio_stash_ids`;
@@ -0,0 +1,201 @@
+-- remove the organized columns
+
+CREATE TABLE `_scenes_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510) not null,
+  `checksum` varchar(255),
+  `oshash` varchar(255),
+  `title` varchar(255),
+  `details` text,
+  `url` varchar(255),
+  `date` date,
+  `rating` tinyint,
+  `size` varchar(255),
+  `duration` float,
+  `video_codec` varchar(255),
+  `audio_codec` varchar(255),
+  `width` tinyint,
+  `height` tinyint,
+  `framerate` float,
+  `bitrate` integer,
+  `studio_id` integer,
+  `o_counter` tinyint not null default 0,
+  `format` varchar(255),
+  `created_at` datetime not null,
+  `updated_at` datetime not null, `file_mod_time` datetime,
+  foreign key(`studio_id`) references `studios`(`id`) on delete SET NULL,
+  CHECK (`checksum` is not null or `oshash` is not null)
+);
+
+INSERT INTO `_scenes_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `oshash`,
+    `title`,
+    `details`,
+    `url`,
+    `date`,
+    `rating`,
+    `size`,
+    `duration`,
+    `video_codec`,
+    `audio_codec`,
+    `width`,
+    `height`,
+    `framerate`,
+    `bitrate`,
+    `studio_id`,
+    `o_counter`,
+    `format`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`
+  )
+  SELECT
+    `id`,
+    `path`,
+    `checksum`,
+    `oshash`,
+    `title`,
+    `details`,
+    `url`,
+    `date`,
+    `rating`,
+    `size`,
+    `duration`,
+    `video_codec`,
+    `audio_codec`,
+    `width`,
+    `height`,
+    `framerate`,
+    `bitrate`,
+    `studio_id`,
+    `o_counter`,
+    `format`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`
+  FROM `scenes`;
+
+DROP TABLE `scenes`;
+ALTER TABLE `_scenes_new` rename to `scenes`;
+
+CREATE INDEX `index_scenes_on_studio_id` on `scenes` (`studio_id`);
+CREATE UNIQUE INDEX `scenes_checksum_unique` on `scenes` (`checksum`);
+CREATE UNIQUE INDEX `scenes_oshash_unique` on `scenes` (`oshash`);
+CREATE UNIQUE INDEX `scenes_path_unique` on `scenes` (`path`);
+
+CREATE TABLE `_images_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510) not null,
+  `checksum` varchar(255) not null,
+  `title` varchar(255),
+  `rating` tinyint,
+  `size` integer,
+  `width` tinyint,
+  `height` tinyint,
+  `studio_id` integer,
+  `o_counter` tinyint not null default 0,
+  `created_at` datetime not null,
+  `updated_at` datetime not null, `file_mod_time` datetime,
+  foreign key(`studio_id`) references `studios`(`id`) on delete SET NULL
+);
+
+INSERT INTO `_images_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `title`,
+    `rating`,
+    `size`,
+    `width`,
+    `height`,
+    `studio_id`,
+    `o_counter`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`
+  )
+  SELECT
+    `id`,
+    `path`,
+    `checksum`,
+    `title`,
+    `rating`,
+    `size`,
+    `width`,
+    `height`,
+    `studio_id`,
+    `o_counter`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`
+  FROM `images`;
+
+DROP TABLE `images`;
+ALTER TABLE `_images_new` rename to `images`;
+
+CREATE INDEX `index_images_on_studio_id` on `images` (`studio_id`);
+
+CREATE TABLE `_galleries_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510),
+  `checksum` varchar(255) not null,
+  `zip` boolean not null default '0',
+  `title` varchar(255),
+  `url` varchar(255),
+  `date` date,
+  `details` text,
+  `studio_id` integer,
+  `rating` tinyint,
+  `scene_id` integer,
+  `created_at` datetime not null,
+  `updated_at` datetime not null, `file_mod_time` datetime,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete SET NULL,
+  foreign key(`studio_id`) references `studios`(`id`) on delete SET NULL
+);
+
+INSERT INTO `_galleries_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `zip`,
+    `title`,
+    `url`,
+    `date`,
+    `details`,
+    `studio_id`,
+    `rating`,
+    `scene_id`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`
+  )
+  SELECT
+    `id`,
+    `path`,
+    `checksum`,
+    `zip`,
+    `title`,
+    `url`,
+    `date`,
+    `details`,
+    `studio_id`,
+    `rating`,
+    `scene_id`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`
+  FROM `galleries`;
+
+DROP TABLE `galleries`;
+ALTER TABLE `_galleries_new` rename to `galleries`;
+
+CREATE UNIQUE INDEX `galleries_checksum_unique` on `galleries` (`checksum`);
+CREATE UNIQUE INDEX `galleries_path_unique` on `galleries` (`path`);
+CREATE INDEX `index_galleries_on_scene_id` on `galleries` (`scene_id`);
+CREATE INDEX `index_galleries_on_studio_id` on `galleries` (`studio_id`);
@@ -0,0 +1 @@
+-- scene sizes are repopulated on the next scan, so there is nothing to revert
@@ -0,0 +1,65 @@
+-- move the scene relationship back into galleries. Galleries linked to
+-- multiple scenes keep only the first scene.
+CREATE TABLE `_galleries_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510),
+  `checksum` varchar(255) not null,
+  `zip` boolean not null default '0',
+  `title` varchar(255),
+  `url` varchar(255),
+  `date` date,
+  `details` text,
+  `studio_id` integer,
+  `rating` tinyint,
+  `scene_id` integer,
+  `created_at` datetime not null,
+  `updated_at` datetime not null, `file_mod_time` datetime, `organized` boolean not null default '0',
+  foreign key(`scene_id`) references `scenes`(`id`) on delete SET NULL,
+  foreign key(`studio_id`) references `studios`(`id`) on delete SET NULL
+);
+
+INSERT INTO `_galleries_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `zip`,
+    `title`,
+    `url`,
+    `date`,
+    `details`,
+    `studio_id`,
+    `rating`,
+    `scene_id`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`,
+    `organized`
+  )
+  SELECT
+    `id`,
+    `path`,
+    `checksum`,
+    `zip`,
+    `title`,
+    `url`,
+    `date`,
+    `details`,
+    `studio_id`,
+    `rating`,
+    (SELECT MIN(`scene_id`) FROM `scenes_galleries` WHERE `gallery_id` = `galleries`.`id`),
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`,
+    `organized`
+  FROM `galleries`;
+
+DROP TABLE `galleries`;
+ALTER TABLE `_galleries_new` rename to `galleries`;
+
+CREATE UNIQUE INDEX `galleries_checksum_unique` on `galleries` (`checksum`);
+CREATE UNIQUE INDEX `galleries_path_unique` on `galleries` (`path`);
+CREATE INDEX `index_galleries_on_scene_id` on `galleries` (`scene_id`);
+CREATE INDEX `index_galleries_on_studio_id` on `galleries` (`studio_id`);
+
+DROP TABLE IF EXISTS `scenes_galleries`;
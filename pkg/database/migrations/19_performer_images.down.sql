@@ -0,0 +1,22 @@
+-- remove the position column. Only the first image of each performer is kept.
+CREATE TABLE `_performers_image_new` (
+  `performer_id` integer,
+  `image` blob not null,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE
+);
+
+INSERT INTO `_performers_image_new`
+  (
+    `performer_id`,
+    `image`
+  )
+  SELECT
+    `performer_id`,
+    `image`
+  FROM `performers_image`
+  WHERE `position` = 0;
+
+DROP TABLE `performers_image`;
+ALTER TABLE `_performers_image_new` rename to `performers_image`;
+
+CREATE UNIQUE INDEX `index_performer_image_on_performer_id` on `performers_image` (`performer_id`);
@@ -0,0 +1,23 @@
+-- add position to performer images to allow multiple images per performer
+ALTER TABLE `performers_image` rename to `_performers_image_old`;
+
+DROP INDEX IF EXISTS `index_performer_image_on_performer_id`;
+
+CREATE TABLE `performers_image` (
+  `performer_id` integer,
+  `position` integer not null default 0,
+  `image` blob not null,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_performer_image_on_performer_id_position` on `performers_image` (`performer_id`, `position`);
+
+INSERT INTO `performers_image`
+  (
+    `performer_id`,
+    `position`,
+    `image`
+  )
+  SELECT `performer_id`, 0, `image` from `_performers_image_old`;
+
+DROP TABLE `_performers_image_old`;
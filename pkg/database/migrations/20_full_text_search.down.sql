@@ -0,0 +1,30 @@
+-- remove the full text search tables and their triggers
+DROP TRIGGER IF EXISTS `scenes_fts_insert`;
+DROP TRIGGER IF EXISTS `scenes_fts_delete`;
+DROP TRIGGER IF EXISTS `scenes_fts_update`;
+DROP TABLE IF EXISTS `scenes_fts`;
+
+DROP TRIGGER IF EXISTS `scene_markers_fts_insert`;
+DROP TRIGGER IF EXISTS `scene_markers_fts_delete`;
+DROP TRIGGER IF EXISTS `scene_markers_fts_update`;
+DROP TABLE IF EXISTS `scene_markers_fts`;
+
+DROP TRIGGER IF EXISTS `images_fts_insert`;
+DROP TRIGGER IF EXISTS `images_fts_delete`;
+DROP TRIGGER IF EXISTS `images_fts_update`;
+DROP TABLE IF EXISTS `images_fts`;
+
+DROP TRIGGER IF EXISTS `galleries_fts_insert`;
+DROP TRIGGER IF EXISTS `galleries_fts_delete`;
+DROP TRIGGER IF EXISTS `galleries_fts_update`;
+DROP TABLE IF EXISTS `galleries_fts`;
+
+DROP TRIGGER IF EXISTS `performers_fts_insert`;
+DROP TRIGGER IF EXISTS `performers_fts_delete`;
+DROP TRIGGER IF EXISTS `performers_fts_update`;
+DROP TABLE IF EXISTS `performers_fts`;
+
+DROP TRIGGER IF EXISTS `tags_fts_insert`;
+DROP TRIGGER IF EXISTS `tags_fts_delete`;
+DROP TRIGGER IF EXISTS `tags_fts_update`;
+DROP TABLE IF EXISTS `tags_fts`;
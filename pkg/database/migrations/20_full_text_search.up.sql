@@ -0,0 +1,133 @@
+-- full-text search tables, kept in sync with the content tables using triggers
+
+CREATE VIRTUAL TABLE `scenes_fts` USING fts5(
+  `title`, `details`, `path`, `oshash`, `checksum`,
+  content = 'scenes',
+  content_rowid = 'id',
+  tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER `scenes_fts_insert` AFTER INSERT ON `scenes` BEGIN
+  INSERT INTO `scenes_fts` (`rowid`, `title`, `details`, `path`, `oshash`, `checksum`) VALUES (new.`id`, new.`title`, new.`details`, new.`path`, new.`oshash`, new.`checksum`);
+END;
+
+CREATE TRIGGER `scenes_fts_delete` AFTER DELETE ON `scenes` BEGIN
+  INSERT INTO `scenes_fts` (`scenes_fts`, `rowid`, `title`, `details`, `path`, `oshash`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`details`, old.`path`, old.`oshash`, old.`checksum`);
+END;
+
+CREATE TRIGGER `scenes_fts_update` AFTER UPDATE OF `title`, `details`, `path`, `oshash`, `checksum` ON `scenes` BEGIN
+  INSERT INTO `scenes_fts` (`scenes_fts`, `rowid`, `title`, `details`, `path`, `oshash`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`details`, old.`path`, old.`oshash`, old.`checksum`);
+  INSERT INTO `scenes_fts` (`rowid`, `title`, `details`, `path`, `oshash`, `checksum`) VALUES (new.`id`, new.`title`, new.`details`, new.`path`, new.`oshash`, new.`checksum`);
+END;
+
+INSERT INTO `scenes_fts` (`scenes_fts`) VALUES ('rebuild');
+
+CREATE VIRTUAL TABLE `scene_markers_fts` USING fts5(
+  `title`,
+  content = 'scene_markers',
+  content_rowid = 'id',
+  tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER `scene_markers_fts_insert` AFTER INSERT ON `scene_markers` BEGIN
+  INSERT INTO `scene_markers_fts` (`rowid`, `title`) VALUES (new.`id`, new.`title`);
+END;
+
+CREATE TRIGGER `scene_markers_fts_delete` AFTER DELETE ON `scene_markers` BEGIN
+  INSERT INTO `scene_markers_fts` (`scene_markers_fts`, `rowid`, `title`) VALUES ('delete', old.`id`, old.`title`);
+END;
+
+CREATE TRIGGER `scene_markers_fts_update` AFTER UPDATE OF `title` ON `scene_markers` BEGIN
+  INSERT INTO `scene_markers_fts` (`scene_markers_fts`, `rowid`, `title`) VALUES ('delete', old.`id`, old.`title`);
+  INSERT INTO `scene_markers_fts` (`rowid`, `title`) VALUES (new.`id`, new.`title`);
+END;
+
+INSERT INTO `scene_markers_fts` (`scene_markers_fts`) VALUES ('rebuild');
+
+CREATE VIRTUAL TABLE `performers_fts` USING fts5(
+  `name`, `aliases`, `checksum`, `birthdate`, `ethnicity`,
+  content = 'performers',
+  content_rowid = 'id',
+  tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER `performers_fts_insert` AFTER INSERT ON `performers` BEGIN
+  INSERT INTO `performers_fts` (`rowid`, `name`, `aliases`, `checksum`, `birthdate`, `ethnicity`) VALUES (new.`id`, new.`name`, new.`aliases`, new.`checksum`, new.`birthdate`, new.`ethnicity`);
+END;
+
+CREATE TRIGGER `performers_fts_delete` AFTER DELETE ON `performers` BEGIN
+  INSERT INTO `performers_fts` (`performers_fts`, `rowid`, `name`, `aliases`, `checksum`, `birthdate`, `ethnicity`) VALUES ('delete', old.`id`, old.`name`, old.`aliases`, old.`checksum`, old.`birthdate`, old.`ethnicity`);
+END;
+
+CREATE TRIGGER `performers_fts_update` AFTER UPDATE OF `name`, `aliases`, `checksum`, `birthdate`, `ethnicity` ON `performers` BEGIN
+  INSERT INTO `performers_fts` (`performers_fts`, `rowid`, `name`, `aliases`, `checksum`, `birthdate`, `ethnicity`) VALUES ('delete', old.`id`, old.`name`, old.`aliases`, old.`checksum`, old.`birthdate`, old.`ethnicity`);
+  INSERT INTO `performers_fts` (`rowid`, `name`, `aliases`, `checksum`, `birthdate`, `ethnicity`) VALUES (new.`id`, new.`name`, new.`aliases`, new.`checksum`, new.`birthdate`, new.`ethnicity`);
+END;
+
+INSERT INTO `performers_fts` (`performers_fts`) VALUES ('rebuild');
+
+CREATE VIRTUAL TABLE `galleries_fts` USING fts5(
+  `title`, `path`, `checksum`,
+  content = 'galleries',
+  content_rowid = 'id',
+  tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER `galleries_fts_insert` AFTER INSERT ON `galleries` BEGIN
+  INSERT INTO `galleries_fts` (`rowid`, `title`, `path`, `checksum`) VALUES (new.`id`, new.`title`, new.`path`, new.`checksum`);
+END;
+
+CREATE TRIGGER `galleries_fts_delete` AFTER DELETE ON `galleries` BEGIN
+  INSERT INTO `galleries_fts` (`galleries_fts`, `rowid`, `title`, `path`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`path`, old.`checksum`);
+END;
+
+CREATE TRIGGER `galleries_fts_update` AFTER UPDATE OF `title`, `path`, `checksum` ON `galleries` BEGIN
+  INSERT INTO `galleries_fts` (`galleries_fts`, `rowid`, `title`, `path`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`path`, old.`checksum`);
+  INSERT INTO `galleries_fts` (`rowid`, `title`, `path`, `checksum`) VALUES (new.`id`, new.`title`, new.`path`, new.`checksum`);
+END;
+
+INSERT INTO `galleries_fts` (`galleries_fts`) VALUES ('rebuild');
+
+CREATE VIRTUAL TABLE `images_fts` USING fts5(
+  `title`, `path`, `checksum`,
+  content = 'images',
+  content_rowid = 'id',
+  tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER `images_fts_insert` AFTER INSERT ON `images` BEGIN
+  INSERT INTO `images_fts` (`rowid`, `title`, `path`, `checksum`) VALUES (new.`id`, new.`title`, new.`path`, new.`checksum`);
+END;
+
+CREATE TRIGGER `images_fts_delete` AFTER DELETE ON `images` BEGIN
+  INSERT INTO `images_fts` (`images_fts`, `rowid`, `title`, `path`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`path`, old.`checksum`);
+END;
+
+CREATE TRIGGER `images_fts_update` AFTER UPDATE OF `title`, `path`, `checksum` ON `images` BEGIN
+  INSERT INTO `images_fts` (`images_fts`, `rowid`, `title`, `path`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`path`, old.`checksum`);
+  INSERT INTO `images_fts` (`rowid`, `title`, `path`, `checksum`) VALUES (new.`id`, new.`title`, new.`path`, new.`checksum`);
+END;
+
+INSERT INTO `images_fts` (`images_fts`) VALUES ('rebuild');
+
+CREATE VIRTUAL TABLE `tags_fts` USING fts5(
+  `name`,
+  content = 'tags',
+  content_rowid = 'id',
+  tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER `tags_fts_insert` AFTER INSERT ON `tags` BEGIN
+  INSERT INTO `tags_fts` (`rowid`, `name`) VALUES (new.`id`, new.`name`);
+END;
+
+CREATE TRIGGER `tags_fts_delete` AFTER DELETE ON `tags` BEGIN
+  INSERT INTO `tags_fts` (`tags_fts`, `rowid`, `name`) VALUES ('delete', old.`id`, old.`name`);
+END;
+
+CREATE TRIGGER `tags_fts_update` AFTER UPDATE OF `name` ON `tags` BEGIN
+  INSERT INTO `tags_fts` (`tags_fts`, `rowid`, `name`) VALUES ('delete', old.`id`, old.`name`);
+  INSERT INTO `tags_fts` (`rowid`, `name`) VALUES (new.`id`, new.`name`);
+END;
+
+INSERT INTO `tags_fts` (`tags_fts`) VALUES ('rebuild');
@@ -0,0 +1,9 @@
+CREATE TABLE `saved_filters` (
+  `id` integer not null primary key autoincrement,
+  `mode` varchar(255) not null,
+  `name` varchar(510) not null,
+  `find_filter` text,
+  `object_filter` text
+);
+
+CREATE UNIQUE INDEX `index_saved_filters_on_mode_name_unique` on `saved_filters` (`mode`, `name`);
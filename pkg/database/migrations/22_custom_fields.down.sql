@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `scene_custom_fields`;
+DROP TABLE IF EXISTS `performer_custom_fields`;
+DROP TABLE IF EXISTS `studio_custom_fields`;
+DROP TABLE IF EXISTS `movie_custom_fields`;
+DROP TABLE IF EXISTS `gallery_custom_fields`;
+DROP TABLE IF EXISTS `image_custom_fields`;
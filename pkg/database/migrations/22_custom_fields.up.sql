@@ -0,0 +1,70 @@
+-- custom field values are stored in a blob column, which has no type
+-- affinity, so that integer values are compared as integers and text values
+-- as text. The type column distinguishes booleans from integers, and dates
+-- from strings.
+
+CREATE TABLE `scene_custom_fields` (
+  `scene_id` integer not null,
+  `field` varchar(64) not null,
+  `type` varchar(16) not null,
+  `value` blob not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_scene_custom_fields_on_scene_id_field` on `scene_custom_fields` (`scene_id`, `field`);
+CREATE INDEX `index_scene_custom_fields_on_field_value` on `scene_custom_fields` (`field`, `value`);
+
+CREATE TABLE `performer_custom_fields` (
+  `performer_id` integer not null,
+  `field` varchar(64) not null,
+  `type` varchar(16) not null,
+  `value` blob not null,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_performer_custom_fields_on_performer_id_field` on `performer_custom_fields` (`performer_id`, `field`);
+CREATE INDEX `index_performer_custom_fields_on_field_value` on `performer_custom_fields` (`field`, `value`);
+
+CREATE TABLE `studio_custom_fields` (
+  `studio_id` integer not null,
+  `field` varchar(64) not null,
+  `type` varchar(16) not null,
+  `value` blob not null,
+  foreign key(`studio_id`) references `studios`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_studio_custom_fields_on_studio_id_field` on `studio_custom_fields` (`studio_id`, `field`);
+CREATE INDEX `index_studio_custom_fields_on_field_value` on `studio_custom_fields` (`field`, `value`);
+
+CREATE TABLE `movie_custom_fields` (
+  `movie_id` integer not null,
+  `field` varchar(64) not null,
+  `type` varchar(16) not null,
+  `value` blob not null,
+  foreign key(`movie_id`) references `movies`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_movie_custom_fields_on_movie_id_field` on `movie_custom_fields` (`movie_id`, `field`);
+CREATE INDEX `index_movie_custom_fields_on_field_value` on `movie_custom_fields` (`field`, `value`);
+
+CREATE TABLE `gallery_custom_fields` (
+  `gallery_id` integer not null,
+  `field` varchar(64) not null,
+  `type` varchar(16) not null,
+  `value` blob not null,
+  foreign key(`gallery_id`) references `galleries`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_gallery_custom_fields_on_gallery_id_field` on `gallery_custom_fields` (`gallery_id`, `field`);
+CREATE INDEX `index_gallery_custom_fields_on_field_value` on `gallery_custom_fields` (`field`, `value`);
+
+CREATE TABLE `image_custom_fields` (
+  `image_id` integer not null,
+  `field` varchar(64) not null,
+  `type` varchar(16) not null,
+  `value` blob not null,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_image_custom_fields_on_image_id_field` on `image_custom_fields` (`image_id`, `field`);
+CREATE INDEX `index_image_custom_fields_on_field_value` on `image_custom_fields` (`field`, `value`);
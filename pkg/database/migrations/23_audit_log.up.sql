@@ -0,0 +1,13 @@
+CREATE TABLE `audit_log` (
+  `id` integer not null primary key autoincrement,
+  `entity_type` varchar(32) not null,
+  `entity_id` integer not null,
+  `action` varchar(16) not null,
+  `field` varchar(64),
+  `old_value` text,
+  `new_value` text,
+  `source` varchar(16) not null,
+  `created_at` datetime not null
+);
+
+CREATE INDEX `index_audit_log_on_entity_type_entity_id` on `audit_log` (`entity_type`, `entity_id`);
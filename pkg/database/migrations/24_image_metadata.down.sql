@@ -0,0 +1,71 @@
+DROP TABLE IF EXISTS `images_keywords`;
+
+-- remove the metadata columns
+CREATE TABLE `_images_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510) not null,
+  `checksum` varchar(255) not null,
+  `title` varchar(255),
+  `rating` tinyint,
+  `size` integer,
+  `width` tinyint,
+  `height` tinyint,
+  `studio_id` integer,
+  `o_counter` tinyint not null default 0,
+  `created_at` datetime not null,
+  `updated_at` datetime not null, `file_mod_time` datetime, `organized` boolean not null default '0',
+  foreign key(`studio_id`) references `studios`(`id`) on delete SET NULL
+);
+
+INSERT INTO `_images_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `title`,
+    `rating`,
+    `size`,
+    `width`,
+    `height`,
+    `studio_id`,
+    `o_counter`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`,
+    `organized`
+  )
+  SELECT
+    `id`,
+    `path`,
+    `checksum`,
+    `title`,
+    `rating`,
+    `size`,
+    `width`,
+    `height`,
+    `studio_id`,
+    `o_counter`,
+    `created_at`,
+    `updated_at`,
+    `file_mod_time`,
+    `organized`
+  FROM `images`;
+
+DROP TABLE `images`;
+ALTER TABLE `_images_new` rename to `images`;
+
+CREATE INDEX `index_images_on_studio_id` on `images` (`studio_id`);
+
+-- the full text search triggers are dropped with the table
+CREATE TRIGGER `images_fts_insert` AFTER INSERT ON `images` BEGIN
+  INSERT INTO `images_fts` (`rowid`, `title`, `path`, `checksum`) VALUES (new.`id`, new.`title`, new.`path`, new.`checksum`);
+END;
+
+CREATE TRIGGER `images_fts_delete` AFTER DELETE ON `images` BEGIN
+  INSERT INTO `images_fts` (`images_fts`, `rowid`, `title`, `path`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`path`, old.`checksum`);
+END;
+
+CREATE TRIGGER `images_fts_update` AFTER UPDATE OF `title`, `path`, `checksum` ON `images` BEGIN
+  INSERT INTO `images_fts` (`images_fts`, `rowid`, `title`, `path`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`path`, old.`checksum`);
+  INSERT INTO `images_fts` (`rowid`, `title`, `path`, `checksum`) VALUES (new.`id`, new.`title`, new.`path`, new.`checksum`);
+END;
@@ -0,0 +1,13 @@
+-- metadata read from the EXIF and XMP data of image files. A null
+-- orientation indicates that the metadata has not been read.
+ALTER TABLE `images` ADD COLUMN `date` date;
+ALTER TABLE `images` ADD COLUMN `orientation` tinyint;
+ALTER TABLE `images` ADD COLUMN `camera` varchar(255);
+
+CREATE TABLE `images_keywords` (
+  `image_id` integer not null,
+  `keyword` varchar(255) not null,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_images_keywords_on_image_id` on `images_keywords` (`image_id`);
@@ -0,0 +1,6 @@
+-- the format is the image format, or the container of video clips. A null
+-- format means the file has not been inspected.
+ALTER TABLE `images` ADD COLUMN `format` varchar(255);
+ALTER TABLE `images` ADD COLUMN `is_animated` boolean not null default '0';
+-- duration is only set for video clips
+ALTER TABLE `images` ADD COLUMN `duration` float;
@@ -0,0 +1,92 @@
+DROP TABLE `galleries_chapters`;
+
+-- remove the image position
+CREATE TABLE `_galleries_images_new` (
+  `gallery_id` integer,
+  `image_id` integer,
+  foreign key(`gallery_id`) references `galleries`(`id`) on delete CASCADE,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE
+);
+
+INSERT INTO `_galleries_images_new` (`gallery_id`, `image_id`)
+  SELECT `gallery_id`, `image_id` FROM `galleries_images`;
+
+DROP TABLE `galleries_images`;
+ALTER TABLE `_galleries_images_new` rename to `galleries_images`;
+
+CREATE INDEX `index_galleries_images_on_image_id` on `galleries_images` (`image_id`);
+CREATE INDEX `index_galleries_images_on_gallery_id` on `galleries_images` (`gallery_id`);
+
+-- remove the cover image
+CREATE TABLE `_galleries_new` (
+  `id` integer not null primary key autoincrement,
+  `path` varchar(510),
+  `checksum` varchar(255) not null,
+  `zip` boolean not null default '0',
+  `title` varchar(255),
+  `url` varchar(255),
+  `date` date,
+  `details` text,
+  `studio_id` integer,
+  `rating` tinyint,
+  `file_mod_time` datetime,
+  `organized` boolean not null default '0',
+  `created_at` datetime not null,
+  `updated_at` datetime not null,
+  foreign key(`studio_id`) references `studios`(`id`) on delete SET NULL
+);
+
+INSERT INTO `_galleries_new`
+  (
+    `id`,
+    `path`,
+    `checksum`,
+    `zip`,
+    `title`,
+    `url`,
+    `date`,
+    `details`,
+    `studio_id`,
+    `rating`,
+    `file_mod_time`,
+    `organized`,
+    `created_at`,
+    `updated_at`
+  )
+  SELECT
+    `id`,
+    `path`,
+    `checksum`,
+    `zip`,
+    `title`,
+    `url`,
+    `date`,
+    `details`,
+    `studio_id`,
+    `rating`,
+    `file_mod_time`,
+    `organized`,
+    `created_at`,
+    `updated_at`
+  FROM `galleries`;
+
+DROP TABLE `galleries`;
+ALTER TABLE `_galleries_new` rename to `galleries`;
+
+CREATE UNIQUE INDEX `galleries_path_unique` on `galleries` (`path`);
+CREATE UNIQUE INDEX `galleries_checksum_unique` on `galleries` (`checksum`);
+CREATE INDEX `index_galleries_on_studio_id` on `galleries` (`studio_id`);
+
+-- the full text search triggers are dropped with the table
+CREATE TRIGGER `galleries_fts_insert` AFTER INSERT ON `galleries` BEGIN
+  INSERT INTO `galleries_fts` (`rowid`, `title`, `path`, `checksum`) VALUES (new.`id`, new.`title`, new.`path`, new.`checksum`);
+END;
+
+CREATE TRIGGER `galleries_fts_delete` AFTER DELETE ON `galleries` BEGIN
+  INSERT INTO `galleries_fts` (`galleries_fts`, `rowid`, `title`, `path`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`path`, old.`checksum`);
+END;
+
+CREATE TRIGGER `galleries_fts_update` AFTER UPDATE OF `title`, `path`, `checksum` ON `galleries` BEGIN
+  INSERT INTO `galleries_fts` (`galleries_fts`, `rowid`, `title`, `path`, `checksum`) VALUES ('delete', old.`id`, old.`title`, old.`path`, old.`checksum`);
+  INSERT INTO `galleries_fts` (`rowid`, `title`, `path`, `checksum`) VALUES (new.`id`, new.`title`, new.`path`, new.`checksum`);
+END;
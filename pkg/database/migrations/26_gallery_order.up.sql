@@ -0,0 +1,14 @@
+-- explicit image order within a gallery. Images with a null position follow
+-- the ordered images, ordered by path.
+ALTER TABLE `galleries_images` ADD COLUMN `position` integer;
+
+ALTER TABLE `galleries` ADD COLUMN `cover_image_id` integer REFERENCES `images`(`id`) ON DELETE SET NULL;
+
+CREATE TABLE `galleries_chapters` (
+  `gallery_id` integer not null,
+  `title` varchar(255) not null,
+  `image_index` integer not null,
+  foreign key(`gallery_id`) references `galleries`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_galleries_chapters_on_gallery_id` on `galleries_chapters` (`gallery_id`);
@@ -0,0 +1,3 @@
+-- 64 bit perceptual hash of the image, used to find duplicate images with
+-- different encodings
+ALTER TABLE `images` ADD COLUMN `phash` integer;
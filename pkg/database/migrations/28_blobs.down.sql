@@ -0,0 +1,102 @@
+-- restore the images from the blobs. Images stored in the filesystem are
+-- lost.
+ALTER TABLE `performers_image` rename to `_performers_image_old`;
+DROP INDEX `index_performer_image_on_performer_id_position`;
+DROP INDEX `index_performers_image_on_image_blob`;
+
+CREATE TABLE `performers_image` (
+  `performer_id` integer,
+  `position` integer not null default 0,
+  `image` blob not null,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_performer_image_on_performer_id_position` on `performers_image` (`performer_id`, `position`);
+
+INSERT INTO `performers_image` (`performer_id`, `position`, `image`)
+  SELECT `performer_id`, `position`, `blobs`.`blob` FROM `_performers_image_old`
+  INNER JOIN `blobs` ON `blobs`.`checksum` = `image_blob`
+  WHERE `blobs`.`blob` IS NOT NULL;
+
+DROP TABLE `_performers_image_old`;
+
+ALTER TABLE `studios_image` rename to `_studios_image_old`;
+DROP INDEX `index_studio_image_on_studio_id`;
+DROP INDEX `index_studios_image_on_image_blob`;
+
+CREATE TABLE `studios_image` (
+  `studio_id` integer,
+  `image` blob not null,
+  foreign key(`studio_id`) references `studios`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_studio_image_on_studio_id` on `studios_image` (`studio_id`);
+
+INSERT INTO `studios_image` (`studio_id`, `image`)
+  SELECT `studio_id`, `blobs`.`blob` FROM `_studios_image_old`
+  INNER JOIN `blobs` ON `blobs`.`checksum` = `image_blob`
+  WHERE `blobs`.`blob` IS NOT NULL;
+
+DROP TABLE `_studios_image_old`;
+
+ALTER TABLE `tags_image` rename to `_tags_image_old`;
+DROP INDEX `index_tag_image_on_tag_id`;
+DROP INDEX `index_tags_image_on_image_blob`;
+
+CREATE TABLE `tags_image` (
+  `tag_id` integer,
+  `image` blob not null,
+  foreign key(`tag_id`) references `tags`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_tag_image_on_tag_id` on `tags_image` (`tag_id`);
+
+INSERT INTO `tags_image` (`tag_id`, `image`)
+  SELECT `tag_id`, `blobs`.`blob` FROM `_tags_image_old`
+  INNER JOIN `blobs` ON `blobs`.`checksum` = `image_blob`
+  WHERE `blobs`.`blob` IS NOT NULL;
+
+DROP TABLE `_tags_image_old`;
+
+ALTER TABLE `movies_images` rename to `_movies_images_old`;
+DROP INDEX `index_movie_images_on_movie_id`;
+DROP INDEX `index_movies_images_on_front_image_blob`;
+DROP INDEX `index_movies_images_on_back_image_blob`;
+
+CREATE TABLE `movies_images` (
+  `movie_id` integer,
+  `front_image` blob not null,
+  `back_image` blob,
+  foreign key(`movie_id`) references `movies`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_movie_images_on_movie_id` on `movies_images` (`movie_id`);
+
+INSERT INTO `movies_images` (`movie_id`, `front_image`, `back_image`)
+  SELECT `movie_id`, `front`.`blob`, `back`.`blob` FROM `_movies_images_old`
+  INNER JOIN `blobs` AS `front` ON `front`.`checksum` = `front_image_blob`
+  LEFT JOIN `blobs` AS `back` ON `back`.`checksum` = `back_image_blob`
+  WHERE `front`.`blob` IS NOT NULL;
+
+DROP TABLE `_movies_images_old`;
+
+ALTER TABLE `scenes_cover` rename to `_scenes_cover_old`;
+DROP INDEX `index_scene_covers_on_scene_id`;
+DROP INDEX `index_scenes_cover_on_cover_blob`;
+
+CREATE TABLE `scenes_cover` (
+  `scene_id` integer,
+  `cover` blob not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE UNIQUE INDEX `index_scene_covers_on_scene_id` on `scenes_cover` (`scene_id`);
+
+INSERT INTO `scenes_cover` (`scene_id`, `cover`)
+  SELECT `scene_id`, `blobs`.`blob` FROM `_scenes_cover_old`
+  INNER JOIN `blobs` ON `blobs`.`checksum` = `cover_blob`
+  WHERE `blobs`.`blob` IS NOT NULL;
+
+DROP TABLE `_scenes_cover_old`;
+
+DROP TABLE `blobs`;
@@ -0,0 +1,119 @@
+-- performer, studio, tag, movie and scene images are stored once in the
+-- blobs table, keyed by their MD5 checksum. The blob is NULL if the image
+-- is stored in the filesystem.
+CREATE TABLE `blobs` (
+  `checksum` varchar(255) not null primary key,
+  `blob` blob
+);
+
+INSERT OR IGNORE INTO `blobs` (`checksum`, `blob`)
+  SELECT md5(`image`), `image` FROM `performers_image`;
+INSERT OR IGNORE INTO `blobs` (`checksum`, `blob`)
+  SELECT md5(`image`), `image` FROM `studios_image`;
+INSERT OR IGNORE INTO `blobs` (`checksum`, `blob`)
+  SELECT md5(`image`), `image` FROM `tags_image`;
+INSERT OR IGNORE INTO `blobs` (`checksum`, `blob`)
+  SELECT md5(`front_image`), `front_image` FROM `movies_images`;
+INSERT OR IGNORE INTO `blobs` (`checksum`, `blob`)
+  SELECT md5(`back_image`), `back_image` FROM `movies_images` WHERE `back_image` IS NOT NULL;
+INSERT OR IGNORE INTO `blobs` (`checksum`, `blob`)
+  SELECT md5(`cover`), `cover` FROM `scenes_cover`;
+
+-- replace the images with references to the blobs
+ALTER TABLE `performers_image` rename to `_performers_image_old`;
+DROP INDEX `index_performer_image_on_performer_id_position`;
+
+CREATE TABLE `performers_image` (
+  `performer_id` integer,
+  `position` integer not null default 0,
+  `image_blob` varchar(255) not null,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE,
+  foreign key(`image_blob`) references `blobs`(`checksum`)
+);
+
+CREATE UNIQUE INDEX `index_performer_image_on_performer_id_position` on `performers_image` (`performer_id`, `position`);
+CREATE INDEX `index_performers_image_on_image_blob` on `performers_image` (`image_blob`);
+
+INSERT INTO `performers_image` (`performer_id`, `position`, `image_blob`)
+  SELECT `performer_id`, `position`, md5(`image`) FROM `_performers_image_old`;
+
+DROP TABLE `_performers_image_old`;
+
+ALTER TABLE `studios_image` rename to `_studios_image_old`;
+DROP INDEX `index_studio_image_on_studio_id`;
+
+CREATE TABLE `studios_image` (
+  `studio_id` integer,
+  `image_blob` varchar(255) not null,
+  foreign key(`studio_id`) references `studios`(`id`) on delete CASCADE,
+  foreign key(`image_blob`) references `blobs`(`checksum`)
+);
+
+CREATE UNIQUE INDEX `index_studio_image_on_studio_id` on `studios_image` (`studio_id`);
+CREATE INDEX `index_studios_image_on_image_blob` on `studios_image` (`image_blob`);
+
+INSERT INTO `studios_image` (`studio_id`, `image_blob`)
+  SELECT `studio_id`, md5(`image`) FROM `_studios_image_old`;
+
+DROP TABLE `_studios_image_old`;
+
+ALTER TABLE `tags_image` rename to `_tags_image_old`;
+DROP INDEX `index_tag_image_on_tag_id`;
+
+CREATE TABLE `tags_image` (
+  `tag_id` integer,
+  `image_blob` varchar(255) not null,
+  foreign key(`tag_id`) references `tags`(`id`) on delete CASCADE,
+  foreign key(`image_blob`) references `blobs`(`checksum`)
+);
+
+CREATE UNIQUE INDEX `index_tag_image_on_tag_id` on `tags_image` (`tag_id`);
+CREATE INDEX `index_tags_image_on_image_blob` on `tags_image` (`image_blob`);
+
+INSERT INTO `tags_image` (`tag_id`, `image_blob`)
+  SELECT `tag_id`, md5(`image`) FROM `_tags_image_old`;
+
+DROP TABLE `_tags_image_old`;
+
+ALTER TABLE `movies_images` rename to `_movies_images_old`;
+DROP INDEX `index_movie_images_on_movie_id`;
+
+CREATE TABLE `movies_images` (
+  `movie_id` integer,
+  `front_image_blob` varchar(255) not null,
+  `back_image_blob` varchar(255),
+  foreign key(`movie_id`) references `movies`(`id`) on delete CASCADE,
+  foreign key(`front_image_blob`) references `blobs`(`checksum`),
+  foreign key(`back_image_blob`) references `blobs`(`checksum`)
+);
+
+CREATE UNIQUE INDEX `index_movie_images_on_movie_id` on `movies_images` (`movie_id`);
+CREATE INDEX `index_movies_images_on_front_image_blob` on `movies_images` (`front_image_blob`);
+CREATE INDEX `index_movies_images_on_back_image_blob` on `movies_images` (`back_image_blob`);
+
+INSERT INTO `movies_images` (`movie_id`, `front_image_blob`, `back_image_blob`)
+  SELECT
+    `movie_id`,
+    md5(`front_image`),
+    CASE WHEN `back_image` IS NULL THEN NULL ELSE md5(`back_image`) END
+  FROM `_movies_images_old`;
+
+DROP TABLE `_movies_images_old`;
+
+ALTER TABLE `scenes_cover` rename to `_scenes_cover_old`;
+DROP INDEX `index_scene_covers_on_scene_id`;
+
+CREATE TABLE `scenes_cover` (
+  `scene_id` integer,
+  `cover_blob` varchar(255) not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE,
+  foreign key(`cover_blob`) references `blobs`(`checksum`)
+);
+
+CREATE UNIQUE INDEX `index_scene_covers_on_scene_id` on `scenes_cover` (`scene_id`);
+CREATE INDEX `index_scenes_cover_on_cover_blob` on `scenes_cover` (`cover_blob`);
+
+INSERT INTO `scenes_cover` (`scene_id`, `cover_blob`)
+  SELECT `scene_id`, md5(`cover`) FROM `_scenes_cover_old`;
+
+DROP TABLE `_scenes_cover_old`;
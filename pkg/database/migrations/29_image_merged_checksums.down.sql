@@ -0,0 +1,2 @@
+-- remove the merged image checksums
+DROP TABLE `images_merged_checksums`;
@@ -0,0 +1,10 @@
+-- checksums of the image files that were merged into an image, so that the
+-- merged files are not added again when they are scanned
+CREATE TABLE `images_merged_checksums` (
+  `image_id` integer not null,
+  `checksum` varchar(255) not null,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_images_merged_checksums_on_image_id` on `images_merged_checksums` (`image_id`);
+CREATE INDEX `index_images_merged_checksums_on_checksum` on `images_merged_checksums` (`checksum`);
@@ -0,0 +1,66 @@
+DROP TABLE IF EXISTS `movies_scenes`;
+DROP TABLE IF EXISTS `movies`;
+
+-- remove the movie_id column
+CREATE TABLE `_scraped_items_new` (
+  `id` integer not null primary key autoincrement,
+  `title` varchar(255),
+  `description` text,
+  `url` varchar(255),
+  `date` date,
+  `rating` varchar(255),
+  `tags` varchar(510),
+  `models` varchar(510),
+  `episode` integer,
+  `gallery_filename` varchar(255),
+  `gallery_url` varchar(510),
+  `video_filename` varchar(255),
+  `video_url` varchar(255),
+  `studio_id` integer,
+  `created_at` datetime not null,
+  `updated_at` datetime not null,
+  foreign key(`studio_id`) references `studios`(`id`)
+);
+
+INSERT INTO `_scraped_items_new`
+  (
+    `id`,
+    `title`,
+    `description`,
+    `url`,
+    `date`,
+    `rating`,
+    `tags`,
+    `models`,
+    `episode`,
+    `gallery_filename`,
+    `gallery_url`,
+    `video_filename`,
+    `video_url`,
+    `studio_id`,
+    `created_at`,
+    `updated_at`
+  )
+  SELECT
+    `id`,
+    `title`,
+    `description`,
+    `url`,
+    `date`,
+    `rating`,
+    `tags`,
+    `models`,
+    `episode`,
+    `gallery_filename`,
+    `gallery_url`,
+    `video_filename`,
+    `video_url`,
+    `studio_id`,
+    `created_at`,
+    `updated_at`
+  FROM `scraped_items`;
+
+DROP TABLE `scraped_items`;
+ALTER TABLE `_scraped_items_new` rename to `scraped_items`;
+
+CREATE INDEX `index_scraped_items_on_studio_id` on `scraped_items` (`studio_id`);
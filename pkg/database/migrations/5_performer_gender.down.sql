@@ -9,6 +9,7 @@ ALTER TABLE `performers` RENAME TO `performers_old`;
 ALTER TABLE `performers_scenes` RENAME TO `performers_scenes_old`;
 
 -- drop the indexes
+DROP INDEX IF EXISTS `performers_checksum_unique`;
 DROP INDEX IF EXISTS `index_performers_on_name`;
 DROP INDEX IF EXISTS `index_performers_on_checksum`;
 DROP INDEX IF EXISTS `index_performers_scenes_on_scene_id`;
@@ -81,6 +82,7 @@ DROP TABLE `performers_scenes_old`;
 DROP TABLE `performers_old`;
 
 -- re-create the indexes after removing the old tables
+CREATE UNIQUE INDEX `performers_checksum_unique` on `performers` (`checksum`);
 CREATE INDEX `index_performers_on_name` on `performers` (`name`);
 CREATE INDEX `index_performers_on_checksum` on `performers` (`checksum`);
 CREATE INDEX `index_performers_scenes_on_scene_id` on `performers_scenes` (`scene_id`);
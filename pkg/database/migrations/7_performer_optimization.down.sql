@@ -0,0 +1,81 @@
+-- restore the original column order and checksum index
+CREATE TABLE `_performers_new` (
+  `id` integer not null primary key autoincrement,
+  `image` blob not null,
+  `checksum` varchar(255) not null,
+  `name` varchar(255),
+  `url` varchar(255),
+  `twitter` varchar(255),
+  `instagram` varchar(255),
+  `birthdate` date,
+  `ethnicity` varchar(255),
+  `country` varchar(255),
+  `eye_color` varchar(255),
+  `height` varchar(255),
+  `measurements` varchar(255),
+  `fake_tits` varchar(255),
+  `career_length` varchar(255),
+  `tattoos` varchar(255),
+  `piercings` varchar(255),
+  `aliases` varchar(255),
+  `favorite` boolean not null default '0',
+  `created_at` datetime not null,
+  `updated_at` datetime not null
+, `gender` varchar(20));
+
+INSERT INTO `_performers_new`
+  (
+    `id`,
+    `image`,
+    `checksum`,
+    `name`,
+    `url`,
+    `twitter`,
+    `instagram`,
+    `birthdate`,
+    `ethnicity`,
+    `country`,
+    `eye_color`,
+    `height`,
+    `measurements`,
+    `fake_tits`,
+    `career_length`,
+    `tattoos`,
+    `piercings`,
+    `aliases`,
+    `favorite`,
+    `created_at`,
+    `updated_at`,
+    `gender`
+  )
+  SELECT
+    `id`,
+    `image`,
+    `checksum`,
+    `name`,
+    `url`,
+    `twitter`,
+    `instagram`,
+    `birthdate`,
+    `ethnicity`,
+    `country`,
+    `eye_color`,
+    `height`,
+    `measurements`,
+    `fake_tits`,
+    `career_length`,
+    `tattoos`,
+    `piercings`,
+    `aliases`,
+    `favorite`,
+    `created_at`,
+    `updated_at`,
+    `gender`
+  FROM `performers`;
+
+DROP TABLE `performers`;
+ALTER TABLE `_performers_new` rename to `performers`;
+
+CREATE INDEX `index_performers_on_checksum` on `performers` (`checksum`);
+CREATE INDEX `index_performers_on_name` on `performers` (`name`);
+CREATE UNIQUE INDEX `performers_checksum_unique` on `performers` (`checksum`);
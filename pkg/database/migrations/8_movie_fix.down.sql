@@ -0,0 +1,85 @@
+-- revert the movie column types and remove studio_id
+
+CREATE TABLE `_movies_new` (
+  `id` integer not null primary key autoincrement,
+  `name` varchar(255),
+  `aliases` varchar(255),
+  `duration` varchar(6),
+  `date` date,
+  `rating` varchar(1),
+  `director` varchar(255),
+  `synopsis` text,
+  `front_image` blob not null,
+  `back_image` blob,
+  `checksum` varchar(255) not null,
+  `url` varchar(255),
+  `created_at` datetime not null,
+  `updated_at` datetime not null
+);
+
+INSERT INTO `_movies_new`
+  (
+    `id`,
+    `name`,
+    `aliases`,
+    `duration`,
+    `date`,
+    `rating`,
+    `director`,
+    `synopsis`,
+    `front_image`,
+    `back_image`,
+    `checksum`,
+    `url`,
+    `created_at`,
+    `updated_at`
+  )
+  SELECT
+    `id`,
+    `name`,
+    `aliases`,
+    CAST(`duration` as varchar(6)),
+    `date`,
+    CAST(`rating` as varchar(1)),
+    `director`,
+    `synopsis`,
+    `front_image`,
+    `back_image`,
+    `checksum`,
+    `url`,
+    `created_at`,
+    `updated_at`
+  FROM `movies`;
+
+DROP TABLE `movies`;
+ALTER TABLE `_movies_new` rename to `movies`;
+
+CREATE UNIQUE INDEX `movies_checksum_unique` on `movies` (`checksum`);
+
+-- only the first of any duplicate scene indexes is kept
+CREATE TABLE `_movies_scenes_new` (
+  `movie_id` integer,
+  `scene_id` integer,
+  `scene_index` varchar(2),
+  foreign key(`movie_id`) references `movies`(`id`),
+  foreign key(`scene_id`) references `scenes`(`id`)
+);
+
+INSERT INTO `_movies_scenes_new`
+  (
+    `movie_id`,
+    `scene_id`,
+    `scene_index`
+  )
+  SELECT
+    `movie_id`,
+    `scene_id`,
+    CASE WHEN `rowid` = (SELECT MIN(`rowid`) FROM `movies_scenes` AS `m` WHERE `m`.`movie_id` = `movies_scenes`.`movie_id` AND `m`.`scene_index` = `movies_scenes`.`scene_index`) THEN CAST(`scene_index` as varchar(2)) ELSE NULL END
+  FROM `movies_scenes`;
+
+DROP TABLE `movies_scenes`;
+ALTER TABLE `_movies_scenes_new` rename to `movies_scenes`;
+
+CREATE UNIQUE INDEX `index_movie_id_scene_index_unique` ON `movies_scenes` ( `movie_id`, `scene_index` );
+CREATE INDEX `index_movies_scenes_on_movie_id` on `movies_scenes` (`movie_id`);
+CREATE INDEX `index_movies_scenes_on_scene_id` on `movies_scenes` (`scene_id`);
@@ -0,0 +1,37 @@
+-- remove the parent_id column
+CREATE TABLE `_studios_new` (
+  `id` integer not null primary key autoincrement,
+  `image` blob not null,
+  `checksum` varchar(255) not null,
+  `name` varchar(255),
+  `url` varchar(255),
+  `created_at` datetime not null,
+  `updated_at` datetime not null
+);
+
+INSERT INTO `_studios_new`
+  (
+    `id`,
+    `image`,
+    `checksum`,
+    `name`,
+    `url`,
+    `created_at`,
+    `updated_at`
+  )
+  SELECT
+    `id`,
+    `image`,
+    `checksum`,
+    `name`,
+    `url`,
+    `created_at`,
+    `updated_at`
+  FROM `studios`;
+
+DROP TABLE `studios`;
+ALTER TABLE `_studios_new` rename to `studios`;
+
+CREATE INDEX `index_studios_on_checksum` on `studios` (`checksum`);
+CREATE INDEX `index_studios_on_name` on `studios` (`name`);
+CREATE UNIQUE INDEX `studios_checksum_unique` on `studios` (`checksum`);
@@ -0,0 +1,94 @@
+// +build integration
+
+package database
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func countStudios(t *testing.T, db *sqlx.DB) int {
+	var ret int
+	if err := db.Get(&ret, "SELECT COUNT(*) FROM studios"); err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func TestRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-restore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	origPath := dbPath
+	origDB := DB
+	defer func() {
+		if DB != nil {
+			DB.Close()
+		}
+		dbPath = origPath
+		DB = origDB
+	}()
+
+	path := filepath.Join(dir, "stash-go.sqlite")
+	schemaAtVersion(t, path, appSchemaVersion)
+	dbPath = path
+	DB = open(path, false)
+
+	insertStudio := func(name string) {
+		if _, err := DB.Exec("INSERT INTO studios (checksum, name, created_at, updated_at) VALUES (?, ?, '', '')", name, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	insertStudio("first")
+	backupPath := filepath.Join(dir, "backup.sqlite")
+	if err := Backup(DB, backupPath); err != nil {
+		t.Fatal(err)
+	}
+	insertStudio("second")
+
+	migrated, err := Restore(backupPath)
+	assert.Nil(t, err)
+	assert.False(t, migrated)
+	if assert.NotNil(t, DB) {
+		assert.Equal(t, 1, countStudios(t, DB))
+	}
+
+	// the database before restoring is kept
+	preRestore, err := sqlx.Connect(sqlite3Driver, "file:"+path+".pre-restore?mode=ro")
+	if assert.Nil(t, err) {
+		assert.Equal(t, 2, countStudios(t, preRestore))
+		preRestore.Close()
+	}
+
+	// a backup that fails to migrate is reverted
+	insertStudio("third")
+	badPath := filepath.Join(dir, "bad.sqlite")
+	if err := Backup(DB, badPath); err != nil {
+		t.Fatal(err)
+	}
+	bad, err := sqlx.Connect(sqlite3Driver, "file:"+badPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the latest migration fails since its tables already exist
+	if _, err := bad.Exec("UPDATE schema_migrations SET version = ?", appSchemaVersion-1); err != nil {
+		t.Fatal(err)
+	}
+	bad.Close()
+
+	_, err = Restore(badPath)
+	assert.NotNil(t, err)
+	if assert.NotNil(t, DB) {
+		assert.Equal(t, 2, countStudios(t, DB))
+	}
+	assert.Equal(t, appSchemaVersion, Version())
+}
@@ -0,0 +1,39 @@
+package ffmpeg
+
+import "fmt"
+
+type AnimatedThumbnailOptions struct {
+	OutputPath string
+	// MaxSize is the maximum width and height of the thumbnail. Smaller
+	// inputs are not scaled.
+	MaxSize int
+	// Quality is the quality of the thumbnail, from 1 to 100. Defaults to
+	// 70 if zero.
+	Quality int
+}
+
+// AnimatedThumbnail encodes an animated WebP thumbnail of an animated image
+// or video clip.
+func (e *Encoder) AnimatedThumbnail(probeResult VideoFile, options AnimatedThumbnailOptions) error {
+	quality := options.Quality
+	if quality == 0 {
+		quality = 70
+	}
+
+	args := []string{
+		"-v", "error",
+		"-i", probeResult.Path,
+		"-y",
+		"-c:v", "libwebp",
+		"-q:v", fmt.Sprint(quality),
+		"-compression_level", "4",
+		"-preset", "default",
+		"-loop", "0",
+		"-vf", fmt.Sprintf("scale=w='min(%[1]d,iw)':h='min(%[1]d,ih)':force_original_aspect_ratio=decrease", options.MaxSize),
+		"-an",
+		"-f", "webp",
+		options.OutputPath,
+	}
+	_, err := e.run(probeResult, args)
+	return err
+}
@@ -0,0 +1,118 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// imageFormatCodecs are the encoders of still image formats, in order of
+// preference.
+var imageFormatCodecs = map[string][]string{
+	"webp": {"libwebp"},
+	"avif": {"libaom-av1", "libsvtav1"},
+}
+
+var (
+	availableEncoders      = make(map[string][]string)
+	availableEncodersMutex sync.Mutex
+)
+
+// getEncoders returns the names of the encoders supported by ffmpeg. The
+// result is cached for each ffmpeg path.
+func (e *Encoder) getEncoders() []string {
+	availableEncodersMutex.Lock()
+	defer availableEncodersMutex.Unlock()
+
+	if ret, found := availableEncoders[e.Path]; found {
+		return ret
+	}
+
+	ret := []string{}
+	out, err := exec.Command(e.Path, "-hide_banner", "-encoders").Output()
+	if err == nil {
+		// encoders are listed after a line of dashes as flags followed by
+		// the encoder name
+		listed := false
+		for _, line := range strings.Split(string(out), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+
+			if listed {
+				ret = append(ret, fields[1])
+			} else if strings.HasPrefix(fields[0], "---") {
+				listed = true
+			}
+		}
+	}
+
+	availableEncoders[e.Path] = ret
+	return ret
+}
+
+func (e *Encoder) getImageCodec(format string) string {
+	encoders := e.getEncoders()
+	for _, codec := range imageFormatCodecs[format] {
+		for _, encoder := range encoders {
+			if encoder == codec {
+				return codec
+			}
+		}
+	}
+
+	return ""
+}
+
+// SupportsImageFormat returns true if ffmpeg can encode still images in the
+// provided format.
+func (e *Encoder) SupportsImageFormat(format string) bool {
+	return e.getImageCodec(format) != ""
+}
+
+type ImageEncodeOptions struct {
+	InputPath  string
+	OutputPath string
+	// Format is the format of the output image, either webp or avif.
+	Format string
+	// Quality is the quality of WebP images, from 1 to 100. Defaults to 80
+	// if zero.
+	Quality int
+}
+
+// EncodeImage encodes a still image in the WebP or AVIF format.
+func (e *Encoder) EncodeImage(options ImageEncodeOptions) error {
+	codec := e.getImageCodec(options.Format)
+	if codec == "" {
+		return fmt.Errorf("ffmpeg does not support encoding %s images", options.Format)
+	}
+
+	args := []string{
+		"-v", "error",
+		"-i", options.InputPath,
+		"-y",
+		"-frames:v", "1",
+		"-c:v", codec,
+	}
+
+	switch codec {
+	case "libwebp":
+		quality := options.Quality
+		if quality == 0 {
+			quality = 80
+		}
+		args = append(args, "-q:v", strconv.Itoa(quality))
+	case "libaom-av1":
+		args = append(args, "-still-picture", "1", "-crf", "30", "-cpu-used", "6", "-pix_fmt", "yuv420p")
+	case "libsvtav1":
+		args = append(args, "-crf", "30", "-preset", "8", "-pix_fmt", "yuv420p")
+	}
+
+	args = append(args, "-f", options.Format, options.OutputPath)
+
+	_, err := e.run(VideoFile{Path: options.InputPath}, args)
+	return err
+}
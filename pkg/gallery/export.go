@@ -66,6 +66,62 @@ func GetStudioName(reader models.StudioReader, gallery *models.Gallery) (string,
 	return "", nil
 }
 
+// GetCoverChecksum returns the checksum of the chosen cover image of the
+// provided gallery. It returns an empty string if no cover image is chosen.
+func GetCoverChecksum(reader models.ImageReader, gallery *models.Gallery) (string, error) {
+	if gallery.CoverImageID.Valid {
+		image, err := reader.Find(int(gallery.CoverImageID.Int64))
+		if err != nil {
+			return "", err
+		}
+
+		if image != nil {
+			return image.Checksum, nil
+		}
+	}
+
+	return "", nil
+}
+
+// GetImageOrderChecksums returns the checksums of the images of the gallery
+// that have been explicitly ordered, in order.
+func GetImageOrderChecksums(galleryReader models.GalleryReader, imageReader models.ImageReader, galleryID int) ([]string, error) {
+	imageIDs, err := galleryReader.GetImageOrder(galleryID)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := imageReader.FindMany(imageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []string
+	for _, image := range images {
+		results = append(results, image.Checksum)
+	}
+
+	return results, nil
+}
+
+// GetChaptersJSON returns the chapters of the gallery as JSON objects.
+func GetChaptersJSON(reader models.GalleryReader, galleryID int) ([]jsonschema.GalleryChapter, error) {
+	chapters, err := reader.GetChapters(galleryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []jsonschema.GalleryChapter
+	for _, c := range chapters {
+		results = append(results, jsonschema.GalleryChapter{
+			Title:      c.Title,
+			ImageIndex: c.ImageIndex,
+		})
+	}
+
+	return results, nil
+}
+
 func GetIDs(galleries []*models.Gallery) []int {
 	var results []int
 	for _, gallery := range galleries {
@@ -256,9 +256,84 @@ func (i *Importer) PostImport(id int) error {
 		}
 	}
 
+	if len(i.Input.Chapters) > 0 {
+		var chapters []models.GalleryChapter
+		for _, c := range i.Input.Chapters {
+			chapters = append(chapters, models.GalleryChapter{
+				Title:      c.Title,
+				ImageIndex: c.ImageIndex,
+			})
+		}
+
+		if err := i.ReaderWriter.UpdateChapters(id, chapters); err != nil {
+			return fmt.Errorf("error setting gallery chapters: %s", err.Error())
+		}
+	}
+
+	if len(i.Input.CustomFields) > 0 {
+		if err := i.ReaderWriter.UpdateCustomFields(id, i.Input.CustomFields); err != nil {
+			return fmt.Errorf("error setting gallery custom fields: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
+// ImportImageOrder sets the cover image and the image order of the imported
+// gallery. It must be run after the images of the gallery are imported.
+// Images that are not in the gallery are ignored.
+func ImportImageOrder(qb models.GalleryReaderWriter, imageReader models.ImageReader, galleryJSON jsonschema.Gallery) error {
+	if galleryJSON.Cover == "" && len(galleryJSON.ImageOrder) == 0 {
+		return nil
+	}
+
+	g, err := qb.FindByChecksum(galleryJSON.Checksum)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return fmt.Errorf("gallery with checksum %s not found", galleryJSON.Checksum)
+	}
+
+	galleryImageIDs, err := qb.GetImageIDs(g.ID)
+	if err != nil {
+		return err
+	}
+
+	getImageID := func(checksum string) (*int, error) {
+		image, err := imageReader.FindByChecksum(checksum)
+		if err != nil || image == nil || !utils.IntInclude(galleryImageIDs, image.ID) {
+			return nil, err
+		}
+		return &image.ID, nil
+	}
+
+	if galleryJSON.Cover != "" {
+		coverID, err := getImageID(galleryJSON.Cover)
+		if err != nil {
+			return err
+		}
+		if coverID != nil {
+			if _, err := SetCover(qb, g.ID, coverID); err != nil {
+				return err
+			}
+		}
+	}
+
+	var imageIDs []int
+	for _, checksum := range galleryJSON.ImageOrder {
+		imageID, err := getImageID(checksum)
+		if err != nil {
+			return err
+		}
+		if imageID != nil {
+			imageIDs = utils.IntAppendUnique(imageIDs, *imageID)
+		}
+	}
+
+	return qb.UpdateImageOrder(g.ID, imageIDs)
+}
+
 func (i *Importer) Name() string {
 	return i.Input.Path
 }
@@ -404,6 +404,89 @@ func TestImporterPostImportUpdateTags(t *testing.T) {
 	galleryReaderWriter.AssertExpectations(t)
 }
 
+func TestImporterPostImportUpdateChapters(t *testing.T) {
+	galleryReaderWriter := &mocks.GalleryReaderWriter{}
+
+	i := Importer{
+		ReaderWriter: galleryReaderWriter,
+		Input: jsonschema.Gallery{
+			Chapters: []jsonschema.GalleryChapter{
+				{
+					Title:      "chapter",
+					ImageIndex: 2,
+				},
+			},
+		},
+	}
+
+	updateErr := errors.New("UpdateChapters error")
+
+	galleryReaderWriter.On("UpdateChapters", galleryID, []models.GalleryChapter{
+		{
+			Title:      "chapter",
+			ImageIndex: 2,
+		},
+	}).Return(nil).Once()
+	galleryReaderWriter.On("UpdateChapters", errTagsID, mock.AnythingOfType("[]models.GalleryChapter")).Return(updateErr).Once()
+
+	err := i.PostImport(galleryID)
+	assert.Nil(t, err)
+
+	err = i.PostImport(errTagsID)
+	assert.NotNil(t, err)
+
+	galleryReaderWriter.AssertExpectations(t)
+}
+
+func TestImportImageOrder(t *testing.T) {
+	galleryReaderWriter := &mocks.GalleryReaderWriter{}
+	imageReader := &mocks.ImageReaderWriter{}
+
+	const (
+		imageID        = 1
+		coverImageID   = 2
+		otherImageID   = 3
+		imageChecksum  = "imageChecksum"
+		coverChecksum  = "coverChecksum"
+		otherChecksum  = "otherChecksum"
+		unknownImage   = "unknownImage"
+		orderGalleryID = 4
+	)
+
+	galleryReaderWriter.On("FindByChecksum", checksum).Return(&models.Gallery{
+		ID: orderGalleryID,
+	}, nil).Once()
+	galleryReaderWriter.On("GetImageIDs", orderGalleryID).Return([]int{imageID, coverImageID}, nil).Once()
+	imageReader.On("FindByChecksum", imageChecksum).Return(&models.Image{ID: imageID}, nil)
+	imageReader.On("FindByChecksum", coverChecksum).Return(&models.Image{ID: coverImageID}, nil)
+	imageReader.On("FindByChecksum", otherChecksum).Return(&models.Image{ID: otherImageID}, nil)
+	imageReader.On("FindByChecksum", unknownImage).Return(nil, nil)
+
+	// the cover is validated when it is set
+	galleryReaderWriter.On("GetImageIDs", orderGalleryID).Return([]int{imageID, coverImageID}, nil).Once()
+	galleryReaderWriter.On("UpdatePartial", mock.MatchedBy(func(p models.GalleryPartial) bool {
+		return p.ID == orderGalleryID && p.CoverImageID != nil && p.CoverImageID.Int64 == coverImageID
+	})).Return(nil, nil).Once()
+
+	// images not in the gallery are ignored
+	galleryReaderWriter.On("UpdateImageOrder", orderGalleryID, []int{coverImageID, imageID}).Return(nil).Once()
+
+	err := ImportImageOrder(galleryReaderWriter, imageReader, jsonschema.Gallery{
+		Checksum:   checksum,
+		Cover:      coverChecksum,
+		ImageOrder: []string{coverChecksum, otherChecksum, unknownImage, imageChecksum},
+	})
+	assert.Nil(t, err)
+
+	// galleries without a cover or image order are not changed
+	err = ImportImageOrder(galleryReaderWriter, imageReader, jsonschema.Gallery{
+		Checksum: checksum,
+	})
+	assert.Nil(t, err)
+
+	galleryReaderWriter.AssertExpectations(t)
+}
+
 func TestImporterFindExistingID(t *testing.T) {
 	readerWriter := &mocks.GalleryReaderWriter{}
 
@@ -1,6 +1,10 @@
 package gallery
 
 import (
+	"database/sql"
+	"fmt"
+	"time"
+
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
 )
@@ -21,3 +25,78 @@ func AddImage(qb models.GalleryReaderWriter, galleryID int, imageID int) error {
 	imageIDs = utils.IntAppendUnique(imageIDs, imageID)
 	return qb.UpdateImages(galleryID, imageIDs)
 }
+
+func RemoveImage(qb models.GalleryReaderWriter, galleryID int, imageID int) error {
+	imageIDs, err := qb.GetImageIDs(galleryID)
+	if err != nil {
+		return err
+	}
+
+	imageIDs = utils.IntExclude(imageIDs, []int{imageID})
+	return qb.UpdateImages(galleryID, imageIDs)
+}
+
+// SetImageOrder sets the order of the provided images in the gallery. The
+// other images in the gallery follow the provided images.
+func SetImageOrder(qb models.GalleryReaderWriter, galleryID int, imageIDs []int) error {
+	galleryImageIDs, err := qb.GetImageIDs(galleryID)
+	if err != nil {
+		return err
+	}
+
+	for i, imageID := range imageIDs {
+		if !utils.IntInclude(galleryImageIDs, imageID) {
+			return fmt.Errorf("image %d is not in gallery %d", imageID, galleryID)
+		}
+		if utils.IntInclude(imageIDs[:i], imageID) {
+			return fmt.Errorf("image %d is provided more than once", imageID)
+		}
+	}
+
+	return qb.UpdateImageOrder(galleryID, imageIDs)
+}
+
+// SetCover sets the cover image of the gallery, which must be in the
+// gallery. The cover is cleared if imageID is nil.
+func SetCover(qb models.GalleryReaderWriter, galleryID int, imageID *int) (*models.Gallery, error) {
+	cover := sql.NullInt64{}
+	if imageID != nil {
+		galleryImageIDs, err := qb.GetImageIDs(galleryID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !utils.IntInclude(galleryImageIDs, *imageID) {
+			return nil, fmt.Errorf("image %d is not in gallery %d", *imageID, galleryID)
+		}
+
+		cover = sql.NullInt64{Int64: int64(*imageID), Valid: true}
+	}
+
+	return qb.UpdatePartial(models.GalleryPartial{
+		ID:           galleryID,
+		CoverImageID: &cover,
+		UpdatedAt:    &models.SQLiteTimestamp{Timestamp: time.Now()},
+	})
+}
+
+// ChaptersFromInput returns the chapters of the provided input, returning an
+// error if a chapter is invalid.
+func ChaptersFromInput(input []*models.GalleryChapterInput) ([]models.GalleryChapter, error) {
+	var ret []models.GalleryChapter
+	for _, c := range input {
+		if c.Title == "" {
+			return nil, fmt.Errorf("chapter title must not be empty")
+		}
+		if c.ImageIndex < 0 {
+			return nil, fmt.Errorf("chapter %s has a negative image index", c.Title)
+		}
+
+		ret = append(ret, models.GalleryChapter{
+			Title:      c.Title,
+			ImageIndex: c.ImageIndex,
+		})
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,451 @@
+package image
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Archive is an archive file containing images, such as a zip or rar file.
+type Archive interface {
+	// Files returns the files in the archive, excluding directories.
+	Files() []ArchiveFile
+	// Open returns a reader of the contents of the file with the provided
+	// name.
+	Open(name string) (io.ReadCloser, error)
+	Close() error
+}
+
+// ArchiveFile is a file within an archive.
+type ArchiveFile struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+type archiveFileInfo struct {
+	ArchiveFile
+}
+
+func (f archiveFileInfo) Name() string {
+	return f.ArchiveFile.Name
+}
+
+func (f archiveFileInfo) Size() int64 {
+	return f.ArchiveFile.Size
+}
+
+func (f archiveFileInfo) Mode() os.FileMode {
+	return 0444
+}
+
+func (f archiveFileInfo) ModTime() time.Time {
+	return f.ArchiveFile.ModTime
+}
+
+func (f archiveFileInfo) IsDir() bool {
+	return false
+}
+
+func (f archiveFileInfo) Sys() interface{} {
+	return nil
+}
+
+// ArchiveFormat is the format of an archive file.
+type ArchiveFormat string
+
+const (
+	ArchiveFormatZip ArchiveFormat = "zip"
+	ArchiveFormatRar ArchiveFormat = "rar"
+	ArchiveFormat7z  ArchiveFormat = "7z"
+)
+
+var archiveSignatures = map[ArchiveFormat][][]byte{
+	ArchiveFormatZip: {[]byte("PK\x03\x04"), []byte("PK\x05\x06")},
+	ArchiveFormatRar: {[]byte("Rar!\x1a\x07")},
+	ArchiveFormat7z:  {[]byte("7z\xbc\xaf\x27\x1c")},
+}
+
+// GetArchiveFormat returns the format of the archive file at the provided
+// path from its signature. Comic book archives are commonly zip files with
+// a cbr extension, so the extension is not used.
+func GetArchiveFormat(path string) (ArchiveFormat, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	header = header[:n]
+
+	for format, signatures := range archiveSignatures {
+		for _, s := range signatures {
+			if bytes.HasPrefix(header, s) {
+				return format, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%s is not a supported archive file", path)
+}
+
+// OpenArchive opens the archive file at the provided path. Zip files are
+// read directly. Rar and 7z files are read using the bsdtar tool of
+// libarchive, which must be installed.
+func OpenArchive(path string) (Archive, error) {
+	format, err := GetArchiveFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == ArchiveFormatZip {
+		return openZipArchive(path)
+	}
+
+	return openLibarchiveArchive(path)
+}
+
+type zipArchive struct {
+	r *zip.ReadCloser
+}
+
+func openZipArchive(path string) (*zipArchive, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zipArchive{r: r}, nil
+}
+
+func (a *zipArchive) Files() []ArchiveFile {
+	var ret []ArchiveFile
+	for _, f := range a.r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		ret = append(ret, ArchiveFile{
+			Name:    f.Name,
+			Size:    int64(f.UncompressedSize64),
+			ModTime: f.Modified,
+		})
+	}
+	return ret
+}
+
+func (a *zipArchive) Open(name string) (io.ReadCloser, error) {
+	for _, f := range a.r.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func (a *zipArchive) Close() error {
+	return a.r.Close()
+}
+
+// libarchiveTool is the executable used to read archives that are not zip
+// files.
+const libarchiveTool = "bsdtar"
+
+var (
+	libarchiveOnce  sync.Once
+	libarchiveFound bool
+)
+
+// HasLibarchive returns true if the bsdtar tool used to read rar and 7z
+// archives is installed.
+func HasLibarchive() bool {
+	libarchiveOnce.Do(func() {
+		_, err := exec.LookPath(libarchiveTool)
+		libarchiveFound = err == nil
+	})
+	return libarchiveFound
+}
+
+// maxCachedListings is the number of archive listings kept in memory, since
+// listing an archive with bsdtar requires reading all of its headers.
+const maxCachedListings = 16
+
+type archiveListing struct {
+	path    string
+	modTime time.Time
+	size    int64
+	files   []ArchiveFile
+}
+
+var (
+	archiveListings      []*archiveListing
+	archiveListingsMutex sync.Mutex
+)
+
+func getCachedListing(path string, fi os.FileInfo) []ArchiveFile {
+	archiveListingsMutex.Lock()
+	defer archiveListingsMutex.Unlock()
+
+	for _, l := range archiveListings {
+		if l.path == path && l.modTime.Equal(fi.ModTime()) && l.size == fi.Size() {
+			return l.files
+		}
+	}
+	return nil
+}
+
+func cacheListing(path string, fi os.FileInfo, files []ArchiveFile) {
+	archiveListingsMutex.Lock()
+	defer archiveListingsMutex.Unlock()
+
+	var ret []*archiveListing
+	for _, l := range archiveListings {
+		if l.path != path {
+			ret = append(ret, l)
+		}
+	}
+
+	ret = append(ret, &archiveListing{
+		path:    path,
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+		files:   files,
+	})
+
+	if len(ret) > maxCachedListings {
+		ret = ret[len(ret)-maxCachedListings:]
+	}
+	archiveListings = ret
+}
+
+type libarchiveArchive struct {
+	path  string
+	tool  string
+	files []ArchiveFile
+}
+
+func openLibarchiveArchive(path string) (*libarchiveArchive, error) {
+	tool, err := exec.LookPath(libarchiveTool)
+	if err != nil {
+		return nil, fmt.Errorf("%s is required to read %s: %s", libarchiveTool, path, err.Error())
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &libarchiveArchive{
+		path:  path,
+		tool:  tool,
+		files: getCachedListing(path, fi),
+	}
+
+	if ret.files == nil {
+		if ret.files, err = ret.list(); err != nil {
+			return nil, err
+		}
+		cacheListing(path, fi, ret.files)
+	}
+
+	return ret, nil
+}
+
+// list returns the files in the archive, from an mtree listing of its
+// entries.
+func (a *libarchiveArchive) list() ([]ArchiveFile, error) {
+	cmd := exec.Command(a.tool, "-cf", "-", "--format", "mtree", "--options", "!all,type,size,time", "@"+a.path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %s %s", a.path, err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	return parseMtree(out)
+}
+
+func parseMtree(data []byte) ([]ArchiveFile, error) {
+	// files are never listed, so directories with the same name as files
+	// do not need to be handled
+	ret := []ArchiveFile{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		name, err := mtreeUnescape(fields[0])
+		if err != nil {
+			return nil, err
+		}
+
+		f := ArchiveFile{
+			Name: strings.TrimPrefix(name, "./"),
+		}
+		isFile := false
+		for _, kw := range fields[1:] {
+			kv := strings.SplitN(kw, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			switch kv[0] {
+			case "type":
+				isFile = kv[1] == "file"
+			case "size":
+				f.Size, _ = strconv.ParseInt(kv[1], 10, 64)
+			case "time":
+				seconds := strings.SplitN(kv[1], ".", 2)[0]
+				t, _ := strconv.ParseInt(seconds, 10, 64)
+				f.ModTime = time.Unix(t, 0)
+			}
+		}
+
+		if isFile {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret, scanner.Err()
+}
+
+// mtreeUnescape decodes the octal escape sequences of mtree file names.
+func mtreeUnescape(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var ret strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			ret.WriteByte(s[i])
+			continue
+		}
+
+		if i+4 > len(s) {
+			return "", fmt.Errorf("invalid mtree name %q", s)
+		}
+		v, err := strconv.ParseUint(s[i+1:i+4], 8, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid mtree name %q", s)
+		}
+		ret.WriteByte(byte(v))
+		i += 3
+	}
+
+	return ret.String(), nil
+}
+
+func (a *libarchiveArchive) Files() []ArchiveFile {
+	return a.files
+}
+
+// escapePattern escapes the wildcard characters of the name, since bsdtar
+// extracts entries matching a pattern.
+func escapePattern(name string) string {
+	var ret strings.Builder
+	for _, c := range name {
+		switch c {
+		case '\\', '*', '?', '[':
+			ret.WriteRune('\\')
+		}
+		ret.WriteRune(c)
+	}
+	return ret.String()
+}
+
+func (a *libarchiveArchive) Open(name string) (io.ReadCloser, error) {
+	found := false
+	for _, f := range a.files {
+		if f.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, os.ErrNotExist
+	}
+
+	// -q stops reading the archive after the first match
+	cmd := exec.Command(a.tool, "-xqOf", a.path, "--", escapePattern(name))
+	ret := &commandReadCloser{cmd: cmd}
+	cmd.Stderr = &ret.stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	ret.stdout = stdout
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (a *libarchiveArchive) Close() error {
+	return nil
+}
+
+// commandReadCloser reads the output of a command. An error is returned at
+// the end of the output if the command fails.
+type commandReadCloser struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+	done   bool
+}
+
+func (c *commandReadCloser) wait() error {
+	if c.done {
+		return nil
+	}
+	c.done = true
+
+	if err := c.cmd.Wait(); err != nil {
+		return errors.New(strings.TrimSpace(c.stderr.String()) + ": " + err.Error())
+	}
+	return nil
+}
+
+func (c *commandReadCloser) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if err == io.EOF {
+		if waitErr := c.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (c *commandReadCloser) Close() error {
+	if c.done {
+		return nil
+	}
+
+	// the output may not have been read to the end
+	c.cmd.Process.Kill()
+	c.wait()
+	return nil
+}
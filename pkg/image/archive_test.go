@@ -0,0 +1,146 @@
+package image
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMtree(t *testing.T) {
+	const listing = `#mtree
+./a.jpg time=1577934245.0 type=file size=4
+./sub type=dir
+./sub/b\040c\134d.png time=1577934246.500000000 type=file size=10
+`
+
+	files, err := parseMtree([]byte(listing))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, []ArchiveFile{
+		{Name: "a.jpg", Size: 4, ModTime: time.Unix(1577934245, 0)},
+		{Name: `sub/b c\d.png`, Size: 10, ModTime: time.Unix(1577934246, 0)},
+	}, files)
+
+	_, err = parseMtree([]byte("./a\\04 type=file\n"))
+	assert.NotNil(t, err)
+}
+
+func TestEscapePattern(t *testing.T) {
+	assert.Equal(t, `sub/c\[1]\*\?.png`, escapePattern("sub/c[1]*?.png"))
+	assert.Equal(t, `d\\e.jpg`, escapePattern(`d\e.jpg`))
+}
+
+var testArchiveFiles = map[string]string{
+	"a.jpg":          "a",
+	"sub/b c[1].png": "bc",
+}
+
+func testArchive(t *testing.T, path string) {
+	a, err := OpenArchive(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer a.Close()
+
+	files := a.Files()
+	assert.Len(t, files, len(testArchiveFiles))
+	for _, f := range files {
+		want, found := testArchiveFiles[f.Name]
+		if !assert.True(t, found, f.Name) {
+			continue
+		}
+		assert.Equal(t, int64(len(want)), f.Size, f.Name)
+
+		r, err := a.Open(f.Name)
+		if !assert.Nil(t, err, f.Name) {
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		r.Close()
+		assert.Nil(t, err, f.Name)
+		assert.Equal(t, want, string(got), f.Name)
+	}
+
+	_, err = a.Open("missing.jpg")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func writeTestFiles(t *testing.T, dir string) {
+	for name, contents := range testArchiveFiles {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestZipArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.cbz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	if _, err := w.Create("sub/"); err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range testArchiveFiles {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(contents))
+	}
+	w.Close()
+	f.Close()
+
+	format, err := GetArchiveFormat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, ArchiveFormatZip, format)
+
+	testArchive(t, path)
+}
+
+func TestLibarchiveArchive(t *testing.T) {
+	if _, err := exec.LookPath(libarchiveTool); err != nil {
+		t.Skipf("%s not found", libarchiveTool)
+	}
+
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srcDir := filepath.Join(dir, "src")
+	writeTestFiles(t, srcDir)
+
+	path := filepath.Join(dir, "test.cb7")
+	cmd := exec.Command(libarchiveTool, "--format", "7zip", "-cf", path, "-C", srcDir, ".")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s: %s", err.Error(), out)
+	}
+
+	format, err := GetArchiveFormat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, ArchiveFormat7z, format)
+
+	testArchive(t, path)
+}
@@ -0,0 +1,260 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"image"
+	"io"
+	"io/ioutil"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// Format describes how an image file is encoded.
+type Format struct {
+	// Name is the name of the format, as registered with the image package,
+	// such as "jpeg" or "gif".
+	Name     string
+	Animated bool
+	// Width and Height are the dimensions of the image, if they are read
+	// from the file header. They are zero otherwise.
+	Width  int
+	Height int
+}
+
+// ReadFileFormat returns the format of the image file at the provided path,
+// which may be in a zip file.
+func ReadFileFormat(path string) (*Format, error) {
+	f, err := openSourceImage(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadFormat(f)
+}
+
+// ReadFormat returns the format of the image, and whether it is an animated
+// GIF, PNG or WebP image. The name of the returned format is empty if the
+// format is not recognised.
+func ReadFormat(r io.Reader) (*Format, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(12)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	ret := &Format{}
+	err = nil
+	switch {
+	case bytes.HasPrefix(header, []byte("GIF8")):
+		ret.Name = "gif"
+		ret.Animated, err = gifAnimated(br)
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		ret.Name = "png"
+		ret.Animated, err = pngAnimated(br)
+	case len(header) == 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		// animated WebP images cannot be decoded, so the dimensions are
+		// read from the header
+		ret.Name = "webp"
+		err = readWebPFormat(br, ret)
+	default:
+		_, ret.Name, _ = image.DecodeConfig(br)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// gifAnimated returns true if the GIF image has more than one frame.
+func gifAnimated(r *bufio.Reader) (bool, error) {
+	// header and logical screen descriptor
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return false, ignoreEOF(err)
+	}
+
+	if header[10]&0x80 != 0 {
+		// global color table
+		if err := skip(r, 3<<(uint(header[10]&0x07)+1)); err != nil {
+			return false, err
+		}
+	}
+
+	frames := 0
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return false, ignoreEOF(err)
+		}
+
+		switch b {
+		case 0x21:
+			// extension: label followed by data sub-blocks
+			if _, err := r.ReadByte(); err != nil {
+				return false, ignoreEOF(err)
+			}
+			if err := skipGIFSubBlocks(r); err != nil {
+				return false, err
+			}
+		case 0x2c:
+			frames++
+			if frames > 1 {
+				return true, nil
+			}
+
+			descriptor := make([]byte, 9)
+			if _, err := io.ReadFull(r, descriptor); err != nil {
+				return false, ignoreEOF(err)
+			}
+			if descriptor[8]&0x80 != 0 {
+				// local color table
+				if err := skip(r, 3<<(uint(descriptor[8]&0x07)+1)); err != nil {
+					return false, err
+				}
+			}
+
+			// LZW minimum code size followed by image data sub-blocks
+			if _, err := r.ReadByte(); err != nil {
+				return false, ignoreEOF(err)
+			}
+			if err := skipGIFSubBlocks(r); err != nil {
+				return false, err
+			}
+		default:
+			// trailer or invalid data
+			return false, nil
+		}
+	}
+}
+
+func skipGIFSubBlocks(r *bufio.Reader) error {
+	for {
+		size, err := r.ReadByte()
+		if err != nil {
+			return ignoreEOF(err)
+		}
+		if size == 0 {
+			return nil
+		}
+		if err := skip(r, int64(size)); err != nil {
+			return err
+		}
+	}
+}
+
+// pngAnimated returns true if the PNG image is an APNG image with more than
+// one frame.
+func pngAnimated(r *bufio.Reader) (bool, error) {
+	// skip the signature
+	if err := skip(r, 8); err != nil {
+		return false, err
+	}
+
+	for {
+		var header struct {
+			Length uint32
+			Type   [4]byte
+		}
+		if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+			return false, ignoreEOF(err)
+		}
+
+		chunkType := string(header.Type[:])
+		switch chunkType {
+		case "IDAT", "IEND":
+			// the animation control chunk precedes the image data
+			return false, nil
+		case "acTL":
+			var frames uint32
+			if err := binary.Read(r, binary.BigEndian, &frames); err != nil {
+				return false, ignoreEOF(err)
+			}
+			return frames > 1, nil
+		}
+
+		// the chunk data is followed by the CRC
+		if err := skip(r, int64(header.Length)+4); err != nil {
+			return false, err
+		}
+	}
+}
+
+// readWebPFormat sets the animated flag and the dimensions of extended WebP
+// images.
+func readWebPFormat(r *bufio.Reader, f *Format) error {
+	header := make([]byte, 12+8+10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return ignoreEOF(err)
+	}
+
+	chunk := header[12:]
+	if !bytes.Equal(chunk[0:4], []byte("VP8X")) {
+		// simple WebP images cannot be animated
+		return nil
+	}
+
+	const animationBit = 1 << 1
+	data := chunk[8:]
+	f.Animated = data[0]&animationBit != 0
+	f.Width = int(uint32(data[4])|uint32(data[5])<<8|uint32(data[6])<<16) + 1
+	f.Height = int(uint32(data[7])|uint32(data[8])<<8|uint32(data[9])<<16) + 1
+	return nil
+}
+
+func skip(r io.Reader, n int64) error {
+	_, err := io.CopyN(ioutil.Discard, r, n)
+	return ignoreEOF(err)
+}
+
+// SetFormat sets the format and animated flag of the image from its file.
+// The width and height are set if the image could not be decoded to read
+// them.
+func SetFormat(i *models.Image, f *Format) {
+	i.Format = sql.NullString{
+		String: f.Name,
+		Valid:  true,
+	}
+	i.IsAnimated = f.Animated
+	i.Duration = sql.NullFloat64{}
+
+	if !i.Width.Valid && f.Width > 0 {
+		i.Width = sql.NullInt64{Int64: int64(f.Width), Valid: true}
+		i.Height = sql.NullInt64{Int64: int64(f.Height), Valid: true}
+	}
+}
+
+// SetClipDetails sets the format, duration and dimensions of a video clip
+// from its probe result. Clips are always animated.
+func SetClipDetails(i *models.Image, videoFile *ffmpeg.VideoFile) {
+	container := ffmpeg.MatchContainer(videoFile.Container, videoFile.Path)
+
+	i.Format = sql.NullString{
+		String: string(container),
+		Valid:  true,
+	}
+	i.IsAnimated = true
+	i.Duration = sql.NullFloat64{
+		Float64: videoFile.Duration,
+		Valid:   true,
+	}
+	i.Width = sql.NullInt64{
+		Int64: int64(videoFile.Width),
+		Valid: true,
+	}
+	i.Height = sql.NullInt64{
+		Int64: int64(videoFile.Height),
+		Valid: true,
+	}
+}
+
+// IsClip returns true if the image is a video clip.
+func IsClip(i *models.Image) bool {
+	return i.Duration.Valid
+}
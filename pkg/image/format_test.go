@@ -0,0 +1,89 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testGIF(t *testing.T, frames int) []byte {
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 4, 4), palette))
+		g.Delay = append(g.Delay, 10)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testAPNG(t *testing.T, frames uint32) []byte {
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	encoded := pngBuf.Bytes()
+
+	// the animation control chunk follows the IHDR chunk
+	const ihdrEnd = 8 + 8 + 13 + 4
+	acTL := make([]byte, 8)
+	binary.BigEndian.PutUint32(acTL, frames)
+
+	var buf []byte
+	buf = append(buf, encoded[:ihdrEnd]...)
+	buf = append(buf, pngChunk("acTL", acTL)...)
+	return append(buf, encoded[ihdrEnd:]...)
+}
+
+func testWebP(flags byte, width, height int) []byte {
+	vp8x := []byte{flags, 0, 0, 0}
+	w := width - 1
+	h := height - 1
+	vp8x = append(vp8x, byte(w), byte(w>>8), byte(w>>16), byte(h), byte(h>>8), byte(h>>16))
+
+	chunk := append([]byte("VP8X\x0a\x00\x00\x00"), vp8x...)
+	ret := []byte("RIFF\x00\x00\x00\x00WEBP")
+	binary.LittleEndian.PutUint32(ret[4:], uint32(4+len(chunk)))
+	return append(ret, chunk...)
+}
+
+func TestReadFormat(t *testing.T) {
+	var jpegBuf bytes.Buffer
+	if err := jpeg.Encode(&jpegBuf, image.NewGray(image.Rect(0, 0, 2, 2)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want Format
+	}{
+		{"jpeg", jpegBuf.Bytes(), Format{Name: "jpeg"}},
+		{"static gif", testGIF(t, 1), Format{Name: "gif"}},
+		{"animated gif", testGIF(t, 3), Format{Name: "gif", Animated: true}},
+		{"static apng", testAPNG(t, 1), Format{Name: "png"}},
+		{"animated apng", testAPNG(t, 2), Format{Name: "png", Animated: true}},
+		{"extended webp", testWebP(0x10, 300, 200), Format{Name: "webp", Width: 300, Height: 200}},
+		{"animated webp", testWebP(0x12, 640, 480), Format{Name: "webp", Animated: true, Width: 640, Height: 480}},
+		{"unknown", []byte("not an image"), Format{}},
+	}
+
+	for _, tc := range tests {
+		got, err := ReadFormat(bytes.NewReader(tc.data))
+		if !assert.Nil(t, err, tc.name) {
+			continue
+		}
+		assert.Equal(t, tc.want, *got, tc.name)
+	}
+}
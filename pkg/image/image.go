@@ -1,7 +1,6 @@
 package image
 
 import (
-	"archive/zip"
 	"database/sql"
 	"fmt"
 	"image"
@@ -68,7 +67,7 @@ func IsZipPath(p string) bool {
 
 type imageReadCloser struct {
 	src io.ReadCloser
-	zrc *zip.ReadCloser
+	a   Archive
 }
 
 func (i *imageReadCloser) Read(p []byte) (n int, err error) {
@@ -78,8 +77,8 @@ func (i *imageReadCloser) Read(p []byte) (n int, err error) {
 func (i *imageReadCloser) Close() error {
 	err := i.src.Close()
 	var err2 error
-	if i.zrc != nil {
-		err2 = i.zrc.Close()
+	if i.a != nil {
+		err2 = i.a.Close()
 	}
 
 	if err != nil {
@@ -89,34 +88,29 @@ func (i *imageReadCloser) Close() error {
 }
 
 func openSourceImage(path string) (io.ReadCloser, error) {
-	// may need to read from a zip file
+	// may need to read from an archive file
 	zipFilename, filename := getFilePath(path)
 	if zipFilename != "" {
-		r, err := zip.OpenReader(zipFilename)
+		a, err := OpenArchive(zipFilename)
 		if err != nil {
 			return nil, err
 		}
 
-		// defer closing of zip to the calling function, unless an error
-		// is returned, in which case it should be closed immediately
-
-		// find the file matching the filename
-		for _, f := range r.File {
-			if f.Name == filename {
-				src, err := f.Open()
-				if err != nil {
-					r.Close()
-					return nil, err
-				}
-				return &imageReadCloser{
-					src: src,
-					zrc: r,
-				}, nil
+		// defer closing of the archive to the calling function, unless an
+		// error is returned, in which case it should be closed immediately
+		src, err := a.Open(filename)
+		if err != nil {
+			a.Close()
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("file with name '%s' not found in archive file '%s'", filename, zipFilename)
 			}
+			return nil, err
 		}
 
-		r.Close()
-		return nil, fmt.Errorf("file with name '%s' not found in zip file '%s'", filename, zipFilename)
+		return &imageReadCloser{
+			src: src,
+			a:   a,
+		}, nil
 	}
 
 	return os.Open(filename)
@@ -175,6 +169,28 @@ func SetFileDetails(i *models.Image) error {
 	return nil
 }
 
+// SetMetadata sets the date, orientation and camera of the image from the
+// provided metadata. The width and height must already be set, and are
+// swapped if the orientation rotates the image.
+func SetMetadata(i *models.Image, metadata *Metadata) {
+	i.Date = models.SQLiteDate{
+		String: metadata.Date,
+		Valid:  metadata.Date != "",
+	}
+	i.Orientation = sql.NullInt64{
+		Int64: int64(metadata.Orientation),
+		Valid: true,
+	}
+	i.Camera = sql.NullString{
+		String: metadata.Camera,
+		Valid:  metadata.Camera != "",
+	}
+
+	if OrientationTransposed(metadata.Orientation) {
+		i.Width, i.Height = i.Height, i.Width
+	}
+}
+
 // GetFileModTime gets the file modification time, handling files in zip files.
 func GetFileModTime(path string) (time.Time, error) {
 	fi, err := stat(path)
@@ -190,23 +206,23 @@ func GetFileModTime(path string) (time.Time, error) {
 }
 
 func stat(path string) (os.FileInfo, error) {
-	// may need to read from a zip file
+	// may need to read from an archive file
 	zipFilename, filename := getFilePath(path)
 	if zipFilename != "" {
-		r, err := zip.OpenReader(zipFilename)
+		a, err := OpenArchive(zipFilename)
 		if err != nil {
 			return nil, err
 		}
-		defer r.Close()
+		defer a.Close()
 
 		// find the file matching the filename
-		for _, f := range r.File {
+		for _, f := range a.Files() {
 			if f.Name == filename {
-				return f.FileInfo(), nil
+				return archiveFileInfo{f}, nil
 			}
 		}
 
-		return nil, fmt.Errorf("file with name '%s' not found in zip file '%s'", filename, zipFilename)
+		return nil, fmt.Errorf("file with name '%s' not found in archive file '%s'", filename, zipFilename)
 	}
 
 	return os.Stat(filename)
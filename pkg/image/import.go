@@ -257,6 +257,12 @@ func (i *Importer) PostImport(id int) error {
 		}
 	}
 
+	if len(i.Input.CustomFields) > 0 {
+		if err := i.ReaderWriter.UpdateCustomFields(id, i.Input.CustomFields); err != nil {
+			return fmt.Errorf("error setting image custom fields: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
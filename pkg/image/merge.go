@@ -0,0 +1,190 @@
+package image
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// Merge merges the metadata of the source images into the destination image
+// and returns the updated destination. Empty fields of the destination are
+// set from the first source with a value, o-counters are summed, and
+// galleries, performers, tags and keywords are combined. Custom fields are
+// combined in the same way, with those of the destination taking
+// precedence. The checksums of the source files are recorded against the
+// destination, so that the source files are not added again by the scan.
+// The source images are not destroyed.
+func Merge(qb models.ImageReaderWriter, destination *models.Image, sources []*models.Image) (*models.Image, error) {
+	updated := *destination
+
+	galleryIDs, err := qb.GetGalleryIDs(destination.ID)
+	if err != nil {
+		return nil, err
+	}
+	performerIDs, err := qb.GetPerformerIDs(destination.ID)
+	if err != nil {
+		return nil, err
+	}
+	tagIDs, err := qb.GetTagIDs(destination.ID)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := qb.GetKeywords(destination.ID)
+	if err != nil {
+		return nil, err
+	}
+	mergedChecksums, err := qb.GetMergedChecksums(destination.ID)
+	if err != nil {
+		return nil, err
+	}
+	customFields := make(models.CustomFieldMap)
+
+	for _, source := range sources {
+		if !updated.Title.Valid || updated.Title.String == "" {
+			updated.Title = source.Title
+		}
+		if !updated.Rating.Valid {
+			updated.Rating = source.Rating
+		}
+		if !updated.StudioID.Valid {
+			updated.StudioID = source.StudioID
+		}
+		if !updated.Date.Valid {
+			updated.Date = source.Date
+		}
+		updated.Organized = updated.Organized || source.Organized
+		updated.OCounter += source.OCounter
+
+		ids, err := qb.GetGalleryIDs(source.ID)
+		if err != nil {
+			return nil, err
+		}
+		galleryIDs = utils.IntAppendUniques(galleryIDs, ids)
+
+		ids, err = qb.GetPerformerIDs(source.ID)
+		if err != nil {
+			return nil, err
+		}
+		performerIDs = utils.IntAppendUniques(performerIDs, ids)
+
+		ids, err = qb.GetTagIDs(source.ID)
+		if err != nil {
+			return nil, err
+		}
+		tagIDs = utils.IntAppendUniques(tagIDs, ids)
+
+		sourceKeywords, err := qb.GetKeywords(source.ID)
+		if err != nil {
+			return nil, err
+		}
+		keywords = appendUniqueStrings(keywords, sourceKeywords)
+
+		sourceChecksums, err := qb.GetMergedChecksums(source.ID)
+		if err != nil {
+			return nil, err
+		}
+		mergedChecksums = appendUniqueStrings(mergedChecksums, append([]string{source.Checksum}, sourceChecksums...))
+
+		sourceFields, err := qb.GetCustomFields(source.ID)
+		if err != nil {
+			return nil, err
+		}
+		for field, value := range sourceFields {
+			if _, found := customFields[field]; !found {
+				customFields[field] = value
+			}
+		}
+	}
+
+	destinationFields, err := qb.GetCustomFields(destination.ID)
+	if err != nil {
+		return nil, err
+	}
+	customFields = customFields.Merge(destinationFields)
+
+	ret, err := qb.UpdateFull(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := qb.UpdateGalleries(destination.ID, galleryIDs); err != nil {
+		return nil, err
+	}
+	if err := qb.UpdatePerformers(destination.ID, performerIDs); err != nil {
+		return nil, err
+	}
+	if err := qb.UpdateTags(destination.ID, tagIDs); err != nil {
+		return nil, err
+	}
+	if err := qb.UpdateKeywords(destination.ID, keywords); err != nil {
+		return nil, err
+	}
+	if err := qb.UpdateCustomFields(destination.ID, customFields); err != nil {
+		return nil, err
+	}
+	if err := qb.UpdateMergedChecksums(destination.ID, mergedChecksums); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// MergeGalleries moves the gallery state of the source images to the
+// destination image, which must already be in the galleries of the sources.
+// Galleries with a source image as their cover use the destination as their
+// cover, and the destination takes the position of the source images in the
+// gallery order, keeping the first position if it has several.
+func MergeGalleries(qb models.GalleryReaderWriter, destinationID int, sources []*models.Image) error {
+	for _, source := range sources {
+		galleries, err := qb.FindByImageID(source.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, g := range galleries {
+			if g.CoverImageID.Valid && g.CoverImageID.Int64 == int64(source.ID) {
+				if _, err := qb.UpdatePartial(models.GalleryPartial{
+					ID:           g.ID,
+					CoverImageID: &sql.NullInt64{Int64: int64(destinationID), Valid: true},
+					UpdatedAt:    &models.SQLiteTimestamp{Timestamp: time.Now()},
+				}); err != nil {
+					return err
+				}
+			}
+
+			order, err := qb.GetImageOrder(g.ID)
+			if err != nil {
+				return err
+			}
+
+			if !utils.IntInclude(order, source.ID) {
+				continue
+			}
+
+			var newOrder []int
+			for _, id := range order {
+				if id == source.ID {
+					id = destinationID
+				}
+				newOrder = utils.IntAppendUnique(newOrder, id)
+			}
+
+			if err := qb.UpdateImageOrder(g.ID, newOrder); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func appendUniqueStrings(vs []string, toAdd []string) []string {
+	for _, v := range toAdd {
+		if !utils.StrInclude(vs, v) {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
@@ -0,0 +1,157 @@
+package image
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	mergeDestinationID = 1
+	mergeSource1ID     = 2
+	mergeSource2ID     = 3
+)
+
+func TestMerge(t *testing.T) {
+	mockImageReader := &mocks.ImageReaderWriter{}
+
+	destination := &models.Image{
+		ID:       mergeDestinationID,
+		Title:    sql.NullString{String: "destination", Valid: true},
+		OCounter: 1,
+	}
+	sources := []*models.Image{
+		{
+			ID:       mergeSource1ID,
+			Checksum: "source1",
+			Title:    sql.NullString{String: "source", Valid: true},
+			Rating:   sql.NullInt64{Int64: 4, Valid: true},
+			OCounter: 2,
+		},
+		{
+			ID:        mergeSource2ID,
+			Checksum:  "source2",
+			Rating:    sql.NullInt64{Int64: 2, Valid: true},
+			StudioID:  sql.NullInt64{Int64: existingStudioID, Valid: true},
+			Organized: true,
+			OCounter:  3,
+		},
+	}
+
+	mockImageReader.On("GetGalleryIDs", mergeDestinationID).Return([]int{1}, nil).Once()
+	mockImageReader.On("GetGalleryIDs", mergeSource1ID).Return([]int{1, 2}, nil).Once()
+	mockImageReader.On("GetGalleryIDs", mergeSource2ID).Return([]int{3}, nil).Once()
+	mockImageReader.On("GetPerformerIDs", mergeDestinationID).Return(nil, nil).Once()
+	mockImageReader.On("GetPerformerIDs", mergeSource1ID).Return([]int{4}, nil).Once()
+	mockImageReader.On("GetPerformerIDs", mergeSource2ID).Return(nil, nil).Once()
+	mockImageReader.On("GetTagIDs", mergeDestinationID).Return([]int{5}, nil).Once()
+	mockImageReader.On("GetTagIDs", mergeSource1ID).Return(nil, nil).Once()
+	mockImageReader.On("GetTagIDs", mergeSource2ID).Return([]int{5, 6}, nil).Once()
+	mockImageReader.On("GetKeywords", mergeDestinationID).Return([]string{"beach"}, nil).Once()
+	mockImageReader.On("GetKeywords", mergeSource1ID).Return([]string{"beach", "sunset"}, nil).Once()
+	mockImageReader.On("GetKeywords", mergeSource2ID).Return(nil, nil).Once()
+	mockImageReader.On("GetMergedChecksums", mergeDestinationID).Return([]string{"merged"}, nil).Once()
+	mockImageReader.On("GetMergedChecksums", mergeSource1ID).Return(nil, nil).Once()
+	mockImageReader.On("GetMergedChecksums", mergeSource2ID).Return([]string{"merged", "source2merged"}, nil).Once()
+	mockImageReader.On("GetCustomFields", mergeDestinationID).Return(models.CustomFieldMap{"a": "destination"}, nil).Once()
+	mockImageReader.On("GetCustomFields", mergeSource1ID).Return(models.CustomFieldMap{"a": "source", "b": "source1"}, nil).Once()
+	mockImageReader.On("GetCustomFields", mergeSource2ID).Return(models.CustomFieldMap{"b": "source2"}, nil).Once()
+
+	updated := models.Image{
+		ID:        mergeDestinationID,
+		Title:     sql.NullString{String: "destination", Valid: true},
+		Rating:    sql.NullInt64{Int64: 4, Valid: true},
+		StudioID:  sql.NullInt64{Int64: existingStudioID, Valid: true},
+		Organized: true,
+		OCounter:  6,
+	}
+	mockImageReader.On("UpdateFull", updated).Return(&updated, nil).Once()
+	mockImageReader.On("UpdateGalleries", mergeDestinationID, []int{1, 2, 3}).Return(nil).Once()
+	mockImageReader.On("UpdatePerformers", mergeDestinationID, []int{4}).Return(nil).Once()
+	mockImageReader.On("UpdateTags", mergeDestinationID, []int{5, 6}).Return(nil).Once()
+	mockImageReader.On("UpdateKeywords", mergeDestinationID, []string{"beach", "sunset"}).Return(nil).Once()
+	mockImageReader.On("UpdateCustomFields", mergeDestinationID, models.CustomFieldMap{
+		"a": "destination",
+		"b": "source1",
+	}).Return(nil).Once()
+	mockImageReader.On("UpdateMergedChecksums", mergeDestinationID, []string{"merged", "source1", "source2", "source2merged"}).Return(nil).Once()
+
+	ret, err := Merge(mockImageReader, destination, sources)
+	assert.Nil(t, err)
+	assert.Equal(t, &updated, ret)
+
+	// the destination is not modified
+	assert.Equal(t, 1, destination.OCounter)
+
+	mockImageReader.AssertExpectations(t)
+}
+
+func TestMergeUpdateError(t *testing.T) {
+	mockImageReader := &mocks.ImageReaderWriter{}
+
+	destination := &models.Image{
+		ID: mergeDestinationID,
+	}
+
+	mockImageReader.On("GetGalleryIDs", mock.Anything).Return(nil, nil)
+	mockImageReader.On("GetPerformerIDs", mock.Anything).Return(nil, nil)
+	mockImageReader.On("GetTagIDs", mock.Anything).Return(nil, nil)
+	mockImageReader.On("GetKeywords", mock.Anything).Return(nil, nil)
+	mockImageReader.On("GetMergedChecksums", mock.Anything).Return(nil, nil)
+	mockImageReader.On("GetCustomFields", mock.Anything).Return(nil, nil)
+	mockImageReader.On("UpdateFull", mock.Anything).Return(nil, errors.New("UpdateFull error")).Once()
+
+	_, err := Merge(mockImageReader, destination, []*models.Image{
+		{ID: mergeSource1ID},
+	})
+	assert.NotNil(t, err)
+
+	mockImageReader.AssertExpectations(t)
+}
+
+func TestMergeGalleries(t *testing.T) {
+	mockGalleryReader := &mocks.GalleryReaderWriter{}
+
+	const (
+		coverGalleryID   = 1
+		orderedGalleryID = 2
+	)
+
+	sources := []*models.Image{
+		{ID: mergeSource1ID},
+		{ID: mergeSource2ID},
+	}
+
+	mockGalleryReader.On("FindByImageID", mergeSource1ID).Return([]*models.Gallery{
+		{
+			ID:           coverGalleryID,
+			CoverImageID: sql.NullInt64{Int64: mergeSource1ID, Valid: true},
+		},
+		{ID: orderedGalleryID},
+	}, nil).Once()
+	mockGalleryReader.On("FindByImageID", mergeSource2ID).Return([]*models.Gallery{
+		{ID: orderedGalleryID},
+	}, nil).Once()
+
+	mockGalleryReader.On("UpdatePartial", mock.MatchedBy(func(partial models.GalleryPartial) bool {
+		return partial.ID == coverGalleryID && *partial.CoverImageID == sql.NullInt64{Int64: mergeDestinationID, Valid: true}
+	})).Return(nil, nil).Once()
+
+	mockGalleryReader.On("GetImageOrder", coverGalleryID).Return(nil, nil).Once()
+
+	// the destination takes the position of the first source, and keeps its
+	// first position when the second source is merged
+	mockGalleryReader.On("GetImageOrder", orderedGalleryID).Return([]int{4, mergeSource1ID, mergeSource2ID}, nil).Once()
+	mockGalleryReader.On("UpdateImageOrder", orderedGalleryID, []int{4, mergeDestinationID, mergeSource2ID}).Return(nil).Once()
+	mockGalleryReader.On("GetImageOrder", orderedGalleryID).Return([]int{4, mergeDestinationID, mergeSource2ID}, nil).Once()
+	mockGalleryReader.On("UpdateImageOrder", orderedGalleryID, []int{4, mergeDestinationID}).Return(nil).Once()
+
+	assert.Nil(t, MergeGalleries(mockGalleryReader, mergeDestinationID, sources))
+
+	mockGalleryReader.AssertExpectations(t)
+}
@@ -0,0 +1,655 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/disintegration/imaging"
+)
+
+// Metadata is the EXIF and XMP metadata embedded in an image file.
+type Metadata struct {
+	// Date is the date the image was taken, in YYYY-MM-DD format.
+	Date string
+	// Orientation is the EXIF orientation of the image, from 1 to 8.
+	Orientation int
+	Camera      string
+	Keywords    []string
+}
+
+// OrientationNormal is the orientation of images that are stored the way
+// up they are displayed.
+const OrientationNormal = 1
+
+// OrientationTransposed returns true if the orientation rotates the image by
+// 90 degrees, such that the displayed width and height are swapped.
+func OrientationTransposed(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// Orient returns the image transformed to display the right way up for the
+// provided EXIF orientation.
+func Orient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	}
+	return img
+}
+
+// maxMetadataSize is the maximum size of a metadata chunk that is read.
+const maxMetadataSize = 16 << 20
+
+const (
+	exifPrefix = "Exif\x00\x00"
+	xmpPrefix  = "http://ns.adobe.com/xap/1.0/\x00"
+)
+
+// ReadFileMetadata returns the metadata of the image file at the provided
+// path, which may be in a zip file.
+func ReadFileMetadata(path string) (*Metadata, error) {
+	f, err := openSourceImage(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadMetadata(f)
+}
+
+// ReadMetadata reads the EXIF and XMP metadata of a JPEG, PNG or WebP image.
+// The orientation of the returned metadata is normal and all other fields
+// are empty if the image has no metadata or is in another format.
+func ReadMetadata(r io.Reader) (*Metadata, error) {
+	br := bufio.NewReader(r)
+	// files shorter than the header cannot have metadata
+	header, err := br.Peek(12)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	m := &metadataReader{}
+	err = nil
+	switch {
+	case bytes.HasPrefix(header, []byte("\xff\xd8")):
+		err = m.readJPEG(br)
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		err = m.readPNG(br)
+	case len(header) == 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		err = m.readWebP(br)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m.metadata(), nil
+}
+
+// metadataReader collects the values read from the EXIF and XMP data. EXIF
+// values take precedence over XMP values.
+type metadataReader struct {
+	exifDate        string
+	exifOrientation int
+	exifMake        string
+	exifModel       string
+	exifKeywords    []string
+
+	xmpDate        string
+	xmpOrientation int
+	xmpMake        string
+	xmpModel       string
+	xmpKeywords    []string
+}
+
+func (m *metadataReader) metadata() *Metadata {
+	ret := &Metadata{
+		Date:        firstNonEmpty(m.exifDate, m.xmpDate),
+		Orientation: OrientationNormal,
+		Camera:      cameraName(firstNonEmpty(m.exifMake, m.xmpMake), firstNonEmpty(m.exifModel, m.xmpModel)),
+	}
+
+	for _, o := range []int{m.exifOrientation, m.xmpOrientation} {
+		if o >= 1 && o <= 8 {
+			ret.Orientation = o
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, k := range append(m.xmpKeywords, m.exifKeywords...) {
+		k = strings.TrimSpace(k)
+		if k == "" || seen[strings.ToLower(k)] {
+			continue
+		}
+		seen[strings.ToLower(k)] = true
+		ret.Keywords = append(ret.Keywords, k)
+	}
+
+	return ret
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// cameraName returns the camera name from the make and model. The make is
+// omitted if the model already includes it, such as "Canon" and
+// "Canon EOS 5D".
+func cameraName(make, model string) string {
+	if make == "" || model == "" {
+		return make + model
+	}
+
+	makeWords := strings.Fields(make)
+	if len(makeWords) > 0 && strings.HasPrefix(strings.ToLower(model), strings.ToLower(makeWords[0])) {
+		return model
+	}
+
+	return make + " " + model
+}
+
+func (m *metadataReader) readJPEG(r *bufio.Reader) error {
+	// skip the start of image marker
+	if _, err := r.Discard(2); err != nil {
+		return err
+	}
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return ignoreEOF(err)
+		}
+		if b != 0xff {
+			return errors.New("invalid JPEG marker")
+		}
+
+		marker, err := r.ReadByte()
+		for err == nil && marker == 0xff {
+			// fill bytes
+			marker, err = r.ReadByte()
+		}
+		if err != nil {
+			return ignoreEOF(err)
+		}
+
+		switch {
+		case marker == 0xd9 || marker == 0xda:
+			// metadata precedes the image data
+			return nil
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			// markers without a length
+			continue
+		}
+
+		var length uint16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return ignoreEOF(err)
+		}
+		if length < 2 {
+			return errors.New("invalid JPEG segment length")
+		}
+		size := int64(length) - 2
+
+		// metadata is stored in APP1 segments
+		if marker != 0xe1 {
+			if _, err := io.CopyN(ioutil.Discard, r, size); err != nil {
+				return ignoreEOF(err)
+			}
+			continue
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return ignoreEOF(err)
+		}
+
+		switch {
+		case bytes.HasPrefix(data, []byte(exifPrefix)):
+			m.readExif(data[len(exifPrefix):])
+		case bytes.HasPrefix(data, []byte(xmpPrefix)):
+			m.readXMP(data[len(xmpPrefix):])
+		}
+	}
+}
+
+func (m *metadataReader) readPNG(r *bufio.Reader) error {
+	// skip the signature
+	if _, err := r.Discard(8); err != nil {
+		return err
+	}
+
+	for {
+		var header struct {
+			Length uint32
+			Type   [4]byte
+		}
+		if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+			return ignoreEOF(err)
+		}
+
+		chunkType := string(header.Type[:])
+		if chunkType == "IDAT" || chunkType == "IEND" {
+			// metadata precedes the image data
+			return nil
+		}
+
+		// the chunk data is followed by the CRC
+		size := int64(header.Length) + 4
+		if (chunkType != "eXIf" && chunkType != "iTXt") || header.Length > maxMetadataSize {
+			if _, err := io.CopyN(ioutil.Discard, r, size); err != nil {
+				return ignoreEOF(err)
+			}
+			continue
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return ignoreEOF(err)
+		}
+		data = data[:header.Length]
+
+		if chunkType == "eXIf" {
+			m.readExif(data)
+		} else if xmp := pngXMP(data); xmp != nil {
+			m.readXMP(xmp)
+		}
+	}
+}
+
+// pngXMP returns the XMP data of an iTXt chunk, or nil if the chunk does not
+// contain XMP data.
+func pngXMP(data []byte) []byte {
+	// keyword, compression flag, compression method, language tag,
+	// translated keyword, text
+	const keyword = "XML:com.adobe.xmp\x00"
+	if !bytes.HasPrefix(data, []byte(keyword)) || len(data) < len(keyword)+2 {
+		return nil
+	}
+
+	compressed := data[len(keyword)] == 1
+	rest := data[len(keyword)+2:]
+	for i := 0; i < 2; i++ {
+		end := bytes.IndexByte(rest, 0)
+		if end == -1 {
+			return nil
+		}
+		rest = rest[end+1:]
+	}
+
+	if !compressed {
+		return rest
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(rest))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+
+	ret, err := ioutil.ReadAll(io.LimitReader(zr, maxMetadataSize))
+	if err != nil {
+		return nil
+	}
+	return ret
+}
+
+func (m *metadataReader) readWebP(r *bufio.Reader) error {
+	// skip the RIFF header
+	if _, err := r.Discard(12); err != nil {
+		return err
+	}
+
+	for {
+		var header struct {
+			Type   [4]byte
+			Length uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+			return ignoreEOF(err)
+		}
+
+		// chunks are padded to an even size
+		size := int64(header.Length) + int64(header.Length&1)
+		chunkType := string(header.Type[:])
+		if (chunkType != "EXIF" && chunkType != "XMP ") || header.Length > maxMetadataSize {
+			if _, err := io.CopyN(ioutil.Discard, r, size); err != nil {
+				return ignoreEOF(err)
+			}
+			continue
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return ignoreEOF(err)
+		}
+		data = data[:header.Length]
+
+		if chunkType == "EXIF" {
+			// some encoders include the JPEG EXIF prefix
+			m.readExif(bytes.TrimPrefix(data, []byte(exifPrefix)))
+		} else {
+			m.readXMP(data)
+		}
+	}
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	return err
+}
+
+// EXIF tags
+const (
+	tagMake              = 0x010f
+	tagModel             = 0x0110
+	tagOrientation       = 0x0112
+	tagDateTime          = 0x0132
+	tagExifIFD           = 0x8769
+	tagDateTimeOriginal  = 0x9003
+	tagDateTimeDigitized = 0x9004
+	tagXPKeywords        = 0x9c9e
+)
+
+// EXIF value types
+const (
+	typeByte      = 1
+	typeASCII     = 2
+	typeShort     = 3
+	typeLong      = 4
+	typeRational  = 5
+	typeUndefined = 7
+	typeSLong     = 9
+	typeSRational = 10
+)
+
+var exifTypeSizes = map[uint16]uint64{
+	typeByte:      1,
+	typeASCII:     1,
+	typeShort:     2,
+	typeLong:      4,
+	typeRational:  8,
+	typeUndefined: 1,
+	typeSLong:     4,
+	typeSRational: 8,
+}
+
+type exifValue struct {
+	valueType uint16
+	data      []byte
+}
+
+type exifReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// readExif reads the values of the TIFF structured EXIF data. Invalid data
+// is ignored.
+func (m *metadataReader) readExif(data []byte) {
+	if len(data) < 8 {
+		return
+	}
+
+	e := exifReader{data: data}
+	switch string(data[0:2]) {
+	case "II":
+		e.order = binary.LittleEndian
+	case "MM":
+		e.order = binary.BigEndian
+	default:
+		return
+	}
+
+	if e.order.Uint16(data[2:4]) != 42 {
+		return
+	}
+
+	ifd0, err := e.readIFD(e.order.Uint32(data[4:8]))
+	if err != nil {
+		return
+	}
+
+	m.exifMake = e.ascii(ifd0[tagMake])
+	m.exifModel = e.ascii(ifd0[tagModel])
+	m.exifOrientation = int(e.uint(ifd0[tagOrientation]))
+	m.exifKeywords = xpKeywords(ifd0[tagXPKeywords])
+
+	dates := []string{e.ascii(ifd0[tagDateTime])}
+	if v, found := ifd0[tagExifIFD]; found {
+		if exifIFD, err := e.readIFD(e.uint(v)); err == nil {
+			dates = append([]string{e.ascii(exifIFD[tagDateTimeOriginal]), e.ascii(exifIFD[tagDateTimeDigitized])}, dates...)
+		}
+	}
+
+	for _, d := range dates {
+		if date := parseDate(d, "2006:01:02"); date != "" {
+			m.exifDate = date
+			break
+		}
+	}
+}
+
+func (e exifReader) readIFD(offset uint32) (map[uint16]exifValue, error) {
+	data := e.data
+	if uint64(offset)+2 > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid IFD offset %d", offset)
+	}
+
+	count := uint64(e.order.Uint16(data[offset:]))
+	start := uint64(offset) + 2
+	if start+count*12 > uint64(len(data)) {
+		return nil, errors.New("invalid IFD entry count")
+	}
+
+	ret := make(map[uint16]exifValue)
+	for i := uint64(0); i < count; i++ {
+		entry := data[start+i*12 : start+(i+1)*12]
+		tag := e.order.Uint16(entry[0:2])
+		valueType := e.order.Uint16(entry[2:4])
+
+		typeSize, known := exifTypeSizes[valueType]
+		if !known {
+			continue
+		}
+
+		size := typeSize * uint64(e.order.Uint32(entry[4:8]))
+		var value []byte
+		if size <= 4 {
+			value = entry[8 : 8+size]
+		} else {
+			valueOffset := uint64(e.order.Uint32(entry[8:12]))
+			if valueOffset+size > uint64(len(data)) {
+				continue
+			}
+			value = data[valueOffset : valueOffset+size]
+		}
+
+		ret[tag] = exifValue{valueType: valueType, data: value}
+	}
+
+	return ret, nil
+}
+
+func (e exifReader) ascii(v exifValue) string {
+	if v.valueType != typeASCII {
+		return ""
+	}
+
+	s := string(v.data)
+	if end := strings.IndexByte(s, 0); end != -1 {
+		s = s[:end]
+	}
+	return strings.TrimSpace(s)
+}
+
+func (e exifReader) uint(v exifValue) uint32 {
+	switch {
+	case v.valueType == typeShort && len(v.data) >= 2:
+		return uint32(e.order.Uint16(v.data))
+	case v.valueType == typeLong && len(v.data) >= 4:
+		return e.order.Uint32(v.data)
+	}
+	return 0
+}
+
+// xpKeywords returns the semicolon-separated keywords of the Windows
+// XPKeywords tag, which is always UTF-16LE encoded.
+func xpKeywords(v exifValue) []string {
+	if v.valueType != typeByte || len(v.data) < 2 {
+		return nil
+	}
+
+	u := make([]uint16, len(v.data)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(v.data[i*2:])
+	}
+
+	s := string(utf16.Decode(u))
+	if end := strings.IndexByte(s, 0); end != -1 {
+		s = s[:end]
+	}
+
+	return strings.Split(s, ";")
+}
+
+// parseDate returns the date of a date time string in YYYY-MM-DD format, or
+// an empty string if the string does not start with a valid date in the
+// provided layout.
+func parseDate(s string, layout string) string {
+	if len(s) < len(layout) {
+		return ""
+	}
+
+	t, err := time.Parse(layout, s[:len(layout)])
+	if err != nil {
+		return ""
+	}
+
+	return t.Format("2006-01-02")
+}
+
+// XMP properties
+var (
+	xmpCreateDate       = xml.Name{Space: "http://ns.adobe.com/xap/1.0/", Local: "CreateDate"}
+	xmpDateTimeOriginal = xml.Name{Space: "http://ns.adobe.com/exif/1.0/", Local: "DateTimeOriginal"}
+	xmpDateCreated      = xml.Name{Space: "http://ns.adobe.com/photoshop/1.0/", Local: "DateCreated"}
+	xmpOrientation      = xml.Name{Space: "http://ns.adobe.com/tiff/1.0/", Local: "Orientation"}
+	xmpMake             = xml.Name{Space: "http://ns.adobe.com/tiff/1.0/", Local: "Make"}
+	xmpModel            = xml.Name{Space: "http://ns.adobe.com/tiff/1.0/", Local: "Model"}
+	xmpSubject          = xml.Name{Space: "http://purl.org/dc/elements/1.1/", Local: "subject"}
+)
+
+var xmpProperties = []xml.Name{
+	xmpCreateDate,
+	xmpDateTimeOriginal,
+	xmpDateCreated,
+	xmpOrientation,
+	xmpMake,
+	xmpModel,
+	xmpSubject,
+}
+
+func isXMPProperty(n xml.Name) bool {
+	for _, p := range xmpProperties {
+		if n == p {
+			return true
+		}
+	}
+	return false
+}
+
+// readXMP reads the values of the XMP packet. Properties may be stored as
+// attributes or elements, and element values may be in rdf containers.
+// Invalid data is ignored.
+func (m *metadataReader) readXMP(data []byte) {
+	values := make(map[xml.Name][]string)
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var property xml.Name
+	depth := 0
+	for {
+		t, err := d.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			if depth > 0 {
+				depth++
+				continue
+			}
+
+			for _, a := range t.Attr {
+				if isXMPProperty(a.Name) {
+					values[a.Name] = append(values[a.Name], a.Value)
+				}
+			}
+
+			if isXMPProperty(t.Name) {
+				property = t.Name
+				depth = 1
+			}
+		case xml.EndElement:
+			if depth > 0 {
+				depth--
+			}
+		case xml.CharData:
+			if depth > 0 {
+				if v := strings.TrimSpace(string(t)); v != "" {
+					values[property] = append(values[property], v)
+				}
+			}
+		}
+	}
+
+	first := func(n xml.Name) string {
+		if len(values[n]) > 0 {
+			return values[n][0]
+		}
+		return ""
+	}
+
+	for _, n := range []xml.Name{xmpDateTimeOriginal, xmpCreateDate, xmpDateCreated} {
+		if date := parseDate(first(n), "2006-01-02"); date != "" {
+			m.xmpDate = date
+			break
+		}
+	}
+
+	fmt.Sscanf(first(xmpOrientation), "%d", &m.xmpOrientation)
+	m.xmpMake = first(xmpMake)
+	m.xmpModel = first(xmpModel)
+	m.xmpKeywords = values[xmpSubject]
+}
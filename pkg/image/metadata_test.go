@@ -0,0 +1,237 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testExifEntry struct {
+	tag       uint16
+	valueType uint16
+	count     uint32
+	data      []byte
+}
+
+func testExifASCII(tag uint16, s string) testExifEntry {
+	return testExifEntry{tag, typeASCII, uint32(len(s) + 1), append([]byte(s), 0)}
+}
+
+func testExifShort(tag uint16, v uint16) testExifEntry {
+	data := make([]byte, 2)
+	binary.LittleEndian.PutUint16(data, v)
+	return testExifEntry{tag, typeShort, 1, data}
+}
+
+func testExifLong(tag uint16, v uint32) testExifEntry {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, v)
+	return testExifEntry{tag, typeLong, 1, data}
+}
+
+func testExifXPKeywords(s string) testExifEntry {
+	var data []byte
+	for _, u := range utf16.Encode([]rune(s + "\x00")) {
+		data = append(data, byte(u), byte(u>>8))
+	}
+	return testExifEntry{tagXPKeywords, typeByte, uint32(len(data)), data}
+}
+
+// writeTestIFD writes an IFD at the end of buf, with values that do not fit
+// in the entries following it.
+func writeTestIFD(buf []byte, entries []testExifEntry) []byte {
+	start := len(buf)
+	valuesOffset := start + 2 + len(entries)*12 + 4
+
+	ifd := make([]byte, valuesOffset-start)
+	binary.LittleEndian.PutUint16(ifd, uint16(len(entries)))
+
+	var values []byte
+	for i, e := range entries {
+		entry := ifd[2+i*12:]
+		binary.LittleEndian.PutUint16(entry[0:], e.tag)
+		binary.LittleEndian.PutUint16(entry[2:], e.valueType)
+		binary.LittleEndian.PutUint32(entry[4:], e.count)
+		if len(e.data) <= 4 {
+			copy(entry[8:], e.data)
+		} else {
+			binary.LittleEndian.PutUint32(entry[8:], uint32(valuesOffset+len(values)))
+			values = append(values, e.data...)
+		}
+	}
+
+	return append(append(buf, ifd...), values...)
+}
+
+func testExif() []byte {
+	const exifIFDOffset = 512
+
+	buf := []byte("II\x2a\x00\x08\x00\x00\x00")
+	buf = writeTestIFD(buf, []testExifEntry{
+		testExifASCII(tagMake, "Canon"),
+		testExifASCII(tagModel, "Canon EOS 5D"),
+		testExifShort(tagOrientation, 6),
+		testExifASCII(tagDateTime, "2020:01:02 03:04:05"),
+		testExifLong(tagExifIFD, exifIFDOffset),
+		testExifXPKeywords("beach;sunset"),
+	})
+
+	buf = append(buf, make([]byte, exifIFDOffset-len(buf))...)
+	return writeTestIFD(buf, []testExifEntry{
+		testExifASCII(tagDateTimeOriginal, "2019:05:04 10:11:12"),
+	})
+}
+
+const testXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:tiff="http://ns.adobe.com/tiff/1.0/"
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    tiff:Orientation="3"
+    xmp:CreateDate="2018-07-08T09:10:11">
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>Beach</rdf:li>
+     <rdf:li>holiday</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+
+func jpegSegment(marker byte, data []byte) []byte {
+	ret := []byte{0xff, marker, 0, 0}
+	binary.BigEndian.PutUint16(ret[2:], uint16(len(data)+2))
+	return append(ret, data...)
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	ret := make([]byte, 4)
+	binary.BigEndian.PutUint32(ret, uint32(len(data)))
+	ret = append(append(ret, chunkType...), data...)
+
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(append([]byte(chunkType), data...)))
+	return append(ret, crc...)
+}
+
+func TestReadMetadataJPEG(t *testing.T) {
+	var buf []byte
+	buf = append(buf, 0xff, 0xd8)
+	buf = append(buf, jpegSegment(0xe0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))...)
+	buf = append(buf, jpegSegment(0xe1, append([]byte(exifPrefix), testExif()...))...)
+	buf = append(buf, jpegSegment(0xe1, append([]byte(xmpPrefix), testXMP...))...)
+	buf = append(buf, 0xff, 0xda)
+
+	m, err := ReadMetadata(bytes.NewReader(buf))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// EXIF values take precedence over XMP values
+	assert.Equal(t, &Metadata{
+		Date:        "2019-05-04",
+		Orientation: 6,
+		Camera:      "Canon EOS 5D",
+		Keywords:    []string{"Beach", "holiday", "sunset"},
+	}, m)
+}
+
+func TestReadMetadataPNG(t *testing.T) {
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	encoded := pngBuf.Bytes()
+
+	// the IHDR chunk follows the signature
+	const ihdrEnd = 8 + 8 + 13 + 4
+	var buf []byte
+	buf = append(buf, encoded[:ihdrEnd]...)
+	buf = append(buf, pngChunk("iTXt", append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), testXMP...))...)
+	buf = append(buf, encoded[ihdrEnd:]...)
+
+	m, err := ReadMetadata(bytes.NewReader(buf))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Metadata{
+		Date:        "2018-07-08",
+		Orientation: 3,
+		Keywords:    []string{"Beach", "holiday"},
+	}, m)
+
+	// the image must still decode
+	_, err = png.Decode(bytes.NewReader(buf))
+	assert.Nil(t, err)
+}
+
+func TestReadMetadataNone(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ReadMetadata(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, &Metadata{Orientation: OrientationNormal}, m)
+
+	m, err = ReadMetadata(bytes.NewReader([]byte("GIF89a")))
+	assert.Nil(t, err)
+	assert.Equal(t, &Metadata{Orientation: OrientationNormal}, m)
+}
+
+func TestReadMetadataInvalidExif(t *testing.T) {
+	exif := testExif()
+	// point the exif IFD past the end of the data
+	truncated := exif[:len(exif)-20]
+
+	var buf []byte
+	buf = append(buf, 0xff, 0xd8)
+	buf = append(buf, jpegSegment(0xe1, append([]byte(exifPrefix), truncated...))...)
+	buf = append(buf, 0xff, 0xd9)
+
+	m, err := ReadMetadata(bytes.NewReader(buf))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, m.Orientation)
+	assert.Equal(t, "2020-01-02", m.Date)
+}
+
+func TestCameraName(t *testing.T) {
+	tests := []struct {
+		make  string
+		model string
+		want  string
+	}{
+		{"Canon", "Canon EOS 5D", "Canon EOS 5D"},
+		{"NIKON CORPORATION", "NIKON D750", "NIKON D750"},
+		{"Apple", "iPhone 12", "Apple iPhone 12"},
+		{"", "iPhone 12", "iPhone 12"},
+		{"Apple", "", "Apple"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, cameraName(tc.make, tc.model))
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 4, 2))
+
+	for orientation := 1; orientation <= 8; orientation++ {
+		size := Orient(src, orientation).Bounds().Size()
+		if OrientationTransposed(orientation) {
+			assert.Equal(t, image.Pt(2, 4), size, "orientation %d", orientation)
+		} else {
+			assert.Equal(t, image.Pt(4, 2), size, "orientation %d", orientation)
+		}
+	}
+}
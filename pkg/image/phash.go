@@ -0,0 +1,96 @@
+package image
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/disintegration/imaging"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	// phashSampleSize is the width and height the image is reduced to
+	// before the discrete cosine transform.
+	phashSampleSize = 32
+	// phashSize is the width and height of the lowest frequencies of the
+	// transform that are used for the hash.
+	phashSize = 8
+)
+
+var phashCosines = func() [phashSampleSize][phashSampleSize]float64 {
+	var ret [phashSampleSize][phashSampleSize]float64
+	for u := 0; u < phashSampleSize; u++ {
+		for x := 0; x < phashSampleSize; x++ {
+			ret[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * phashSampleSize))
+		}
+	}
+	return ret
+}()
+
+// PerceptualHash returns the 64 bit DCT perceptual hash of the image. Images
+// that look the same have hashes that differ by few bits, regardless of
+// their dimensions and encoding.
+func PerceptualHash(img image.Image) uint64 {
+	resized := imaging.Resize(img, phashSampleSize, phashSampleSize, imaging.Linear)
+
+	var pixels [phashSampleSize][phashSampleSize]float64
+	for y := 0; y < phashSampleSize; y++ {
+		for x := 0; x < phashSampleSize; x++ {
+			c := resized.NRGBAAt(x, y)
+			pixels[y][x] = 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+		}
+	}
+
+	// only the lowest frequencies of the two-dimensional transform are
+	// needed
+	var rows [phashSampleSize][phashSize]float64
+	for y := 0; y < phashSampleSize; y++ {
+		for u := 0; u < phashSize; u++ {
+			sum := 0.0
+			for x := 0; x < phashSampleSize; x++ {
+				sum += pixels[y][x] * phashCosines[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+
+	coefficients := make([]float64, 0, phashSize*phashSize)
+	for v := 0; v < phashSize; v++ {
+		for u := 0; u < phashSize; u++ {
+			sum := 0.0
+			for y := 0; y < phashSampleSize; y++ {
+				sum += rows[y][u] * phashCosines[v][y]
+			}
+			coefficients = append(coefficients, sum)
+		}
+	}
+
+	sorted := append([]float64(nil), coefficients...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var ret uint64
+	for i, c := range coefficients {
+		if c > median {
+			ret |= 1 << uint(len(coefficients)-1-i)
+		}
+	}
+
+	return ret
+}
+
+// CalculatePerceptualHash returns the perceptual hash of the image file,
+// oriented using the orientation of the image.
+func CalculatePerceptualHash(i *models.Image) (uint64, error) {
+	src, err := GetSourceImage(i)
+	if err != nil {
+		return 0, err
+	}
+
+	if i.Orientation.Valid {
+		src = Orient(src, int(i.Orientation.Int64))
+	}
+
+	return PerceptualHash(src), nil
+}
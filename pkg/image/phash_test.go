@@ -0,0 +1,54 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stashapp/stash/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPattern(width, height int, f func(x, y float64) uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetGray(x, y, color.Gray{Y: f(float64(x)/float64(width), float64(y)/float64(height))})
+		}
+	}
+	return img
+}
+
+func TestPerceptualHash(t *testing.T) {
+	gradient := func(x, y float64) uint8 {
+		if x > 0.3 && x < 0.6 && y > 0.2 && y < 0.5 {
+			return 255
+		}
+		return uint8(x * 200)
+	}
+	other := func(x, y float64) uint8 {
+		if int(y*8)%2 == 0 {
+			return 220
+		}
+		return uint8(y * 100)
+	}
+
+	original := testPattern(400, 300, gradient)
+	hash := PerceptualHash(original)
+
+	// a smaller jpeg encoding of the same image
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, imaging.Resize(original, 200, 150, imaging.Lanczos), &jpeg.Options{Quality: 60}); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.LessOrEqual(t, utils.HammingDistance(hash, PerceptualHash(encoded)), 4)
+	assert.Greater(t, utils.HammingDistance(hash, PerceptualHash(testPattern(400, 300, other))), 10)
+}
@@ -0,0 +1,115 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"image"
+
+	"github.com/disintegration/imaging"
+	"github.com/stashapp/stash/pkg/ffmpeg"
+)
+
+// ErrCropOutOfBounds is returned when the crop rectangle of an image does
+// not overlap the image.
+var ErrCropOutOfBounds = errors.New("crop rectangle is outside of the image")
+
+// Crop is a rectangle of an image in pixels, relative to the top left corner
+// of the image displayed the right way up.
+type Crop struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// ProcessOptions are the options used to process images of performers,
+// studios and tags before they are stored.
+type ProcessOptions struct {
+	// Crop is the rectangle the image is cropped to. The image is not
+	// cropped if nil.
+	Crop *Crop
+	// MaxWidth and MaxHeight are the maximum size of the image. The image
+	// is reduced to fit within the size, keeping its aspect ratio. Zero
+	// values are not limited.
+	MaxWidth  int
+	MaxHeight int
+	// Format is the format the image is stored in. If empty, the image is
+	// stored in its original format unless it is cropped or resized, in
+	// which case it is stored as PNG if it has transparency and JPEG
+	// otherwise.
+	Format ResizeFormat
+	// Quality is the quality of JPEG and WebP images, from 1 to 100. The
+	// default quality of the encoder is used if it is zero.
+	Quality int
+	// Encoder is used to encode WebP and AVIF images.
+	Encoder ffmpeg.Encoder
+	// TmpDir is the directory of temporary files written when encoding
+	// WebP and AVIF images.
+	TmpDir string
+}
+
+func (o ProcessOptions) transforms() bool {
+	return o.Crop != nil || o.MaxWidth > 0 || o.MaxHeight > 0
+}
+
+// ProcessData crops, resizes and encodes the image data with the provided
+// options. Images are transformed to display the right way up for their
+// EXIF orientation when they are re-encoded. Images that cannot be decoded,
+// such as SVG images, are returned as is, unless they need to be cropped or
+// resized, in which case ErrNotResizable is returned.
+func ProcessData(data []byte, options ProcessOptions) ([]byte, error) {
+	if options.Format == "" && !options.transforms() {
+		return data, nil
+	}
+
+	srcImage, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if options.transforms() {
+			return nil, ErrNotResizable
+		}
+		return data, nil
+	}
+
+	if metadata, err := ReadMetadata(bytes.NewReader(data)); err == nil {
+		srcImage = Orient(srcImage, metadata.Orientation)
+	}
+
+	if options.Crop != nil {
+		if srcImage, err = crop(srcImage, *options.Crop); err != nil {
+			return nil, err
+		}
+	}
+
+	srcImage = Resize(srcImage, options.MaxWidth, options.MaxHeight, ResizeFitContain, ResizeFilterLanczos)
+
+	format := options.Format
+	if format == "" {
+		format = ResizeFormatJPEG
+		if !isOpaque(srcImage) {
+			format = ResizeFormatPNG
+		}
+	}
+
+	return encode(srcImage, format, options.Quality, options.Encoder, options.TmpDir)
+}
+
+// crop returns the part of the image within the crop rectangle, limited to
+// the bounds of the image.
+func crop(srcImage image.Image, c Crop) (image.Image, error) {
+	bounds := srcImage.Bounds()
+	rect := image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height).Add(bounds.Min).Intersect(bounds)
+	if rect.Empty() {
+		return nil, ErrCropOutOfBounds
+	}
+
+	return imaging.Crop(srcImage, rect), nil
+}
+
+// isOpaque returns true if the image has no transparent pixels. Images of
+// types that don't report their opacity are assumed to be opaque.
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
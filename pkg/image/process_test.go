@@ -0,0 +1,88 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeTestPNG(t *testing.T, img image.Image) []byte {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessData(t *testing.T) {
+	opaque := encodeTestPNG(t, image.NewGray(image.Rect(0, 0, 400, 200)))
+	transparent := encodeTestPNG(t, image.NewNRGBA(image.Rect(0, 0, 400, 200)))
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		options    ProcessOptions
+		wantFormat string
+		wantSize   image.Point
+		wantErr    error
+	}{
+		{"unchanged", opaque, ProcessOptions{}, "png", image.Pt(400, 200), nil},
+		{"format", opaque, ProcessOptions{Format: ResizeFormatJPEG}, "jpeg", image.Pt(400, 200), nil},
+		{"max width", opaque, ProcessOptions{MaxWidth: 100}, "jpeg", image.Pt(100, 50), nil},
+		{"max size", opaque, ProcessOptions{MaxWidth: 200, MaxHeight: 50}, "jpeg", image.Pt(100, 50), nil},
+		{"transparent", transparent, ProcessOptions{MaxWidth: 100}, "png", image.Pt(100, 50), nil},
+		{"crop", opaque, ProcessOptions{Crop: &Crop{X: 50, Y: 50, Width: 100, Height: 120}}, "jpeg", image.Pt(100, 120), nil},
+		{"crop partly outside", opaque, ProcessOptions{Crop: &Crop{X: 350, Y: 150, Width: 100, Height: 100}}, "jpeg", image.Pt(50, 50), nil},
+		{"crop outside", opaque, ProcessOptions{Crop: &Crop{X: 400, Y: 0, Width: 100, Height: 100}}, "", image.Point{}, ErrCropOutOfBounds},
+		{"crop and resize", opaque, ProcessOptions{Crop: &Crop{Width: 200, Height: 200}, MaxWidth: 100}, "jpeg", image.Pt(100, 100), nil},
+		{"svg", svg, ProcessOptions{Format: ResizeFormatJPEG}, "", image.Point{}, nil},
+		{"svg resize", svg, ProcessOptions{MaxWidth: 100}, "", image.Point{}, ErrNotResizable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessData(tt.data, tt.options)
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+
+			if tt.wantFormat == "" {
+				assert.Equal(t, tt.data, got)
+				return
+			}
+
+			config, format, err := image.DecodeConfig(bytes.NewReader(got))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantFormat, format)
+			assert.Equal(t, tt.wantSize, image.Pt(config.Width, config.Height))
+		})
+	}
+}
+
+func TestProcessDataOpaque(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	got, err := ProcessData(encodeTestPNG(t, img), ProcessOptions{MaxWidth: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, format, err := image.DecodeConfig(bytes.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "jpeg", format)
+}
@@ -0,0 +1,253 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/disintegration/imaging"
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// ErrNotResizable is returned when resizing an image that cannot be
+// decoded, such as an SVG image.
+var ErrNotResizable = errors.New("image cannot be resized")
+
+// ResizeFit is the way an image is fitted to the requested size when both
+// a width and height are requested.
+type ResizeFit string
+
+const (
+	// ResizeFitContain scales the image to fit within the requested size,
+	// keeping its aspect ratio.
+	ResizeFitContain ResizeFit = "contain"
+	// ResizeFitCover scales the image to cover the requested size, keeping
+	// its aspect ratio, and crops it to the requested size.
+	ResizeFitCover ResizeFit = "cover"
+	// ResizeFitFill stretches the image to the requested size.
+	ResizeFitFill ResizeFit = "fill"
+)
+
+func (f ResizeFit) IsValid() bool {
+	switch f {
+	case ResizeFitContain, ResizeFitCover, ResizeFitFill:
+		return true
+	}
+	return false
+}
+
+// ResizeFilter is the resampling filter used to resize images.
+type ResizeFilter string
+
+const (
+	ResizeFilterBox             ResizeFilter = "box"
+	ResizeFilterLinear          ResizeFilter = "linear"
+	ResizeFilterCatmullRom      ResizeFilter = "catmull_rom"
+	ResizeFilterLanczos         ResizeFilter = "lanczos"
+	ResizeFilterNearestNeighbor ResizeFilter = "nearest_neighbor"
+)
+
+var resizeFilters = map[ResizeFilter]imaging.ResampleFilter{
+	ResizeFilterBox:             imaging.Box,
+	ResizeFilterLinear:          imaging.Linear,
+	ResizeFilterCatmullRom:      imaging.CatmullRom,
+	ResizeFilterLanczos:         imaging.Lanczos,
+	ResizeFilterNearestNeighbor: imaging.NearestNeighbor,
+}
+
+func (f ResizeFilter) IsValid() bool {
+	_, found := resizeFilters[f]
+	return found
+}
+
+// resampleFilter returns the imaging filter of the filter, defaulting to
+// Lanczos.
+func (f ResizeFilter) resampleFilter() imaging.ResampleFilter {
+	if ret, found := resizeFilters[f]; found {
+		return ret
+	}
+	return imaging.Lanczos
+}
+
+// ResizeFormat is the format resized images are encoded in.
+type ResizeFormat string
+
+const (
+	ResizeFormatJPEG ResizeFormat = "jpeg"
+	ResizeFormatPNG  ResizeFormat = "png"
+	ResizeFormatWebP ResizeFormat = "webp"
+	ResizeFormatAVIF ResizeFormat = "avif"
+)
+
+func (f ResizeFormat) IsValid() bool {
+	switch f {
+	case ResizeFormatJPEG, ResizeFormatPNG, ResizeFormatWebP, ResizeFormatAVIF:
+		return true
+	}
+	return false
+}
+
+// ContentType returns the MIME type of the format.
+func (f ResizeFormat) ContentType() string {
+	return "image/" + string(f)
+}
+
+// Resize returns the image resized to the provided width and height using
+// the provided filter. A zero width or height is calculated from the aspect
+// ratio of the image, and the fit is only used when both are provided.
+// Images are never enlarged.
+func Resize(srcImage image.Image, width int, height int, fit ResizeFit, filter ResizeFilter) image.Image {
+	dim := srcImage.Bounds().Size()
+	resampleFilter := filter.resampleFilter()
+
+	if width == 0 || height == 0 || fit == ResizeFitContain {
+		if (width == 0 || width >= dim.X) && (height == 0 || height >= dim.Y) {
+			return srcImage
+		}
+
+		if width == 0 || height == 0 {
+			return imaging.Resize(srcImage, width, height, resampleFilter)
+		}
+		return imaging.Fit(srcImage, width, height, resampleFilter)
+	}
+
+	if fit == ResizeFitCover {
+		// reduce the size while keeping its aspect ratio, so that the crop
+		// is the same as it would be at the requested size
+		scale := 1.0
+		if width > dim.X {
+			scale = float64(dim.X) / float64(width)
+		}
+		if height > dim.Y {
+			if s := float64(dim.Y) / float64(height); s < scale {
+				scale = s
+			}
+		}
+		width = int(float64(width)*scale + 0.5)
+		height = int(float64(height)*scale + 0.5)
+
+		return imaging.Fill(srcImage, width, height, imaging.Center, resampleFilter)
+	}
+
+	if width > dim.X {
+		width = dim.X
+	}
+	if height > dim.Y {
+		height = dim.Y
+	}
+	return imaging.Resize(srcImage, width, height, resampleFilter)
+}
+
+// ResizeOptions are the options used to resize an image.
+type ResizeOptions struct {
+	Width  int
+	Height int
+	Fit    ResizeFit
+	Format ResizeFormat
+	// Filter is the resampling filter. Defaults to Lanczos.
+	Filter ResizeFilter
+	// Quality is the quality of JPEG and WebP images, from 1 to 100. The
+	// default quality of the encoder is used if it is zero.
+	Quality int
+	// Encoder is used to encode WebP and AVIF images.
+	Encoder ffmpeg.Encoder
+	// TmpDir is the directory of temporary files written when encoding
+	// WebP and AVIF images.
+	TmpDir string
+}
+
+// ResizeFile returns the image file resized with the provided options and
+// transformed to display the right way up. Animated images are resized to
+// a still image of their first frame. Video clips cannot be resized.
+func ResizeFile(i *models.Image, options ResizeOptions) ([]byte, error) {
+	if IsClip(i) {
+		return nil, ErrNotResizable
+	}
+
+	srcImage, err := GetSourceImage(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.Orientation.Valid {
+		srcImage = Orient(srcImage, int(i.Orientation.Int64))
+	}
+
+	return resizeAndEncode(srcImage, options)
+}
+
+// ResizeData returns the encoded image data resized with the provided
+// options. Returns ErrNotResizable if the data cannot be decoded.
+func ResizeData(data []byte, options ResizeOptions) ([]byte, error) {
+	srcImage, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotResizable
+	}
+
+	return resizeAndEncode(srcImage, options)
+}
+
+func resizeAndEncode(srcImage image.Image, options ResizeOptions) ([]byte, error) {
+	resizedImage := Resize(srcImage, options.Width, options.Height, options.Fit, options.Filter)
+	return encode(resizedImage, options.Format, options.Quality, options.Encoder, options.TmpDir)
+}
+
+// encode encodes the image in the provided format. JPEG and PNG images are
+// encoded natively, and other formats using ffmpeg.
+func encode(img image.Image, format ResizeFormat, quality int, encoder ffmpeg.Encoder, tmpDir string) ([]byte, error) {
+	switch format {
+	case ResizeFormatJPEG:
+		var jpegOptions *jpeg.Options
+		if quality > 0 {
+			jpegOptions = &jpeg.Options{Quality: quality}
+		}
+
+		buf := new(bytes.Buffer)
+		if err := jpeg.Encode(buf, img, jpegOptions); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ResizeFormatPNG:
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, img); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	// ffmpeg reads the resized image from a lossless temporary file
+	dir, err := ioutil.TempDir(tmpDir, "resize")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	inputPath := filepath.Join(dir, "input.png")
+	f, err := os.Create(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	err = png.Encode(f, img)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	outputPath := filepath.Join(dir, "output."+string(format))
+	if err := encoder.EncodeImage(ffmpeg.ImageEncodeOptions{
+		InputPath:  inputPath,
+		OutputPath: outputPath,
+		Format:     string(format),
+		Quality:    quality,
+	}); err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadFile(outputPath)
+}
@@ -0,0 +1,37 @@
+package image
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResize(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 400, 200))
+
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		fit    ResizeFit
+		want   image.Point
+	}{
+		{"width", 100, 0, ResizeFitContain, image.Pt(100, 50)},
+		{"height", 0, 100, ResizeFitCover, image.Pt(200, 100)},
+		{"contain", 100, 100, ResizeFitContain, image.Pt(100, 50)},
+		{"cover", 100, 100, ResizeFitCover, image.Pt(100, 100)},
+		{"fill", 100, 100, ResizeFitFill, image.Pt(100, 100)},
+		{"contain larger", 800, 800, ResizeFitContain, image.Pt(400, 200)},
+		{"width larger", 800, 0, ResizeFitContain, image.Pt(400, 200)},
+		{"cover larger", 800, 800, ResizeFitCover, image.Pt(200, 200)},
+		{"fill larger", 800, 100, ResizeFitFill, image.Pt(400, 100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resize(src, tt.width, tt.height, tt.fit, ResizeFilterBox)
+			assert.Equal(t, tt.want, got.Bounds().Size())
+		})
+	}
+}
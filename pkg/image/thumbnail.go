@@ -1,12 +1,13 @@
 package image
 
-import (
-	"bytes"
-	"image"
-	"image/jpeg"
+import "image"
 
-	"github.com/disintegration/imaging"
-)
+// OrientedThumbnailNeeded returns true if a thumbnail is needed for the
+// image, either because it is larger than the max size or because it must be
+// transformed to display the right way up for the provided EXIF orientation.
+func OrientedThumbnailNeeded(srcImage image.Image, orientation int, maxSize int) bool {
+	return ThumbnailNeeded(srcImage, maxSize) || (orientation >= 2 && orientation <= 8)
+}
 
 func ThumbnailNeeded(srcImage image.Image, maxSize int) bool {
 	dim := srcImage.Bounds().Max
@@ -16,25 +17,16 @@ func ThumbnailNeeded(srcImage image.Image, maxSize int) bool {
 	return w > maxSize || h > maxSize
 }
 
-// GetThumbnail returns the thumbnail image of the provided image resized to
-// the provided max size. It resizes based on the largest X/Y direction.
-// It returns nil and an error if an error occurs reading, decoding or encoding
-// the image.
-func GetThumbnail(srcImage image.Image, maxSize int) ([]byte, error) {
-	var resizedImage image.Image
-
-	// if height is longer then resize by height instead of width
-	dim := srcImage.Bounds().Max
-	if dim.Y > dim.X {
-		resizedImage = imaging.Resize(srcImage, 0, maxSize, imaging.Box)
-	} else {
-		resizedImage = imaging.Resize(srcImage, maxSize, 0, imaging.Box)
-	}
+// GetThumbnail returns the thumbnail of the provided image, resized to fit
+// within the width and height of the provided options and encoded in their
+// format. Images smaller than the size are not enlarged.
+func GetThumbnail(srcImage image.Image, options ResizeOptions) ([]byte, error) {
+	options.Fit = ResizeFitContain
+	return resizeAndEncode(srcImage, options)
+}
 
-	buf := new(bytes.Buffer)
-	err := jpeg.Encode(buf, resizedImage, nil)
-	if err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+// GetOrientedThumbnail returns the thumbnail image of the provided image
+// transformed to display the right way up for the provided EXIF orientation.
+func GetOrientedThumbnail(srcImage image.Image, orientation int, options ResizeOptions) ([]byte, error) {
+	return GetThumbnail(Orient(srcImage, orientation), options)
 }
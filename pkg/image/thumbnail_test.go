@@ -0,0 +1,42 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrientedThumbnail(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 400, 200))
+
+	options := ResizeOptions{
+		Width:   100,
+		Height:  100,
+		Format:  ResizeFormatJPEG,
+		Filter:  ResizeFilterBox,
+		Quality: 50,
+	}
+
+	// orientation 6 is rotated 90 degrees
+	data, err := GetOrientedThumbnail(src, 6, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thumbnail, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, image.Pt(50, 100), thumbnail.Bounds().Size())
+
+	// lower quality images are smaller
+	options.Quality = 100
+	best, err := GetOrientedThumbnail(src, 6, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, len(best) > len(data))
+}
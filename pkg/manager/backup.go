@@ -0,0 +1,149 @@
+package manager
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/database"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// minimum delay before running a scheduled backup, so that an overdue backup
+// does not run while the server is starting up.
+const backupStartupDelay = time.Minute
+
+type backupScheduler struct {
+	mutex sync.Mutex
+	timer *time.Timer
+	// running is true while a scheduled backup or a restore is running
+	running bool
+}
+
+// start marks a backup or restore as running. Returns false if one is
+// already running.
+func (b *backupScheduler) start() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.running {
+		return false
+	}
+	b.running = true
+	return true
+}
+
+func (b *backupScheduler) done() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.running = false
+}
+
+var backups backupScheduler
+
+// RefreshBackupSchedule reschedules the next database backup using the
+// current configuration. The next backup is scheduled relative to the most
+// recent existing backup.
+func (s *singleton) RefreshBackupSchedule() {
+	backups.mutex.Lock()
+	defer backups.mutex.Unlock()
+
+	if backups.timer != nil {
+		backups.timer.Stop()
+		backups.timer = nil
+	}
+
+	interval := time.Duration(config.GetBackupInterval()) * time.Hour
+	if interval <= 0 || config.GetDatabasePath() == "" {
+		return
+	}
+
+	delay := interval
+	existing, err := database.ListBackups(config.GetBackupDirectory())
+	if err != nil {
+		logger.Warnf("error listing database backups: %s", err.Error())
+	} else if len(existing) > 0 {
+		delay = time.Until(existing[0].Time.Add(interval))
+	}
+
+	if delay < backupStartupDelay {
+		delay = backupStartupDelay
+	}
+
+	logger.Debugf("Next scheduled database backup in %s", delay.Round(time.Second))
+	backups.timer = time.AfterFunc(delay, func() {
+		runScheduledBackup()
+		s.RefreshBackupSchedule()
+	})
+}
+
+func runScheduledBackup() {
+	if !backups.start() {
+		logger.Warn("Skipping scheduled database backup: a database restore is running")
+		return
+	}
+	defer backups.done()
+
+	// don't back up a database that is not yet initialised or needs migrating
+	if database.DB == nil || database.NeedsMigration() {
+		logger.Warn("Skipping scheduled database backup: database is not ready")
+		return
+	}
+
+	dir := config.GetBackupDirectory()
+	if err := utils.EnsureDir(dir); err != nil {
+		logger.Errorf("error creating backup directory %s: %s", dir, err.Error())
+		return
+	}
+
+	if err := database.Backup(database.DB, database.ScheduledBackupPathInDir(dir)); err != nil {
+		logger.Errorf("error performing scheduled database backup: %s", err.Error())
+		return
+	}
+
+	if err := database.RotateBackups(dir, config.GetBackupCount()); err != nil {
+		logger.Errorf("error removing old database backups: %s", err.Error())
+	}
+}
+
+// RestoreDatabase replaces the database with the backup at backupPath. The
+// database cannot be restored while a job or a scheduled backup is running,
+// since the database is closed while it is restored. Transactions are
+// prevented from running while the database is restored.
+func (s *singleton) RestoreDatabase(backupPath string) error {
+	if !s.Status.setStatusIfIdle(RestoreDatabase) {
+		return errors.New("cannot restore the database while a job is running")
+	}
+	defer s.returnToIdleState()
+
+	if !backups.start() {
+		return errors.New("cannot restore the database while a backup is running")
+	}
+	defer backups.done()
+
+	migrated, err := s.restoreLocked(backupPath)
+	if err != nil {
+		return err
+	}
+
+	if migrated {
+		s.PostMigrate()
+	}
+
+	return nil
+}
+
+// restoreLocked restores the database with transactions locked out.
+func (s *singleton) restoreLocked(backupPath string) (bool, error) {
+	if locker, ok := s.TxnManager.(models.LockableTransactionManager); ok {
+		if err := locker.Lock(); err != nil {
+			return false, err
+		}
+		defer locker.Unlock()
+	}
+
+	return database.Restore(backupPath)
+}
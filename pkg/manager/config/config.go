@@ -5,12 +5,14 @@ import (
 	"runtime"
 
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"regexp"
 
 	"github.com/spf13/viper"
 
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
 )
@@ -28,6 +30,12 @@ const DefaultMaxSessionAge = 60 * 60 * 1 // 1 hours
 
 const Database = "database"
 
+// database backup options
+const BackupInterval = "backup_interval"
+const BackupDirectory = "backup_directory"
+const BackupCount = "backup_count"
+const backupCountDefault = 7
+
 const Exclude = "exclude"
 const ImageExclude = "image_exclude"
 
@@ -43,8 +51,52 @@ const GalleryExtensions = "gallery_extensions"
 
 var defaultGalleryExtensions = []string{"zip", "cbz"}
 
+// defaultLibarchiveGalleryExtensions are the default extensions of gallery
+// archives that are read using libarchive. They are only used by default if
+// libarchive is installed.
+var defaultLibarchiveGalleryExtensions = []string{"rar", "cbr", "7z", "cb7"}
+
 const CreateGalleriesFromFolders = "create_galleries_from_folders"
 
+// GalleryFolderRules is the config key of the rules of the folders galleries
+// are created from.
+const GalleryFolderRules = "gallery_folder_rules"
+
+// ImageKeywordsAsTags is the config key used to determine if keywords
+// embedded in images are added to images as tags with the same name.
+const ImageKeywordsAsTags = "image_keywords_as_tags"
+
+// ImageClips is the config key used to determine if short video clips in
+// folders with images are scanned as images.
+const ImageClips = "image_clips"
+
+// ImageClipMaxDuration is the config key for the maximum duration in seconds
+// of video clips that are scanned as images.
+const ImageClipMaxDuration = "image_clip_max_duration"
+const DefaultImageClipMaxDuration = 30
+
+// image thumbnail options
+const ImageThumbnailSizes = "image_thumbnail_sizes"
+const ImageThumbnailQuality = "image_thumbnail_quality"
+const DefaultImageThumbnailQuality = 75
+const ImageThumbnailFormat = "image_thumbnail_format"
+const ImageThumbnailFilter = "image_thumbnail_filter"
+
+// ResizedImageCacheSize is the config key for the maximum size in megabytes
+// of resized images kept in the generated directory.
+const ResizedImageCacheSize = "resized_image_cache_size"
+const DefaultResizedImageCacheSize = 1024
+
+// BlobsStorage is the config key for where performer, studio, tag, movie and
+// scene images are stored, and BlobsPath for the directory of images stored
+// in the filesystem.
+const BlobsStorage = "blobs_storage"
+const BlobsPath = "blobs_path"
+
+// StoredImageFormat is the config key for the format that performer, studio
+// and tag images are stored in.
+const StoredImageFormat = "stored_image_format"
+
 // CalculateMD5 is the config key used to determine if MD5 should be calculated
 // for video files.
 const CalculateMD5 = "calculate_md5"
@@ -89,6 +141,14 @@ const ScraperUserAgent = "scraper_user_agent"
 const ScraperCertCheck = "scraper_cert_check"
 const ScraperCDPPath = "scraper_cdp_path"
 
+// ScraperCredentials is the config key for the credentials used by scrapers
+// to login. This should be manually configured only.
+const ScraperCredentials = "scraper_credentials"
+
+// package index options
+const ScraperPackageIndex = "scraper_package_index"
+const PluginPackageIndex = "plugin_package_index"
+
 // stash-box options
 const StashBoxes = "stash_boxes"
 
@@ -179,6 +239,30 @@ func GetDatabasePath() string {
 	return viper.GetString(Database)
 }
 
+// GetBackupInterval returns the number of hours between scheduled database
+// backups. A value of zero or less disables scheduled backups.
+func GetBackupInterval() int {
+	return viper.GetInt(BackupInterval)
+}
+
+// GetBackupDirectory returns the directory that database backups are written
+// to. Defaults to the directory containing the database.
+func GetBackupDirectory() string {
+	ret := viper.GetString(BackupDirectory)
+	if ret == "" {
+		ret = filepath.Dir(GetDatabasePath())
+	}
+
+	return ret
+}
+
+// GetBackupCount returns the number of scheduled database backups to keep.
+// Older backups are removed after a scheduled backup. A value of zero or less
+// keeps all backups.
+func GetBackupCount() int {
+	return viper.GetInt(BackupCount)
+}
+
 func GetJWTSignKey() []byte {
 	return []byte(viper.GetString(JWTSignKey))
 }
@@ -222,7 +306,10 @@ func GetImageExtensions() []string {
 func GetGalleryExtensions() []string {
 	ret := viper.GetStringSlice(GalleryExtensions)
 	if ret == nil {
-		ret = defaultGalleryExtensions
+		ret = append([]string{}, defaultGalleryExtensions...)
+		if image.HasLibarchive() {
+			ret = append(ret, defaultLibarchiveGalleryExtensions...)
+		}
 	}
 	return ret
 }
@@ -231,6 +318,115 @@ func GetCreateGalleriesFromFolders() bool {
 	return viper.GetBool(CreateGalleriesFromFolders)
 }
 
+// GetGalleryFolderRules returns the rules of the folders galleries are
+// created from, in the order they are applied.
+func GetGalleryFolderRules() []*models.GalleryFolderRule {
+	var rules []*models.GalleryFolderRule
+	viper.UnmarshalKey(GalleryFolderRules, &rules)
+	return rules
+}
+
+func GetImageKeywordsAsTags() bool {
+	return viper.GetBool(ImageKeywordsAsTags)
+}
+
+func GetImageClips() bool {
+	return viper.GetBool(ImageClips)
+}
+
+// GetImageClipMaxDuration returns the maximum duration in seconds of video
+// clips that are scanned as images.
+func GetImageClipMaxDuration() int {
+	viper.SetDefault(ImageClipMaxDuration, DefaultImageClipMaxDuration)
+	return viper.GetInt(ImageClipMaxDuration)
+}
+
+// GetImageThumbnailSizes returns the maximum widths and heights of generated
+// image thumbnails. The first size is served by default. Defaults to a
+// single size of 640.
+func GetImageThumbnailSizes() []int {
+	var ret []int
+	for _, size := range viper.GetIntSlice(ImageThumbnailSizes) {
+		if size > 0 {
+			ret = append(ret, size)
+		}
+	}
+
+	if len(ret) == 0 {
+		return []int{models.DefaultGthumbWidth}
+	}
+
+	return ret
+}
+
+// GetImageThumbnailQuality returns the quality of generated image
+// thumbnails, from 1 to 100.
+func GetImageThumbnailQuality() int {
+	ret := viper.GetInt(ImageThumbnailQuality)
+	if ret < 1 || ret > 100 {
+		return DefaultImageThumbnailQuality
+	}
+	return ret
+}
+
+// GetImageThumbnailFormat returns the format of generated image thumbnails.
+// Defaults to JPEG.
+func GetImageThumbnailFormat() models.ImageThumbnailFormat {
+	ret := models.ImageThumbnailFormat(viper.GetString(ImageThumbnailFormat))
+	if !ret.IsValid() {
+		return models.ImageThumbnailFormatJpeg
+	}
+	return ret
+}
+
+// GetImageThumbnailFilter returns the resampling filter used to resize
+// generated image thumbnails. Defaults to Box.
+func GetImageThumbnailFilter() models.ImageThumbnailFilter {
+	ret := models.ImageThumbnailFilter(viper.GetString(ImageThumbnailFilter))
+	if !ret.IsValid() {
+		return models.ImageThumbnailFilterBox
+	}
+	return ret
+}
+
+// GetResizedImageCacheSize returns the maximum size in megabytes of resized
+// images kept in the generated directory. Resized images are not kept if it
+// is zero.
+func GetResizedImageCacheSize() int {
+	viper.SetDefault(ResizedImageCacheSize, DefaultResizedImageCacheSize)
+	return viper.GetInt(ResizedImageCacheSize)
+}
+
+// GetStoredImageFormat returns the format that performer, studio and tag
+// images are stored in. Defaults to the original format of the images.
+func GetStoredImageFormat() models.StoredImageFormat {
+	ret := models.StoredImageFormat(viper.GetString(StoredImageFormat))
+	if !ret.IsValid() {
+		return models.StoredImageFormatOriginal
+	}
+	return ret
+}
+
+// GetBlobsStorage returns where performer, studio, tag, movie and scene
+// images are stored. Defaults to the database.
+func GetBlobsStorage() models.BlobsStorageType {
+	ret := models.BlobsStorageType(viper.GetString(BlobsStorage))
+	if !ret.IsValid() {
+		return models.BlobsStorageTypeDatabase
+	}
+	return ret
+}
+
+// GetBlobsPath returns the directory of images stored in the filesystem.
+// Defaults to the blobs directory next to the config file.
+func GetBlobsPath() string {
+	ret := viper.GetString(BlobsPath)
+	if ret == "" {
+		return filepath.Join(GetConfigPath(), "blobs")
+	}
+	return ret
+}
+
 func GetLanguage() string {
 	ret := viper.GetString(Language)
 
@@ -275,6 +471,18 @@ func GetScraperCDPPath() string {
 	return viper.GetString(ScraperCDPPath)
 }
 
+// GetScraperCredentials returns the credentials used by scrapers to login,
+// keyed by scraper ID. The scraper IDs and credential names are lower case,
+// since the configuration keys are not case-sensitive.
+func GetScraperCredentials() map[string]map[string]string {
+	ret := make(map[string]map[string]string)
+	for id := range viper.GetStringMap(ScraperCredentials) {
+		ret[id] = viper.GetStringMapString(ScraperCredentials + "." + id)
+	}
+
+	return ret
+}
+
 // GetScraperCertCheck returns true if the scraper should check for insecure
 // certificates when fetching an image or a page.
 func GetScraperCertCheck() bool {
@@ -286,6 +494,18 @@ func GetScraperCertCheck() bool {
 	return ret
 }
 
+// GetScraperPackageIndex returns the url or path of the index used to
+// install scraper packages.
+func GetScraperPackageIndex() string {
+	return viper.GetString(ScraperPackageIndex)
+}
+
+// GetPluginPackageIndex returns the url or path of the index used to
+// install plugin packages.
+func GetPluginPackageIndex() string {
+	return viper.GetString(PluginPackageIndex)
+}
+
 func GetStashBoxes() []*models.StashBox {
 	var boxes []*models.StashBox
 	viper.UnmarshalKey(StashBoxes, &boxes)
@@ -462,6 +682,26 @@ func ValidateStashBoxes(boxes []*models.StashBoxInput) error {
 	return nil
 }
 
+// ValidateGalleryFolderRules returns an error if a rule has a negative depth
+// or minimum number of images, or a pattern that is not a valid regular
+// expression.
+func ValidateGalleryFolderRules(rules []*models.GalleryFolderRuleInput) error {
+	for _, rule := range rules {
+		if rule.Depth < 0 {
+			return errors.New("Gallery folder rule depth cannot be negative")
+		}
+		if rule.MinImages < 0 {
+			return errors.New("Gallery folder rule minimum images cannot be negative")
+		}
+		if rule.Pattern != nil {
+			if _, err := regexp.Compile(*rule.Pattern); err != nil {
+				return fmt.Errorf("Gallery folder rule pattern is invalid: %s", err.Error())
+			}
+		}
+	}
+	return nil
+}
+
 // GetMaxSessionAge gets the maximum age for session cookies, in seconds.
 // Session cookie expiry times are refreshed every request.
 func GetMaxSessionAge() int {
@@ -612,6 +852,7 @@ func IsValid() bool {
 
 func setDefaultValues() {
 	viper.SetDefault(ParallelTasks, parallelTasksDefault)
+	viper.SetDefault(BackupCount, backupCountDefault)
 	viper.SetDefault(PreviewSegmentDuration, previewSegmentDurationDefault)
 	viper.SetDefault(PreviewSegments, previewSegmentsDefault)
 	viper.SetDefault(PreviewExcludeStart, previewExcludeStartDefault)
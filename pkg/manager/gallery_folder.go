@@ -0,0 +1,385 @@
+package manager
+
+import (
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/gallery"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// defaultGalleryFolderRules create galleries from every folder with images.
+var defaultGalleryFolderRules = []*models.GalleryFolderRule{{}}
+
+// getGalleryFolderRules returns the configured gallery folder rules, or the
+// default rules if none are configured.
+func getGalleryFolderRules() []*models.GalleryFolderRule {
+	rules := config.GetGalleryFolderRules()
+	if len(rules) == 0 {
+		return defaultGalleryFolderRules
+	}
+	return rules
+}
+
+// galleryFolder is a folder that a gallery folder rule applies to.
+type galleryFolder struct {
+	path string
+	// recursive is true if the images in the subfolders of the folder are
+	// added to its gallery.
+	recursive bool
+	minImages int
+	// fields are the values of the named groups of the rule pattern.
+	fields map[string]string
+}
+
+// galleryFolderRoot returns the directory containing dir that the depth of
+// the rule is relative to, or an empty string if the rule does not apply
+// to dir.
+func galleryFolderRoot(dir string, stashPaths []string, rule *models.GalleryFolderRule) string {
+	if rule.Path != nil && *rule.Path != "" {
+		if isDirInDir(*rule.Path, dir) {
+			return *rule.Path
+		}
+		return ""
+	}
+
+	for _, stashPath := range stashPaths {
+		if isDirInDir(stashPath, dir) {
+			return stashPath
+		}
+	}
+	return ""
+}
+
+func isDirInDir(parent string, dir string) bool {
+	rel, err := filepath.Rel(parent, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// folderHierarchy returns root and the folders below it down to dir, so
+// that the index of each folder is its depth below root.
+func folderHierarchy(root string, dir string) []string {
+	ret := []string{root}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return ret
+	}
+
+	current := root
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, name)
+		ret = append(ret, current)
+	}
+	return ret
+}
+
+// galleryFolderCandidates returns the folder of each rule that applies to
+// the images in dir, in the order of the rules. Rules that merge subfolders
+// apply to the highest folder that matches them.
+func galleryFolderCandidates(dir string, stashPaths []string, rules []*models.GalleryFolderRule) []galleryFolder {
+	var ret []galleryFolder
+	for _, rule := range rules {
+		root := galleryFolderRoot(dir, stashPaths, rule)
+		if root == "" {
+			continue
+		}
+
+		var re *regexp.Regexp
+		if rule.Pattern != nil && *rule.Pattern != "" {
+			var err error
+			re, err = regexp.Compile(*rule.Pattern)
+			if err != nil {
+				logger.Warnf("invalid gallery folder rule pattern %s: %s", *rule.Pattern, err.Error())
+				continue
+			}
+		}
+
+		folders := folderHierarchy(root, dir)
+		start := len(folders) - 1
+		if rule.MergeSubfolders {
+			start = 0
+		}
+
+		for depth := start; depth < len(folders); depth++ {
+			if rule.Depth != 0 && depth != rule.Depth {
+				continue
+			}
+
+			folder := galleryFolder{
+				path:      folders[depth],
+				recursive: rule.MergeSubfolders,
+				minImages: rule.MinImages,
+				fields:    make(map[string]string),
+			}
+
+			if re != nil {
+				match := re.FindStringSubmatch(filepath.Base(folder.path))
+				if match == nil {
+					continue
+				}
+
+				for i, name := range re.SubexpNames() {
+					if name != "" && match[i] != "" {
+						folder.fields[name] = match[i]
+					}
+				}
+			}
+
+			ret = append(ret, folder)
+			break
+		}
+	}
+
+	return ret
+}
+
+var errMinImagesFound = errors.New("minimum number of images found")
+
+// folderHasMinImages returns true if the folder, and its subfolders if
+// recursive is true, contain at least min images.
+func folderHasMinImages(path string, recursive bool, min int) bool {
+	// the image being added is in the folder
+	if min <= 1 {
+		return true
+	}
+
+	count := 0
+	if !recursive {
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return false
+		}
+
+		for _, f := range files {
+			if !f.IsDir() && isImage(f.Name()) {
+				count++
+			}
+		}
+		return count >= min
+	}
+
+	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if !info.IsDir() && isImage(info.Name()) {
+			count++
+			if count >= min {
+				return errMinImagesFound
+			}
+		}
+		return nil
+	})
+
+	return count >= min
+}
+
+// parseFolderDate returns the date in the name of a folder in the database
+// date format. Dates may be separated by dashes, dots, underscores or
+// spaces, or not at all.
+func parseFolderDate(s string) (string, bool) {
+	s = strings.NewReplacer(".", "-", "_", "-", " ", "-").Replace(strings.TrimSpace(s))
+
+	if d, err := utils.ParseDateStringAsFormat(s, "2006-01-02"); err == nil {
+		return d, true
+	}
+
+	if t, err := time.Parse("20060102", s); err == nil {
+		return t.Format("2006-01-02"), true
+	}
+
+	return "", false
+}
+
+// splitFolderNames splits the performer names in the name of a folder.
+func splitFolderNames(s string) []string {
+	var ret []string
+	for _, name := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '&'
+	}) {
+		if name = strings.TrimSpace(name); name != "" {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
+
+// createFolderGallery creates the gallery of the folder. The title, date,
+// studio and performers are set from the fields of the folder. Studios and
+// performers that do not exist are not created.
+func createFolderGallery(r models.Repository, folder galleryFolder) (*models.Gallery, error) {
+	currentTime := time.Now()
+	newGallery := models.Gallery{
+		Checksum: utils.MD5FromString(folder.path),
+		Path: sql.NullString{
+			String: folder.path,
+			Valid:  true,
+		},
+		CreatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+		UpdatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+	}
+
+	if title := folder.fields["title"]; title != "" {
+		newGallery.Title = sql.NullString{String: title, Valid: true}
+	}
+
+	if date := folder.fields["date"]; date != "" {
+		if d, ok := parseFolderDate(date); ok {
+			newGallery.Date = models.SQLiteDate{String: d, Valid: true}
+		} else {
+			logger.Warnf("Invalid date %s in folder %s", date, folder.path)
+		}
+	}
+
+	if name := folder.fields["studio"]; name != "" {
+		studio, err := r.Studio().FindByName(strings.TrimSpace(name), true)
+		if err != nil {
+			return nil, err
+		}
+
+		if studio != nil {
+			newGallery.StudioID = sql.NullInt64{Int64: int64(studio.ID), Valid: true}
+		} else {
+			logger.Infof("Studio %s of folder %s not found", name, folder.path)
+		}
+	}
+
+	logger.Infof("Creating gallery for folder %s", folder.path)
+	g, err := r.Gallery().Create(newGallery)
+	if err != nil {
+		return nil, err
+	}
+
+	if names := splitFolderNames(folder.fields["performer"]); len(names) > 0 {
+		performers, err := r.Performer().FindByNames(names, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(performers) < len(names) {
+			logger.Infof("Not all performers of folder %s were found", folder.path)
+		}
+
+		var performerIDs []int
+		for _, p := range performers {
+			performerIDs = utils.IntAppendUnique(performerIDs, p.ID)
+		}
+
+		if err := r.Gallery().UpdatePerformers(g.ID, performerIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+// findFolderGallery returns the gallery of the folder of the first rule
+// that applies to the image with the provided path. The gallery is created
+// if the folder has the minimum number of images of the rule, otherwise the
+// next rule is tried. Returns nil if no rule applies.
+func findFolderGallery(r models.Repository, imagePath string, rules []*models.GalleryFolderRule) (*models.Gallery, error) {
+	var stashPaths []string
+	for _, s := range config.GetStashPaths() {
+		stashPaths = append(stashPaths, s.Path)
+	}
+
+	for _, folder := range galleryFolderCandidates(filepath.Dir(imagePath), stashPaths, rules) {
+		g, err := r.Gallery().FindByPath(folder.path)
+		if err != nil {
+			return nil, err
+		}
+
+		if g != nil {
+			return g, nil
+		}
+
+		if !folderHasMinImages(folder.path, folder.recursive, folder.minImages) {
+			continue
+		}
+
+		return createFolderGallery(r, folder)
+	}
+
+	return nil, nil
+}
+
+// setFolderGallery adds the image to the gallery of the first gallery
+// folder rule that applies to it, and removes it from the galleries of its
+// other folders. Returns the ids of the galleries that the image was removed
+// from, and true if the galleries of the image were changed.
+func setFolderGallery(r models.Repository, i *models.Image, rules []*models.GalleryFolderRule) ([]int, bool, error) {
+	qb := r.Gallery()
+	g, err := findFolderGallery(r, i.Path, rules)
+	if err != nil {
+		return nil, false, err
+	}
+
+	galleries, err := qb.FindByImageID(i.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var removed []int
+	found := false
+	dir := filepath.Dir(i.Path)
+	for _, other := range galleries {
+		if g != nil && other.ID == g.ID {
+			found = true
+			continue
+		}
+
+		if other.Zip || !other.Path.Valid || !isDirInDir(other.Path.String, dir) {
+			continue
+		}
+
+		if err := gallery.RemoveImage(qb, other.ID, i.ID); err != nil {
+			return nil, false, err
+		}
+		removed = append(removed, other.ID)
+	}
+
+	changed := len(removed) > 0
+	if g != nil && !found {
+		if err := gallery.AddImage(qb, g.ID, i.ID); err != nil {
+			return nil, false, err
+		}
+		changed = true
+	}
+
+	return removed, changed, nil
+}
+
+// destroyEmptyFolderGallery destroys the folder gallery with the provided id
+// if it no longer has any images. Returns true if the gallery was destroyed.
+func destroyEmptyFolderGallery(r models.Repository, galleryID int) (bool, error) {
+	g, err := r.Gallery().Find(galleryID)
+	if err != nil || g == nil {
+		return false, err
+	}
+
+	if g.Zip || !g.Path.Valid {
+		return false, nil
+	}
+
+	count, err := r.Image().CountByGalleryID(galleryID)
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	logger.Infof("Deleting empty gallery for folder %s", g.Path.String)
+	if err := r.Gallery().Destroy(galleryID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
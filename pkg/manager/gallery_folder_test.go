@@ -0,0 +1,156 @@
+package manager
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGalleryFolderCandidates(t *testing.T) {
+	stash := "stash"
+	other := "other"
+	stashPaths := []string{stash, other}
+
+	dir := filepath.Join(stash, "2021-03-04 Alice & Bob", "set", "raw")
+	pattern := `^(?P<date>\d{4}-\d{2}-\d{2}) (?P<performer>.*)$`
+	otherPath := other
+
+	tests := []struct {
+		name  string
+		dir   string
+		rules []*models.GalleryFolderRule
+		want  []galleryFolder
+	}{
+		{
+			"default",
+			dir,
+			defaultGalleryFolderRules,
+			[]galleryFolder{{path: dir, fields: map[string]string{}}},
+		},
+		{
+			"depth",
+			dir,
+			[]*models.GalleryFolderRule{{Depth: 2}, {Depth: 3, MinImages: 5}},
+			[]galleryFolder{{path: dir, minImages: 5, fields: map[string]string{}}},
+		},
+		{
+			"merge depth",
+			dir,
+			[]*models.GalleryFolderRule{{Depth: 2, MergeSubfolders: true}},
+			[]galleryFolder{{path: filepath.Join(stash, "2021-03-04 Alice & Bob", "set"), recursive: true, fields: map[string]string{}}},
+		},
+		{
+			"merge pattern",
+			dir,
+			[]*models.GalleryFolderRule{{Pattern: &pattern, MergeSubfolders: true}},
+			[]galleryFolder{{
+				path:      filepath.Join(stash, "2021-03-04 Alice & Bob"),
+				recursive: true,
+				fields: map[string]string{
+					"date":      "2021-03-04",
+					"performer": "Alice & Bob",
+				},
+			}},
+		},
+		{
+			"pattern not matched",
+			dir,
+			[]*models.GalleryFolderRule{{Pattern: &pattern}},
+			nil,
+		},
+		{
+			"path",
+			dir,
+			[]*models.GalleryFolderRule{{Path: &otherPath}},
+			nil,
+		},
+		{
+			"stash root",
+			other,
+			[]*models.GalleryFolderRule{{Path: &otherPath}},
+			[]galleryFolder{{path: other, fields: map[string]string{}}},
+		},
+		{
+			"outside stashes",
+			filepath.Join("elsewhere", "folder"),
+			defaultGalleryFolderRules,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, galleryFolderCandidates(tt.dir, stashPaths, tt.rules), tt.name)
+	}
+}
+
+func TestParseFolderDate(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+		ok   bool
+	}{
+		{"2021-03-04", "2021-03-04", true},
+		{"2021.03.04", "2021-03-04", true},
+		{"2021_03_04", "2021-03-04", true},
+		{"20210304", "2021-03-04", true},
+		{"2021-13-04", "", false},
+		{"March", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseFolderDate(tt.s)
+		assert.Equal(t, tt.want, got, tt.s)
+		assert.Equal(t, tt.ok, ok, tt.s)
+	}
+}
+
+func TestSplitFolderNames(t *testing.T) {
+	assert.Equal(t, []string{"Alice", "Bob", "Carol"}, splitFolderNames("Alice, Bob & Carol"))
+	assert.Nil(t, splitFolderNames(" , "))
+}
+
+func TestDestroyEmptyFolderGallery(t *testing.T) {
+	const (
+		emptyGalleryID = iota + 1
+		nonEmptyGalleryID
+		zipGalleryID
+		userGalleryID
+	)
+
+	path := sql.NullString{String: "folder", Valid: true}
+
+	r := mocks.NewTransactionManager()
+	gqb := r.Gallery().(*mocks.GalleryReaderWriter)
+	iqb := r.Image().(*mocks.ImageReaderWriter)
+
+	gqb.On("Find", emptyGalleryID).Return(&models.Gallery{ID: emptyGalleryID, Path: path}, nil).Once()
+	gqb.On("Find", nonEmptyGalleryID).Return(&models.Gallery{ID: nonEmptyGalleryID, Path: path}, nil).Once()
+	gqb.On("Find", zipGalleryID).Return(&models.Gallery{ID: zipGalleryID, Path: path, Zip: true}, nil).Once()
+	gqb.On("Find", userGalleryID).Return(&models.Gallery{ID: userGalleryID}, nil).Once()
+	iqb.On("CountByGalleryID", emptyGalleryID).Return(0, nil).Once()
+	iqb.On("CountByGalleryID", nonEmptyGalleryID).Return(1, nil).Once()
+	gqb.On("Destroy", emptyGalleryID).Return(nil).Once()
+
+	tests := []struct {
+		galleryID int
+		want      bool
+	}{
+		{emptyGalleryID, true},
+		{nonEmptyGalleryID, false},
+		{zipGalleryID, false},
+		{userGalleryID, false},
+	}
+
+	for _, tt := range tests {
+		got, err := destroyEmptyFolderGallery(r, tt.galleryID)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, got, tt.galleryID)
+	}
+
+	gqb.AssertExpectations(t)
+	iqb.AssertExpectations(t)
+}
@@ -1,25 +1,198 @@
 package manager
 
 import (
-	"archive/zip"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
+// GetImageThumbnailSize returns the configured image thumbnail size closest
+// to the requested size. This is the smallest size at least as large as the
+// requested size, or the largest size if there are none. The first size is
+// returned if the requested size is zero.
+func GetImageThumbnailSize(requested int) int {
+	sizes := config.GetImageThumbnailSizes()
+	if requested <= 0 {
+		return sizes[0]
+	}
+
+	ret := 0
+	largest := 0
+	for _, size := range sizes {
+		if size >= requested && (ret == 0 || size < ret) {
+			ret = size
+		}
+		if size > largest {
+			largest = size
+		}
+	}
+
+	if ret == 0 {
+		return largest
+	}
+	return ret
+}
+
+// GetImageThumbnailPath returns the path of the generated thumbnail of the
+// provided size for the provided image. Thumbnails of animated images are
+// animated WebP images.
+func GetImageThumbnailPath(i *models.Image, size int) string {
+	if i.IsAnimated {
+		return GetInstance().Paths.Generated.GetAnimatedThumbnailPath(i.Checksum, size)
+	}
+
+	return GetInstance().Paths.Generated.GetThumbnailPath(i.Checksum, size, string(getImageThumbnailFormat()))
+}
+
+func getImageThumbnailFormat() image.ResizeFormat {
+	return image.ResizeFormat(strings.ToLower(config.GetImageThumbnailFormat().String()))
+}
+
 // DeleteGeneratedImageFiles deletes generated files for the provided image.
+// Thumbnails of all sizes and formats are deleted, including those that are
+// no longer configured.
 func DeleteGeneratedImageFiles(image *models.Image) {
-	thumbPath := GetInstance().Paths.Generated.GetThumbnailPath(image.Checksum, models.DefaultGthumbWidth)
+	removeThumbnails(GetInstance().Paths.Generated.GetThumbnailPattern(image.Checksum), nil)
+}
+
+// removeThumbnails removes the thumbnail files matching the glob pattern,
+// except for those in keep.
+func removeThumbnails(pattern string, keep []string) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.Warnf("Could not find thumbnails matching %s: %s", pattern, err.Error())
+		return
+	}
+
+	for _, thumbPath := range matches {
+		if utils.StrInclude(keep, thumbPath) {
+			continue
+		}
+
+		if err := os.Remove(thumbPath); err != nil {
+			logger.Warnf("Could not delete file %s: %s", thumbPath, err.Error())
+		}
+	}
+}
+
+// GenerateImageThumbnails generates the thumbnails of all configured sizes
+// for the provided image. Existing thumbnails are generated again if
+// overwrite is true. Thumbnails of sizes or formats that are no longer
+// configured are deleted.
+func GenerateImageThumbnails(i *models.Image, overwrite bool) error {
+	var thumbPaths []string
+	for _, size := range config.GetImageThumbnailSizes() {
+		if _, err := GenerateImageThumbnail(i, size, overwrite); err != nil {
+			return err
+		}
+		thumbPaths = append(thumbPaths, GetImageThumbnailPath(i, size))
+	}
+
+	removeThumbnails(GetInstance().Paths.Generated.GetThumbnailPattern(i.Checksum), thumbPaths)
+	return nil
+}
+
+// GenerateImageThumbnail generates the thumbnail of the provided size for
+// the provided image if it does not exist or overwrite is true, and the
+// image is too large or needs to be transformed to display the right way up.
+// Returns true if the thumbnail exists afterwards.
+func GenerateImageThumbnail(i *models.Image, size int, overwrite bool) (bool, error) {
+	thumbPath := GetImageThumbnailPath(i, size)
 	exists, _ := utils.FileExists(thumbPath)
 	if exists {
-		err := os.Remove(thumbPath)
-		if err != nil {
-			logger.Warnf("Could not delete file %s: %s", thumbPath, err.Error())
+		if !overwrite {
+			return true, nil
 		}
+
+		// remove the existing thumbnail, in case it is no longer needed
+		if err := os.Remove(thumbPath); err != nil {
+			return false, err
+		}
+	}
+
+	if i.IsAnimated {
+		return generateAnimatedImageThumbnail(i, thumbPath, size)
+	}
+
+	srcImage, err := image.GetSourceImage(i)
+	if err != nil {
+		return false, fmt.Errorf("error reading image %s: %s", i.Path, err.Error())
+	}
+
+	orientation := int(i.Orientation.Int64)
+	if !image.OrientedThumbnailNeeded(srcImage, orientation, size) {
+		return false, nil
+	}
+
+	options := image.ResizeOptions{
+		Width:   size,
+		Height:  size,
+		Format:  getImageThumbnailFormat(),
+		Filter:  image.ResizeFilter(strings.ToLower(config.GetImageThumbnailFilter().String())),
+		Quality: config.GetImageThumbnailQuality(),
+		Encoder: ffmpeg.NewEncoder(GetInstance().FFMPEGPath),
+		TmpDir:  GetInstance().Paths.Generated.Tmp,
+	}
+	if options.Format != image.ResizeFormatJPEG {
+		GetInstance().Paths.Generated.EnsureTmpDir()
+	}
+
+	data, err := image.GetOrientedThumbnail(srcImage, orientation, options)
+	if err != nil {
+		return false, fmt.Errorf("error getting thumbnail for image %s: %s", i.Path, err.Error())
+	}
+
+	if err := utils.WriteFile(thumbPath, data); err != nil {
+		return false, fmt.Errorf("error writing thumbnail for image %s: %s", i.Path, err.Error())
+	}
+
+	return true, nil
+}
+
+func generateAnimatedImageThumbnail(i *models.Image, thumbPath string, size int) (bool, error) {
+	// ffmpeg cannot read files in zip files, so the original file is served
+	// instead
+	if image.IsZipPath(i.Path) {
+		return false, nil
+	}
+
+	// small animated images are served as is, but clips always need a
+	// thumbnail to be displayed as an image
+	maxSize := int64(size)
+	if !image.IsClip(i) && i.Width.Int64 <= maxSize && i.Height.Int64 <= maxSize {
+		return false, nil
+	}
+
+	if err := utils.EnsureDirAll(filepath.Dir(thumbPath)); err != nil {
+		return false, err
 	}
+
+	encoder := ffmpeg.NewEncoder(GetInstance().FFMPEGPath)
+	probeResult := ffmpeg.VideoFile{
+		Path:     i.Path,
+		Duration: i.Duration.Float64,
+	}
+	options := ffmpeg.AnimatedThumbnailOptions{
+		OutputPath: thumbPath,
+		MaxSize:    size,
+		Quality:    config.GetImageThumbnailQuality(),
+	}
+
+	if err := encoder.AnimatedThumbnail(probeResult, options); err != nil {
+		// don't leave a partially written thumbnail
+		os.Remove(thumbPath)
+		return false, fmt.Errorf("error generating animated thumbnail for image %s: %s", i.Path, err.Error())
+	}
+
+	return true, nil
 }
 
 // DeleteImageFile deletes the image file from the filesystem.
@@ -30,23 +203,21 @@ func DeleteImageFile(image *models.Image) {
 	}
 }
 
-func walkGalleryZip(path string, walkFunc func(file *zip.File) error) error {
-	readCloser, err := zip.OpenReader(path)
+func walkGalleryArchive(path string, walkFunc func(file image.ArchiveFile) error) error {
+	a, err := image.OpenArchive(path)
 	if err != nil {
 		return err
 	}
-	defer readCloser.Close()
-
-	for _, file := range readCloser.File {
-		if file.FileInfo().IsDir() {
-			continue
-		}
+	defer a.Close()
 
+	for _, file := range a.Files() {
 		if strings.Contains(file.Name, "__MACOSX") {
 			continue
 		}
 
-		if !isImage(file.Name) {
+		// ffmpeg cannot read files in zip files, so video clips in zip files
+		// are not scanned as images
+		if !isImage(file.Name) || isVideo(file.Name) {
 			continue
 		}
 
@@ -59,9 +230,9 @@ func walkGalleryZip(path string, walkFunc func(file *zip.File) error) error {
 	return nil
 }
 
-func countImagesInZip(path string) int {
+func countImagesInArchive(path string) int {
 	ret := 0
-	walkGalleryZip(path, func(file *zip.File) error {
+	walkGalleryArchive(path, func(file image.ArchiveFile) error {
 		ret++
 		return nil
 	})
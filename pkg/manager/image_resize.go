@@ -0,0 +1,206 @@
+package manager
+
+import (
+	"container/list"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// ResizeFormatSupported returns true if resized images can be encoded in
+// the provided format. WebP and AVIF images require ffmpeg to support them.
+func ResizeFormatSupported(format image.ResizeFormat) bool {
+	if format == image.ResizeFormatJPEG || format == image.ResizeFormatPNG {
+		return true
+	}
+
+	encoder := ffmpeg.NewEncoder(GetInstance().FFMPEGPath)
+	return encoder.SupportsImageFormat(string(format))
+}
+
+// GetResizedImage returns the image file resized with the provided options.
+// Resized images are cached in the generated directory.
+func GetResizedImage(i *models.Image, options image.ResizeOptions) ([]byte, error) {
+	return getResizedImage(i.Checksum, options, func(options image.ResizeOptions) ([]byte, error) {
+		return image.ResizeFile(i, options)
+	})
+}
+
+// GetResizedImageData returns the encoded image data resized with the
+// provided options. Resized images are cached in the generated directory.
+func GetResizedImageData(data []byte, options image.ResizeOptions) ([]byte, error) {
+	return getResizedImage(utils.MD5FromBytes(data), options, func(options image.ResizeOptions) ([]byte, error) {
+		return image.ResizeData(data, options)
+	})
+}
+
+func getResizedImage(checksum string, options image.ResizeOptions, resize func(options image.ResizeOptions) ([]byte, error)) ([]byte, error) {
+	key := fmt.Sprintf("%s_%dx%d_%s", checksum, options.Width, options.Height, options.Fit)
+	path := GetInstance().Paths.Generated.GetResizedImagePath(utils.MD5FromString(key), string(options.Format))
+
+	if data := resizedImages.get(path); data != nil {
+		return data, nil
+	}
+
+	options.Encoder = ffmpeg.NewEncoder(GetInstance().FFMPEGPath)
+	options.TmpDir = GetInstance().Paths.Generated.Tmp
+	GetInstance().Paths.Generated.EnsureTmpDir()
+
+	data, err := resize(options)
+	if err != nil {
+		return nil, err
+	}
+
+	resizedImages.add(path, data)
+	return data, nil
+}
+
+// resizedImageCache is a least recently used cache of resized image files.
+// The order of use is kept in the modification times of the files, so that
+// it is kept after restarting.
+type resizedImageCache struct {
+	// getDir returns the directory of the cached files
+	getDir func() string
+	// getMaxSize returns the maximum total size in bytes of the cached files
+	getMaxSize func() int64
+
+	mutex   sync.Mutex
+	dir     string
+	entries *list.List
+	index   map[string]*list.Element
+	size    int64
+}
+
+type resizedImageCacheEntry struct {
+	path string
+	size int64
+}
+
+var resizedImages = &resizedImageCache{
+	getDir: func() string {
+		return GetInstance().Paths.Generated.ResizedImages
+	},
+	getMaxSize: func() int64 {
+		return int64(config.GetResizedImageCacheSize()) * 1024 * 1024
+	},
+}
+
+// load reads the existing files of the cache if its directory has changed.
+func (c *resizedImageCache) load() {
+	dir := c.getDir()
+	if c.dir == dir && c.entries != nil {
+		return
+	}
+
+	c.dir = dir
+	c.entries = list.New()
+	c.index = make(map[string]*list.Element)
+	c.size = 0
+
+	var files []os.FileInfo
+	var paths []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, info)
+			paths = append(paths, path)
+		}
+		return nil
+	})
+
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return files[order[i]].ModTime().After(files[order[j]].ModTime())
+	})
+
+	for _, i := range order {
+		c.index[paths[i]] = c.entries.PushBack(&resizedImageCacheEntry{
+			path: paths[i],
+			size: files[i].Size(),
+		})
+		c.size += files[i].Size()
+	}
+}
+
+func (c *resizedImageCache) remove(e *list.Element) {
+	entry := e.Value.(*resizedImageCacheEntry)
+	c.entries.Remove(e)
+	delete(c.index, entry.path)
+	c.size -= entry.size
+}
+
+// get returns the contents of the cached file with the provided path, or nil
+// if it is not cached.
+func (c *resizedImageCache) get(path string) []byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.load()
+
+	e := c.index[path]
+	if e == nil {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		c.remove(e)
+		return nil
+	}
+
+	c.entries.MoveToFront(e)
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return data
+}
+
+// add writes the file to the cache, then deletes the least recently used
+// files while the cache is larger than its maximum size.
+func (c *resizedImageCache) add(path string, data []byte) {
+	maxSize := c.getMaxSize()
+	if maxSize <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.load()
+
+	if err := utils.WriteFile(path, data); err != nil {
+		logger.Warnf("error writing resized image: %s", err.Error())
+		return
+	}
+
+	if e := c.index[path]; e != nil {
+		c.remove(e)
+	}
+	c.index[path] = c.entries.PushFront(&resizedImageCacheEntry{
+		path: path,
+		size: int64(len(data)),
+	})
+	c.size += int64(len(data))
+
+	for c.size > maxSize && c.entries.Len() > 0 {
+		e := c.entries.Back()
+		entry := e.Value.(*resizedImageCacheEntry)
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			logger.Warnf("error deleting resized image %s: %s", entry.path, err.Error())
+		}
+		c.remove(e)
+	}
+}
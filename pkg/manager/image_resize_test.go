@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizedImageCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resized")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &resizedImageCache{
+		getDir: func() string {
+			return dir
+		},
+		getMaxSize: func() int64 {
+			return 10
+		},
+	}
+
+	pathA := filepath.Join(dir, "a", "a.jpeg")
+	pathB := filepath.Join(dir, "b", "b.jpeg")
+	pathC := filepath.Join(dir, "c", "c.jpeg")
+
+	assert.Nil(t, c.get(pathA))
+
+	c.add(pathA, []byte("aaaa"))
+	c.add(pathB, []byte("bbbb"))
+	assert.Equal(t, []byte("aaaa"), c.get(pathA))
+
+	// b is the least recently used, so it is deleted
+	c.add(pathC, []byte("cccc"))
+	assert.Nil(t, c.get(pathB))
+	_, err = os.Stat(pathB)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, []byte("aaaa"), c.get(pathA))
+	assert.Equal(t, []byte("cccc"), c.get(pathC))
+
+	// existing files are read when the directory is first used
+	reloaded := &resizedImageCache{
+		getDir:     c.getDir,
+		getMaxSize: c.getMaxSize,
+	}
+	assert.Equal(t, []byte("cccc"), reloaded.get(pathC))
+	assert.Equal(t, int64(8), reloaded.size)
+
+	// files larger than the cache are not kept
+	reloaded.add(pathB, []byte("bbbbbbbbbbbb"))
+	assert.Nil(t, reloaded.get(pathB))
+	assert.Equal(t, int64(0), reloaded.size)
+}
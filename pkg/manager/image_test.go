@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetImageThumbnailSize(t *testing.T) {
+	config.Set(config.ImageThumbnailSizes, []int{640, 320, 1280})
+	defer config.Set(config.ImageThumbnailSizes, nil)
+
+	tests := []struct {
+		requested int
+		want      int
+	}{
+		{0, 640},
+		{100, 320},
+		{320, 320},
+		{400, 640},
+		{1000, 1280},
+		{2000, 1280},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, GetImageThumbnailSize(tt.requested), tt.requested)
+	}
+}
+
+func TestRemoveThumbnails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thumbnails")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keep := filepath.Join(dir, "checksum_640.webp")
+	stale := []string{
+		filepath.Join(dir, "checksum_640.jpg"),
+		filepath.Join(dir, "checksum_320.webp"),
+	}
+	other := filepath.Join(dir, "other_640.webp")
+
+	for _, f := range append(stale, keep, other) {
+		if err := ioutil.WriteFile(f, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removeThumbnails(filepath.Join(dir, "checksum_*"), []string{keep})
+
+	for _, f := range stale {
+		_, err := os.Stat(f)
+		assert.True(t, os.IsNotExist(err), f)
+	}
+	assert.FileExists(t, keep)
+	assert.FileExists(t, other)
+
+	removeThumbnails(filepath.Join(dir, "checksum_*"), nil)
+	_, err = os.Stat(keep)
+	assert.True(t, os.IsNotExist(err))
+	assert.FileExists(t, other)
+}
+
+func TestImageFolderCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-folder-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newImageFolderCache()
+	assert.False(t, c.hasImages(dir))
+
+	// the result is cached for the scan
+	if err := ioutil.WriteFile(filepath.Join(dir, "image.jpg"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, c.hasImages(dir))
+	assert.True(t, newImageFolderCache().hasImages(dir))
+
+	var nilCache *imageFolderCache
+	assert.True(t, nilCache.hasImages(dir))
+}
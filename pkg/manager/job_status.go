@@ -3,16 +3,20 @@ package manager
 type JobStatus int
 
 const (
-	Idle            JobStatus = 0
-	Import          JobStatus = 1
-	Export          JobStatus = 2
-	Scan            JobStatus = 3
-	Generate        JobStatus = 4
-	Clean           JobStatus = 5
-	Scrape          JobStatus = 6
-	AutoTag         JobStatus = 7
-	Migrate         JobStatus = 8
-	PluginOperation JobStatus = 9
+	Idle                    JobStatus = 0
+	Import                  JobStatus = 1
+	Export                  JobStatus = 2
+	Scan                    JobStatus = 3
+	Generate                JobStatus = 4
+	Clean                   JobStatus = 5
+	Scrape                  JobStatus = 6
+	AutoTag                 JobStatus = 7
+	Migrate                 JobStatus = 8
+	PluginOperation         JobStatus = 9
+	CheckIntegrity          JobStatus = 10
+	MigrateBlobs            JobStatus = 11
+	ApplyGalleryFolderRules JobStatus = 12
+	RestoreDatabase         JobStatus = 13
 )
 
 func (s JobStatus) String() string {
@@ -37,6 +41,14 @@ func (s JobStatus) String() string {
 		statusMessage = "Clean"
 	case PluginOperation:
 		statusMessage = "Plugin Operation"
+	case CheckIntegrity:
+		statusMessage = "Check Integrity"
+	case MigrateBlobs:
+		statusMessage = "Migrate Blobs"
+	case ApplyGalleryFolderRules:
+		statusMessage = "Apply Gallery Folder Rules"
+	case RestoreDatabase:
+		statusMessage = "Restore Database"
 	}
 
 	return statusMessage
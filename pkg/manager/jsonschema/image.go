@@ -16,18 +16,19 @@ type ImageFile struct {
 }
 
 type Image struct {
-	Title      string          `json:"title,omitempty"`
-	Checksum   string          `json:"checksum,omitempty"`
-	Studio     string          `json:"studio,omitempty"`
-	Rating     int             `json:"rating,omitempty"`
-	Organized  bool            `json:"organized,omitempty"`
-	OCounter   int             `json:"o_counter,omitempty"`
-	Galleries  []string        `json:"galleries,omitempty"`
-	Performers []string        `json:"performers,omitempty"`
-	Tags       []string        `json:"tags,omitempty"`
-	File       *ImageFile      `json:"file,omitempty"`
-	CreatedAt  models.JSONTime `json:"created_at,omitempty"`
-	UpdatedAt  models.JSONTime `json:"updated_at,omitempty"`
+	Title        string                 `json:"title,omitempty"`
+	Checksum     string                 `json:"checksum,omitempty"`
+	Studio       string                 `json:"studio,omitempty"`
+	Rating       int                    `json:"rating,omitempty"`
+	Organized    bool                   `json:"organized,omitempty"`
+	OCounter     int                    `json:"o_counter,omitempty"`
+	Galleries    []string               `json:"galleries,omitempty"`
+	Performers   []string               `json:"performers,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	File         *ImageFile             `json:"file,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+	CreatedAt    models.JSONTime        `json:"created_at,omitempty"`
+	UpdatedAt    models.JSONTime        `json:"updated_at,omitempty"`
 }
 
 func LoadImageFile(filePath string) (*Image, error) {
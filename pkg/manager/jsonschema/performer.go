@@ -9,26 +9,28 @@ import (
 )
 
 type Performer struct {
-	Name         string          `json:"name,omitempty"`
-	Gender       string          `json:"gender,omitempty"`
-	URL          string          `json:"url,omitempty"`
-	Twitter      string          `json:"twitter,omitempty"`
-	Instagram    string          `json:"instagram,omitempty"`
-	Birthdate    string          `json:"birthdate,omitempty"`
-	Ethnicity    string          `json:"ethnicity,omitempty"`
-	Country      string          `json:"country,omitempty"`
-	EyeColor     string          `json:"eye_color,omitempty"`
-	Height       string          `json:"height,omitempty"`
-	Measurements string          `json:"measurements,omitempty"`
-	FakeTits     string          `json:"fake_tits,omitempty"`
-	CareerLength string          `json:"career_length,omitempty"`
-	Tattoos      string          `json:"tattoos,omitempty"`
-	Piercings    string          `json:"piercings,omitempty"`
-	Aliases      string          `json:"aliases,omitempty"`
-	Favorite     bool            `json:"favorite,omitempty"`
-	Image        string          `json:"image,omitempty"`
-	CreatedAt    models.JSONTime `json:"created_at,omitempty"`
-	UpdatedAt    models.JSONTime `json:"updated_at,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	Gender       string                 `json:"gender,omitempty"`
+	URL          string                 `json:"url,omitempty"`
+	Twitter      string                 `json:"twitter,omitempty"`
+	Instagram    string                 `json:"instagram,omitempty"`
+	Birthdate    string                 `json:"birthdate,omitempty"`
+	Ethnicity    string                 `json:"ethnicity,omitempty"`
+	Country      string                 `json:"country,omitempty"`
+	EyeColor     string                 `json:"eye_color,omitempty"`
+	Height       string                 `json:"height,omitempty"`
+	Measurements string                 `json:"measurements,omitempty"`
+	FakeTits     string                 `json:"fake_tits,omitempty"`
+	CareerLength string                 `json:"career_length,omitempty"`
+	Tattoos      string                 `json:"tattoos,omitempty"`
+	Piercings    string                 `json:"piercings,omitempty"`
+	Aliases      string                 `json:"aliases,omitempty"`
+	Favorite     bool                   `json:"favorite,omitempty"`
+	Image        string                 `json:"image,omitempty"`
+	Images       []string               `json:"images,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+	CreatedAt    models.JSONTime        `json:"created_at,omitempty"`
+	UpdatedAt    models.JSONTime        `json:"updated_at,omitempty"`
 }
 
 func LoadPerformerFile(filePath string) (*Performer, error) {
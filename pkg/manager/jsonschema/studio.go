@@ -9,12 +9,13 @@ import (
 )
 
 type Studio struct {
-	Name         string          `json:"name,omitempty"`
-	URL          string          `json:"url,omitempty"`
-	ParentStudio string          `json:"parent_studio,omitempty"`
-	Image        string          `json:"image,omitempty"`
-	CreatedAt    models.JSONTime `json:"created_at,omitempty"`
-	UpdatedAt    models.JSONTime `json:"updated_at,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	URL          string                 `json:"url,omitempty"`
+	ParentStudio string                 `json:"parent_studio,omitempty"`
+	Image        string                 `json:"image,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+	CreatedAt    models.JSONTime        `json:"created_at,omitempty"`
+	UpdatedAt    models.JSONTime        `json:"updated_at,omitempty"`
 }
 
 func LoadStudioFile(filePath string) (*Studio, error) {
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/manager/paths"
@@ -74,6 +75,10 @@ func Initialize() *singleton {
 		}
 
 		initFFMPEG()
+
+		if !image.HasLibarchive() {
+			logger.Warn("bsdtar was not found. Install libarchive to scan rar and 7z files as galleries.")
+		}
 	})
 
 	return instance
@@ -190,9 +195,10 @@ func initPluginCache() *plugin.Cache {
 // initScraperCache initializes a new scraper cache and returns it.
 func (s *singleton) initScraperCache() *scraper.Cache {
 	scraperConfig := scraper.GlobalConfig{
-		Path:      config.GetScrapersPath(),
-		UserAgent: config.GetScraperUserAgent(),
-		CDPPath:   config.GetScraperCDPPath(),
+		Path:        config.GetScrapersPath(),
+		UserAgent:   config.GetScraperUserAgent(),
+		CDPPath:     config.GetScraperCDPPath(),
+		Credentials: config.GetScraperCredentials(),
 	}
 	ret, err := scraper.NewCache(scraperConfig, s.TxnManager)
 
@@ -213,6 +219,13 @@ func (s *singleton) RefreshConfig() {
 		utils.EnsureDir(s.Paths.Generated.Downloads)
 		paths.EnsureJSONDirs(config.GetMetadataPath())
 	}
+
+	sqlite.SetBlobStoreOptions(sqlite.BlobStoreOptions{
+		UseFilesystem: config.GetBlobsStorage() == models.BlobsStorageTypeFilesystem,
+		Path:          config.GetBlobsPath(),
+	})
+
+	s.RefreshBackupSchedule()
 }
 
 // RefreshScraperCache refreshes the scraper cache. Call this when scraper
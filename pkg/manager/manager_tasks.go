@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/remeh/sizedwaitgroup"
 
+	"github.com/stashapp/stash/pkg/database"
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sqlite"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
@@ -41,6 +45,10 @@ type TaskStatus struct {
 	total      int
 }
 
+// taskStatusMutex guards setting the task status. TaskStatus values are
+// copied, so the mutex cannot be a field.
+var taskStatusMutex sync.Mutex
+
 func (t *TaskStatus) Stop() bool {
 	t.stopping = true
 	t.updated()
@@ -48,8 +56,26 @@ func (t *TaskStatus) Stop() bool {
 }
 
 func (t *TaskStatus) SetStatus(s JobStatus) {
+	taskStatusMutex.Lock()
+	defer taskStatusMutex.Unlock()
+
+	t.Status = s
+	t.updated()
+}
+
+// setStatusIfIdle sets the status if no job is running. Returns false if a
+// job is running. The status is checked and set atomically.
+func (t *TaskStatus) setStatusIfIdle(s JobStatus) bool {
+	taskStatusMutex.Lock()
+	defer taskStatusMutex.Unlock()
+
+	if t.Status != Idle {
+		return false
+	}
+
 	t.Status = s
 	t.updated()
+	return true
 }
 
 func (t *TaskStatus) setProgress(upTo int, total int) {
@@ -188,6 +214,7 @@ func (s *singleton) Scan(input models.ScanMetadataInput) {
 		s.Status.Progress = 0
 		fileNamingAlgo := config.GetVideoFileNamingAlgorithm()
 		calculateMD5 := config.IsCalculateMD5()
+		imageFolders := newImageFolderCache()
 
 		i := 0
 		stoppingErr := errors.New("stopping")
@@ -222,6 +249,8 @@ func (s *singleton) Scan(input models.ScanMetadataInput) {
 					GeneratePreview:      utils.IsTrue(input.ScanGeneratePreviews),
 					GenerateImagePreview: utils.IsTrue(input.ScanGenerateImagePreviews),
 					GenerateSprite:       utils.IsTrue(input.ScanGenerateSprites),
+					GenerateImagePhash:   utils.IsTrue(input.ScanGenerateImagePhashes),
+					imageFolders:         imageFolders,
 				}
 				go task.Start(&wg)
 
@@ -375,6 +404,10 @@ func (s *singleton) Generate(input models.GenerateMetadataInput) {
 	if err != nil {
 		logger.Error(err.Error())
 	}
+	galleryIDs, err := utils.StringSliceToIntSlice(input.GalleryIDs)
+	if err != nil {
+		logger.Error(err.Error())
+	}
 
 	go func() {
 		defer s.returnToIdleState()
@@ -382,6 +415,7 @@ func (s *singleton) Generate(input models.GenerateMetadataInput) {
 		var scenes []*models.Scene
 		var err error
 		var markers []*models.SceneMarker
+		var images []*models.Image
 
 		if err := s.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
 			qb := r.Scene()
@@ -402,6 +436,13 @@ func (s *singleton) Generate(input models.GenerateMetadataInput) {
 				}
 			}
 
+			if utils.IsTrue(input.ImagePhashes) || utils.IsTrue(input.ImageThumbnails) {
+				images, err = findGenerateImages(r.Image(), galleryIDs)
+				if err != nil {
+					return err
+				}
+			}
+
 			return nil
 		}); err != nil {
 			logger.Error(err.Error())
@@ -415,7 +456,8 @@ func (s *singleton) Generate(input models.GenerateMetadataInput) {
 
 		s.Status.Progress = 0
 		lenScenes := len(scenes)
-		total := lenScenes + len(markers)
+		lenMarkers := len(markers)
+		total := lenScenes + lenMarkers + len(images)
 
 		if s.Status.stopping {
 			logger.Info("Stopping due to user request")
@@ -529,12 +571,67 @@ func (s *singleton) Generate(input models.GenerateMetadataInput) {
 
 		wg.Wait()
 
+		for i, image := range images {
+			s.Status.setProgress(lenScenes+lenMarkers+i, total)
+			if s.Status.stopping {
+				logger.Info("Stopping due to user request")
+				return
+			}
+
+			if utils.IsTrue(input.ImagePhashes) {
+				wg.Add()
+				task := GenerateImagePhashTask{
+					txnManager: s.TxnManager,
+					Image:      *image,
+					Overwrite:  overwrite,
+				}
+				go task.Start(&wg)
+			}
+
+			if utils.IsTrue(input.ImageThumbnails) {
+				wg.Add()
+				task := GenerateImageThumbnailTask{
+					Image:     *image,
+					Overwrite: overwrite,
+				}
+				go task.Start(&wg)
+			}
+		}
+
+		wg.Wait()
+
 		instance.Paths.Generated.EmptyTmpDir()
 		elapsed := time.Since(start)
 		logger.Info(fmt.Sprintf("Generate finished (%s)", elapsed))
 	}()
 }
 
+// findGenerateImages returns the images of the provided galleries, or all
+// images if no galleries are provided.
+func findGenerateImages(qb models.ImageReader, galleryIDs []int) ([]*models.Image, error) {
+	if len(galleryIDs) == 0 {
+		return qb.All()
+	}
+
+	var ret []*models.Image
+	found := make(map[int]bool)
+	for _, galleryID := range galleryIDs {
+		images, err := qb.FindByGalleryID(galleryID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, image := range images {
+			if !found[image.ID] {
+				found[image.ID] = true
+				ret = append(ret, image)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
 func (s *singleton) GenerateDefaultScreenshot(sceneId string) {
 	s.generateScreenshot(sceneId, nil)
 }
@@ -919,6 +1016,8 @@ func (s *singleton) Clean(input models.CleanMetadataInput) {
 			wg.Wait()
 		}
 
+		s.cleanBlobs(input.DryRun)
+
 		logger.Info("Finished Cleaning")
 	}()
 }
@@ -973,6 +1072,191 @@ func (s *singleton) MigrateHash() {
 	}()
 }
 
+// MigrateBlobs moves the performer, studio, tag, movie and scene images to
+// the configured blobs storage, then deletes the images that are no longer
+// used.
+func (s *singleton) MigrateBlobs() {
+	if s.Status.Status != Idle {
+		return
+	}
+	s.Status.SetStatus(MigrateBlobs)
+	s.Status.indefiniteProgress()
+
+	go func() {
+		defer s.returnToIdleState()
+
+		storage := config.GetBlobsStorage()
+		logger.Infof("Moving images to the %s", strings.ToLower(storage.String()))
+
+		moved, err := sqlite.MigrateBlobs(func(done int, total int) bool {
+			s.Status.setProgress(done, total)
+			return !s.Status.stopping
+		})
+		if err != nil {
+			logger.Errorf("Error moving images: %s", err.Error())
+			return
+		}
+		logger.Infof("Moved %d images", moved)
+
+		if s.Status.stopping {
+			logger.Info("Stopping due to user request")
+			return
+		}
+
+		s.cleanBlobs(false)
+
+		if storage == models.BlobsStorageTypeFilesystem && moved > 0 {
+			// reclaim the space of the images moved out of the database
+			logger.Info("Performing vacuum on database")
+			if _, err := database.DB.Exec("VACUUM"); err != nil {
+				logger.Warnf("error while performing vacuum: %s", err.Error())
+			}
+		}
+
+		logger.Info("Finished moving images")
+	}()
+}
+
+// cleanBlobs deletes the images that are no longer used.
+func (s *singleton) cleanBlobs(dryRun bool) {
+	blobs, files, err := sqlite.CleanBlobs(dryRun)
+	if err != nil {
+		logger.Errorf("Error deleting unused images: %s", err.Error())
+		return
+	}
+
+	if dryRun {
+		logger.Infof("Found %d unused images and %d unused image files", blobs, files)
+	} else if blobs > 0 || files > 0 {
+		logger.Infof("Deleted %d unused images and %d unused image files", blobs, files)
+	}
+}
+
+// ApplyGalleryFolderRules adds the images that are not in zip files to the
+// galleries of the gallery folder rules, and deletes the folder galleries
+// that no longer have any images. Returns an error if galleries are not
+// created from folders.
+func (s *singleton) ApplyGalleryFolderRules() error {
+	if !config.GetCreateGalleriesFromFolders() {
+		return errors.New("creating galleries from folders is not enabled")
+	}
+
+	if s.Status.Status != Idle {
+		return nil
+	}
+	s.Status.SetStatus(ApplyGalleryFolderRules)
+	s.Status.indefiniteProgress()
+
+	go func() {
+		defer s.returnToIdleState()
+
+		var images []*models.Image
+		if err := s.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+			var err error
+			images, err = r.Image().All()
+			return err
+		}); err != nil {
+			logger.Errorf("Error getting images: %s", err.Error())
+			return
+		}
+
+		rules := getGalleryFolderRules()
+		updated := 0
+		var removedFrom []int
+		for i, img := range images {
+			s.Status.setProgress(i, len(images))
+			if s.Status.stopping {
+				logger.Info("Stopping due to user request")
+				return
+			}
+
+			if image.IsZipPath(img.Path) {
+				continue
+			}
+
+			if err := s.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+				removed, changed, err := setFolderGallery(r, img, rules)
+				if err != nil {
+					return err
+				}
+				if changed {
+					updated++
+				}
+				removedFrom = utils.IntAppendUniques(removedFrom, removed)
+				return nil
+			}); err != nil {
+				logger.Errorf("Error applying gallery folder rules to %s: %s", img.Path, err.Error())
+			}
+		}
+
+		logger.Infof("Updated the galleries of %d images", updated)
+
+		deleted := 0
+		for _, galleryID := range removedFrom {
+			if err := s.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+				destroyed, err := destroyEmptyFolderGallery(r, galleryID)
+				if destroyed {
+					deleted++
+				}
+				return err
+			}); err != nil {
+				logger.Errorf("Error deleting empty gallery: %s", err.Error())
+			}
+		}
+
+		logger.Infof("Deleted %d empty galleries", deleted)
+	}()
+
+	return nil
+}
+
+func (s *singleton) CheckIntegrity(repair bool) {
+	if s.Status.Status != Idle {
+		return
+	}
+	s.Status.SetStatus(CheckIntegrity)
+	s.Status.indefiniteProgress()
+
+	go func() {
+		defer s.returnToIdleState()
+
+		if repair {
+			logger.Info("Checking and repairing database integrity")
+		} else {
+			logger.Info("Checking database integrity")
+		}
+
+		report, err := database.CheckIntegrity(repair, config.GetBlobsPath())
+		if err != nil {
+			logger.Errorf("Error checking database integrity: %s", err.Error())
+			return
+		}
+
+		for _, e := range report.Errors {
+			logger.Errorf("Integrity check: %s", e)
+		}
+		for _, o := range report.Orphans {
+			if repair {
+				logger.Infof("Repaired %d %s", o.Count, o.Description)
+			} else {
+				logger.Warnf("Found %d %s", o.Count, o.Description)
+			}
+		}
+		for _, v := range report.ForeignKeyViolations {
+			logger.Warnf("Found %d %s", v.Count, v.Description)
+		}
+		for _, checksum := range report.MissingBlobs {
+			logger.Warnf("Found missing file of image %s in %s", checksum, config.GetBlobsPath())
+		}
+
+		if report.OK() {
+			logger.Info("No database integrity problems found")
+		}
+
+		logger.Info("Finished checking database integrity")
+	}()
+}
+
 func (s *singleton) returnToIdleState() {
 	if r := recover(); r != nil {
 		logger.Info("recovered from ", r)
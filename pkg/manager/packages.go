@@ -0,0 +1,35 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/pkgmgr"
+)
+
+// GetPackageManager returns the package manager used to install packages
+// of the provided type.
+func (s *singleton) GetPackageManager(packageType models.PackageType) (*pkgmgr.Manager, error) {
+	switch packageType {
+	case models.PackageTypeScraper:
+		return pkgmgr.NewManager(config.GetScraperPackageIndex(), config.GetScrapersPath()), nil
+	case models.PackageTypePlugin:
+		return pkgmgr.NewManager(config.GetPluginPackageIndex(), config.GetPluginsPath()), nil
+	}
+
+	return nil, fmt.Errorf("unsupported package type: %s", packageType)
+}
+
+// ReloadPackages reloads the scrapers or plugins after the packages of the
+// provided type have changed.
+func (s *singleton) ReloadPackages(packageType models.PackageType) error {
+	switch packageType {
+	case models.PackageTypeScraper:
+		return s.ScraperCache.ReloadScrapers()
+	case models.PackageTypePlugin:
+		return s.PluginCache.ReloadPlugins()
+	}
+
+	return nil
+}
@@ -13,19 +13,21 @@ const thumbDirDepth int = 2
 const thumbDirLength int = 2 // thumbDirDepth * thumbDirLength must be smaller than the length of checksum
 
 type generatedPaths struct {
-	Screenshots string
-	Thumbnails  string
-	Vtt         string
-	Markers     string
-	Transcodes  string
-	Downloads   string
-	Tmp         string
+	Screenshots   string
+	Thumbnails    string
+	ResizedImages string
+	Vtt           string
+	Markers       string
+	Transcodes    string
+	Downloads     string
+	Tmp           string
 }
 
 func newGeneratedPaths() *generatedPaths {
 	gp := generatedPaths{}
 	gp.Screenshots = filepath.Join(config.GetGeneratedPath(), "screenshots")
 	gp.Thumbnails = filepath.Join(config.GetGeneratedPath(), "thumbnails")
+	gp.ResizedImages = filepath.Join(config.GetGeneratedPath(), "resized")
 	gp.Vtt = filepath.Join(config.GetGeneratedPath(), "vtt")
 	gp.Markers = filepath.Join(config.GetGeneratedPath(), "markers")
 	gp.Transcodes = filepath.Join(config.GetGeneratedPath(), "transcodes")
@@ -62,7 +64,32 @@ func (gp *generatedPaths) TempDir(pattern string) (string, error) {
 	return ret, nil
 }
 
-func (gp *generatedPaths) GetThumbnailPath(checksum string, width int) string {
-	fname := fmt.Sprintf("%s_%d.jpg", checksum, width)
+// GetThumbnailPath returns the path of the image thumbnail of the provided
+// width in the format with the provided extension. JPEG thumbnails have a
+// jpg extension.
+func (gp *generatedPaths) GetThumbnailPath(checksum string, width int, extension string) string {
+	if extension == "jpeg" {
+		extension = "jpg"
+	}
+	fname := fmt.Sprintf("%s_%d.%s", checksum, width, extension)
 	return filepath.Join(gp.Thumbnails, utils.GetIntraDir(checksum, thumbDirDepth, thumbDirLength), fname)
 }
+
+// GetThumbnailPattern returns a glob pattern matching the paths of the image
+// thumbnails of all widths and formats for the provided checksum.
+func (gp *generatedPaths) GetThumbnailPattern(checksum string) string {
+	fname := fmt.Sprintf("%s_*", checksum)
+	return filepath.Join(gp.Thumbnails, utils.GetIntraDir(checksum, thumbDirDepth, thumbDirLength), fname)
+}
+
+func (gp *generatedPaths) GetAnimatedThumbnailPath(checksum string, width int) string {
+	fname := fmt.Sprintf("%s_%d.webp", checksum, width)
+	return filepath.Join(gp.Thumbnails, utils.GetIntraDir(checksum, thumbDirDepth, thumbDirLength), fname)
+}
+
+// GetResizedImagePath returns the path of a cached resized image. The hash
+// identifies the source image and the resize options.
+func (gp *generatedPaths) GetResizedImagePath(hash string, extension string) string {
+	fname := fmt.Sprintf("%s.%s", hash, extension)
+	return filepath.Join(gp.ResizedImages, utils.GetIntraDir(hash, thumbDirDepth, thumbDirLength), fname)
+}
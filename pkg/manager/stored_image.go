@@ -0,0 +1,25 @@
+package manager
+
+import (
+	"strings"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// ProcessStoredImage crops and resizes performer, studio and tag image data
+// with the provided options, and encodes it in the configured stored image
+// format.
+func ProcessStoredImage(data []byte, options image.ProcessOptions) ([]byte, error) {
+	if format := config.GetStoredImageFormat(); format != models.StoredImageFormatOriginal {
+		options.Format = image.ResizeFormat(strings.ToLower(format.String()))
+	}
+
+	options.Encoder = ffmpeg.NewEncoder(GetInstance().FFMPEGPath)
+	options.TmpDir = GetInstance().Paths.Generated.Tmp
+	GetInstance().Paths.Generated.EnsureTmpDir()
+
+	return image.ProcessData(data, options)
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/stashapp/stash/pkg/audit"
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/scene"
@@ -95,7 +96,12 @@ func (t *AutoTagPerformerTask) autoTagPerformer() {
 		}
 
 		for _, s := range scenes {
-			added, err := scene.AddPerformer(qb, s.ID, t.performer.ID)
+			var added bool
+			err := audit.Update(r, models.AuditSourceEnumAutoTag, models.AuditEntityEnumScene, s.ID, func() error {
+				var err error
+				added, err = scene.AddPerformer(qb, s.ID, t.performer.ID)
+				return err
+			})
 
 			if err != nil {
 				return fmt.Errorf("Error adding performer '%s' to scene '%s': %s", t.performer.Name.String, s.GetTitle(), err.Error())
@@ -150,7 +156,10 @@ func (t *AutoTagStudioTask) autoTagStudio() {
 				StudioID: &studioID,
 			}
 
-			if _, err := qb.Update(scenePartial); err != nil {
+			if err := audit.Update(r, models.AuditSourceEnumAutoTag, models.AuditEntityEnumScene, s.ID, func() error {
+				_, err := qb.Update(scenePartial)
+				return err
+			}); err != nil {
 				return fmt.Errorf("Error adding studio to scene: %s", err.Error())
 			}
 		}
@@ -184,7 +193,12 @@ func (t *AutoTagTagTask) autoTagTag() {
 		}
 
 		for _, s := range scenes {
-			added, err := scene.AddTag(qb, s.ID, t.tag.ID)
+			var added bool
+			err := audit.Update(r, models.AuditSourceEnumAutoTag, models.AuditEntityEnumScene, s.ID, func() error {
+				var err error
+				added, err = scene.AddTag(qb, s.ID, t.tag.ID)
+				return err
+			})
 
 			if err != nil {
 				return fmt.Errorf("Error adding tag '%s' to scene '%s': %s", t.tag.Name, s.GetTitle(), err.Error())
@@ -102,7 +102,7 @@ func (t *CleanTask) shouldCleanGallery(g *models.Gallery) bool {
 		return true
 	}
 
-	if countImagesInZip(path) == 0 {
+	if countImagesInArchive(path) == 0 {
 		logger.Infof("Gallery has 0 images. Cleaning: \"%s\"", path)
 		return true
 	}
@@ -121,7 +121,17 @@ func (t *CleanTask) shouldCleanImage(s *models.Image) bool {
 		return true
 	}
 
-	if !matchExtension(s.Path, config.GetImageExtensions()) {
+	if image.IsClip(s) {
+		if !config.GetImageClips() {
+			logger.Infof("Video clip and image clips are disabled. Cleaning: \"%s\"", s.Path)
+			return true
+		}
+
+		if !matchExtension(s.Path, config.GetVideoExtensions()) {
+			logger.Infof("File extension does not match video extensions. Cleaning: \"%s\"", s.Path)
+			return true
+		}
+	} else if !matchExtension(s.Path, config.GetImageExtensions()) {
 		logger.Infof("File extension does not match image extensions. Cleaning: \"%s\"", s.Path)
 		return true
 	}
@@ -178,10 +188,7 @@ func (t *CleanTask) deleteImage(imageID int) {
 		return
 	}
 
-	pathErr := os.Remove(GetInstance().Paths.Generated.GetThumbnailPath(t.Image.Checksum, models.DefaultGthumbWidth)) // remove cache dir of gallery
-	if pathErr != nil {
-		logger.Errorf("Error deleting thumbnail image from cache: %s", pathErr)
-	}
+	DeleteGeneratedImageFiles(t.Image)
 }
 
 func (t *CleanTask) fileExists(filename string) (bool, error) {
@@ -376,6 +376,12 @@ func exportScene(wg *sync.WaitGroup, jobChan <-chan *models.Scene, repo models.R
 			continue
 		}
 
+		newSceneJSON.CustomFields, err = sceneReader.GetCustomFields(s.ID)
+		if err != nil {
+			logger.Errorf("[scenes] <%s> error getting scene custom fields: %s", sceneHash, err.Error())
+			continue
+		}
+
 		newSceneJSON.Studio, err = scene.GetStudioName(studioReader, s)
 		if err != nil {
 			logger.Errorf("[scenes] <%s> error getting scene studio name: %s", sceneHash, err.Error())
@@ -497,6 +503,7 @@ func (t *ExportTask) ExportImages(workers int, repo models.ReaderRepository) {
 
 func exportImage(wg *sync.WaitGroup, jobChan <-chan *models.Image, repo models.ReaderRepository, t *ExportTask) {
 	defer wg.Done()
+	imageReader := repo.Image()
 	studioReader := repo.Studio()
 	galleryReader := repo.Gallery()
 	performerReader := repo.Performer()
@@ -514,6 +521,12 @@ func exportImage(wg *sync.WaitGroup, jobChan <-chan *models.Image, repo models.R
 			continue
 		}
 
+		newImageJSON.CustomFields, err = imageReader.GetCustomFields(s.ID)
+		if err != nil {
+			logger.Errorf("[images] <%s> error getting image custom fields: %s", imageHash, err.Error())
+			continue
+		}
+
 		imageGalleries, err := galleryReader.FindByImageID(s.ID)
 		if err != nil {
 			logger.Errorf("[images] <%s> error getting image galleries: %s", imageHash, err.Error())
@@ -617,6 +630,8 @@ func (t *ExportTask) ExportGalleries(workers int, repo models.ReaderRepository)
 
 func exportGallery(wg *sync.WaitGroup, jobChan <-chan *models.Gallery, repo models.ReaderRepository, t *ExportTask) {
 	defer wg.Done()
+	galleryReader := repo.Gallery()
+	imageReader := repo.Image()
 	studioReader := repo.Studio()
 	performerReader := repo.Performer()
 	tagReader := repo.Tag()
@@ -630,6 +645,12 @@ func exportGallery(wg *sync.WaitGroup, jobChan <-chan *models.Gallery, repo mode
 			continue
 		}
 
+		newGalleryJSON.CustomFields, err = galleryReader.GetCustomFields(g.ID)
+		if err != nil {
+			logger.Errorf("[galleries] <%s> error getting gallery custom fields: %s", galleryHash, err.Error())
+			continue
+		}
+
 		newGalleryJSON.Studio, err = gallery.GetStudioName(studioReader, g)
 		if err != nil {
 			logger.Errorf("[galleries] <%s> error getting gallery studio name: %s", galleryHash, err.Error())
@@ -652,6 +673,24 @@ func exportGallery(wg *sync.WaitGroup, jobChan <-chan *models.Gallery, repo mode
 
 		newGalleryJSON.Tags = tag.GetNames(tags)
 
+		newGalleryJSON.Cover, err = gallery.GetCoverChecksum(imageReader, g)
+		if err != nil {
+			logger.Errorf("[galleries] <%s> error getting gallery cover: %s", galleryHash, err.Error())
+			continue
+		}
+
+		newGalleryJSON.ImageOrder, err = gallery.GetImageOrderChecksums(galleryReader, imageReader, g.ID)
+		if err != nil {
+			logger.Errorf("[galleries] <%s> error getting gallery image order: %s", galleryHash, err.Error())
+			continue
+		}
+
+		newGalleryJSON.Chapters, err = gallery.GetChaptersJSON(galleryReader, g.ID)
+		if err != nil {
+			logger.Errorf("[galleries] <%s> error getting gallery chapters: %s", galleryHash, err.Error())
+			continue
+		}
+
 		if t.includeDependencies {
 			if g.StudioID.Valid {
 				t.studios.IDs = utils.IntAppendUnique(t.studios.IDs, int(g.StudioID.Int64))
@@ -725,6 +764,12 @@ func (t *ExportTask) exportPerformer(wg *sync.WaitGroup, jobChan <-chan *models.
 			continue
 		}
 
+		newPerformerJSON.CustomFields, err = performerReader.GetCustomFields(p.ID)
+		if err != nil {
+			logger.Errorf("[performers] <%s> error getting performer custom fields: %s", p.Checksum, err.Error())
+			continue
+		}
+
 		performerJSON, err := t.json.getPerformer(p.Checksum)
 		if err != nil {
 			logger.Debugf("[performers] error reading performer json: %s", err.Error())
@@ -792,6 +837,12 @@ func (t *ExportTask) exportStudio(wg *sync.WaitGroup, jobChan <-chan *models.Stu
 			continue
 		}
 
+		newStudioJSON.CustomFields, err = studioReader.GetCustomFields(s.ID)
+		if err != nil {
+			logger.Errorf("[studios] <%s> error getting studio custom fields: %s", s.Checksum, err.Error())
+			continue
+		}
+
 		studioJSON, err := t.json.getStudio(s.Checksum)
 		if err == nil && jsonschema.CompareJSON(*studioJSON, *newStudioJSON) {
 			continue
@@ -929,6 +980,12 @@ func (t *ExportTask) exportMovie(wg *sync.WaitGroup, jobChan <-chan *models.Movi
 			continue
 		}
 
+		newMovieJSON.CustomFields, err = movieReader.GetCustomFields(m.ID)
+		if err != nil {
+			logger.Errorf("[movies] <%s> error getting movie custom fields: %s", m.Checksum, err.Error())
+			continue
+		}
+
 		if t.includeDependencies {
 			if m.StudioID.Valid {
 				t.studios.IDs = utils.IntAppendUnique(t.studios.IDs, int(m.StudioID.Int64))
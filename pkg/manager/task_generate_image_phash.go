@@ -0,0 +1,52 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/remeh/sizedwaitgroup"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+type GenerateImagePhashTask struct {
+	txnManager models.TransactionManager
+	Image      models.Image
+	Overwrite  bool
+}
+
+func (t *GenerateImagePhashTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
+	defer wg.Done()
+
+	if !t.required() {
+		return
+	}
+
+	hash, err := image.CalculatePerceptualHash(&t.Image)
+	if err != nil {
+		logger.Errorf("error generating perceptual hash for %s: %s", t.Image.Path, err.Error())
+		return
+	}
+
+	if err := t.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		phash := sql.NullInt64{Int64: int64(hash), Valid: true}
+		_, err := r.Image().Update(models.ImagePartial{
+			ID:    t.Image.ID,
+			Phash: &phash,
+		})
+		return err
+	}); err != nil {
+		logger.Errorf("error setting perceptual hash for %s: %s", t.Image.Path, err.Error())
+	}
+}
+
+func (t *GenerateImagePhashTask) required() bool {
+	// video clips are not hashed
+	if image.IsClip(&t.Image) {
+		return false
+	}
+
+	return t.Overwrite || !t.Image.Phash.Valid
+}
@@ -0,0 +1,21 @@
+package manager
+
+import (
+	"github.com/remeh/sizedwaitgroup"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+type GenerateImageThumbnailTask struct {
+	Image     models.Image
+	Overwrite bool
+}
+
+func (t *GenerateImageThumbnailTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
+	defer wg.Done()
+
+	if err := GenerateImageThumbnails(&t.Image, t.Overwrite); err != nil {
+		logger.Errorf("error generating thumbnails for %s: %s", t.Image.Path, err.Error())
+	}
+}
@@ -139,6 +139,7 @@ func (t *ImportTask) Start(wg *sync.WaitGroup) {
 	t.ImportScrapedItems(ctx)
 	t.ImportScenes(ctx)
 	t.ImportImages(ctx)
+	t.ImportGalleryImageOrder(ctx)
 }
 
 func (t *ImportTask) unzipFile() error {
@@ -374,6 +375,28 @@ func (t *ImportTask) ImportGalleries(ctx context.Context) {
 	logger.Info("[galleries] import complete")
 }
 
+// ImportGalleryImageOrder sets the cover images and image order of the
+// imported galleries, which reference the imported images.
+func (t *ImportTask) ImportGalleryImageOrder(ctx context.Context) {
+	logger.Info("[galleries] importing image order")
+
+	for _, mappingJSON := range t.mappings.Galleries {
+		galleryJSON, err := t.json.getGallery(mappingJSON.Checksum)
+		if err != nil {
+			logger.Errorf("[galleries] failed to read json: %s", err.Error())
+			continue
+		}
+
+		if err := t.txnManager.WithTxn(ctx, func(r models.Repository) error {
+			return gallery.ImportImageOrder(r.Gallery(), r.Image(), *galleryJSON)
+		}); err != nil {
+			logger.Errorf("[galleries] <%s> failed to import image order: %s", mappingJSON.Checksum, err.Error())
+		}
+	}
+
+	logger.Info("[galleries] image order import complete")
+}
+
 func (t *ImportTask) ImportTags(ctx context.Context) {
 	logger.Info("[tags] importing")
 
@@ -1,14 +1,15 @@
 package manager
 
 import (
-	"archive/zip"
 	"context"
 	"database/sql"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/remeh/sizedwaitgroup"
@@ -33,13 +34,23 @@ type ScanTask struct {
 	GenerateSprite       bool
 	GeneratePreview      bool
 	GenerateImagePreview bool
+	GenerateImagePhash   bool
 	zipGallery           *models.Gallery
+
+	// imageFolders caches which folders contain images for the scan
+	imageFolders *imageFolderCache
+
+	// videoFile is the probe result of the scanned video file, once it has
+	// been probed
+	videoFile *ffmpeg.VideoFile
 }
 
 func (t *ScanTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
 	if isGallery(t.FilePath) {
 		t.scanGallery()
-	} else if isVideo(t.FilePath) {
+	} else if isVideo(t.FilePath) && t.isImageClip() {
+		t.scanImage()
+	} else if isVideo(t.FilePath) && !t.isExcluded(true) {
 		s := t.scanScene()
 
 		if s != nil {
@@ -101,7 +112,7 @@ func (t *ScanTask) scanGallery() {
 		var err error
 		g, err = r.Gallery().FindByPath(t.FilePath)
 
-		if g != nil && err != nil {
+		if g != nil && err == nil {
 			images, err = r.Image().CountByGalleryID(g.ID)
 			if err != nil {
 				return fmt.Errorf("error getting images for zip gallery %s: %s", t.FilePath, err.Error())
@@ -233,11 +244,13 @@ func (t *ScanTask) scanGallery() {
 				}
 
 				// don't create gallery if it has no images
-				if countImagesInZip(t.FilePath) > 0 {
+				if countImagesInArchive(t.FilePath) > 0 {
 					// only warn when creating the gallery
-					ok, err := utils.IsZipFileUncompressed(t.FilePath)
-					if err == nil && !ok {
-						logger.Warnf("%s is using above store (0) level compression.", t.FilePath)
+					if format, _ := image.GetArchiveFormat(t.FilePath); format == image.ArchiveFormatZip {
+						ok, err := utils.IsZipFileUncompressed(t.FilePath)
+						if err == nil && !ok {
+							logger.Warnf("%s is using above store (0) level compression.", t.FilePath)
+						}
 					}
 
 					logger.Infof("%s doesn't exist.  Creating new item...", t.FilePath)
@@ -407,7 +420,7 @@ func (t *ScanTask) scanScene() *models.Scene {
 
 		// check for container
 		if !s.Format.Valid {
-			videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.FilePath, t.StripFileExtension)
+			videoFile, err := t.probeVideo()
 			if err != nil {
 				return logError(err)
 			}
@@ -475,7 +488,7 @@ func (t *ScanTask) scanScene() *models.Scene {
 		return nil
 	}
 
-	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.FilePath, t.StripFileExtension)
+	videoFile, err := t.probeVideo()
 	if err != nil {
 		logger.Error(err.Error())
 		return nil
@@ -608,7 +621,7 @@ func (t *ScanTask) rescanScene(s *models.Scene, fileModTime time.Time) (*models.
 	}
 
 	// regenerate the file details as well
-	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.FilePath, t.StripFileExtension)
+	videoFile, err := t.probeVideo()
 	if err != nil {
 		return nil, err
 	}
@@ -666,7 +679,7 @@ func (t *ScanTask) makeScreenshots(probeResult *ffmpeg.VideoFile, checksum strin
 
 	if probeResult == nil {
 		var err error
-		probeResult, err = ffmpeg.NewVideoFile(instance.FFProbePath, t.FilePath, t.StripFileExtension)
+		probeResult, err = t.probeVideo()
 
 		if err != nil {
 			logger.Error(err.Error())
@@ -689,7 +702,7 @@ func (t *ScanTask) makeScreenshots(probeResult *ffmpeg.VideoFile, checksum strin
 }
 
 func (t *ScanTask) scanZipImages(zipGallery *models.Gallery) {
-	err := walkGalleryZip(zipGallery.Path.String, func(file *zip.File) error {
+	err := walkGalleryArchive(zipGallery.Path.String, func(file image.ArchiveFile) error {
 		// copy this task and change the filename
 		subTask := *t
 
@@ -779,6 +792,16 @@ func (t *ScanTask) scanImage() {
 			}
 		}
 
+		// read the embedded metadata and format of images scanned before they
+		// were supported
+		if !i.Orientation.Valid || !i.Format.Valid {
+			i, err = t.updateImageMetadata(i)
+			if err != nil {
+				logger.Error(err.Error())
+				return
+			}
+		}
+
 		// We already have this item in the database
 		// check for thumbnails
 		t.generateThumbnail(i)
@@ -799,15 +822,26 @@ func (t *ScanTask) scanImage() {
 
 		// check for scene by checksum and oshash - MD5 should be
 		// redundant, but check both
+		var mergedInto *models.Image
 		if err := t.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
 			var err error
 			i, err = r.Image().FindByChecksum(checksum)
+			if err != nil || i != nil {
+				return err
+			}
+
+			mergedInto, err = r.Image().FindByMergedChecksum(checksum)
 			return err
 		}); err != nil {
 			logger.Error(err.Error())
 			return
 		}
 
+		if mergedInto != nil {
+			logger.Infof("%s was merged into %s. Skipping...", image.PathDisplayName(t.FilePath), image.PathDisplayName(mergedInto.Path))
+			return
+		}
+
 		if i != nil {
 			exists := image.FileExists(i.Path)
 			if exists {
@@ -845,10 +879,22 @@ func (t *ScanTask) scanImage() {
 				return
 			}
 
+			if err := t.setImageFormat(&newImage); err != nil {
+				logger.Error(err.Error())
+				return
+			}
+
+			metadata := t.readImageMetadata()
+			image.SetMetadata(&newImage, metadata)
+
 			if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
 				var err error
 				i, err = r.Image().Create(newImage)
-				return err
+				if err != nil {
+					return err
+				}
+
+				return t.updateImageKeywords(r, i.ID, metadata.Keywords)
 			}); err != nil {
 				logger.Error(err.Error())
 				return
@@ -867,7 +913,7 @@ func (t *ScanTask) scanImage() {
 			// create gallery from folder or associate with existing gallery
 			logger.Infof("Associating image %s with folder gallery", i.Path)
 			if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
-				return t.associateImageWithFolderGallery(i.ID, r.Gallery())
+				return t.associateImageWithFolderGallery(r, i)
 			}); err != nil {
 				logger.Error(err.Error())
 				return
@@ -877,6 +923,17 @@ func (t *ScanTask) scanImage() {
 
 	if i != nil {
 		t.generateThumbnail(i)
+
+		if t.GenerateImagePhash {
+			iwg := sizedwaitgroup.New(1)
+			iwg.Add()
+			taskPhash := GenerateImagePhashTask{
+				txnManager: t.TxnManager,
+				Image:      *i,
+				Overwrite:  false,
+			}
+			taskPhash.Start(&iwg)
+		}
 	}
 }
 
@@ -897,13 +954,28 @@ func (t *ScanTask) rescanImage(i *models.Image, fileModTime time.Time) (*models.
 		return nil, err
 	}
 
+	if err := t.setImageFormat(fileDetails); err != nil {
+		return nil, err
+	}
+
+	metadata := t.readImageMetadata()
+	image.SetMetadata(fileDetails, metadata)
+
 	currentTime := time.Now()
 	imagePartial := models.ImagePartial{
-		ID:       i.ID,
-		Checksum: &checksum,
-		Width:    &fileDetails.Width,
-		Height:   &fileDetails.Height,
-		Size:     &fileDetails.Size,
+		ID:          i.ID,
+		Checksum:    &checksum,
+		Width:       &fileDetails.Width,
+		Height:      &fileDetails.Height,
+		Size:        &fileDetails.Size,
+		Date:        &fileDetails.Date,
+		Orientation: &fileDetails.Orientation,
+		Camera:      &fileDetails.Camera,
+		Format:      &fileDetails.Format,
+		IsAnimated:  &fileDetails.IsAnimated,
+		Duration:    &fileDetails.Duration,
+		// the perceptual hash is calculated again if required
+		Phash: &sql.NullInt64{},
 		FileModTime: &models.NullSQLiteTimestamp{
 			Timestamp: fileModTime,
 			Valid:     true,
@@ -915,83 +987,288 @@ func (t *ScanTask) rescanImage(i *models.Image, fileModTime time.Time) (*models.
 	if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
 		var err error
 		ret, err = r.Image().Update(imagePartial)
-		return err
+		if err != nil {
+			return err
+		}
+
+		return t.updateImageKeywords(r, i.ID, metadata.Keywords)
 	}); err != nil {
 		return nil, err
 	}
 
-	// remove the old thumbnail if the checksum changed - we'll regenerate it
-	if oldChecksum != checksum {
-		err = os.Remove(GetInstance().Paths.Generated.GetThumbnailPath(oldChecksum, models.DefaultGthumbWidth)) // remove cache dir of gallery
-		if err != nil {
-			logger.Errorf("Error deleting thumbnail image: %s", err)
-		}
+	// remove the old thumbnail if the checksum changed, or if it was
+	// generated before the orientation was known - we'll regenerate it
+	if oldChecksum != checksum || (!i.Orientation.Valid && metadata.Orientation != image.OrientationNormal) {
+		DeleteGeneratedImageFiles(i)
 	}
 
 	return ret, nil
 }
 
-func (t *ScanTask) associateImageWithFolderGallery(imageID int, qb models.GalleryReaderWriter) error {
-	// find a gallery with the path specified
-	path := filepath.Dir(t.FilePath)
-	g, err := qb.FindByPath(path)
+// readImageMetadata returns the metadata embedded in the scanned image file.
+// Metadata that cannot be read is logged and treated as empty.
+func (t *ScanTask) readImageMetadata() *image.Metadata {
+	ret, err := image.ReadFileMetadata(t.FilePath)
 	if err != nil {
-		return err
+		logger.Warnf("error reading metadata for %s: %s", image.PathDisplayName(t.FilePath), err.Error())
+		return &image.Metadata{Orientation: image.OrientationNormal}
 	}
 
-	if g == nil {
-		checksum := utils.MD5FromString(path)
+	return ret
+}
 
-		// create the gallery
-		currentTime := time.Now()
+// updateImageMetadata reads the embedded metadata and format of an unmodified
+// image file if they have not been read, and stores them.
+func (t *ScanTask) updateImageMetadata(i *models.Image) (*models.Image, error) {
+	logger.Infof("reading metadata for %s", image.PathDisplayName(t.FilePath))
 
-		newGallery := models.Gallery{
-			Checksum: checksum,
-			Path: sql.NullString{
-				String: path,
-				Valid:  true,
-			},
-			CreatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
-			UpdatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+	updated := *i
+	imagePartial := models.ImagePartial{
+		ID:     i.ID,
+		Width:  &updated.Width,
+		Height: &updated.Height,
+	}
+
+	if !i.Format.Valid {
+		if err := t.setImageFormat(&updated); err != nil {
+			return nil, err
 		}
 
-		logger.Infof("Creating gallery for folder %s", path)
-		g, err = qb.Create(newGallery)
+		imagePartial.Format = &updated.Format
+		imagePartial.IsAnimated = &updated.IsAnimated
+		imagePartial.Duration = &updated.Duration
+	}
+
+	var metadata *image.Metadata
+	if !i.Orientation.Valid {
+		metadata = t.readImageMetadata()
+		image.SetMetadata(&updated, metadata)
+
+		imagePartial.Date = &updated.Date
+		imagePartial.Orientation = &updated.Orientation
+		imagePartial.Camera = &updated.Camera
+	}
+
+	var ret *models.Image
+	if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		var err error
+		ret, err = r.Image().Update(imagePartial)
+		if err != nil || metadata == nil {
+			return err
+		}
+
+		return t.updateImageKeywords(r, i.ID, metadata.Keywords)
+	}); err != nil {
+		return nil, err
+	}
+
+	// remove the existing thumbnail if it was not generated the right way up
+	if metadata != nil && metadata.Orientation != image.OrientationNormal {
+		DeleteGeneratedImageFiles(ret)
+	}
+
+	return ret, nil
+}
+
+// setImageFormat sets the format of the scanned image file. Video clips are
+// probed with ffprobe.
+func (t *ScanTask) setImageFormat(i *models.Image) error {
+	if isVideo(t.FilePath) {
+		videoFile, err := t.probeVideo()
 		if err != nil {
 			return err
 		}
+
+		image.SetClipDetails(i, videoFile)
+		return nil
 	}
 
-	// associate image with gallery
-	err = gallery.AddImage(qb, g.ID, imageID)
-	return err
+	format, err := image.ReadFileFormat(t.FilePath)
+	if err != nil {
+		return err
+	}
+
+	image.SetFormat(i, format)
+	return nil
 }
 
-func (t *ScanTask) generateThumbnail(i *models.Image) {
-	thumbPath := GetInstance().Paths.Generated.GetThumbnailPath(i.Checksum, models.DefaultGthumbWidth)
-	exists, _ := utils.FileExists(thumbPath)
-	if exists {
-		return
+// isImageClip returns true if the video file should be scanned as an image.
+// If configured, short video clips in folders with images are scanned as
+// images, unless they have already been scanned as scenes.
+func (t *ScanTask) isImageClip() bool {
+	// ffprobe cannot read files in zip files, so clips in zip files cannot be
+	// probed or thumbnailed
+	if !config.GetImageClips() || image.IsZipPath(t.FilePath) {
+		return false
 	}
 
-	srcImage, err := image.GetSourceImage(i)
+	var existingImage, existingScene bool
+	if err := t.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		i, err := r.Image().FindByPath(t.FilePath)
+		if err != nil {
+			return err
+		}
+		existingImage = i != nil
+
+		s, err := r.Scene().FindByPath(t.FilePath)
+		existingScene = s != nil
+		return err
+	}); err != nil {
+		logger.Error(err.Error())
+		return false
+	}
+
+	if existingImage || existingScene {
+		return existingImage
+	}
+
+	if t.isExcluded(false) || !t.imageFolders.hasImages(filepath.Dir(t.FilePath)) {
+		return false
+	}
+
+	videoFile, err := t.probeVideo()
 	if err != nil {
-		logger.Errorf("error reading image %s: %s", i.Path, err.Error())
-		return
+		logger.Error(err.Error())
+		return false
 	}
 
-	if image.ThumbnailNeeded(srcImage, models.DefaultGthumbWidth) {
-		data, err := image.GetThumbnail(srcImage, models.DefaultGthumbWidth)
+	return videoFile.Duration <= float64(config.GetImageClipMaxDuration())
+}
+
+// probeVideo returns the probe result of the scanned video file. The file is
+// only probed once by the task.
+func (t *ScanTask) probeVideo() (*ffmpeg.VideoFile, error) {
+	if t.videoFile == nil {
+		videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.FilePath, t.StripFileExtension)
 		if err != nil {
-			logger.Errorf("error getting thumbnail for image %s: %s", i.Path, err.Error())
-			return
+			return nil, err
+		}
+		t.videoFile = videoFile
+	}
+
+	return t.videoFile, nil
+}
+
+// isExcluded returns true if the library of the scanned file excludes videos
+// or images.
+func (t *ScanTask) isExcluded(video bool) bool {
+	stash := getStashFromPath(t.FilePath)
+	if stash == nil {
+		return false
+	}
+
+	if video {
+		return stash.ExcludeVideo
+	}
+	return stash.ExcludeImage
+}
+
+// imageFolderCache caches whether folders contain images, so that each
+// folder is only read once for a scan. It is safe for concurrent use. A nil
+// cache reads the folder every time.
+type imageFolderCache struct {
+	mutex   sync.Mutex
+	folders map[string]bool
+}
+
+func newImageFolderCache() *imageFolderCache {
+	return &imageFolderCache{
+		folders: make(map[string]bool),
+	}
+}
+
+func (c *imageFolderCache) hasImages(path string) bool {
+	if c == nil {
+		return folderHasImages(path)
+	}
+
+	c.mutex.Lock()
+	ret, found := c.folders[path]
+	c.mutex.Unlock()
+
+	if found {
+		return ret
+	}
+
+	ret = folderHasImages(path)
+
+	c.mutex.Lock()
+	c.folders[path] = ret
+	c.mutex.Unlock()
+
+	return ret
+}
+
+func folderHasImages(path string) bool {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return false
+	}
+
+	for _, f := range files {
+		if !f.IsDir() && isImage(f.Name()) {
+			return true
 		}
+	}
+
+	return false
+}
 
-		err = utils.WriteFile(thumbPath, data)
+// updateImageKeywords sets the keywords of the image. If configured, existing
+// tags with the same names as the keywords are added to the image.
+func (t *ScanTask) updateImageKeywords(r models.Repository, imageID int, keywords []string) error {
+	qb := r.Image()
+	if err := qb.UpdateKeywords(imageID, keywords); err != nil {
+		return err
+	}
+
+	if !config.GetImageKeywordsAsTags() || len(keywords) == 0 {
+		return nil
+	}
+
+	tagIDs, err := qb.GetTagIDs(imageID)
+	if err != nil {
+		return err
+	}
+
+	newTagIDs := tagIDs
+	for _, keyword := range keywords {
+		tag, err := r.Tag().FindByName(keyword, true)
 		if err != nil {
-			logger.Errorf("error writing thumbnail for image %s: %s", i.Path, err)
+			return err
+		}
+
+		if tag != nil {
+			newTagIDs = utils.IntAppendUnique(newTagIDs, tag.ID)
 		}
 	}
+
+	if len(newTagIDs) == len(tagIDs) {
+		return nil
+	}
+
+	return qb.UpdateTags(imageID, newTagIDs)
+}
+
+func (t *ScanTask) associateImageWithFolderGallery(r models.Repository, i *models.Image) error {
+	g, err := findFolderGallery(r, t.FilePath, getGalleryFolderRules())
+	if err != nil {
+		return err
+	}
+
+	if g == nil {
+		logger.Debugf("No gallery folder rule applies to %s", t.FilePath)
+		return nil
+	}
+
+	// associate image with gallery
+	return gallery.AddImage(r.Gallery(), g.ID, i.ID)
+}
+
+func (t *ScanTask) generateThumbnail(i *models.Image) {
+	if err := GenerateImageThumbnails(i, false); err != nil {
+		logger.Error(err.Error())
+	}
 }
 
 func (t *ScanTask) calculateChecksum() (string, error) {
@@ -1032,6 +1309,12 @@ func (t *ScanTask) doesPathExist() bool {
 			if s != nil {
 				ret = true
 			}
+
+			// video clips may be scanned as images
+			i, _ := r.Image().FindByPath(t.FilePath)
+			if i != nil {
+				ret = true
+			}
 		} else if matchExtension(t.FilePath, imgExt) {
 			i, _ := r.Image().FindByPath(t.FilePath)
 			if i != nil {
@@ -1075,7 +1358,10 @@ func walkFilesToScan(s *models.StashConfig, f filepath.WalkFunc) error {
 			return nil
 		}
 
-		if !s.ExcludeVideo && matchExtension(path, vidExt) && !matchFileRegex(path, excludeVidRegex) {
+		// video files may be scanned as image clips in libraries that exclude
+		// video
+		scanVideo := !s.ExcludeVideo || (!s.ExcludeImage && config.GetImageClips())
+		if scanVideo && matchExtension(path, vidExt) && !matchFileRegex(path, excludeVidRegex) {
 			return f(path, info, err)
 		}
 
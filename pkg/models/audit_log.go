@@ -0,0 +1,19 @@
+package models
+
+// ChangeSourceHeader is the HTTP header that clients set to the
+// AuditSourceEnum of the changes they make.
+const ChangeSourceHeader = "X-Stash-Change-Source"
+
+type AuditLogReader interface {
+	Find(id int) (*AuditLogEntry, error)
+	FindByEntity(entityType AuditEntityEnum, entityID int) ([]*AuditLogEntry, error)
+}
+
+type AuditLogWriter interface {
+	Create(newEntry AuditLogEntry) (*AuditLogEntry, error)
+}
+
+type AuditLogReaderWriter interface {
+	AuditLogReader
+	AuditLogWriter
+}
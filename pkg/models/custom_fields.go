@@ -0,0 +1,255 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	CustomFieldTypeString = "string"
+	CustomFieldTypeInt    = "int"
+	CustomFieldTypeDate   = "date"
+	CustomFieldTypeBool   = "bool"
+)
+
+// CustomFieldMaxNameLength is the maximum length of a custom field name.
+const CustomFieldMaxNameLength = 64
+
+var customFieldDateRE = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// CustomFieldMap maps custom field names to their values. Values are
+// strings, int64s or bools. Dates are strings in YYYY-MM-DD format. Values
+// may also be CustomFieldTypedValues, or maps with type and value keys, to
+// set their type explicitly.
+type CustomFieldMap map[string]interface{}
+
+// CustomFieldTypedValue is a custom field value with an explicit type. It
+// is encoded as its value.
+type CustomFieldTypedValue struct {
+	Type  string
+	Value interface{}
+}
+
+func (v CustomFieldTypedValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Value)
+}
+
+func isCustomFieldType(t string) bool {
+	switch t {
+	case CustomFieldTypeString, CustomFieldTypeInt, CustomFieldTypeDate, CustomFieldTypeBool:
+		return true
+	}
+	return false
+}
+
+// CustomFieldValue is a custom field value with its type, as it is stored.
+// Boolean values are stored as integers.
+type CustomFieldValue struct {
+	Field string      `db:"field" json:"field"`
+	Type  string      `db:"type" json:"type"`
+	Value interface{} `db:"value" json:"value"`
+}
+
+// NewCustomFieldValue returns the stored form of a custom field value.
+// The type of CustomFieldTypedValues, and of maps with type and value keys,
+// is used as provided. Otherwise the type is inferred: integral numbers are
+// ints, and strings in YYYY-MM-DD format that are valid dates are dates.
+// Returns an error if the field name is invalid or the value is not a
+// string, integer or boolean, or does not match its type.
+func NewCustomFieldValue(field string, value interface{}) (*CustomFieldValue, error) {
+	if field == "" {
+		return nil, fmt.Errorf("custom field name must not be empty")
+	}
+	if len(field) > CustomFieldMaxNameLength {
+		return nil, fmt.Errorf("custom field name %s is longer than %d characters", field, CustomFieldMaxNameLength)
+	}
+
+	switch v := value.(type) {
+	case CustomFieldTypedValue:
+		return newTypedCustomFieldValue(field, v.Type, v.Value)
+	case *CustomFieldTypedValue:
+		return newTypedCustomFieldValue(field, v.Type, v.Value)
+	case map[string]interface{}:
+		t, _ := v["type"].(string)
+		if t == "" {
+			return nil, fmt.Errorf("custom field %s: type is required", field)
+		}
+		return newTypedCustomFieldValue(field, t, v["value"])
+	}
+
+	return inferCustomFieldValue(field, value)
+}
+
+// newTypedCustomFieldValue returns the stored form of a custom field value
+// with the provided type.
+func newTypedCustomFieldValue(field string, t string, value interface{}) (*CustomFieldValue, error) {
+	t = strings.ToLower(t)
+	if !isCustomFieldType(t) {
+		return nil, fmt.Errorf("custom field %s: invalid type %s", field, t)
+	}
+
+	// dates are inferred from strings, so are converted as strings
+	inferAs := t
+	if t == CustomFieldTypeDate {
+		inferAs = CustomFieldTypeString
+	}
+
+	ret, err := inferCustomFieldValue(field, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.Type == CustomFieldTypeDate {
+		ret.Type = CustomFieldTypeString
+	}
+
+	if ret.Type != inferAs {
+		return nil, fmt.Errorf("custom field %s: %v is not a %s value", field, value, t)
+	}
+
+	if t == CustomFieldTypeDate {
+		s, _ := ret.Value.(string)
+		if _, err := time.Parse("2006-01-02", s); err != nil || !customFieldDateRE.MatchString(s) {
+			return nil, fmt.Errorf("custom field %s: %s is not a date in YYYY-MM-DD format", field, s)
+		}
+	}
+
+	ret.Type = t
+	return ret, nil
+}
+
+// inferCustomFieldValue returns the stored form of a custom field value,
+// inferring its type from the value.
+func inferCustomFieldValue(field string, value interface{}) (*CustomFieldValue, error) {
+	ret := &CustomFieldValue{Field: field}
+
+	switch v := value.(type) {
+	case string:
+		ret.Type = CustomFieldTypeString
+		ret.Value = v
+		if customFieldDateRE.MatchString(v) {
+			if _, err := time.Parse("2006-01-02", v); err == nil {
+				ret.Type = CustomFieldTypeDate
+			}
+		}
+	case bool:
+		ret.Type = CustomFieldTypeBool
+		ret.Value = int64(0)
+		if v {
+			ret.Value = int64(1)
+		}
+	case int:
+		ret.Type = CustomFieldTypeInt
+		ret.Value = int64(v)
+	case int32:
+		ret.Type = CustomFieldTypeInt
+		ret.Value = int64(v)
+	case int64:
+		ret.Type = CustomFieldTypeInt
+		ret.Value = v
+	case float64:
+		// json numbers are decoded as float64
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("custom field %s: %v is not an integer", field, v)
+		}
+		ret.Type = CustomFieldTypeInt
+		ret.Value = int64(v)
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("custom field %s: %s is not an integer", field, v.String())
+		}
+		ret.Type = CustomFieldTypeInt
+		ret.Value = i
+	default:
+		return nil, fmt.Errorf("custom field %s: unsupported value type %T", field, value)
+	}
+
+	return ret, nil
+}
+
+// Interface returns the value as it is returned in a CustomFieldMap.
+func (v CustomFieldValue) Interface() interface{} {
+	if v.Type == CustomFieldTypeBool {
+		i, _ := v.Value.(int64)
+		return i != 0
+	}
+
+	// text values may be read as bytes
+	if b, ok := v.Value.([]byte); ok {
+		return string(b)
+	}
+
+	return v.Value
+}
+
+// Values returns the stored form of the custom fields, ordered by field
+// name. Returns an error if any field is invalid.
+func (m CustomFieldMap) Values() ([]CustomFieldValue, error) {
+	var ret []CustomFieldValue
+	for field, value := range m {
+		v, err := NewCustomFieldValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, *v)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Field < ret[j].Field
+	})
+
+	return ret, nil
+}
+
+// Merge returns a copy of the custom fields with the provided fields set.
+// Fields with a nil value are removed.
+func (m CustomFieldMap) Merge(fields map[string]interface{}) CustomFieldMap {
+	ret := make(CustomFieldMap)
+	for k, v := range m {
+		ret[k] = v
+	}
+
+	for k, v := range fields {
+		if v == nil {
+			delete(ret, k)
+		} else {
+			ret[k] = v
+		}
+	}
+
+	return ret
+}
+
+// CustomFieldMapFromValues returns a CustomFieldMap of the provided stored
+// values. Values with a type that would not be inferred from them are
+// CustomFieldTypedValues, so that they keep their type when stored again.
+func CustomFieldMapFromValues(values []CustomFieldValue) CustomFieldMap {
+	ret := make(CustomFieldMap)
+	for _, v := range values {
+		value := v.Interface()
+		if inferred, err := inferCustomFieldValue(v.Field, value); err == nil && inferred.Type != v.Type {
+			ret[v.Field] = CustomFieldTypedValue{Type: v.Type, Value: value}
+		} else {
+			ret[v.Field] = value
+		}
+	}
+	return ret
+}
+
+// CustomFieldsFromInput returns the custom fields of an update input,
+// removing fields with nil values.
+func CustomFieldsFromInput(input map[string]interface{}) CustomFieldMap {
+	return CustomFieldMap{}.Merge(input)
+}
+
+// CustomFieldsReaderWriter gets and replaces the custom fields of objects.
+type CustomFieldsReaderWriter interface {
+	GetCustomFields(id int) (CustomFieldMap, error)
+	UpdateCustomFields(id int, fields CustomFieldMap) error
+}
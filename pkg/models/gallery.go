@@ -15,6 +15,9 @@ type GalleryReader interface {
 	GetTagIDs(galleryID int) ([]int, error)
 	GetSceneIDs(galleryID int) ([]int, error)
 	GetImageIDs(galleryID int) ([]int, error)
+	GetImageOrder(galleryID int) ([]int, error)
+	GetChapters(galleryID int) ([]*GalleryChapter, error)
+	GetCustomFields(galleryID int) (CustomFieldMap, error)
 }
 
 type GalleryWriter interface {
@@ -27,6 +30,9 @@ type GalleryWriter interface {
 	UpdateTags(galleryID int, tagIDs []int) error
 	UpdateScenes(galleryID int, sceneIDs []int) error
 	UpdateImages(galleryID int, imageIDs []int) error
+	UpdateImageOrder(galleryID int, imageIDs []int) error
+	UpdateChapters(galleryID int, chapters []GalleryChapter) error
+	UpdateCustomFields(galleryID int, fields CustomFieldMap) error
 }
 
 type GalleryReaderWriter interface {
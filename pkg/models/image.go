@@ -4,6 +4,9 @@ type ImageReader interface {
 	Find(id int) (*Image, error)
 	FindMany(ids []int) ([]*Image, error)
 	FindByChecksum(checksum string) (*Image, error)
+	// FindByMergedChecksum returns the image that an image file with the
+	// provided checksum was merged into.
+	FindByMergedChecksum(checksum string) (*Image, error)
 	FindByGalleryID(galleryID int) ([]*Image, error)
 	CountByGalleryID(galleryID int) (int, error)
 	FindByPath(path string) (*Image, error)
@@ -17,9 +20,15 @@ type ImageReader interface {
 	// CountByTagID(tagID int) (int, error)
 	All() ([]*Image, error)
 	Query(imageFilter *ImageFilterType, findFilter *FindFilterType) ([]*Image, int, error)
+	// FindDuplicates returns groups of images with perceptual hashes within
+	// the provided hamming distance of each other.
+	FindDuplicates(distance int) ([][]*Image, error)
 	GetGalleryIDs(imageID int) ([]int, error)
 	GetTagIDs(imageID int) ([]int, error)
 	GetPerformerIDs(imageID int) ([]int, error)
+	GetCustomFields(imageID int) (CustomFieldMap, error)
+	GetKeywords(imageID int) ([]string, error)
+	GetMergedChecksums(imageID int) ([]string, error)
 }
 
 type ImageWriter interface {
@@ -33,6 +42,9 @@ type ImageWriter interface {
 	UpdateGalleries(imageID int, galleryIDs []int) error
 	UpdatePerformers(imageID int, performerIDs []int) error
 	UpdateTags(imageID int, tagIDs []int) error
+	UpdateCustomFields(imageID int, fields CustomFieldMap) error
+	UpdateKeywords(imageID int, keywords []string) error
+	UpdateMergedChecksums(imageID int, checksums []string) error
 }
 
 type ImageReaderWriter interface {
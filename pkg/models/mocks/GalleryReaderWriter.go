@@ -254,6 +254,52 @@ func (_m *GalleryReaderWriter) FindMany(ids []int) ([]*models.Gallery, error) {
 	return r0, r1
 }
 
+// GetChapters provides a mock function with given fields: galleryID
+func (_m *GalleryReaderWriter) GetChapters(galleryID int) ([]*models.GalleryChapter, error) {
+	ret := _m.Called(galleryID)
+
+	var r0 []*models.GalleryChapter
+	if rf, ok := ret.Get(0).(func(int) []*models.GalleryChapter); ok {
+		r0 = rf(galleryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.GalleryChapter)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(galleryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCustomFields provides a mock function with given fields: galleryID
+func (_m *GalleryReaderWriter) GetCustomFields(galleryID int) (models.CustomFieldMap, error) {
+	ret := _m.Called(galleryID)
+
+	var r0 models.CustomFieldMap
+	if rf, ok := ret.Get(0).(func(int) models.CustomFieldMap); ok {
+		r0 = rf(galleryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.CustomFieldMap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(galleryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetImageIDs provides a mock function with given fields: galleryID
 func (_m *GalleryReaderWriter) GetImageIDs(galleryID int) ([]int, error) {
 	ret := _m.Called(galleryID)
@@ -277,6 +323,29 @@ func (_m *GalleryReaderWriter) GetImageIDs(galleryID int) ([]int, error) {
 	return r0, r1
 }
 
+// GetImageOrder provides a mock function with given fields: galleryID
+func (_m *GalleryReaderWriter) GetImageOrder(galleryID int) ([]int, error) {
+	ret := _m.Called(galleryID)
+
+	var r0 []int
+	if rf, ok := ret.Get(0).(func(int) []int); ok {
+		r0 = rf(galleryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(galleryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPerformerIDs provides a mock function with given fields: galleryID
 func (_m *GalleryReaderWriter) GetPerformerIDs(galleryID int) ([]int, error) {
 	ret := _m.Called(galleryID)
@@ -399,6 +468,34 @@ func (_m *GalleryReaderWriter) Update(updatedGallery models.Gallery) (*models.Ga
 	return r0, r1
 }
 
+// UpdateChapters provides a mock function with given fields: galleryID, chapters
+func (_m *GalleryReaderWriter) UpdateChapters(galleryID int, chapters []models.GalleryChapter) error {
+	ret := _m.Called(galleryID, chapters)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []models.GalleryChapter) error); ok {
+		r0 = rf(galleryID, chapters)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCustomFields provides a mock function with given fields: galleryID, fields
+func (_m *GalleryReaderWriter) UpdateCustomFields(galleryID int, fields models.CustomFieldMap) error {
+	ret := _m.Called(galleryID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, models.CustomFieldMap) error); ok {
+		r0 = rf(galleryID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateFileModTime provides a mock function with given fields: id, modTime
 func (_m *GalleryReaderWriter) UpdateFileModTime(id int, modTime models.NullSQLiteTimestamp) error {
 	ret := _m.Called(id, modTime)
@@ -413,6 +510,20 @@ func (_m *GalleryReaderWriter) UpdateFileModTime(id int, modTime models.NullSQLi
 	return r0
 }
 
+// UpdateImageOrder provides a mock function with given fields: galleryID, imageIDs
+func (_m *GalleryReaderWriter) UpdateImageOrder(galleryID int, imageIDs []int) error {
+	ret := _m.Called(galleryID, imageIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []int) error); ok {
+		r0 = rf(galleryID, imageIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateImages provides a mock function with given fields: galleryID, imageIDs
 func (_m *GalleryReaderWriter) UpdateImages(galleryID int, imageIDs []int) error {
 	ret := _m.Called(galleryID, imageIDs)
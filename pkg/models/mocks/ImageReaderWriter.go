@@ -181,6 +181,29 @@ func (_m *ImageReaderWriter) FindByChecksum(checksum string) (*models.Image, err
 	return r0, r1
 }
 
+// FindByMergedChecksum provides a mock function with given fields: checksum
+func (_m *ImageReaderWriter) FindByMergedChecksum(checksum string) (*models.Image, error) {
+	ret := _m.Called(checksum)
+
+	var r0 *models.Image
+	if rf, ok := ret.Get(0).(func(string) *models.Image); ok {
+		r0 = rf(checksum)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(checksum)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByGalleryID provides a mock function with given fields: galleryID
 func (_m *ImageReaderWriter) FindByGalleryID(galleryID int) ([]*models.Image, error) {
 	ret := _m.Called(galleryID)
@@ -227,6 +250,29 @@ func (_m *ImageReaderWriter) FindByPath(path string) (*models.Image, error) {
 	return r0, r1
 }
 
+// FindDuplicates provides a mock function with given fields: distance
+func (_m *ImageReaderWriter) FindDuplicates(distance int) ([][]*models.Image, error) {
+	ret := _m.Called(distance)
+
+	var r0 [][]*models.Image
+	if rf, ok := ret.Get(0).(func(int) [][]*models.Image); ok {
+		r0 = rf(distance)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]*models.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(distance)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindMany provides a mock function with given fields: ids
 func (_m *ImageReaderWriter) FindMany(ids []int) ([]*models.Image, error) {
 	ret := _m.Called(ids)
@@ -250,6 +296,29 @@ func (_m *ImageReaderWriter) FindMany(ids []int) ([]*models.Image, error) {
 	return r0, r1
 }
 
+// GetCustomFields provides a mock function with given fields: imageID
+func (_m *ImageReaderWriter) GetCustomFields(imageID int) (models.CustomFieldMap, error) {
+	ret := _m.Called(imageID)
+
+	var r0 models.CustomFieldMap
+	if rf, ok := ret.Get(0).(func(int) models.CustomFieldMap); ok {
+		r0 = rf(imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.CustomFieldMap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetGalleryIDs provides a mock function with given fields: imageID
 func (_m *ImageReaderWriter) GetGalleryIDs(imageID int) ([]int, error) {
 	ret := _m.Called(imageID)
@@ -273,6 +342,52 @@ func (_m *ImageReaderWriter) GetGalleryIDs(imageID int) ([]int, error) {
 	return r0, r1
 }
 
+// GetKeywords provides a mock function with given fields: imageID
+func (_m *ImageReaderWriter) GetKeywords(imageID int) ([]string, error) {
+	ret := _m.Called(imageID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(int) []string); ok {
+		r0 = rf(imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMergedChecksums provides a mock function with given fields: imageID
+func (_m *ImageReaderWriter) GetMergedChecksums(imageID int) ([]string, error) {
+	ret := _m.Called(imageID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(int) []string); ok {
+		r0 = rf(imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPerformerIDs provides a mock function with given fields: imageID
 func (_m *ImageReaderWriter) GetPerformerIDs(imageID int) ([]int, error) {
 	ret := _m.Called(imageID)
@@ -435,6 +550,20 @@ func (_m *ImageReaderWriter) Update(updatedImage models.ImagePartial) (*models.I
 	return r0, r1
 }
 
+// UpdateCustomFields provides a mock function with given fields: imageID, fields
+func (_m *ImageReaderWriter) UpdateCustomFields(imageID int, fields models.CustomFieldMap) error {
+	ret := _m.Called(imageID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, models.CustomFieldMap) error); ok {
+		r0 = rf(imageID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateFull provides a mock function with given fields: updatedImage
 func (_m *ImageReaderWriter) UpdateFull(updatedImage models.Image) (*models.Image, error) {
 	ret := _m.Called(updatedImage)
@@ -472,6 +601,34 @@ func (_m *ImageReaderWriter) UpdateGalleries(imageID int, galleryIDs []int) erro
 	return r0
 }
 
+// UpdateKeywords provides a mock function with given fields: imageID, keywords
+func (_m *ImageReaderWriter) UpdateKeywords(imageID int, keywords []string) error {
+	ret := _m.Called(imageID, keywords)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []string) error); ok {
+		r0 = rf(imageID, keywords)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateMergedChecksums provides a mock function with given fields: imageID, checksums
+func (_m *ImageReaderWriter) UpdateMergedChecksums(imageID int, checksums []string) error {
+	ret := _m.Called(imageID, checksums)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []string) error); ok {
+		r0 = rf(imageID, checksums)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePerformers provides a mock function with given fields: imageID, performerIDs
 func (_m *ImageReaderWriter) UpdatePerformers(imageID int, performerIDs []int) error {
 	ret := _m.Called(imageID, performerIDs)
@@ -245,6 +245,50 @@ func (_m *MovieReaderWriter) GetBackImage(movieID int) ([]byte, error) {
 	return r0, r1
 }
 
+// GetBackImageChecksum provides a mock function with given fields: movieID
+func (_m *MovieReaderWriter) GetBackImageChecksum(movieID int) (string, error) {
+	ret := _m.Called(movieID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(int) string); ok {
+		r0 = rf(movieID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(movieID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCustomFields provides a mock function with given fields: movieID
+func (_m *MovieReaderWriter) GetCustomFields(movieID int) (models.CustomFieldMap, error) {
+	ret := _m.Called(movieID)
+
+	var r0 models.CustomFieldMap
+	if rf, ok := ret.Get(0).(func(int) models.CustomFieldMap); ok {
+		r0 = rf(movieID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.CustomFieldMap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(movieID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFrontImage provides a mock function with given fields: movieID
 func (_m *MovieReaderWriter) GetFrontImage(movieID int) ([]byte, error) {
 	ret := _m.Called(movieID)
@@ -268,6 +312,27 @@ func (_m *MovieReaderWriter) GetFrontImage(movieID int) ([]byte, error) {
 	return r0, r1
 }
 
+// GetFrontImageChecksum provides a mock function with given fields: movieID
+func (_m *MovieReaderWriter) GetFrontImageChecksum(movieID int) (string, error) {
+	ret := _m.Called(movieID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(int) string); ok {
+		r0 = rf(movieID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(movieID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Query provides a mock function with given fields: movieFilter, findFilter
 func (_m *MovieReaderWriter) Query(movieFilter *models.MovieFilterType, findFilter *models.FindFilterType) ([]*models.Movie, int, error) {
 	ret := _m.Called(movieFilter, findFilter)
@@ -321,6 +386,20 @@ func (_m *MovieReaderWriter) Update(updatedMovie models.MoviePartial) (*models.M
 	return r0, r1
 }
 
+// UpdateCustomFields provides a mock function with given fields: movieID, fields
+func (_m *MovieReaderWriter) UpdateCustomFields(movieID int, fields models.CustomFieldMap) error {
+	ret := _m.Called(movieID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, models.CustomFieldMap) error); ok {
+		r0 = rf(movieID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateFull provides a mock function with given fields: updatedMovie
 func (_m *MovieReaderWriter) UpdateFull(updatedMovie models.Movie) (*models.Movie, error) {
 	ret := _m.Called(updatedMovie)
@@ -291,6 +291,29 @@ func (_m *PerformerReaderWriter) FindNamesBySceneID(sceneID int) ([]*models.Perf
 	return r0, r1
 }
 
+// GetCustomFields provides a mock function with given fields: performerID
+func (_m *PerformerReaderWriter) GetCustomFields(performerID int) (models.CustomFieldMap, error) {
+	ret := _m.Called(performerID)
+
+	var r0 models.CustomFieldMap
+	if rf, ok := ret.Get(0).(func(int) models.CustomFieldMap); ok {
+		r0 = rf(performerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.CustomFieldMap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(performerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetImage provides a mock function with given fields: performerID
 func (_m *PerformerReaderWriter) GetImage(performerID int) ([]byte, error) {
 	ret := _m.Called(performerID)
@@ -314,6 +337,94 @@ func (_m *PerformerReaderWriter) GetImage(performerID int) ([]byte, error) {
 	return r0, r1
 }
 
+// GetImageByIndex provides a mock function with given fields: performerID, index
+func (_m *PerformerReaderWriter) GetImageByIndex(performerID int, index int) ([]byte, error) {
+	ret := _m.Called(performerID, index)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(int, int) []byte); ok {
+		r0 = rf(performerID, index)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(performerID, index)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImageChecksumByIndex provides a mock function with given fields: performerID, index
+func (_m *PerformerReaderWriter) GetImageChecksumByIndex(performerID int, index int) (string, error) {
+	ret := _m.Called(performerID, index)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(int, int) string); ok {
+		r0 = rf(performerID, index)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(performerID, index)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImageCount provides a mock function with given fields: performerID
+func (_m *PerformerReaderWriter) GetImageCount(performerID int) (int, error) {
+	ret := _m.Called(performerID)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int) int); ok {
+		r0 = rf(performerID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(performerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImages provides a mock function with given fields: performerID
+func (_m *PerformerReaderWriter) GetImages(performerID int) ([][]byte, error) {
+	ret := _m.Called(performerID)
+
+	var r0 [][]byte
+	if rf, ok := ret.Get(0).(func(int) [][]byte); ok {
+		r0 = rf(performerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(performerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStashIDs provides a mock function with given fields: performerID
 func (_m *PerformerReaderWriter) GetStashIDs(performerID int) ([]*models.StashID, error) {
 	ret := _m.Called(performerID)
@@ -390,6 +501,20 @@ func (_m *PerformerReaderWriter) Update(updatedPerformer models.PerformerPartial
 	return r0, r1
 }
 
+// UpdateCustomFields provides a mock function with given fields: performerID, fields
+func (_m *PerformerReaderWriter) UpdateCustomFields(performerID int, fields models.CustomFieldMap) error {
+	ret := _m.Called(performerID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, models.CustomFieldMap) error); ok {
+		r0 = rf(performerID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateFull provides a mock function with given fields: updatedPerformer
 func (_m *PerformerReaderWriter) UpdateFull(updatedPerformer models.Performer) (*models.Performer, error) {
 	ret := _m.Called(updatedPerformer)
@@ -427,6 +552,20 @@ func (_m *PerformerReaderWriter) UpdateImage(performerID int, image []byte) erro
 	return r0
 }
 
+// UpdateImages provides a mock function with given fields: performerID, images
+func (_m *PerformerReaderWriter) UpdateImages(performerID int, images [][]byte) error {
+	ret := _m.Called(performerID, images)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, [][]byte) error); ok {
+		r0 = rf(performerID, images)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateStashIDs provides a mock function with given fields: performerID, stashIDs
 func (_m *PerformerReaderWriter) UpdateStashIDs(performerID int, stashIDs []models.StashID) error {
 	ret := _m.Called(performerID, stashIDs)
@@ -461,6 +461,50 @@ func (_m *SceneReaderWriter) GetCover(sceneID int) ([]byte, error) {
 	return r0, r1
 }
 
+// GetCoverChecksum provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetCoverChecksum(sceneID int) (string, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(int) string); ok {
+		r0 = rf(sceneID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCustomFields provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetCustomFields(sceneID int) (models.CustomFieldMap, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 models.CustomFieldMap
+	if rf, ok := ret.Get(0).(func(int) models.CustomFieldMap); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.CustomFieldMap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMovies provides a mock function with given fields: sceneID
 func (_m *SceneReaderWriter) GetMovies(sceneID int) ([]models.MoviesScenes, error) {
 	ret := _m.Called(sceneID)
@@ -729,6 +773,20 @@ func (_m *SceneReaderWriter) UpdateCover(sceneID int, cover []byte) error {
 	return r0
 }
 
+// UpdateCustomFields provides a mock function with given fields: sceneID, fields
+func (_m *SceneReaderWriter) UpdateCustomFields(sceneID int, fields models.CustomFieldMap) error {
+	ret := _m.Called(sceneID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, models.CustomFieldMap) error); ok {
+		r0 = rf(sceneID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateFileModTime provides a mock function with given fields: id, modTime
 func (_m *SceneReaderWriter) UpdateFileModTime(id int, modTime models.NullSQLiteTimestamp) error {
 	ret := _m.Called(id, modTime)
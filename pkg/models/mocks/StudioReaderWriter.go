@@ -222,6 +222,29 @@ func (_m *StudioReaderWriter) FindMany(ids []int) ([]*models.Studio, error) {
 	return r0, r1
 }
 
+// GetCustomFields provides a mock function with given fields: studioID
+func (_m *StudioReaderWriter) GetCustomFields(studioID int) (models.CustomFieldMap, error) {
+	ret := _m.Called(studioID)
+
+	var r0 models.CustomFieldMap
+	if rf, ok := ret.Get(0).(func(int) models.CustomFieldMap); ok {
+		r0 = rf(studioID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.CustomFieldMap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(studioID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetImage provides a mock function with given fields: studioID
 func (_m *StudioReaderWriter) GetImage(studioID int) ([]byte, error) {
 	ret := _m.Called(studioID)
@@ -245,6 +268,27 @@ func (_m *StudioReaderWriter) GetImage(studioID int) ([]byte, error) {
 	return r0, r1
 }
 
+// GetImageChecksum provides a mock function with given fields: studioID
+func (_m *StudioReaderWriter) GetImageChecksum(studioID int) (string, error) {
+	ret := _m.Called(studioID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(int) string); ok {
+		r0 = rf(studioID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(studioID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStashIDs provides a mock function with given fields: studioID
 func (_m *StudioReaderWriter) GetStashIDs(studioID int) ([]*models.StashID, error) {
 	ret := _m.Called(studioID)
@@ -342,6 +386,20 @@ func (_m *StudioReaderWriter) Update(updatedStudio models.StudioPartial) (*model
 	return r0, r1
 }
 
+// UpdateCustomFields provides a mock function with given fields: studioID, fields
+func (_m *StudioReaderWriter) UpdateCustomFields(studioID int, fields models.CustomFieldMap) error {
+	ret := _m.Called(studioID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, models.CustomFieldMap) error); ok {
+		r0 = rf(studioID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateFull provides a mock function with given fields: updatedStudio
 func (_m *StudioReaderWriter) UpdateFull(updatedStudio models.Studio) (*models.Studio, error) {
 	ret := _m.Called(updatedStudio)
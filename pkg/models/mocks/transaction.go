@@ -7,12 +7,14 @@ import (
 )
 
 type TransactionManager struct {
+	auditLog    models.AuditLogReaderWriter
 	gallery     models.GalleryReaderWriter
 	image       models.ImageReaderWriter
 	movie       models.MovieReaderWriter
 	performer   models.PerformerReaderWriter
 	scene       models.SceneReaderWriter
 	sceneMarker models.SceneMarkerReaderWriter
+	savedFilter models.SavedFilterReaderWriter
 	scrapedItem models.ScrapedItemReaderWriter
 	studio      models.StudioReaderWriter
 	tag         models.TagReaderWriter
@@ -20,12 +22,14 @@ type TransactionManager struct {
 
 func NewTransactionManager() *TransactionManager {
 	return &TransactionManager{
+		auditLog:    &AuditLogReaderWriter{},
 		gallery:     &GalleryReaderWriter{},
 		image:       &ImageReaderWriter{},
 		movie:       &MovieReaderWriter{},
 		performer:   &PerformerReaderWriter{},
 		scene:       &SceneReaderWriter{},
 		sceneMarker: &SceneMarkerReaderWriter{},
+		savedFilter: &SavedFilterReaderWriter{},
 		scrapedItem: &ScrapedItemReaderWriter{},
 		studio:      &StudioReaderWriter{},
 		tag:         &TagReaderWriter{},
@@ -36,6 +40,10 @@ func (t *TransactionManager) WithTxn(ctx context.Context, fn func(r models.Repos
 	return fn(t)
 }
 
+func (t *TransactionManager) AuditLog() models.AuditLogReaderWriter {
+	return t.auditLog
+}
+
 func (t *TransactionManager) Gallery() models.GalleryReaderWriter {
 	return t.gallery
 }
@@ -60,6 +68,10 @@ func (t *TransactionManager) Scene() models.SceneReaderWriter {
 	return t.scene
 }
 
+func (t *TransactionManager) SavedFilter() models.SavedFilterReaderWriter {
+	return t.savedFilter
+}
+
 func (t *TransactionManager) ScrapedItem() models.ScrapedItemReaderWriter {
 	return t.scrapedItem
 }
@@ -80,6 +92,10 @@ func (t *TransactionManager) WithReadTxn(ctx context.Context, fn func(r models.R
 	return fn(&ReadTransaction{t: t})
 }
 
+func (r *ReadTransaction) AuditLog() models.AuditLogReader {
+	return r.t.auditLog
+}
+
 func (r *ReadTransaction) Gallery() models.GalleryReader {
 	return r.t.gallery
 }
@@ -104,6 +120,10 @@ func (r *ReadTransaction) Scene() models.SceneReader {
 	return r.t.scene
 }
 
+func (r *ReadTransaction) SavedFilter() models.SavedFilterReader {
+	return r.t.savedFilter
+}
+
 func (r *ReadTransaction) ScrapedItem() models.ScrapedItemReader {
 	return r.t.scrapedItem
 }
@@ -0,0 +1,28 @@
+package models
+
+import "database/sql"
+
+// AuditLogEntry records a change made to an object. Updates are recorded
+// with one entry per changed field. The values of creates and destroys are
+// the JSON-encoded fields of the object.
+type AuditLogEntry struct {
+	ID         int             `db:"id" json:"id"`
+	EntityType AuditEntityEnum `db:"entity_type" json:"entity_type"`
+	EntityID   int             `db:"entity_id" json:"entity_id"`
+	Action     AuditActionEnum `db:"action" json:"action"`
+	Field      sql.NullString  `db:"field" json:"field"`
+	OldValue   sql.NullString  `db:"old_value" json:"old_value"`
+	NewValue   sql.NullString  `db:"new_value" json:"new_value"`
+	Source     AuditSourceEnum `db:"source" json:"source"`
+	CreatedAt  SQLiteTimestamp `db:"created_at" json:"created_at"`
+}
+
+type AuditLogEntries []*AuditLogEntry
+
+func (e *AuditLogEntries) Append(o interface{}) {
+	*e = append(*e, o.(*AuditLogEntry))
+}
+
+func (e *AuditLogEntries) New() interface{} {
+	return &AuditLogEntry{}
+}
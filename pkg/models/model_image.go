@@ -6,17 +6,26 @@ import (
 
 // Image stores the metadata for a single image.
 type Image struct {
-	ID          int                 `db:"id" json:"id"`
-	Checksum    string              `db:"checksum" json:"checksum"`
-	Path        string              `db:"path" json:"path"`
-	Title       sql.NullString      `db:"title" json:"title"`
-	Rating      sql.NullInt64       `db:"rating" json:"rating"`
-	Organized   bool                `db:"organized" json:"organized"`
-	OCounter    int                 `db:"o_counter" json:"o_counter"`
-	Size        sql.NullInt64       `db:"size" json:"size"`
-	Width       sql.NullInt64       `db:"width" json:"width"`
-	Height      sql.NullInt64       `db:"height" json:"height"`
-	StudioID    sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
+	ID          int             `db:"id" json:"id"`
+	Checksum    string          `db:"checksum" json:"checksum"`
+	Path        string          `db:"path" json:"path"`
+	Title       sql.NullString  `db:"title" json:"title"`
+	Rating      sql.NullInt64   `db:"rating" json:"rating"`
+	Organized   bool            `db:"organized" json:"organized"`
+	OCounter    int             `db:"o_counter" json:"o_counter"`
+	Size        sql.NullInt64   `db:"size" json:"size"`
+	Width       sql.NullInt64   `db:"width" json:"width"`
+	Height      sql.NullInt64   `db:"height" json:"height"`
+	StudioID    sql.NullInt64   `db:"studio_id,omitempty" json:"studio_id"`
+	Date        SQLiteDate      `db:"date" json:"date"`
+	Orientation sql.NullInt64   `db:"orientation" json:"orientation"`
+	Camera      sql.NullString  `db:"camera" json:"camera"`
+	Format      sql.NullString  `db:"format" json:"format"`
+	IsAnimated  bool            `db:"is_animated" json:"is_animated"`
+	Duration    sql.NullFloat64 `db:"duration" json:"duration"`
+	// Phash is the perceptual hash of the image, stored as a signed
+	// integer.
+	Phash       sql.NullInt64       `db:"phash" json:"phash"`
 	FileModTime NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
 	CreatedAt   SQLiteTimestamp     `db:"created_at" json:"created_at"`
 	UpdatedAt   SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
@@ -35,6 +44,13 @@ type ImagePartial struct {
 	Width       *sql.NullInt64       `db:"width" json:"width"`
 	Height      *sql.NullInt64       `db:"height" json:"height"`
 	StudioID    *sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
+	Date        *SQLiteDate          `db:"date" json:"date"`
+	Orientation *sql.NullInt64       `db:"orientation" json:"orientation"`
+	Camera      *sql.NullString      `db:"camera" json:"camera"`
+	Format      *sql.NullString      `db:"format" json:"format"`
+	IsAnimated  *bool                `db:"is_animated" json:"is_animated"`
+	Duration    *sql.NullFloat64     `db:"duration" json:"duration"`
+	Phash       *sql.NullInt64       `db:"phash" json:"phash"`
 	FileModTime *NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
 	CreatedAt   *SQLiteTimestamp     `db:"created_at" json:"created_at"`
 	UpdatedAt   *SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
@@ -42,9 +58,10 @@ type ImagePartial struct {
 
 // ImageFileType represents the file metadata for an image.
 type ImageFileType struct {
-	Size   *int `graphql:"size" json:"size"`
-	Width  *int `graphql:"width" json:"width"`
-	Height *int `graphql:"height" json:"height"`
+	Size     *int     `graphql:"size" json:"size"`
+	Width    *int     `graphql:"width" json:"width"`
+	Height   *int     `graphql:"height" json:"height"`
+	Duration *float64 `graphql:"duration" json:"duration"`
 }
 
 type Images []*Image
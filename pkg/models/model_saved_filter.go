@@ -0,0 +1,27 @@
+package models
+
+import "database/sql"
+
+// SavedFilter is a named find filter and mode-specific object filter. The
+// filters are stored as JSON.
+type SavedFilter struct {
+	ID           int            `db:"id" json:"id"`
+	Mode         FilterMode     `db:"mode" json:"mode"`
+	Name         string         `db:"name" json:"name"`
+	FindFilter   sql.NullString `db:"find_filter" json:"find_filter"`
+	ObjectFilter sql.NullString `db:"object_filter" json:"object_filter"`
+}
+
+// SavedFilterDefaultName is the name of the saved filter used as the default
+// filter for a mode.
+const SavedFilterDefaultName = ""
+
+type SavedFilters []*SavedFilter
+
+func (f *SavedFilters) Append(o interface{}) {
+	*f = append(*f, o.(*SavedFilter))
+}
+
+func (f *SavedFilters) New() interface{} {
+	return &SavedFilter{}
+}
@@ -41,6 +41,8 @@ type ScrapedPerformer struct {
 	Piercings    *string `graphql:"piercings" json:"piercings"`
 	Aliases      *string `graphql:"aliases" json:"aliases"`
 	Image        *string `graphql:"image" json:"image"`
+	// Images of the performer. The first image is the primary image.
+	Images []string `graphql:"images" json:"images"`
 }
 
 // this type has no Image field
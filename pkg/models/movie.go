@@ -12,6 +12,11 @@ type MovieReader interface {
 	Query(movieFilter *MovieFilterType, findFilter *FindFilterType) ([]*Movie, int, error)
 	GetFrontImage(movieID int) ([]byte, error)
 	GetBackImage(movieID int) ([]byte, error)
+	// GetFrontImageChecksum and GetBackImageChecksum return the MD5 checksum
+	// of the image, or an empty string if there is no image.
+	GetFrontImageChecksum(movieID int) (string, error)
+	GetBackImageChecksum(movieID int) (string, error)
+	GetCustomFields(movieID int) (CustomFieldMap, error)
 }
 
 type MovieWriter interface {
@@ -21,6 +26,7 @@ type MovieWriter interface {
 	Destroy(id int) error
 	UpdateImages(movieID int, frontImage []byte, backImage []byte) error
 	DestroyImages(movieID int) error
+	UpdateCustomFields(movieID int, fields CustomFieldMap) error
 }
 
 type MovieReaderWriter interface {
@@ -13,7 +13,14 @@ type PerformerReader interface {
 	AllSlim() ([]*Performer, error)
 	Query(performerFilter *PerformerFilterType, findFilter *FindFilterType) ([]*Performer, int, error)
 	GetImage(performerID int) ([]byte, error)
+	GetImageByIndex(performerID int, index int) ([]byte, error)
+	// GetImageChecksumByIndex returns the MD5 checksum of the image at the
+	// provided index, or an empty string if there is no image.
+	GetImageChecksumByIndex(performerID int, index int) (string, error)
+	GetImages(performerID int) ([][]byte, error)
+	GetImageCount(performerID int) (int, error)
 	GetStashIDs(performerID int) ([]*StashID, error)
+	GetCustomFields(performerID int) (CustomFieldMap, error)
 }
 
 type PerformerWriter interface {
@@ -22,8 +29,10 @@ type PerformerWriter interface {
 	UpdateFull(updatedPerformer Performer) (*Performer, error)
 	Destroy(id int) error
 	UpdateImage(performerID int, image []byte) error
+	UpdateImages(performerID int, images [][]byte) error
 	DestroyImage(performerID int) error
 	UpdateStashIDs(performerID int, stashIDs []StashID) error
+	UpdateCustomFields(performerID int, fields CustomFieldMap) error
 }
 
 type PerformerReaderWriter interface {
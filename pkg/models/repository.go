@@ -1,24 +1,28 @@
 package models
 
 type Repository interface {
+	AuditLog() AuditLogReaderWriter
 	Gallery() GalleryReaderWriter
 	Image() ImageReaderWriter
 	Movie() MovieReaderWriter
 	Performer() PerformerReaderWriter
 	Scene() SceneReaderWriter
 	SceneMarker() SceneMarkerReaderWriter
+	SavedFilter() SavedFilterReaderWriter
 	ScrapedItem() ScrapedItemReaderWriter
 	Studio() StudioReaderWriter
 	Tag() TagReaderWriter
 }
 
 type ReaderRepository interface {
+	AuditLog() AuditLogReader
 	Gallery() GalleryReader
 	Image() ImageReader
 	Movie() MovieReader
 	Performer() PerformerReader
 	Scene() SceneReader
 	SceneMarker() SceneMarkerReader
+	SavedFilter() SavedFilterReader
 	ScrapedItem() ScrapedItemReader
 	Studio() StudioReader
 	Tag() TagReader
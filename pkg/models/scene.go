@@ -23,11 +23,15 @@ type SceneReader interface {
 	All() ([]*Scene, error)
 	Query(sceneFilter *SceneFilterType, findFilter *FindFilterType) ([]*Scene, int, error)
 	GetCover(sceneID int) ([]byte, error)
+	// GetCoverChecksum returns the MD5 checksum of the cover, or an empty
+	// string if there is no cover.
+	GetCoverChecksum(sceneID int) (string, error)
 	GetMovies(sceneID int) ([]MoviesScenes, error)
 	GetTagIDs(sceneID int) ([]int, error)
 	GetGalleryIDs(sceneID int) ([]int, error)
 	GetPerformerIDs(sceneID int) ([]int, error)
 	GetStashIDs(sceneID int) ([]*StashID, error)
+	GetCustomFields(sceneID int) (CustomFieldMap, error)
 }
 
 type SceneWriter interface {
@@ -46,6 +50,7 @@ type SceneWriter interface {
 	UpdateGalleries(sceneID int, galleryIDs []int) error
 	UpdateMovies(sceneID int, movies []MoviesScenes) error
 	UpdateStashIDs(sceneID int, stashIDs []StashID) error
+	UpdateCustomFields(sceneID int, fields CustomFieldMap) error
 }
 
 type SceneReaderWriter interface {
@@ -10,8 +10,12 @@ type StudioReader interface {
 	AllSlim() ([]*Studio, error)
 	Query(studioFilter *StudioFilterType, findFilter *FindFilterType) ([]*Studio, int, error)
 	GetImage(studioID int) ([]byte, error)
+	// GetImageChecksum returns the MD5 checksum of the image, or an empty
+	// string if there is no image.
+	GetImageChecksum(studioID int) (string, error)
 	HasImage(studioID int) (bool, error)
 	GetStashIDs(studioID int) ([]*StashID, error)
+	GetCustomFields(studioID int) (CustomFieldMap, error)
 }
 
 type StudioWriter interface {
@@ -22,6 +26,7 @@ type StudioWriter interface {
 	UpdateImage(studioID int, image []byte) error
 	DestroyImage(studioID int) error
 	UpdateStashIDs(studioID int, stashIDs []StashID) error
+	UpdateCustomFields(studioID int, fields CustomFieldMap) error
 }
 
 type StudioReaderWriter interface {
@@ -14,6 +14,9 @@ type TagReader interface {
 	AllSlim() ([]*Tag, error)
 	Query(tagFilter *TagFilterType, findFilter *FindFilterType) ([]*Tag, int, error)
 	GetImage(tagID int) ([]byte, error)
+	// GetImageChecksum returns the MD5 checksum of the image, or an empty
+	// string if there is no image.
+	GetImageChecksum(tagID int) (string, error)
 }
 
 type TagWriter interface {
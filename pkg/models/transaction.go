@@ -21,6 +21,17 @@ type TransactionManager interface {
 	WithReadTxn(ctx context.Context, fn func(r ReaderRepository) error) error
 }
 
+// LockableTransactionManager is a TransactionManager that can prevent
+// transactions from running while the database is replaced.
+type LockableTransactionManager interface {
+	TransactionManager
+	// Lock waits for running transactions to finish and causes new
+	// transactions to fail until Unlock is called. Returns an error if
+	// already locked.
+	Lock() error
+	Unlock()
+}
+
 func WithTxn(txn Transaction, fn func(r Repository) error) error {
 	err := txn.Begin()
 	if err != nil {
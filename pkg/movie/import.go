@@ -122,6 +122,12 @@ func (i *Importer) PostImport(id int) error {
 		}
 	}
 
+	if len(i.Input.CustomFields) > 0 {
+		if err := i.ReaderWriter.UpdateCustomFields(id, i.Input.CustomFields); err != nil {
+			return fmt.Errorf("error setting movie custom fields: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
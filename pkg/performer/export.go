@@ -67,13 +67,18 @@ func ToJSON(reader models.PerformerReader, performer *models.Performer) (*jsonsc
 		newPerformerJSON.Favorite = performer.Favorite.Bool
 	}
 
-	image, err := reader.GetImage(performer.ID)
+	images, err := reader.GetImages(performer.ID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting performers image: %s", err.Error())
 	}
 
-	if len(image) > 0 {
-		newPerformerJSON.Image = utils.GetBase64StringFromData(image)
+	// the primary image is exported separately from the other images
+	for i, image := range images {
+		if i == 0 {
+			newPerformerJSON.Image = utils.GetBase64StringFromData(image)
+		} else {
+			newPerformerJSON.Images = append(newPerformerJSON.Images, utils.GetBase64StringFromData(image))
+		}
 	}
 
 	return &newPerformerJSON, nil
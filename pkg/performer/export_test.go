@@ -39,8 +39,10 @@ const (
 )
 
 var imageBytes = []byte("imageBytes")
+var additionalImageBytes = []byte("additionalImageBytes")
 
 const image = "aW1hZ2VCeXRlcw=="
+const additionalImage = "YWRkaXRpb25hbEltYWdlQnl0ZXM="
 
 var birthDate = models.SQLiteDate{
 	String: "2001-01-01",
@@ -143,10 +145,13 @@ type testScenario struct {
 var scenarios []testScenario
 
 func initTestTable() {
+	fullJSONPerformer := createFullJSONPerformer(performerName, image)
+	fullJSONPerformer.Images = []string{additionalImage}
+
 	scenarios = []testScenario{
 		testScenario{
 			*createFullPerformer(performerID, performerName),
-			createFullJSONPerformer(performerName, image),
+			fullJSONPerformer,
 			false,
 		},
 		testScenario{
@@ -169,9 +174,9 @@ func TestToJSON(t *testing.T) {
 
 	imageErr := errors.New("error getting image")
 
-	mockPerformerReader.On("GetImage", performerID).Return(imageBytes, nil).Once()
-	mockPerformerReader.On("GetImage", noImageID).Return(nil, nil).Once()
-	mockPerformerReader.On("GetImage", errImageID).Return(nil, imageErr).Once()
+	mockPerformerReader.On("GetImages", performerID).Return([][]byte{imageBytes, additionalImageBytes}, nil).Once()
+	mockPerformerReader.On("GetImages", noImageID).Return(nil, nil).Once()
+	mockPerformerReader.On("GetImages", errImageID).Return(nil, imageErr).Once()
 
 	for i, s := range scenarios {
 		tag := s.input
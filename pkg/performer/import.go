@@ -13,8 +13,9 @@ type Importer struct {
 	ReaderWriter models.PerformerReaderWriter
 	Input        jsonschema.Performer
 
-	performer models.Performer
-	imageData []byte
+	performer        models.Performer
+	imageData        []byte
+	additionalImages [][]byte
 }
 
 func (i *Importer) PreImport() error {
@@ -28,16 +29,42 @@ func (i *Importer) PreImport() error {
 		}
 	}
 
+	for _, image := range i.Input.Images {
+		_, imageData, err := utils.ProcessBase64Image(image)
+		if err != nil {
+			return fmt.Errorf("invalid image: %s", err.Error())
+		}
+
+		i.additionalImages = append(i.additionalImages, imageData)
+	}
+
 	return nil
 }
 
 func (i *Importer) PostImport(id int) error {
+	// the first additional image is the primary image if there is no
+	// primary image
+	images := i.additionalImages
 	if len(i.imageData) > 0 {
-		if err := i.ReaderWriter.UpdateImage(id, i.imageData); err != nil {
+		images = append([][]byte{i.imageData}, images...)
+	}
+
+	if len(images) > 1 {
+		if err := i.ReaderWriter.UpdateImages(id, images); err != nil {
+			return fmt.Errorf("error setting performer images: %s", err.Error())
+		}
+	} else if len(images) == 1 {
+		if err := i.ReaderWriter.UpdateImage(id, images[0]); err != nil {
 			return fmt.Errorf("error setting performer image: %s", err.Error())
 		}
 	}
 
+	if len(i.Input.CustomFields) > 0 {
+		if err := i.ReaderWriter.UpdateCustomFields(id, i.Input.CustomFields); err != nil {
+			return fmt.Errorf("error setting performer custom fields: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -75,6 +75,59 @@ func TestImporterPostImport(t *testing.T) {
 	readerWriter.AssertExpectations(t)
 }
 
+func TestImporterPostImportImages(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		Input: jsonschema.Performer{
+			Image:  image,
+			Images: []string{additionalImage},
+		},
+	}
+
+	err := i.PreImport()
+	assert.Nil(t, err)
+
+	readerWriter.On("UpdateImages", performerID, [][]byte{imageBytes, additionalImageBytes}).Return(nil).Once()
+
+	err = i.PostImport(performerID)
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterPostImportAdditionalImagesOnly(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		Input: jsonschema.Performer{
+			Images: []string{additionalImage},
+		},
+	}
+
+	err := i.PreImport()
+	assert.Nil(t, err)
+
+	readerWriter.On("UpdateImage", performerID, additionalImageBytes).Return(nil).Once()
+
+	err = i.PostImport(performerID)
+	assert.Nil(t, err)
+
+	i.Input.Images = []string{additionalImage, image}
+	i.additionalImages = nil
+	err = i.PreImport()
+	assert.Nil(t, err)
+
+	readerWriter.On("UpdateImages", performerID, [][]byte{additionalImageBytes, imageBytes}).Return(nil).Once()
+
+	err = i.PostImport(performerID)
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
 func TestImporterFindExistingID(t *testing.T) {
 	readerWriter := &mocks.PerformerReaderWriter{}
 
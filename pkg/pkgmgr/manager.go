@@ -0,0 +1,385 @@
+package pkgmgr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// Timeout for fetching remote files. Includes transfer time.
+const fetchTimeout = time.Second * 60
+
+// Manager installs packages from a package index into a directory.
+type Manager struct {
+	// IndexURL is the http(s) address or local path of the package index.
+	IndexURL string
+
+	// Path is the directory that packages are installed into.
+	Path string
+
+	// Client is the http client used to fetch remote files.
+	Client *http.Client
+}
+
+// NewManager returns a new Manager installing packages from the index at
+// indexURL into path.
+func NewManager(indexURL string, path string) *Manager {
+	return &Manager{
+		IndexURL: indexURL,
+		Path:     path,
+		Client: &http.Client{
+			Timeout: fetchTimeout,
+		},
+	}
+}
+
+func (m Manager) isRemote() bool {
+	return strings.HasPrefix(m.IndexURL, "http://") || strings.HasPrefix(m.IndexURL, "https://")
+}
+
+// fetch returns the contents of the file at the path relative to the
+// directory of the index. An empty path returns the index itself.
+func (m Manager) fetch(path string) ([]byte, error) {
+	if m.IndexURL == "" {
+		return nil, errors.New("package index is not configured")
+	}
+
+	if !m.isRemote() {
+		fn := m.IndexURL
+		if path != "" {
+			fn = filepath.Join(filepath.Dir(m.IndexURL), filepath.FromSlash(path))
+		}
+		return ioutil.ReadFile(fn)
+	}
+
+	u, err := url.Parse(m.IndexURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		rel, err := url.Parse(filepath.ToSlash(path))
+		if err != nil {
+			return nil, err
+		}
+		u = u.ResolveReference(rel)
+	}
+
+	resp, err := m.Client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching %s: http error %d", u.String(), resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// loadIndex loads and validates the packages in the package index.
+func (m Manager) loadIndex() ([]Package, error) {
+	data, err := m.fetch("")
+	if err != nil {
+		return nil, fmt.Errorf("error loading package index: %s", err.Error())
+	}
+
+	var ret []Package
+	parser := yaml.NewDecoder(bytes.NewReader(data))
+	parser.SetStrict(true)
+	if err := parser.Decode(&ret); err != nil {
+		return nil, fmt.Errorf("error parsing package index: %s", err.Error())
+	}
+
+	for _, p := range ret {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func findPackage(packages []Package, id string) *Package {
+	for i := range packages {
+		if packages[i].ID == id {
+			return &packages[i]
+		}
+	}
+
+	return nil
+}
+
+func (m Manager) packagePath(id string) string {
+	return filepath.Join(m.Path, id)
+}
+
+// loadManifest returns the manifest of the installed package with the
+// provided id. Returns nil if the package is not installed.
+func (m Manager) loadManifest(id string) (*manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(m.packagePath(id), ManifestFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &manifest{}
+	if err := yaml.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf("error parsing manifest of package %s: %s", id, err.Error())
+	}
+
+	return ret, nil
+}
+
+// loadManifests returns the manifests of all installed packages.
+func (m Manager) loadManifests() ([]*manifest, error) {
+	entries, err := ioutil.ReadDir(m.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*manifest
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		mf, err := m.loadManifest(e.Name())
+		if err != nil {
+			logger.Warnf("Error loading package %s: %s", e.Name(), err.Error())
+			continue
+		}
+
+		if mf != nil {
+			ret = append(ret, mf)
+		}
+	}
+
+	return ret, nil
+}
+
+// ListAvailable returns the packages in the package index.
+func (m Manager) ListAvailable() ([]*models.Package, error) {
+	packages, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*models.Package
+	for _, p := range packages {
+		ret = append(ret, p.toPackage())
+	}
+
+	return ret, nil
+}
+
+// ListInstalled returns the installed packages. If the package index
+// contains a different version of an installed package, it is returned
+// as the upgrade of the installed package.
+func (m Manager) ListInstalled() ([]*models.InstalledPackage, error) {
+	manifests, err := m.loadManifests()
+	if err != nil {
+		return nil, err
+	}
+
+	var index []Package
+	if m.IndexURL != "" && len(manifests) > 0 {
+		index, err = m.loadIndex()
+		if err != nil {
+			// don't fail listing the installed packages
+			logger.Warnf("Error checking for package updates: %s", err.Error())
+		}
+	}
+
+	var ret []*models.InstalledPackage
+	for _, mf := range manifests {
+		installed := &models.InstalledPackage{
+			Package: mf.Package.toPackage(),
+		}
+
+		if p := findPackage(index, mf.ID); p != nil && p.Version != mf.Version {
+			installed.Upgrade = p.toPackage()
+		}
+
+		ret = append(ret, installed)
+	}
+
+	return ret, nil
+}
+
+// Install installs the packages with the provided ids from the package
+// index. Returns an error if a package is not in the index or is already
+// installed.
+func (m Manager) Install(ids []string) error {
+	index, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		p := findPackage(index, id)
+		if p == nil {
+			return fmt.Errorf("package %s not found in package index", id)
+		}
+
+		existing, err := m.loadManifest(id)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("package %s is already installed", id)
+		}
+
+		if _, err := os.Stat(m.packagePath(id)); err == nil {
+			return fmt.Errorf("cannot install package %s: %s already exists", id, m.packagePath(id))
+		}
+
+		if err := m.install(*p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Update updates the installed packages with the provided ids to the
+// version in the package index. If ids is empty, then all installed packages
+// with a different version in the index are updated.
+func (m Manager) Update(ids []string) error {
+	index, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		manifests, err := m.loadManifests()
+		if err != nil {
+			return err
+		}
+
+		for _, mf := range manifests {
+			ids = append(ids, mf.ID)
+		}
+	}
+
+	for _, id := range ids {
+		existing, err := m.loadManifest(id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return fmt.Errorf("package %s is not installed", id)
+		}
+
+		p := findPackage(index, id)
+		if p == nil {
+			logger.Warnf("Package %s not found in package index. Not updating.", id)
+			continue
+		}
+
+		if p.Version == existing.Version {
+			continue
+		}
+
+		if err := m.install(*p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Uninstall removes the installed packages with the provided ids.
+func (m Manager) Uninstall(ids []string) error {
+	for _, id := range ids {
+		if err := validateID(id); err != nil {
+			return err
+		}
+
+		existing, err := m.loadManifest(id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return fmt.Errorf("package %s is not installed", id)
+		}
+
+		logger.Infof("Uninstalling package %s", id)
+		if err := os.RemoveAll(m.packagePath(id)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// install downloads the package files into a temporary directory, then
+// replaces the package directory with it.
+func (m Manager) install(p Package) error {
+	logger.Infof("Installing package %s version %s", p.ID, p.Version)
+
+	if err := os.MkdirAll(m.Path, 0755); err != nil {
+		return err
+	}
+
+	tmpDir, err := ioutil.TempDir(m.Path, ".pkgmgr-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, f := range p.Files {
+		data, err := m.fetch(f)
+		if err != nil {
+			return fmt.Errorf("error installing package %s: %s", p.ID, err.Error())
+		}
+
+		fn := filepath.Join(tmpDir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(fn, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	mf := manifest{
+		Package: p,
+		Source:  m.IndexURL,
+	}
+
+	data, err := yaml.Marshal(mf)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, ManifestFilename), data, 0644); err != nil {
+		return err
+	}
+
+	dest := m.packagePath(p.ID)
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpDir, dest)
+}
@@ -0,0 +1,256 @@
+package pkgmgr
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testIndex = `- id: scraperA
+  name: Scraper A
+  version: "1"
+  files:
+    - scraperA.yml
+- id: scraperB
+  name: Scraper B
+  description: The second scraper
+  version: "2"
+  date: "2020-10-01"
+  files:
+    - scraperB/scraperB.yml
+    - scraperB/scraperB.py
+`
+
+type testSource struct {
+	dir string
+}
+
+func newTestSource(t *testing.T, index string) *testSource {
+	dir, err := ioutil.TempDir("", "pkgmgr-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := &testSource{dir: dir}
+	ret.writeFile(t, "index.yml", index)
+	ret.writeFile(t, "scraperA.yml", "name: Scraper A")
+	ret.writeFile(t, "scraperB/scraperB.yml", "name: Scraper B")
+	ret.writeFile(t, "scraperB/scraperB.py", "print()")
+
+	return ret
+}
+
+func (s *testSource) writeFile(t *testing.T, fn string, content string) {
+	fn = filepath.Join(s.dir, filepath.FromSlash(fn))
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(fn, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (s *testSource) indexPath() string {
+	return filepath.Join(s.dir, "index.yml")
+}
+
+func newTestManager(t *testing.T, indexURL string) *Manager {
+	dir, err := ioutil.TempDir("", "pkgmgr-dest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewManager(indexURL, dir)
+}
+
+func assertFileContents(t *testing.T, fn string, expected string) {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Errorf("Error reading %s: %s", fn, err.Error())
+		return
+	}
+
+	assert.Equal(t, expected, string(data))
+}
+
+func testInstallUpdateUninstall(t *testing.T, source *testSource, m *Manager) {
+	available, err := m.ListAvailable()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Len(t, available, 2)
+	assert.Equal(t, "scraperB", available[1].ID)
+	assert.Equal(t, "The second scraper", *available[1].Description)
+
+	installed, err := m.ListInstalled()
+	assert.Nil(t, err)
+	assert.Len(t, installed, 0)
+
+	if !assert.Nil(t, m.Install([]string{"scraperA", "scraperB"})) {
+		return
+	}
+
+	assertFileContents(t, filepath.Join(m.Path, "scraperA", "scraperA.yml"), "name: Scraper A")
+	assertFileContents(t, filepath.Join(m.Path, "scraperB", "scraperB", "scraperB.py"), "print()")
+
+	installed, err = m.ListInstalled()
+	assert.Nil(t, err)
+	assert.Len(t, installed, 2)
+	for _, p := range installed {
+		assert.Nil(t, p.Upgrade)
+	}
+
+	// installing again should fail
+	assert.NotNil(t, m.Install([]string{"scraperA"}))
+
+	// bump the version of scraperA in the index
+	source.writeFile(t, "scraperA.yml", "name: Scraper A v2")
+	source.writeFile(t, "index.yml", `- id: scraperA
+  name: Scraper A
+  version: "2"
+  files:
+    - scraperA.yml
+`)
+
+	installed, err = m.ListInstalled()
+	assert.Nil(t, err)
+	for _, p := range installed {
+		if p.Package.ID == "scraperA" {
+			if assert.NotNil(t, p.Upgrade) {
+				assert.Equal(t, "2", p.Upgrade.Version)
+			}
+		} else {
+			assert.Nil(t, p.Upgrade)
+		}
+	}
+
+	// update all - scraperB is no longer in the index and is left alone
+	if !assert.Nil(t, m.Update(nil)) {
+		return
+	}
+
+	assertFileContents(t, filepath.Join(m.Path, "scraperA", "scraperA.yml"), "name: Scraper A v2")
+	assertFileContents(t, filepath.Join(m.Path, "scraperB", "scraperB", "scraperB.yml"), "name: Scraper B")
+
+	if !assert.Nil(t, m.Uninstall([]string{"scraperA", "scraperB"})) {
+		return
+	}
+
+	installed, err = m.ListInstalled()
+	assert.Nil(t, err)
+	assert.Len(t, installed, 0)
+
+	_, err = os.Stat(filepath.Join(m.Path, "scraperA"))
+	assert.True(t, os.IsNotExist(err))
+
+	// uninstalling a package that isn't installed should fail
+	assert.NotNil(t, m.Uninstall([]string{"scraperA"}))
+
+	// uninstalling the packages directory or its parent should fail
+	assert.NotNil(t, m.Uninstall([]string{"."}))
+	assert.NotNil(t, m.Uninstall([]string{".."}))
+}
+
+func TestLocalIndex(t *testing.T) {
+	source := newTestSource(t, testIndex)
+	defer os.RemoveAll(source.dir)
+
+	m := newTestManager(t, source.indexPath())
+	defer os.RemoveAll(m.Path)
+
+	testInstallUpdateUninstall(t, source, m)
+}
+
+func TestRemoteIndex(t *testing.T) {
+	source := newTestSource(t, testIndex)
+	defer os.RemoveAll(source.dir)
+
+	ts := httptest.NewServer(http.FileServer(http.Dir(source.dir)))
+	defer ts.Close()
+
+	m := newTestManager(t, ts.URL+"/index.yml")
+	defer os.RemoveAll(m.Path)
+
+	testInstallUpdateUninstall(t, source, m)
+}
+
+func TestInstallMissingFile(t *testing.T) {
+	source := newTestSource(t, `- id: missing
+  name: Missing
+  version: "1"
+  files:
+    - missing.yml
+`)
+	defer os.RemoveAll(source.dir)
+
+	m := newTestManager(t, source.indexPath())
+	defer os.RemoveAll(m.Path)
+
+	assert.NotNil(t, m.Install([]string{"missing"}))
+
+	// ensure nothing was left behind
+	entries, err := ioutil.ReadDir(m.Path)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 0)
+}
+
+func TestInvalidIndex(t *testing.T) {
+	scenarios := []string{
+		// invalid id
+		`- id: ../escape
+  name: Escape
+  version: "1"
+  files:
+    - escape.yml
+`,
+		// escaping file path
+		`- id: escape
+  name: Escape
+  version: "1"
+  files:
+    - ../escape.yml
+`,
+		// absolute file path
+		`- id: escape
+  name: Escape
+  version: "1"
+  files:
+    - /escape.yml
+`,
+		// file url
+		`- id: escape
+  name: Escape
+  version: "1"
+  files:
+    - http://host/escape.yml
+`,
+		// overwrites manifest
+		`- id: escape
+  name: Escape
+  version: "1"
+  files:
+    - manifest
+`,
+		// no files
+		`- id: empty
+  name: Empty
+  version: "1"
+`,
+	}
+
+	for _, s := range scenarios {
+		source := newTestSource(t, s)
+		m := NewManager(source.indexPath(), "")
+
+		_, err := m.ListAvailable()
+		assert.NotNil(t, err, s)
+
+		os.RemoveAll(source.dir)
+	}
+}
@@ -0,0 +1,139 @@
+// Package pkgmgr implements functions and types for installing, updating
+// and uninstalling packages of scrapers and plugins from a remote index.
+//
+// A package index is a yml file containing a list of packages, following the
+// Package structure format. The files of each package are located relative
+// to the directory containing the index. The index may be served over http(s)
+// or read from the local filesystem.
+//
+// Installed packages are stored in a subdirectory of the target path named
+// after the package id, along with a manifest file describing the installed
+// package.
+//
+// The main entry into the package manager is via the Manager type.
+package pkgmgr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// ManifestFilename is the name of the file describing an installed package.
+// It has no extension so that it is not loaded as a scraper or plugin
+// configuration.
+const ManifestFilename = "manifest"
+
+var validIDRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// Package describes a package in a package index.
+type Package struct {
+	// Unique identifier of the package. This is used as the name of the
+	// directory the package is installed into.
+	ID string `yaml:"id"`
+
+	// The name of the package. This is displayed in the UI.
+	Name string `yaml:"name"`
+
+	// A description of the package.
+	Description string `yaml:"description,omitempty"`
+
+	// The version of the package. Packages are updated when the version in
+	// the index differs from the installed version.
+	Version string `yaml:"version"`
+
+	// The date of the version of the package.
+	Date string `yaml:"date,omitempty"`
+
+	// The files of the package, relative to the directory of the index.
+	Files []string `yaml:"files"`
+}
+
+// validateID ensures that the package id is a valid name for the package
+// directory, which must not be the packages directory or its parent.
+func validateID(id string) error {
+	if !validIDRegex.MatchString(id) || id == "." || id == ".." {
+		return fmt.Errorf("invalid package id: %s", id)
+	}
+
+	return nil
+}
+
+func (p Package) validate() error {
+	if err := validateID(p.ID); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("package %s: name must not be empty", p.ID)
+	}
+
+	if len(p.Files) == 0 {
+		return fmt.Errorf("package %s: files must not be empty", p.ID)
+	}
+
+	for _, f := range p.Files {
+		if err := validateFile(f); err != nil {
+			return fmt.Errorf("package %s: %s", p.ID, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// validateFile ensures that the file is a relative path that does not
+// escape the package directory, and is not a URL that may refer to another
+// host than the index.
+func validateFile(f string) error {
+	if f == "" {
+		return errors.New("file must not be empty")
+	}
+
+	if u, err := url.Parse(filepath.ToSlash(f)); err != nil || u.Scheme != "" || u.Host != "" {
+		return fmt.Errorf("invalid file path: %s", f)
+	}
+
+	cleaned := path.Clean(filepath.ToSlash(f))
+	if path.IsAbs(cleaned) || filepath.IsAbs(f) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("invalid file path: %s", f)
+	}
+
+	if path.Base(cleaned) == ManifestFilename && path.Dir(cleaned) == "." {
+		return fmt.Errorf("invalid file path: %s", f)
+	}
+
+	return nil
+}
+
+func (p Package) toPackage() *models.Package {
+	ret := &models.Package{
+		ID:      p.ID,
+		Name:    p.Name,
+		Version: p.Version,
+		Files:   p.Files,
+	}
+
+	if p.Description != "" {
+		ret.Description = &p.Description
+	}
+
+	if p.Date != "" {
+		ret.Date = &p.Date
+	}
+
+	return ret
+}
+
+// manifest describes an installed package.
+type manifest struct {
+	Package `yaml:",inline"`
+
+	// The index the package was installed from.
+	Source string `yaml:"source"`
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/shurcooL/graphql"
 
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/plugin/common"
 )
 
@@ -32,8 +33,19 @@ func NewClient(provider common.StashServerConnection) *graphql.Client {
 	}
 
 	httpClient := &http.Client{
-		Jar: cookieJar,
+		Jar:       cookieJar,
+		Transport: pluginTransport{},
 	}
 
 	return graphql.NewClient(u.String(), httpClient)
 }
+
+// pluginTransport attributes changes made using the client to plugins.
+type pluginTransport struct{}
+
+func (t pluginTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip should not modify the request
+	req = req.Clone(req.Context())
+	req.Header.Set(models.ChangeSourceHeader, models.AuditSourceEnumPlugin.String())
+	return http.DefaultTransport.RoundTrip(req)
+}
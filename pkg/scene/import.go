@@ -381,6 +381,12 @@ func (i *Importer) PostImport(id int) error {
 		}
 	}
 
+	if len(i.Input.CustomFields) > 0 {
+		if err := i.ReaderWriter.UpdateCustomFields(id, i.Input.CustomFields); err != nil {
+			return fmt.Errorf("error setting scene custom fields: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
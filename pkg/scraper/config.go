@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -101,6 +102,12 @@ func (c config) validate() error {
 		}
 	}
 
+	if c.DriverOptions != nil {
+		if err := c.DriverOptions.validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -175,11 +182,73 @@ type clickOptions struct {
 	Sleep int    `yaml:"sleep"`
 }
 
+type headerOptions struct {
+	Key   string `yaml:"Key"`
+	Value string `yaml:"Value"`
+}
+
 type scraperDriverOptions struct {
-	UseCDP  bool             `yaml:"useCDP"`
-	Sleep   int              `yaml:"sleep"`
-	Clicks  []*clickOptions  `yaml:"clicks"`
-	Cookies []*cookieOptions `yaml:"cookies"`
+	UseCDP  bool                 `yaml:"useCDP"`
+	Sleep   int                  `yaml:"sleep"`
+	Clicks  []*clickOptions      `yaml:"clicks"`
+	Cookies []*cookieOptions     `yaml:"cookies"`
+	Login   *scraperLoginOptions `yaml:"login"`
+
+	// HTTP method used for the request. Defaults to GET.
+	Method string `yaml:"method"`
+	// Headers added to the request. Values may contain placeholders.
+	Headers []*headerOptions `yaml:"headers"`
+	// Request body. May contain placeholders.
+	Body string `yaml:"body"`
+}
+
+func (o scraperDriverOptions) getMethod() string {
+	if o.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(o.Method)
+}
+
+// getBodyContentType returns the configured Content-Type header, or an
+// empty string if it is not configured.
+func (o scraperDriverOptions) getBodyContentType() string {
+	for _, h := range o.Headers {
+		if strings.EqualFold(h.Key, "Content-Type") {
+			return h.Value
+		}
+	}
+
+	return ""
+}
+
+func (o scraperDriverOptions) validate() error {
+	switch o.getMethod() {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("%s is not a supported request method", o.Method)
+	}
+
+	if o.getMethod() == http.MethodGet && o.Body != "" {
+		return errors.New("request body is not supported for GET requests")
+	}
+
+	if o.UseCDP && (o.getMethod() != http.MethodGet || o.Body != "") {
+		return errors.New("request method and body are not supported when using CDP")
+	}
+
+	for _, h := range o.Headers {
+		if h.Key == "" {
+			return errors.New("header key must not be empty")
+		}
+	}
+
+	if o.Login != nil {
+		if err := o.Login.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func loadScraperFromYAML(id string, reader io.Reader) (*config, error) {
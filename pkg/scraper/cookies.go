@@ -148,3 +148,31 @@ func printCDPCookies(driverOptions scraperDriverOptions, msg string) chromedp.Ac
 		return nil
 	})
 }
+
+// set the cookies of the login session for the url
+func setCDPLoginSession(pageURL string, session *loginSession) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if session == nil {
+			return nil
+		}
+
+		u, err := url.Parse(pageURL)
+		if err != nil {
+			return err
+		}
+
+		for _, cookie := range session.jar.Cookies(u) {
+			success, err := network.SetCookie(cookie.Name, cookie.Value).
+				WithURL(pageURL).
+				Do(ctx)
+			if err != nil {
+				return err
+			}
+			if !success {
+				return fmt.Errorf("could not set chrome cookie %s", cookie.Name)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,383 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v2"
+)
+
+// HarnessOptions contains the options used to run a scraper using the
+// scraper test harness.
+type HarnessOptions struct {
+	// Path to the scraper configuration file.
+	ConfigPath string
+	// Name of the xpath or json scraper to run. May be omitted if the
+	// configuration contains a single scraper, or if the URL matches one of
+	// the by URL configurations.
+	Scraper string
+	// URL to scrape. Ignored if File is set.
+	URL string
+	// Path to a saved html or json document to scrape instead of the URL.
+	File string
+	// Global scraper configuration used when loading URLs.
+	GlobalConfig GlobalConfig
+	// Writer to output the trace of the scrape to. May be nil.
+	Out io.Writer
+}
+
+// HarnessResults contains the results of a scrape run by the test harness,
+// keyed by the section of the scraper configuration. For example, the
+// results of the scene performers section are keyed by "scene.Performers".
+type HarnessResults map[string]mappedResults
+
+// LoadHarnessResults loads the expected results of a scrape from a yaml
+// file. The file format matches the results output by RunHarness.
+func LoadHarnessResults(path string) (HarnessResults, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret HarnessResults
+	if err := yaml.UnmarshalStrict(data, &ret); err != nil {
+		return nil, fmt.Errorf("error reading expected results: %s", err.Error())
+	}
+
+	return ret, nil
+}
+
+// Diff returns the differences between the results and the expected
+// results. Returns an empty slice if the results match.
+func (r HarnessResults) Diff(expected HarnessResults) []string {
+	var ret []string
+
+	for _, section := range sectionKeys(r, expected) {
+		got := r[section]
+		want := expected[section]
+
+		if len(got) != len(want) {
+			ret = append(ret, fmt.Sprintf("%s: expected %d results, got %d", section, len(want), len(got)))
+		}
+
+		for i := 0; i < len(got) && i < len(want); i++ {
+			for _, field := range fieldKeys(got[i], want[i]) {
+				gotValue, gotOK := got[i][field]
+				wantValue, wantOK := want[i][field]
+
+				switch {
+				case !gotOK:
+					ret = append(ret, fmt.Sprintf("%s[%d].%s: expected %q, got nothing", section, i, field, wantValue))
+				case !wantOK:
+					ret = append(ret, fmt.Sprintf("%s[%d].%s: expected nothing, got %q", section, i, field, gotValue))
+				case gotValue != wantValue:
+					ret = append(ret, fmt.Sprintf("%s[%d].%s: expected %q, got %q", section, i, field, wantValue, gotValue))
+				}
+			}
+		}
+	}
+
+	return ret
+}
+
+func sectionKeys(a HarnessResults, b HarnessResults) []string {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+
+	return sortedKeys(keys)
+}
+
+func fieldKeys(a mappedResult, b mappedResult) []string {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+
+	return sortedKeys(keys)
+}
+
+func sortedKeys(keys map[string]bool) []string {
+	var ret []string
+	for k := range keys {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// RunHarness runs the xpath or json scraper from a scraper configuration
+// file against a URL or a saved document. A trace of the values found for
+// each field, including the intermediate post-processed values, is written
+// to the Out writer of the options. Returns the results of all sections of
+// the scraper.
+func RunHarness(options HarnessOptions) (HarnessResults, error) {
+	c, err := loadScraperFromYAMLFile(options.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := getHarnessScraperName(*c, options)
+	if err != nil {
+		return nil, err
+	}
+
+	out := options.Out
+	if out == nil {
+		out = ioutil.Discard
+	}
+
+	tracer := &harnessTracer{out: out}
+
+	var scraper *mappedScraper
+	var q mappedQuery
+	if s := c.XPathScrapers[name]; s != nil {
+		scraper = s
+		q, err = getHarnessXPathQuery(*c, name, options)
+	} else if s := c.JsonScrapers[name]; s != nil {
+		scraper = s
+		q, err = getHarnessJsonQuery(*c, name, options)
+	} else {
+		return nil, fmt.Errorf("scraper with name %s not found in config", name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scraper.runHarness(harnessQuery{mappedQuery: q, tracer: tracer}, tracer), nil
+}
+
+func getHarnessScraperName(c config, options HarnessOptions) (string, error) {
+	if options.Scraper != "" {
+		return options.Scraper, nil
+	}
+
+	// use the scraper of the matching by URL configuration
+	if options.URL != "" {
+		var byURL []*scrapeByURLConfig
+		byURL = append(byURL, c.PerformerByURL...)
+		byURL = append(byURL, c.SceneByURL...)
+		byURL = append(byURL, c.GalleryByURL...)
+		byURL = append(byURL, c.MovieByURL...)
+
+		for _, b := range byURL {
+			if b.Scraper != "" && b.matchesURL(options.URL) {
+				return b.Scraper, nil
+			}
+		}
+	}
+
+	var names []string
+	for k := range c.XPathScrapers {
+		names = append(names, k)
+	}
+	for k := range c.JsonScrapers {
+		names = append(names, k)
+	}
+
+	if len(names) == 1 {
+		return names[0], nil
+	}
+
+	if len(names) == 0 {
+		return "", errors.New("config does not contain any xpath or json scrapers")
+	}
+
+	sort.Strings(names)
+	return "", fmt.Errorf("config contains multiple scrapers, scraper name must be one of: %s", strings.Join(names, ", "))
+}
+
+func getHarnessXPathQuery(c config, name string, options HarnessOptions) (mappedQuery, error) {
+	s := newXpathScraper(scraperTypeConfig{Action: scraperActionXPath, Scraper: name}, nil, c, options.GlobalConfig)
+
+	if options.File != "" {
+		f, err := os.Open(options.File)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		doc, err := html.Parse(f)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.getXPathQuery(doc), nil
+	}
+
+	if options.URL == "" {
+		return nil, errors.New("url or file must be provided")
+	}
+
+	doc, err := s.loadURL(options.URL, queryURLParameterFromURL(options.URL))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getXPathQuery(doc), nil
+}
+
+func getHarnessJsonQuery(c config, name string, options HarnessOptions) (mappedQuery, error) {
+	s := newJsonScraper(scraperTypeConfig{Action: scraperActionJson, Scraper: name}, nil, c, options.GlobalConfig)
+
+	if options.File != "" {
+		doc, err := ioutil.ReadFile(options.File)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.getJsonQuery(string(doc)), nil
+	}
+
+	if options.URL == "" {
+		return nil, errors.New("url or file must be provided")
+	}
+
+	doc, err := s.loadURL(options.URL, queryURLParameterFromURL(options.URL))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getJsonQuery(doc), nil
+}
+
+// runHarness processes all of the configured sections of the scraper,
+// regardless of whether the main section returned any results.
+func (s mappedScraper) runHarness(q mappedQuery, tracer *harnessTracer) HarnessResults {
+	ret := make(HarnessResults)
+
+	process := func(section string, c mappedConfig) {
+		if c == nil {
+			return
+		}
+
+		tracer.traceSection(section)
+		if results := c.process(q, s.Common); len(results) > 0 {
+			ret[section] = results
+		}
+	}
+
+	if s.Performer != nil {
+		process("performer", s.Performer.mappedConfig)
+		process("performer."+mappedScraperConfigPerformerImages, s.Performer.Images)
+	}
+
+	if s.Scene != nil {
+		process("scene", s.Scene.mappedConfig)
+		process("scene."+mappedScraperConfigScenePerformers, s.Scene.Performers)
+		process("scene."+mappedScraperConfigSceneTags, s.Scene.Tags)
+		process("scene."+mappedScraperConfigSceneStudio, s.Scene.Studio)
+		process("scene."+mappedScraperConfigSceneMovies, s.Scene.Movies)
+	}
+
+	if s.Gallery != nil {
+		process("gallery", s.Gallery.mappedConfig)
+		process("gallery."+mappedScraperConfigScenePerformers, s.Gallery.Performers)
+		process("gallery."+mappedScraperConfigSceneTags, s.Gallery.Tags)
+		process("gallery."+mappedScraperConfigSceneStudio, s.Gallery.Studio)
+	}
+
+	if s.Movie != nil {
+		process("movie", s.Movie.mappedConfig)
+		process("movie."+mappedScraperConfigMovieStudio, s.Movie.Studio)
+	}
+
+	return ret
+}
+
+// harnessTracer writes the intermediate values of a scrape.
+type harnessTracer struct {
+	out io.Writer
+}
+
+func (t *harnessTracer) traceSection(section string) {
+	fmt.Fprintf(t.out, "== %s ==\n", section)
+}
+
+func (t *harnessTracer) traceField(key string) {
+	fmt.Fprintf(t.out, "%s\n", key)
+}
+
+func (t *harnessTracer) traceQuery(selector string, found []string) {
+	fmt.Fprintf(t.out, "  selector: %s\n", selector)
+	fmt.Fprintf(t.out, "  found: %s\n", quoteAll(found))
+}
+
+func (t *harnessTracer) traceSubScrape(url string) {
+	fmt.Fprintf(t.out, "  subScraper: loading %s\n", url)
+}
+
+func (t *harnessTracer) tracePostProcess(action postProcessAction, before string, after string) {
+	fmt.Fprintf(t.out, "  %s: %q -> %q\n", postProcessActionName(action), before, after)
+}
+
+func quoteAll(values []string) string {
+	var quoted []string
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func postProcessActionName(action postProcessAction) string {
+	switch action.(type) {
+	case *postProcessParseDate:
+		return "parseDate"
+	case *postProcessReplace:
+		return "replace"
+	case *postProcessSubScraper:
+		return "subScraper"
+	case *postProcessMap:
+		return "map"
+	case *postProcessFeetToCm:
+		return "feetToCm"
+	}
+
+	return fmt.Sprintf("%T", action)
+}
+
+// harnessQuery wraps a mappedQuery, tracing the queries and sub-scrapes
+// performed.
+type harnessQuery struct {
+	mappedQuery
+	tracer *harnessTracer
+}
+
+func (q harnessQuery) runQuery(selector string) []string {
+	ret := q.mappedQuery.runQuery(selector)
+	q.tracer.traceQuery(selector, ret)
+	return ret
+}
+
+func (q harnessQuery) subScrape(value string) mappedQuery {
+	q.tracer.traceSubScrape(value)
+	ss := q.mappedQuery.subScrape(value)
+	if ss == nil {
+		return nil
+	}
+
+	return harnessQuery{mappedQuery: ss, tracer: q.tracer}
+}
+
+func (q harnessQuery) traceField(key string) {
+	q.tracer.traceField(key)
+}
+
+func (q harnessQuery) tracePostProcess(action postProcessAction, before string, after string) {
+	q.tracer.tracePostProcess(action, before, after)
+}
@@ -0,0 +1,88 @@
+package scraper
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const harnessTestConfig = `name: Test
+xPathScrapers:
+  performerScraper:
+    performer:
+      Name:
+        selector: //h1
+        postProcess:
+          - replace:
+              - regex: \s+Profile
+                with: ""
+      Birthdate:
+        selector: //span[@class="dob"]
+        postProcess:
+          - parseDate: January 2, 2006
+`
+
+const harnessTestHTML = `<html>
+<h1>Jane Doe Profile</h1>
+<span class="dob">March 4, 1990</span>
+</html>`
+
+func writeHarnessTestFile(t *testing.T, dir string, name string, contents string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Error writing %s: %s", name, err.Error())
+	}
+
+	return path
+}
+
+func TestRunHarness(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-scraper-harness")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	results, err := RunHarness(HarnessOptions{
+		ConfigPath: writeHarnessTestFile(t, dir, "test.yml", harnessTestConfig),
+		File:       writeHarnessTestFile(t, dir, "performer.html", harnessTestHTML),
+		Out:        &out,
+	})
+	if err != nil {
+		t.Fatalf("Error running harness: %s", err.Error())
+	}
+
+	trace := out.String()
+	assert.True(t, strings.Contains(trace, `replace: "Jane Doe Profile" -> "Jane Doe"`), trace)
+	assert.True(t, strings.Contains(trace, `parseDate: "March 4, 1990" -> "1990-03-04"`), trace)
+
+	const expected = `performer:
+  - Name: Jane Doe
+    Birthdate: "1990-03-04"
+`
+	expectedResults, err := LoadHarnessResults(writeHarnessTestFile(t, dir, "expected.yml", expected))
+	if err != nil {
+		t.Fatalf("Error loading expected results: %s", err.Error())
+	}
+
+	assert.Len(t, results.Diff(expectedResults), 0)
+
+	const different = `performer:
+  - Name: Jane Doe
+    Birthdate: "1990-03-05"
+`
+	differentResults, err := LoadHarnessResults(writeHarnessTestFile(t, dir, "different.yml", different))
+	if err != nil {
+		t.Fatalf("Error loading expected results: %s", err.Error())
+	}
+
+	assert.Equal(t, []string{
+		`performer[0].Birthdate: expected "1990-03-05", got "1990-03-04"`,
+	}, results.Diff(differentResults))
+}
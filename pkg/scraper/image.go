@@ -2,11 +2,14 @@ package scraper
 
 import (
 	"crypto/tls"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/stashapp/stash/pkg/logger"
 	stashConfig "github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
@@ -16,8 +19,24 @@ import (
 // configurable at some point.
 const imageGetTimeout = time.Second * 30
 
+// maxImageSize is the maximum size in bytes of an image that is downloaded.
+var maxImageSize int64 = 50 << 20
+
 func setPerformerImage(p *models.ScrapedPerformer, globalConfig GlobalConfig) error {
-	if p == nil || p.Image == nil || !strings.HasPrefix(*p.Image, "http") {
+	if p == nil {
+		return nil
+	}
+
+	setPerformerImages(p, globalConfig)
+
+	// the first image is the primary image if the image was not set
+	if p.Image == nil && len(p.Images) > 0 {
+		img := p.Images[0]
+		p.Image = &img
+		return nil
+	}
+
+	if p.Image == nil || !strings.HasPrefix(*p.Image, "http") {
 		// nothing to do
 		return nil
 	}
@@ -32,6 +51,28 @@ func setPerformerImage(p *models.ScrapedPerformer, globalConfig GlobalConfig) er
 	return nil
 }
 
+// setPerformerImages gets the images of the performer that appear to be
+// URLs. Images that cannot be retrieved are removed.
+func setPerformerImages(p *models.ScrapedPerformer, globalConfig GlobalConfig) {
+	var images []string
+	for _, image := range p.Images {
+		if !strings.HasPrefix(image, "http") {
+			images = append(images, image)
+			continue
+		}
+
+		img, err := getImage(image, globalConfig)
+		if err != nil {
+			logger.Warnf("Could not set image using URL %s: %s", image, err.Error())
+			continue
+		}
+
+		images = append(images, *img)
+	}
+
+	p.Images = images
+}
+
 func setSceneImage(s *models.ScrapedScene, globalConfig GlobalConfig) error {
 	// don't try to get the image if it doesn't appear to be a URL
 	if s == nil || s.Image == nil || !strings.HasPrefix(*s.Image, "http") {
@@ -84,6 +125,24 @@ func setMovieBackImage(m *models.ScrapedMovie, globalConfig GlobalConfig) error
 }
 
 func getImage(url string, globalConfig GlobalConfig) (*string, error) {
+	resp, body, err := getImageData(url, globalConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// determine the image type and set the base64 type
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+
+	img := "data:" + contentType + ";base64," + utils.GetBase64StringFromData(body)
+	return &img, nil
+}
+
+// getImageData gets the image at the provided URL, returning the response
+// and its body. Returns an error if the body is larger than maxImageSize.
+func getImageData(url string, globalConfig GlobalConfig) (*http.Response, []byte, error) {
 	client := &http.Client{
 		Transport: &http.Transport{ // ignore insecure certificates
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: !stashConfig.GetScraperCertCheck()}},
@@ -92,7 +151,7 @@ func getImage(url string, globalConfig GlobalConfig) (*string, error) {
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	userAgent := globalConfig.UserAgent
@@ -110,24 +169,49 @@ func getImage(url string, globalConfig GlobalConfig) (*string, error) {
 	resp, err := client.Do(req)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	if resp.ContentLength > maxImageSize {
+		return nil, nil, fmt.Errorf("image at %s is larger than %d bytes", url, maxImageSize)
+	}
+
+	// read one more byte than the maximum to detect larger bodies
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if int64(len(body)) > maxImageSize {
+		return nil, nil, fmt.Errorf("image at %s is larger than %d bytes", url, maxImageSize)
+	}
+
+	return resp, body, nil
+}
+
+// FetchImage gets the image at the provided URL using the user agent of the
+// scrapers. Returns an error if the server does not respond with an image.
+func (c Cache) FetchImage(url string) ([]byte, error) {
+	resp, body, err := getImageData(url, c.globalConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	// determine the image type and set the base64 type
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error %d getting image from %s", resp.StatusCode, url)
+	}
+
 	contentType := resp.Header.Get("Content-Type")
-	if contentType == "" {
+	if contentType == "" || contentType == "application/octet-stream" {
 		contentType = http.DetectContentType(body)
 	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%s is not an image: %s", url, contentType)
+	}
 
-	img := "data:" + contentType + ";base64," + utils.GetBase64StringFromData(body)
-	return &img, nil
+	return body, nil
 }
 
 func getStashPerformerImage(stashURL string, performerID string, globalConfig GlobalConfig) (*string, error) {
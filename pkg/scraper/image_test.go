@@ -0,0 +1,43 @@
+package scraper
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetImageDataMaxSize(t *testing.T) {
+	origMaxImageSize := maxImageSize
+	maxImageSize = 8
+	defer func() {
+		maxImageSize = origMaxImageSize
+	}()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := bytes.Repeat([]byte("a"), 8)
+		if r.URL.Path == "/large" {
+			data = append(data, 'a')
+		}
+
+		// flush the headers so that the content length is not set
+		if r.URL.Query().Get("chunked") != "" {
+			w.(http.Flusher).Flush()
+		}
+
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	_, body, err := getImageData(ts.URL+"/small", GlobalConfig{})
+	assert.Nil(t, err)
+	assert.Len(t, body, 8)
+
+	_, _, err = getImageData(ts.URL+"/large", GlobalConfig{})
+	assert.NotNil(t, err)
+
+	_, _, err = getImageData(ts.URL+"/large?chunked=true", GlobalConfig{})
+	assert.NotNil(t, err)
+}
@@ -38,7 +38,7 @@ func (s *jsonScraper) scrapeURL(url string) (string, *mappedScraper, error) {
 		return "", nil, errors.New("json scraper with name " + s.scraper.Scraper + " not found in config")
 	}
 
-	doc, err := s.loadURL(url)
+	doc, err := s.loadURL(url, queryURLParameterFromURL(url))
 
 	if err != nil {
 		return "", nil, err
@@ -47,8 +47,8 @@ func (s *jsonScraper) scrapeURL(url string) (string, *mappedScraper, error) {
 	return doc, scraper, nil
 }
 
-func (s *jsonScraper) loadURL(url string) (string, error) {
-	r, err := loadURL(url, s.config, s.globalConfig)
+func (s *jsonScraper) loadURL(url string, params queryURLParameters) (string, error) {
+	r, err := loadURL(url, params, s.config, s.globalConfig)
 	if err != nil {
 		return "", err
 	}
@@ -129,7 +129,11 @@ func (s *jsonScraper) scrapePerformersByName(name string) ([]*models.ScrapedPerf
 	url := s.scraper.QueryURL
 	url = strings.Replace(url, placeholder, escapedName, -1)
 
-	doc, err := s.loadURL(url)
+	params := queryURLParameters{
+		"query": name,
+		"url":   url,
+	}
+	doc, err := s.loadURL(url, params)
 
 	if err != nil {
 		return nil, err
@@ -166,7 +170,7 @@ func (s *jsonScraper) scrapeSceneByFragment(scene models.SceneUpdateInput) (*mod
 		return nil, errors.New("json scraper with name " + s.scraper.Scraper + " not found in config")
 	}
 
-	doc, err := s.loadURL(url)
+	doc, err := s.loadURL(url, queryURL)
 
 	if err != nil {
 		return nil, err
@@ -199,7 +203,7 @@ func (s *jsonScraper) scrapeGalleryByFragment(gallery models.GalleryUpdateInput)
 		return nil, errors.New("json scraper with name " + s.scraper.Scraper + " not found in config")
 	}
 
-	doc, err := s.loadURL(url)
+	doc, err := s.loadURL(url, queryURL)
 
 	if err != nil {
 		return nil, err
@@ -243,7 +247,8 @@ func (q *jsonQuery) runQuery(selector string) []string {
 }
 
 func (q *jsonQuery) subScrape(value string) mappedQuery {
-	doc, err := q.scraper.loadURL(value)
+	// sub-scrapes are always fetched using GET without a request body
+	doc, err := q.scraper.loadURL(value, nil)
 
 	if err != nil {
 		logger.Warnf("Error getting URL '%s' for sub-scraper: %s", value, err.Error())
@@ -1,8 +1,15 @@
 package scraper
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
 	"gopkg.in/yaml.v2"
 )
 
@@ -91,3 +98,181 @@ jsonScrapers:
 	verifyField(t, "None", scrapedPerformer.Tattoos, "Tattoos")
 	verifyField(t, "Navel", scrapedPerformer.Piercings, "Piercings")
 }
+
+func TestJsonScraperRequestOptions(t *testing.T) {
+	const apiKey = "secret"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if r.Method != http.MethodPost || r.Header.Get("X-Api-Key") != apiKey || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		name := gjson.Get(string(body), "variables.name").String()
+		fmt.Fprintf(w, `{"data": {"performers": [{"name": "%s"}]}}`, name)
+	}))
+	defer ts.Close()
+
+	yamlStr := `name: Test
+performerByName:
+  action: scrapeJson
+  queryURL: ` + ts.URL + `/graphql
+  scraper: performerSearch
+jsonScrapers:
+  performerSearch:
+    performer:
+      Name: data.performers.#.name
+driver:
+  method: post
+  headers:
+    - Key: X-Api-Key
+      Value: "{api_key}"
+  body: '{"query": "query($name: String!) { performers(name: $name) { name } }", "variables": {"name": "{query}"}}'
+`
+
+	c, err := loadScraperFromYAML("test", strings.NewReader(yamlStr))
+	if err != nil {
+		t.Fatalf("Error loading yaml: %s", err.Error())
+	}
+
+	globalConfig := GlobalConfig{
+		Credentials: map[string]map[string]string{
+			"test": {
+				"api_key": apiKey,
+			},
+		},
+	}
+
+	performers, err := c.ScrapePerformerNames("Mia", nil, globalConfig)
+	if err != nil {
+		t.Fatalf("Error scraping performers: %s", err.Error())
+	}
+
+	if len(performers) != 1 {
+		t.Fatalf("Expected 1 performer, got %d", len(performers))
+	}
+
+	verifyField(t, "Mia", performers[0].Name, "Name")
+}
+
+func TestScrapeRequestBodyEscaping(t *testing.T) {
+	const query = `say "hi" \ you & me`
+
+	scenarios := []struct {
+		name        string
+		driver      scraperDriverOptions
+		contentType string
+		body        string
+	}{
+		{
+			"json",
+			scraperDriverOptions{
+				Method: "POST",
+				Body:   `{"variables": {"name": "{query}"}}`,
+			},
+			"application/json",
+			`{"variables": {"name": "say \"hi\" \\ you \u0026 me"}}`,
+		},
+		{
+			"inferred json",
+			scraperDriverOptions{
+				Method: "POST",
+				Body:   `{"name": "{query}", "page": {page}}`,
+			},
+			"application/json",
+			`{"name": "say \"hi\" \\ you \u0026 me", "page": 1}`,
+		},
+		{
+			"form",
+			scraperDriverOptions{
+				Method: "POST",
+				Body:   "name={query}&page=1",
+			},
+			"application/x-www-form-urlencoded",
+			"name=say+%22hi%22+%5C+you+%26+me&page=1",
+		},
+		{
+			"configured json",
+			scraperDriverOptions{
+				Method:  "POST",
+				Headers: []*headerOptions{{Key: "content-type", Value: "application/json; charset=utf-8"}},
+				Body:    `{"name": "{query}", "page": {page}}`,
+			},
+			"application/json; charset=utf-8",
+			`{"name": "say \"hi\" \\ you \u0026 me", "page": 1}`,
+		},
+		{
+			"other",
+			scraperDriverOptions{
+				Method:  "POST",
+				Headers: []*headerOptions{{Key: "Content-Type", Value: "text/plain"}},
+				Body:    "{query}",
+			},
+			"text/plain",
+			query,
+		},
+	}
+
+	for _, s := range scenarios {
+		c := config{ID: "test", DriverOptions: &s.driver}
+		params := queryURLParameters{"query": query, "page": "1"}
+
+		req, err := newScrapeRequest("http://localhost", params, c, GlobalConfig{})
+		if err != nil {
+			t.Errorf("[%s] error creating request: %s", s.name, err.Error())
+			continue
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.Equal(t, s.body, string(body), s.name)
+		assert.Equal(t, s.contentType, req.Header.Get("Content-Type"), s.name)
+
+		if strings.HasPrefix(s.contentType, "application/json") {
+			assert.True(t, gjson.Valid(string(body)), s.name)
+			name := gjson.Get(string(body), "name")
+			if !name.Exists() {
+				name = gjson.Get(string(body), "variables.name")
+			}
+			assert.Equal(t, query, name.String(), s.name)
+		}
+	}
+}
+
+func TestConstructBodyEscapedPlaceholders(t *testing.T) {
+	params := queryURLParameters{"query": "name", "url": "http://example.com"}
+
+	// braces of GraphQL selection sets are escaped
+	body, contentType := params.constructBody(`{"query": "{ findScene(title: \"{query}\") \{url} }"}`, "")
+	assert.Equal(t, `{"query": "{ findScene(title: \"name\") {url} }"}`, body)
+	assert.Equal(t, "application/json", contentType)
+}
+
+func TestInvalidDriverRequestOptions(t *testing.T) {
+	scenarios := []string{
+		`name: Test
+driver:
+  method: DELETE
+`,
+		`name: Test
+driver:
+  body: test
+`,
+		`name: Test
+driver:
+  useCDP: true
+  method: POST
+`,
+		`name: Test
+driver:
+  headers:
+    - Value: test
+`,
+	}
+
+	for _, s := range scenarios {
+		_, err := loadScraperFromYAML("test", strings.NewReader(s))
+		assert.NotNil(t, err, s)
+	}
+}
@@ -0,0 +1,296 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/stashapp/stash/pkg/logger"
+)
+
+type loginType string
+
+const (
+	loginTypeForm loginType = "form"
+	loginTypeJSON loginType = "json"
+)
+
+func (e loginType) IsValid() bool {
+	switch e {
+	case loginTypeForm, loginTypeJSON:
+		return true
+	}
+	return false
+}
+
+const defaultLoginTokenHeader = "Authorization"
+const defaultLoginTokenFormat = "Bearer {token}"
+
+type scraperLoginOptions struct {
+	// Type of the login request. form posts url-encoded fields, json posts
+	// the fields as a json object.
+	Type loginType `yaml:"type"`
+	// URL to post the login request to.
+	URL string `yaml:"url"`
+	// Fields to send in the login request. Values may contain placeholders
+	// such as {username} which are replaced with the credentials
+	// configured for the scraper in the stash configuration.
+	Fields map[string]string `yaml:"fields"`
+	// GJSON path to the token in the login response. If unset, only the
+	// cookies set by the login response are used.
+	TokenPath string `yaml:"tokenPath"`
+	// Header to set to the token. Defaults to Authorization.
+	TokenHeader string `yaml:"tokenHeader"`
+	// Format of the header value. {token} is replaced with the token.
+	// Defaults to "Bearer {token}".
+	TokenFormat string `yaml:"tokenFormat"`
+	// GJSON path to the number of seconds until the token expires.
+	ExpiresInPath string `yaml:"expiresInPath"`
+	// Number of seconds after which the login is refreshed. Overrides
+	// the expiry of the login response cookies and token.
+	Expiry int `yaml:"expiry"`
+}
+
+func (o scraperLoginOptions) validate() error {
+	if o.URL == "" {
+		return errors.New("url is mandatory for scraper login")
+	}
+
+	if o.Type != "" && !o.Type.IsValid() {
+		return fmt.Errorf("%s is not a valid scraper login type", o.Type)
+	}
+
+	return nil
+}
+
+func (o scraperLoginOptions) getType() loginType {
+	if o.Type == "" {
+		return loginTypeForm
+	}
+	return o.Type
+}
+
+func (o scraperLoginOptions) getTokenHeader() string {
+	if o.TokenHeader == "" {
+		return defaultLoginTokenHeader
+	}
+	return o.TokenHeader
+}
+
+func (o scraperLoginOptions) getTokenFormat() string {
+	if o.TokenFormat == "" {
+		return defaultLoginTokenFormat
+	}
+	return o.TokenFormat
+}
+
+// loginSession holds the cookies and headers obtained by logging in with
+// a scraper.
+type loginSession struct {
+	jar     *cookiejar.Jar
+	headers map[string]string
+	expires time.Time
+}
+
+func (s *loginSession) expired() bool {
+	return !s.expires.IsZero() && time.Now().After(s.expires)
+}
+
+// applyHeaders sets the session headers in the provided request headers,
+// which are used for both HTTP and CDP requests. Session headers replace
+// configured headers with the same name.
+func (s *loginSession) applyHeaders(headers map[string]string) {
+	for k, v := range s.headers {
+		headers[k] = v
+	}
+}
+
+// loginSessions caches the login sessions by scraper ID, so that logging
+// in is only performed when the session is missing or has expired.
+var loginSessions = struct {
+	sync.Mutex
+	sessions map[string]*loginSession
+}{
+	sessions: make(map[string]*loginSession),
+}
+
+// clearLoginSessions removes all cached login sessions.
+func clearLoginSessions() {
+	loginSessions.Lock()
+	defer loginSessions.Unlock()
+
+	loginSessions.sessions = make(map[string]*loginSession)
+}
+
+// invalidateLoginSession removes the cached login session for the scraper,
+// forcing the next request to login again.
+func invalidateLoginSession(scraperConfig config) {
+	loginSessions.Lock()
+	defer loginSessions.Unlock()
+
+	delete(loginSessions.sessions, scraperConfig.ID)
+}
+
+// getLoginSession returns the login session for the scraper, logging in if
+// no session exists or the existing session has expired. Returns nil if the
+// scraper has no login configuration.
+func getLoginSession(client *http.Client, scraperConfig config, globalConfig GlobalConfig) (*loginSession, error) {
+	driverOptions := scraperConfig.DriverOptions
+	if driverOptions == nil || driverOptions.Login == nil {
+		return nil, nil
+	}
+
+	loginSessions.Lock()
+	defer loginSessions.Unlock()
+
+	session := loginSessions.sessions[scraperConfig.ID]
+	if session != nil && !session.expired() {
+		return session, nil
+	}
+
+	if session != nil {
+		logger.Debugf("Login session for scraper %s expired", scraperConfig.ID)
+	}
+
+	session, err := login(client, *driverOptions.Login, scraperConfig.ID, globalConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	loginSessions.sessions[scraperConfig.ID] = session
+	return session, nil
+}
+
+// login performs the login request described by the login options, using
+// the credentials configured for the scraper with the provided id.
+func login(client *http.Client, options scraperLoginOptions, scraperID string, globalConfig GlobalConfig) (*loginSession, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{
+		PublicSuffixList: publicsuffix.List,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// replace the placeholders in the fields with the credentials
+	credentials := queryURLParameters(globalConfig.getCredentials(scraperID))
+	fields := make(map[string]string)
+	for k, v := range options.Fields {
+		fields[k] = credentials.constructURL(v)
+	}
+
+	var req *http.Request
+	switch options.getType() {
+	case loginTypeJSON:
+		body, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err = http.NewRequest("POST", options.URL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+	default:
+		values := url.Values{}
+		for k, v := range fields {
+			values.Set(k, v)
+		}
+
+		req, err = http.NewRequest("POST", options.URL, strings.NewReader(values.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	if globalConfig.UserAgent != "" {
+		req.Header.Set("User-Agent", globalConfig.UserAgent)
+	}
+
+	loginClient := *client
+	loginClient.Jar = jar
+
+	logger.Debugf("Logging in to %s for scraper %s", options.URL, scraperID)
+	resp, err := loginClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("login to %s failed: http error %d", options.URL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &loginSession{
+		jar:     jar,
+		headers: make(map[string]string),
+		expires: getCookieExpiry(resp.Cookies()),
+	}
+
+	if options.TokenPath != "" || options.ExpiresInPath != "" {
+		doc := string(body)
+		if !gjson.Valid(doc) {
+			return nil, errors.New("login response is not valid json")
+		}
+
+		if options.TokenPath != "" {
+			token := gjson.Get(doc, options.TokenPath)
+			if !token.Exists() {
+				return nil, fmt.Errorf("could not find token path '%s' in login response", options.TokenPath)
+			}
+
+			value := strings.Replace(options.getTokenFormat(), "{token}", token.String(), -1)
+			session.headers[options.getTokenHeader()] = value
+		}
+
+		if options.ExpiresInPath != "" {
+			expiresIn := gjson.Get(doc, options.ExpiresInPath)
+			if expiresIn.Exists() {
+				session.expires = time.Now().Add(time.Duration(expiresIn.Int()) * time.Second)
+			}
+		}
+	}
+
+	if options.Expiry > 0 {
+		session.expires = time.Now().Add(time.Duration(options.Expiry) * time.Second)
+	}
+
+	return session, nil
+}
+
+// getCookieExpiry returns the earliest expiry time of the provided cookies.
+// Returns the zero time if none of the cookies expire.
+func getCookieExpiry(cookies []*http.Cookie) time.Time {
+	var ret time.Time
+	for _, c := range cookies {
+		var expires time.Time
+		if c.MaxAge > 0 {
+			expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+		} else if !c.Expires.IsZero() {
+			expires = c.Expires
+		}
+
+		if !expires.IsZero() && (ret.IsZero() || expires.Before(ret)) {
+			ret = expires
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,237 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	stashConfig "github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+const loginTestUsername = "username"
+const loginTestPassword = "password"
+const loginTestToken = "token"
+
+func newLoginTestServer(logins *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/form":
+			if r.FormValue("user") != loginTestUsername || r.FormValue("pass") != loginTestPassword {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			*logins++
+			http.SetCookie(w, &http.Cookie{
+				Name:  "session",
+				Value: loginTestToken,
+				Path:  "/",
+			})
+		case "/json":
+			var body struct {
+				User string `json:"user"`
+				Pass string `json:"pass"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.User != loginTestUsername || body.Pass != loginTestPassword {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			*logins++
+			fmt.Fprintf(w, `{"data": {"access_token": "%s", "expires_in": 3600}}`, loginTestToken)
+		default:
+			cookie, _ := r.Cookie("session")
+			authorized := (cookie != nil && cookie.Value == loginTestToken) || r.Header.Get("X-Token") == "Bearer "+loginTestToken
+			if !authorized {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			fmt.Fprint(w, `<html><h1 itemprop="name">The name</h1></html>`)
+		}
+	}))
+}
+
+func loginTestConfig(t *testing.T, id string, serverURL string, login string) *config {
+	yamlStr := `name: Test
+performerByURL:
+  - action: scrapeXPath
+    url:
+      - ` + serverURL + `
+    scraper: performerScraper
+xPathScrapers:
+  performerScraper:
+    performer:
+      Name: //h1[@itemprop="name"]
+driver:
+  login:
+` + login
+
+	c := &config{}
+	if err := yaml.Unmarshal([]byte(yamlStr), &c); err != nil {
+		t.Fatalf("Error loading yaml: %s", err.Error())
+	}
+
+	if err := c.validate(); err != nil {
+		t.Fatalf("Error validating config: %s", err.Error())
+	}
+
+	c.ID = id
+	return c
+}
+
+func TestScraperLogin(t *testing.T) {
+	logins := 0
+	ts := newLoginTestServer(&logins)
+	defer ts.Close()
+
+	globalConfig := GlobalConfig{
+		Credentials: map[string]map[string]string{
+			"form": {
+				"username": loginTestUsername,
+				"password": loginTestPassword,
+			},
+			"json": {
+				"username": loginTestUsername,
+				"password": loginTestPassword,
+			},
+		},
+	}
+
+	const formLogin = `    url: %s/form
+    fields:
+      user: "{username}"
+      pass: "{password}"
+`
+
+	const jsonLogin = `    type: json
+    url: %s/json
+    fields:
+      user: "{username}"
+      pass: "{password}"
+    tokenPath: data.access_token
+    tokenHeader: X-Token
+    expiresInPath: data.expires_in
+`
+
+	scenarios := []struct {
+		id     string
+		config string
+	}{
+		{"form", formLogin},
+		{"json", jsonLogin},
+	}
+
+	for _, s := range scenarios {
+		clearLoginSessions()
+		logins = 0
+		c := loginTestConfig(t, s.id, ts.URL, fmt.Sprintf(s.config, ts.URL))
+
+		// scrape twice to ensure that the session is reused
+		for i := 0; i < 2; i++ {
+			performer, err := c.ScrapePerformerURL(ts.URL+"/performer", nil, globalConfig)
+			if err != nil {
+				t.Errorf("[%s] Error scraping performer: %s", s.id, err.Error())
+				continue
+			}
+
+			verifyField(t, "The name", performer.Name, "Name")
+		}
+
+		assert.Equal(t, 1, logins, s.id)
+	}
+}
+
+func TestScraperLoginExpiry(t *testing.T) {
+	logins := 0
+	ts := newLoginTestServer(&logins)
+	defer ts.Close()
+
+	clearLoginSessions()
+
+	globalConfig := GlobalConfig{
+		Credentials: map[string]map[string]string{
+			"expiry": {
+				"username": loginTestUsername,
+				"password": loginTestPassword,
+			},
+		},
+	}
+
+	c := loginTestConfig(t, "expiry", ts.URL, `    url: `+ts.URL+`/form
+    fields:
+      user: "{username}"
+      pass: "{password}"
+    expiry: 3600
+`)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.ScrapePerformerURL(ts.URL+"/performer", nil, globalConfig); err != nil {
+			t.Errorf("Error scraping performer: %s", err.Error())
+		}
+
+		// expire the session
+		loginSessions.sessions["expiry"].expires = time.Now().Add(-time.Second)
+	}
+
+	// session should be refreshed when expired
+	assert.Equal(t, 2, logins)
+}
+
+func TestScraperLoginInvalidCredentials(t *testing.T) {
+	logins := 0
+	ts := newLoginTestServer(&logins)
+	defer ts.Close()
+
+	clearLoginSessions()
+
+	c := loginTestConfig(t, "invalid", ts.URL, `    url: `+ts.URL+`/form
+    fields:
+      user: "{username}"
+      pass: "{password}"
+`)
+
+	_, err := c.ScrapePerformerURL(ts.URL+"/performer", nil, GlobalConfig{})
+	assert.NotNil(t, err)
+}
+
+func TestScraperLoginConfiguredCredentials(t *testing.T) {
+	logins := 0
+	ts := newLoginTestServer(&logins)
+	defer ts.Close()
+
+	clearLoginSessions()
+
+	// configuration keys are lower cased when read, so the credentials are
+	// set as they are read from the config file
+	origCredentials := viper.Get(stashConfig.ScraperCredentials)
+	stashConfig.Set(stashConfig.ScraperCredentials, map[string]interface{}{
+		"manyvids": map[string]interface{}{
+			"username": loginTestUsername,
+			"password": loginTestPassword,
+		},
+	})
+	defer stashConfig.Set(stashConfig.ScraperCredentials, origCredentials)
+
+	globalConfig := GlobalConfig{
+		Credentials: stashConfig.GetScraperCredentials(),
+	}
+
+	c := loginTestConfig(t, "ManyVids", ts.URL, `    url: `+ts.URL+`/form
+    fields:
+      user: "{userName}"
+      pass: "{passWord}"
+`)
+
+	if _, err := c.ScrapePerformerURL(ts.URL+"/performer", nil, globalConfig); err != nil {
+		t.Errorf("Error scraping performer: %s", err.Error())
+	}
+
+	assert.Equal(t, 1, logins)
+}
@@ -20,6 +20,13 @@ type mappedQuery interface {
 	subScrape(value string) mappedQuery
 }
 
+// queryTracer is implemented by queries that record the intermediate values
+// of a scrape, such as the test harness query.
+type queryTracer interface {
+	traceField(key string)
+	tracePostProcess(action postProcessAction, before string, after string)
+}
+
 type commonMappedConfig map[string]string
 
 type mappedConfig map[string]mappedScraperAttrConfig
@@ -43,6 +50,9 @@ func (s mappedConfig) process(q mappedQuery, common commonMappedConfig) mappedRe
 	var ret mappedResults
 
 	for k, attrConfig := range s {
+		if t, ok := q.(queryTracer); ok {
+			t.traceField(k)
+		}
 
 		if attrConfig.Fixed != "" {
 			// TODO - not sure if this needs to set _all_ indexes for the key
@@ -211,10 +221,44 @@ func (s *mappedGalleryScraperConfig) UnmarshalYAML(unmarshal func(interface{}) e
 
 type mappedPerformerScraperConfig struct {
 	mappedConfig
+
+	Images mappedConfig
 }
 
+const (
+	mappedScraperConfigPerformerImages = "Images"
+)
+
 func (s *mappedPerformerScraperConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	return unmarshal(&s.mappedConfig)
+	if err := unmarshal(&s.mappedConfig); err != nil {
+		return err
+	}
+
+	// each value found for the images is an image of the same performer,
+	// so the images must be processed separately from the other fields
+	if images, ok := s.mappedConfig[mappedScraperConfigPerformerImages]; ok {
+		s.Images = mappedConfig{
+			mappedScraperConfigPerformerImages: images,
+		}
+		delete(s.mappedConfig, mappedScraperConfigPerformerImages)
+	}
+
+	return nil
+}
+
+func (s mappedPerformerScraperConfig) processImages(q mappedQuery, common commonMappedConfig) []string {
+	if s.Images == nil {
+		return nil
+	}
+
+	var ret []string
+	for _, r := range s.Images.process(q, common) {
+		if image := r[mappedScraperConfigPerformerImages]; image != "" {
+			ret = append(ret, image)
+		}
+	}
+
+	return ret
 }
 
 type mappedMovieScraperConfig struct {
@@ -582,8 +626,14 @@ func (c mappedScraperAttrConfig) splitString(value string) []string {
 }
 
 func (c mappedScraperAttrConfig) postProcess(value string, q mappedQuery) string {
+	t, tracing := q.(queryTracer)
 	for _, action := range c.postProcessActions {
+		before := value
 		value = action.Apply(value, q)
+
+		if tracing {
+			t.tracePostProcess(action, before, value)
+		}
 	}
 
 	return value
@@ -652,6 +702,8 @@ func (s mappedScraper) scrapePerformer(q mappedQuery) (*models.ScrapedPerformer,
 		results[0].apply(&ret)
 	}
 
+	ret.Images = performerMap.processImages(q, s.Common)
+
 	return &ret, nil
 }
 
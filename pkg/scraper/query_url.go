@@ -1,9 +1,15 @@
 package scraper
 
 import (
+	"encoding/json"
+	"mime"
+	neturl "net/url"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/tidwall/gjson"
+
 	"github.com/stashapp/stash/pkg/models"
 )
 
@@ -49,13 +55,76 @@ func (p queryURLParameters) applyReplacements(r queryURLReplacements) {
 	}
 }
 
+// queryURLPlaceholderRE matches placeholders, which are names without
+// whitespace within braces. Placeholders preceded by a backslash are
+// escaped, so that braces such as GraphQL selection sets can be used.
+var queryURLPlaceholderRE = regexp.MustCompile(`\\?\{([^{}\s]+)\}`)
+
+const (
+	jsonContentType = "application/json"
+	formContentType = "application/x-www-form-urlencoded"
+)
+
+// constructURL replaces the placeholders in url with the parameter values.
+// Placeholders that don't match a parameter exactly are matched in lower
+// case, since the names of configured credentials are lower case.
 func (p queryURLParameters) constructURL(url string) string {
-	ret := url
-	for k, v := range p {
-		ret = strings.Replace(ret, "{"+k+"}", v, -1)
+	return p.replacePlaceholders(url, nil)
+}
+
+// constructBody replaces the placeholders in the request body with the
+// parameter values, escaped for the content type of the body, and returns
+// the body and its content type. Values are escaped as JSON strings in JSON
+// bodies, and URL encoded in form bodies. If contentType is empty, the body
+// is JSON if it is valid JSON after replacing the placeholders, otherwise
+// it is a form.
+func (p queryURLParameters) constructBody(body string, contentType string) (string, string) {
+	if contentType == "" {
+		if ret := p.replacePlaceholders(body, escapeJSONString); gjson.Valid(ret) {
+			return ret, jsonContentType
+		}
+		return p.replacePlaceholders(body, neturl.QueryEscape), formContentType
 	}
 
-	return ret
+	var escape func(string) string
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == jsonContentType || strings.HasSuffix(mediaType, "+json"):
+		escape = escapeJSONString
+	case mediaType == formContentType:
+		escape = neturl.QueryEscape
+	}
+
+	return p.replacePlaceholders(body, escape), contentType
+}
+
+func (p queryURLParameters) replacePlaceholders(s string, escape func(string) string) string {
+	return queryURLPlaceholderRE.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if strings.HasPrefix(placeholder, `\`) {
+			return placeholder[1:]
+		}
+
+		name := placeholder[1 : len(placeholder)-1]
+		v, found := p[name]
+		if !found {
+			v, found = p[strings.ToLower(name)]
+		}
+		if !found {
+			return placeholder
+		}
+
+		if escape != nil {
+			return escape(v)
+		}
+		return v
+	})
+}
+
+// escapeJSONString escapes s to be placed within a JSON string.
+func escapeJSONString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
 }
 
 // replaceURL does a partial URL Replace ( only url parameter is used)
@@ -21,6 +21,31 @@ type GlobalConfig struct {
 	// Path (file or remote address) to a Chrome CDP instance.
 	CDPPath string
 	Path    string
+
+	// Credentials used by scrapers to login, keyed by scraper ID. Scraper
+	// IDs and credential names are matched case-insensitively.
+	Credentials map[string]map[string]string
+}
+
+// getCredentials returns the credentials of the scraper, keyed by their
+// lower case names.
+func (c GlobalConfig) getCredentials(scraperID string) map[string]string {
+	credentials, found := c.Credentials[scraperID]
+	if !found {
+		for id, v := range c.Credentials {
+			if strings.EqualFold(id, scraperID) {
+				credentials = v
+				break
+			}
+		}
+	}
+
+	ret := make(map[string]string)
+	for k, v := range credentials {
+		ret[strings.ToLower(k)] = v
+	}
+
+	return ret
 }
 
 func (c GlobalConfig) isCDPPathHTTP() bool {
@@ -93,6 +118,7 @@ func loadScrapers(path string) ([]config, error) {
 // In the event of an error during loading, the cache will be left empty.
 func (c *Cache) ReloadScrapers() error {
 	c.scrapers = nil
+	clearLoginSessions()
 	scrapers, err := loadScrapers(c.globalConfig.Path)
 	if err != nil {
 		return err
@@ -106,6 +132,7 @@ func (c *Cache) ReloadScrapers() error {
 // has changed, ReloadScrapers will need to be called separately.
 func (c *Cache) UpdateConfig(globalConfig GlobalConfig) {
 	c.globalConfig = globalConfig
+	clearLoginSessions()
 }
 
 // ListPerformerScrapers returns a list of scrapers that are capable of
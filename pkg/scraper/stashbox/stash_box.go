@@ -65,6 +65,22 @@ func (c Client) QueryStashBoxScene(queryStr string) ([]*models.ScrapedScene, err
 	return ret, nil
 }
 
+// QueryStashBoxPerformer queries stash-box for performers using a query
+// string. The images of each performer are retrieved and base64 encoded.
+func (c Client) QueryStashBoxPerformer(queryStr string) ([]*models.ScrapedPerformer, error) {
+	performers, err := c.client.SearchPerformer(context.TODO(), queryStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*models.ScrapedPerformer
+	for _, p := range performers.SearchPerformer {
+		ret = append(ret, performerFragmentToScrapedPerformer(*p))
+	}
+
+	return ret, nil
+}
+
 // FindStashBoxScenesByFingerprints queries stash-box for scenes using every
 // scene's MD5 checksum and/or oshash.
 func (c Client) FindStashBoxScenesByFingerprints(sceneIDs []string) ([]*models.ScrapedScene, error) {
@@ -355,6 +371,47 @@ func performerFragmentToScrapedScenePerformer(p graphql.PerformerFragment) *mode
 	return sp
 }
 
+func performerFragmentToScrapedPerformer(p graphql.PerformerFragment) *models.ScrapedPerformer {
+	sp := performerFragmentToScrapedScenePerformer(p)
+	ret := &models.ScrapedPerformer{
+		Name:         &sp.Name,
+		Gender:       sp.Gender,
+		Twitter:      sp.Twitter,
+		Birthdate:    sp.Birthdate,
+		Ethnicity:    sp.Ethnicity,
+		Country:      sp.Country,
+		EyeColor:     sp.EyeColor,
+		Height:       sp.Height,
+		Measurements: sp.Measurements,
+		FakeTits:     sp.FakeTits,
+		CareerLength: sp.CareerLength,
+		Tattoos:      sp.Tattoos,
+		Piercings:    sp.Piercings,
+	}
+
+	if len(p.Aliases) > 0 {
+		aliases := strings.Join(p.Aliases, ", ")
+		ret.Aliases = &aliases
+	}
+
+	// the first image is the primary image
+	for _, image := range p.Images {
+		img, err := fetchImage(image.URL)
+		if err != nil {
+			logger.Warnf("Error fetching image %s: %s", image.URL, err.Error())
+			continue
+		}
+
+		ret.Images = append(ret.Images, *img)
+	}
+
+	if len(ret.Images) > 0 {
+		ret.Image = &ret.Images[0]
+	}
+
+	return ret
+}
+
 func getFirstImage(images []*graphql.ImageFragment) *string {
 	ret, err := fetchImage(images[0].URL)
 	if err != nil {
@@ -31,25 +31,10 @@ import (
 const scrapeGetTimeout = time.Second * 60
 const scrapeDefaultSleep = time.Second * 2
 
-func loadURL(url string, scraperConfig config, globalConfig GlobalConfig) (io.Reader, error) {
-	driverOptions := scraperConfig.DriverOptions
-	if driverOptions != nil && driverOptions.UseCDP {
-		// get the page using chrome dp
-		return urlFromCDP(url, *driverOptions, globalConfig)
-	}
-
-	// get the page using http.Client
-	options := cookiejar.Options{
-		PublicSuffixList: publicsuffix.List,
-	}
-	jar, er := cookiejar.New(&options)
-	if er != nil {
-		return nil, er
-	}
-
-	setCookies(jar, scraperConfig)
-	printCookies(jar, scraperConfig, "Jar cookies set from scraper")
-
+// loadURL loads the url using the scraper driver options. params are used
+// to replace the placeholders in the driver request headers and body. If
+// params is nil, then a GET request without a body is performed.
+func loadURL(url string, params queryURLParameters, scraperConfig config, globalConfig GlobalConfig) (io.Reader, error) {
 	client := &http.Client{
 		Transport: &http.Transport{ // ignore insecure certificates
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: !stashConfig.GetScraperCertCheck()},
@@ -62,23 +47,42 @@ func loadURL(url string, scraperConfig config, globalConfig GlobalConfig) (io.Re
 			}
 			return nil
 		},
-		Jar: jar,
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	session, err := getLoginSession(client, scraperConfig, globalConfig)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error logging in: %s", err.Error())
 	}
 
-	userAgent := globalConfig.UserAgent
-	if userAgent != "" {
-		req.Header.Set("User-Agent", userAgent)
+	driverOptions := scraperConfig.DriverOptions
+	if driverOptions != nil && driverOptions.UseCDP {
+		// get the page using chrome dp
+		return urlFromCDP(url, *driverOptions, session, getRequestHeaders(params, session, scraperConfig, globalConfig), globalConfig)
 	}
 
-	resp, err := client.Do(req)
+	// get the page using http.Client
+	resp, err := doScrapeRequest(client, url, params, session, scraperConfig, globalConfig)
 	if err != nil {
 		return nil, err
 	}
+
+	// the login may have been invalidated by the site before the session
+	// expired. Login again and retry once.
+	if session != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+		resp.Body.Close()
+		logger.Debugf("Got http status %d for %s, logging in again", resp.StatusCode, url)
+		invalidateLoginSession(scraperConfig)
+
+		session, err = getLoginSession(client, scraperConfig, globalConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error logging in: %s", err.Error())
+		}
+
+		resp, err = doScrapeRequest(client, url, params, session, scraperConfig, globalConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
@@ -87,15 +91,118 @@ func loadURL(url string, scraperConfig config, globalConfig GlobalConfig) (io.Re
 	}
 
 	bodyReader := bytes.NewReader(body)
-	printCookies(jar, scraperConfig, "Jar cookies found for scraper urls")
+	printCookies(client.Jar.(*cookiejar.Jar), scraperConfig, "Jar cookies found for scraper urls")
 
 	return charset.NewReader(bodyReader, resp.Header.Get("Content-Type"))
 }
 
+// doScrapeRequest sets up the cookie jar of the client and performs the
+// request for the url. If session is not nil, then the session cookies and
+// headers are used.
+func doScrapeRequest(client *http.Client, url string, params queryURLParameters, session *loginSession, scraperConfig config, globalConfig GlobalConfig) (*http.Response, error) {
+	var jar *cookiejar.Jar
+	if session != nil {
+		jar = session.jar
+	} else {
+		options := cookiejar.Options{
+			PublicSuffixList: publicsuffix.List,
+		}
+		var err error
+		jar, err = cookiejar.New(&options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	setCookies(jar, scraperConfig)
+	printCookies(jar, scraperConfig, "Jar cookies set from scraper")
+
+	client.Jar = jar
+
+	req, err := newScrapeRequest(url, params, scraperConfig, globalConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	userAgent := globalConfig.UserAgent
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	for k, v := range getRequestHeaders(params, session, scraperConfig, globalConfig) {
+		req.Header.Set(k, v)
+	}
+
+	return client.Do(req)
+}
+
+// newScrapeRequest creates the request for the url using the method and
+// body of the scraper driver options. If params is nil, then a GET request
+// without a body is created.
+func newScrapeRequest(url string, params queryURLParameters, scraperConfig config, globalConfig GlobalConfig) (*http.Request, error) {
+	driverOptions := scraperConfig.DriverOptions
+	if params == nil || driverOptions == nil {
+		return http.NewRequest(http.MethodGet, url, nil)
+	}
+
+	var body io.Reader
+	contentType := driverOptions.getBodyContentType()
+	if driverOptions.Body != "" {
+		values := getRequestParameters(params, scraperConfig, globalConfig)
+		var s string
+		s, contentType = values.constructBody(driverOptions.Body, contentType)
+		body = strings.NewReader(s)
+	}
+
+	req, err := http.NewRequest(driverOptions.getMethod(), url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if driverOptions.Body != "" {
+		// the configured Content-Type header is set with the other headers
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// getRequestParameters returns the values used to replace the placeholders
+// in the request headers and body. These are the credentials configured
+// for the scraper and the provided params, with params taking precedence.
+func getRequestParameters(params queryURLParameters, scraperConfig config, globalConfig GlobalConfig) queryURLParameters {
+	ret := queryURLParameters(globalConfig.getCredentials(scraperConfig.ID))
+	for k, v := range params {
+		ret[k] = v
+	}
+
+	return ret
+}
+
+// getRequestHeaders returns the headers to set on a scrape request. These
+// are the configured driver headers and the login session headers, with the
+// session headers taking precedence.
+func getRequestHeaders(params queryURLParameters, session *loginSession, scraperConfig config, globalConfig GlobalConfig) map[string]string {
+	ret := make(map[string]string)
+
+	if scraperConfig.DriverOptions != nil {
+		values := getRequestParameters(params, scraperConfig, globalConfig)
+		for _, h := range scraperConfig.DriverOptions.Headers {
+			ret[h.Key] = values.constructURL(h.Value)
+		}
+	}
+
+	if session != nil {
+		session.applyHeaders(ret)
+	}
+
+	return ret
+}
+
 // func urlFromCDP uses chrome cdp and DOM to load and process the url
 // if remote is set as true in the scraperConfig  it will try to use localhost:9222
 // else it will look for google-chrome in path
-func urlFromCDP(url string, driverOptions scraperDriverOptions, globalConfig GlobalConfig) (io.Reader, error) {
+func urlFromCDP(url string, driverOptions scraperDriverOptions, session *loginSession, headers map[string]string, globalConfig GlobalConfig) (io.Reader, error) {
 
 	if !driverOptions.UseCDP {
 		return nil, fmt.Errorf("Url shouldn't be feetched through CDP")
@@ -155,6 +262,8 @@ func urlFromCDP(url string, driverOptions scraperDriverOptions, globalConfig Glo
 	err := chromedp.Run(ctx,
 		network.Enable(),
 		setCDPCookies(driverOptions),
+		setCDPLoginSession(url, session),
+		setCDPHeaders(headers),
 		printCDPCookies(driverOptions, "Cookies found"),
 		chromedp.Navigate(url),
 		chromedp.Sleep(sleepDuration),
@@ -237,3 +346,18 @@ func cdpNetwork(enable bool) chromedp.Action {
 		return nil
 	})
 }
+
+// set the headers sent with every request made by chrome
+func setCDPHeaders(headers map[string]string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if len(headers) == 0 {
+			return nil
+		}
+
+		h := make(network.Headers)
+		for k, v := range headers {
+			h[k] = v
+		}
+		return network.SetExtraHTTPHeaders(h).Do(ctx)
+	})
+}
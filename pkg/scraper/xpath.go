@@ -42,7 +42,7 @@ func (s *xpathScraper) scrapeURL(url string) (*html.Node, *mappedScraper, error)
 		return nil, nil, errors.New("xpath scraper with name " + s.scraper.Scraper + " not found in config")
 	}
 
-	doc, err := s.loadURL(url)
+	doc, err := s.loadURL(url, queryURLParameterFromURL(url))
 
 	if err != nil {
 		return nil, nil, err
@@ -110,7 +110,11 @@ func (s *xpathScraper) scrapePerformersByName(name string) ([]*models.ScrapedPer
 	url := s.scraper.QueryURL
 	url = strings.Replace(url, placeholder, escapedName, -1)
 
-	doc, err := s.loadURL(url)
+	params := queryURLParameters{
+		"query": name,
+		"url":   url,
+	}
+	doc, err := s.loadURL(url, params)
 
 	if err != nil {
 		return nil, err
@@ -147,7 +151,7 @@ func (s *xpathScraper) scrapeSceneByFragment(scene models.SceneUpdateInput) (*mo
 		return nil, errors.New("xpath scraper with name " + s.scraper.Scraper + " not found in config")
 	}
 
-	doc, err := s.loadURL(url)
+	doc, err := s.loadURL(url, queryURL)
 
 	if err != nil {
 		return nil, err
@@ -180,7 +184,7 @@ func (s *xpathScraper) scrapeGalleryByFragment(gallery models.GalleryUpdateInput
 		return nil, errors.New("xpath scraper with name " + s.scraper.Scraper + " not found in config")
 	}
 
-	doc, err := s.loadURL(url)
+	doc, err := s.loadURL(url, queryURL)
 
 	if err != nil {
 		return nil, err
@@ -190,8 +194,8 @@ func (s *xpathScraper) scrapeGalleryByFragment(gallery models.GalleryUpdateInput
 	return scraper.scrapeGallery(q)
 }
 
-func (s *xpathScraper) loadURL(url string) (*html.Node, error) {
-	r, err := loadURL(url, s.config, s.globalConfig)
+func (s *xpathScraper) loadURL(url string, params queryURLParameters) (*html.Node, error) {
+	r, err := loadURL(url, params, s.config, s.globalConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -261,7 +265,8 @@ func (q *xpathQuery) nodeText(n *html.Node) string {
 }
 
 func (q *xpathQuery) subScrape(value string) mappedQuery {
-	doc, err := q.scraper.loadURL(value)
+	// sub-scrapes are always fetched using GET without a request body
+	doc, err := q.scraper.loadURL(value, nil)
 
 	if err != nil {
 		logger.Warnf("Error getting URL '%s' for sub-scraper: %s", value, err.Error())
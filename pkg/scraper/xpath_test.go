@@ -688,6 +688,58 @@ func TestApplySceneXPathConfig(t *testing.T) {
 	verifyField(t, expectedStudioURL, scene.Studio.URL, "Studio.URL")
 }
 
+func TestScrapePerformerImagesXPath(t *testing.T) {
+	const testDoc = `
+	<html>
+	<h1>Name</h1>
+	<img src="/image1.jpg"/>
+	<img src="/image2.jpg"/>
+	</html>
+	`
+
+	const yamlStr = `performer:
+  Name: //h1
+  Images:
+    selector: //img/@src
+    postProcess:
+      - replace:
+          - regex: ^
+            with: https://example.com
+`
+
+	reader := strings.NewReader(testDoc)
+	doc, err := htmlquery.Parse(reader)
+
+	if err != nil {
+		t.Errorf("Error loading document: %s", err.Error())
+		return
+	}
+
+	scraper := mappedScraper{}
+	if err := yaml.Unmarshal([]byte(yamlStr), &scraper); err != nil {
+		t.Errorf("Error loading yaml: %s", err.Error())
+		return
+	}
+
+	q := &xpathQuery{
+		doc: doc,
+	}
+
+	performer, err := scraper.scrapePerformer(q)
+
+	if err != nil {
+		t.Errorf("Error scraping performer: %s", err.Error())
+		return
+	}
+
+	// the images should not result in multiple performers
+	verifyField(t, "Name", performer.Name, "Name")
+	assert.Equal(t, []string{
+		"https://example.com/image1.jpg",
+		"https://example.com/image2.jpg",
+	}, performer.Images)
+}
+
 func TestLoadXPathScraperFromYAML(t *testing.T) {
 	const yamlStr = `name: Test
 performerByURL:
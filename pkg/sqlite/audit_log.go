@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const auditLogTable = "audit_log"
+
+type auditLogQueryBuilder struct {
+	repository
+}
+
+func NewAuditLogReaderWriter(tx dbi) *auditLogQueryBuilder {
+	return &auditLogQueryBuilder{
+		repository{
+			tx:        tx,
+			tableName: auditLogTable,
+			idColumn:  idColumn,
+		},
+	}
+}
+
+func (qb *auditLogQueryBuilder) Create(newObject models.AuditLogEntry) (*models.AuditLogEntry, error) {
+	var ret models.AuditLogEntry
+	if err := qb.insertObject(newObject, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *auditLogQueryBuilder) Find(id int) (*models.AuditLogEntry, error) {
+	var ret models.AuditLogEntry
+	if err := qb.get(id, &ret); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// FindByEntity returns the entries for the provided object, most recent
+// first.
+func (qb *auditLogQueryBuilder) FindByEntity(entityType models.AuditEntityEnum, entityID int) ([]*models.AuditLogEntry, error) {
+	query := "SELECT * FROM " + auditLogTable + " WHERE entity_type = ? AND entity_id = ? ORDER BY created_at DESC, id DESC"
+
+	var ret models.AuditLogEntries
+	if err := qb.query(query, []interface{}{entityType.String(), entityID}, &ret); err != nil {
+		return nil, err
+	}
+
+	return []*models.AuditLogEntry(ret), nil
+}
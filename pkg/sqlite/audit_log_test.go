@@ -0,0 +1,243 @@
+// +build integration
+
+package sqlite_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/audit"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogUpdateRevert(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Studio()
+
+		const name = "TestAuditLogUpdateRevert"
+		const newName = name + " updated"
+		created, err := createStudio(qb, name, nil)
+		if err != nil {
+			return fmt.Errorf("Error creating studio: %s", err.Error())
+		}
+
+		if err := audit.Update(r, models.AuditSourceEnumPlugin, models.AuditEntityEnumStudio, created.ID, func() error {
+			studio := *created
+			studio.Name = sql.NullString{String: newName, Valid: true}
+			studio.Checksum = utils.MD5FromString(newName)
+			if _, err := qb.UpdateFull(studio); err != nil {
+				return err
+			}
+
+			return qb.UpdateCustomFields(created.ID, models.CustomFieldMap{"disc": 1})
+		}); err != nil {
+			return fmt.Errorf("Error updating studio: %s", err.Error())
+		}
+
+		entries, err := r.AuditLog().FindByEntity(models.AuditEntityEnumStudio, created.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding change history: %s", err.Error())
+		}
+
+		// one entry per changed field
+		fields := make(map[string]*models.AuditLogEntry)
+		for _, e := range entries {
+			assert.Equal(t, models.AuditActionEnumUpdate, e.Action)
+			assert.Equal(t, models.AuditSourceEnumPlugin, e.Source)
+			fields[e.Field.String] = e
+		}
+		assert.Len(t, fields, 2)
+
+		nameEntry := fields["name"]
+		if !assert.NotNil(t, nameEntry) {
+			return nil
+		}
+		assert.Equal(t, name, nameEntry.OldValue.String)
+		assert.Equal(t, newName, nameEntry.NewValue.String)
+
+		customFieldsEntry := fields["custom_fields"]
+		if !assert.NotNil(t, customFieldsEntry) {
+			return nil
+		}
+		assert.Equal(t, "{}", customFieldsEntry.OldValue.String)
+		assert.Equal(t, `{"disc":1}`, customFieldsEntry.NewValue.String)
+
+		// revert the name change
+		reverted, err := audit.Revert(r, models.AuditSourceEnumUser, nameEntry.ID)
+		if err != nil {
+			return fmt.Errorf("Error reverting change: %s", err.Error())
+		}
+
+		assert.Equal(t, "name", reverted.Field.String)
+		assert.Equal(t, newName, reverted.OldValue.String)
+		assert.Equal(t, name, reverted.NewValue.String)
+		assert.Equal(t, models.AuditSourceEnumUser, reverted.Source)
+
+		studio, err := qb.Find(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding studio: %s", err.Error())
+		}
+		assert.Equal(t, name, studio.Name.String)
+		assert.Equal(t, utils.MD5FromString(name), studio.Checksum)
+
+		// reverting again should fail since the name has been changed since
+		_, err = audit.Revert(r, models.AuditSourceEnumUser, nameEntry.ID)
+		assert.NotNil(t, err)
+
+		// revert the custom fields change
+		if _, err := audit.Revert(r, models.AuditSourceEnumUser, customFieldsEntry.ID); err != nil {
+			return fmt.Errorf("Error reverting change: %s", err.Error())
+		}
+
+		customFields, err := qb.GetCustomFields(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting custom fields: %s", err.Error())
+		}
+		assert.Len(t, customFields, 0)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestAuditLogCreateDestroy(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Tag()
+
+		const name = "TestAuditLogCreateDestroy"
+		var tagID int
+		if err := audit.Create(r, models.AuditSourceEnumUser, models.AuditEntityEnumTag, func() (int, error) {
+			now := models.SQLiteTimestamp{Timestamp: time.Now()}
+			created, err := qb.Create(models.Tag{Name: name, CreatedAt: now, UpdatedAt: now})
+			if err != nil {
+				return 0, err
+			}
+
+			tagID = created.ID
+			return tagID, nil
+		}); err != nil {
+			return fmt.Errorf("Error creating tag: %s", err.Error())
+		}
+
+		if err := audit.Destroy(r, models.AuditSourceEnumUser, models.AuditEntityEnumTag, tagID, func() error {
+			return qb.Destroy(tagID)
+		}); err != nil {
+			return fmt.Errorf("Error destroying tag: %s", err.Error())
+		}
+
+		entries, err := r.AuditLog().FindByEntity(models.AuditEntityEnumTag, tagID)
+		if err != nil {
+			return fmt.Errorf("Error finding change history: %s", err.Error())
+		}
+
+		if !assert.Len(t, entries, 2) {
+			return nil
+		}
+
+		// most recent first
+		destroyEntry := entries[0]
+		createEntry := entries[1]
+
+		expected := map[string]string{"name": name}
+
+		assert.Equal(t, models.AuditActionEnumCreate, createEntry.Action)
+		assert.False(t, createEntry.OldValue.Valid)
+		var values map[string]string
+		if err := json.Unmarshal([]byte(createEntry.NewValue.String), &values); err != nil {
+			return err
+		}
+		assert.Equal(t, expected, values)
+
+		assert.Equal(t, models.AuditActionEnumDestroy, destroyEntry.Action)
+		assert.False(t, destroyEntry.NewValue.Valid)
+		if err := json.Unmarshal([]byte(destroyEntry.OldValue.String), &values); err != nil {
+			return err
+		}
+		assert.Equal(t, expected, values)
+
+		// only updates can be reverted
+		_, err = audit.Revert(r, models.AuditSourceEnumUser, createEntry.ID)
+		assert.NotNil(t, err)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestAuditLogGalleryImages(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		gqb := r.Gallery()
+
+		galleryID := galleryIDs[galleryIdxWithImage]
+		imageID := imageIDs[imageIdxWithGallery]
+		otherImageID := imageIDs[imageIdxWithPerformer]
+
+		originalImageIDs, err := gqb.GetImageIDs(galleryID)
+		if err != nil {
+			return fmt.Errorf("Error getting gallery images: %s", err.Error())
+		}
+
+		newImageIDs := utils.IntAppendUnique(originalImageIDs, otherImageID)
+		if err := audit.UpdateAll(r, models.AuditSourceEnumUser, models.AuditEntityEnumImage, []int{otherImageID, imageID}, func() error {
+			return gqb.UpdateImages(galleryID, newImageIDs)
+		}); err != nil {
+			return fmt.Errorf("Error adding gallery images: %s", err.Error())
+		}
+
+		// only the added image was changed
+		entries, err := r.AuditLog().FindByEntity(models.AuditEntityEnumImage, otherImageID)
+		if err != nil {
+			return fmt.Errorf("Error finding change history: %s", err.Error())
+		}
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, "gallery_ids", entries[0].Field.String)
+			assert.Equal(t, strconv.Itoa(galleryID), entries[0].NewValue.String)
+		}
+
+		entries, err = r.AuditLog().FindByEntity(models.AuditEntityEnumImage, imageID)
+		if err != nil {
+			return fmt.Errorf("Error finding change history: %s", err.Error())
+		}
+		assert.Len(t, entries, 0)
+
+		// order the images and revert it
+		order := []int{otherImageID, imageID}
+		if err := audit.Update(r, models.AuditSourceEnumUser, models.AuditEntityEnumGallery, galleryID, func() error {
+			return gqb.UpdateImageOrder(galleryID, order)
+		}); err != nil {
+			return fmt.Errorf("Error reordering gallery images: %s", err.Error())
+		}
+
+		entries, err = r.AuditLog().FindByEntity(models.AuditEntityEnumGallery, galleryID)
+		if err != nil {
+			return fmt.Errorf("Error finding change history: %s", err.Error())
+		}
+		if !assert.Len(t, entries, 1) {
+			return nil
+		}
+		assert.Equal(t, "image_order", entries[0].Field.String)
+
+		if _, err := audit.Revert(r, models.AuditSourceEnumUser, entries[0].ID); err != nil {
+			return fmt.Errorf("Error reverting change: %s", err.Error())
+		}
+
+		order, err = gqb.GetImageOrder(galleryID)
+		if err != nil {
+			return fmt.Errorf("Error getting image order: %s", err.Error())
+		}
+		assert.Len(t, order, 0)
+
+		// reset gallery images
+		return gqb.UpdateImages(galleryID, originalImageIDs)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -0,0 +1,280 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stashapp/stash/pkg/blob"
+	"github.com/stashapp/stash/pkg/database"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// blobReferences are the columns that reference blobs by their checksum.
+var blobReferences = []struct {
+	table  string
+	column string
+}{
+	{"performers_image", "image_blob"},
+	{"studios_image", "image_blob"},
+	{"tags_image", "image_blob"},
+	{"movies_images", "front_image_blob"},
+	{"movies_images", "back_image_blob"},
+	{"scenes_cover", "cover_blob"},
+}
+
+// unreferencedBlobsWhere is the where clause of blobs that are not
+// referenced by any object.
+func unreferencedBlobsWhere() string {
+	var clauses []string
+	for _, r := range blobReferences {
+		clauses = append(clauses, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM `%s` WHERE `%s` = `blobs`.`checksum`)", r.table, r.column))
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// BlobStoreOptions are the options of the store of performer, studio, tag,
+// movie and scene images.
+type BlobStoreOptions struct {
+	// UseFilesystem stores new blobs in the filesystem rather than the
+	// database.
+	UseFilesystem bool
+	// Path is the directory of the blobs stored in the filesystem.
+	Path string
+}
+
+var (
+	blobStoreOptions      BlobStoreOptions
+	blobStoreOptionsMutex sync.RWMutex
+)
+
+// SetBlobStoreOptions sets the options of the blob store. Blobs that are
+// already stored are not moved. Use MigrateBlobs to move them.
+func SetBlobStoreOptions(options BlobStoreOptions) {
+	blobStoreOptionsMutex.Lock()
+	defer blobStoreOptionsMutex.Unlock()
+	blobStoreOptions = options
+}
+
+func getBlobStoreOptions() BlobStoreOptions {
+	blobStoreOptionsMutex.RLock()
+	defer blobStoreOptionsMutex.RUnlock()
+	return blobStoreOptions
+}
+
+func (o BlobStoreOptions) filesystem() (*blob.FilesystemStore, error) {
+	if o.Path == "" {
+		return nil, errors.New("blobs path is not set")
+	}
+	return blob.NewFilesystemStore(o.Path), nil
+}
+
+// blobStore stores blobs in the blobs table by their checksum. The blob
+// column is NULL if the blob is stored in the filesystem.
+type blobStore struct {
+	tx dbi
+}
+
+func (s *blobStore) read(checksum string) ([]byte, error) {
+	data, err := getImage(s.tx, "SELECT `blob` FROM `blobs` WHERE `checksum` = ?", checksum)
+	if err != nil || data != nil {
+		return data, err
+	}
+
+	fs, err := getBlobStoreOptions().filesystem()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = fs.Read(checksum)
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob %s: %s", checksum, err.Error())
+	}
+	return data, nil
+}
+
+// write stores the blob and returns its checksum. Nil data is an error, as
+// it was when images were stored in NOT NULL columns. Blobs that are already
+// stored in the database are kept there when storing in the filesystem,
+// while blobs stored in the filesystem are copied to the database when
+// storing in the database.
+func (s *blobStore) write(data []byte) (string, error) {
+	if data == nil {
+		return "", errors.New("blob data is nil")
+	}
+
+	checksum := utils.MD5FromBytes(data)
+	options := getBlobStoreOptions()
+
+	if options.UseFilesystem {
+		fs, err := options.filesystem()
+		if err != nil {
+			return "", err
+		}
+
+		if err := fs.Write(checksum, data); err != nil {
+			return "", err
+		}
+
+		_, err = s.tx.Exec("INSERT OR IGNORE INTO `blobs` (`checksum`, `blob`) VALUES (?, NULL)", checksum)
+		return checksum, err
+	}
+
+	if _, err := s.tx.Exec("INSERT OR IGNORE INTO `blobs` (`checksum`, `blob`) VALUES (?, ?)", checksum, data); err != nil {
+		return "", err
+	}
+
+	_, err := s.tx.Exec("UPDATE `blobs` SET `blob` = ? WHERE `checksum` = ? AND `blob` IS NULL", data, checksum)
+	return checksum, err
+}
+
+// deleteUnreferenced deletes the blobs with the provided checksums that are
+// no longer referenced. Files of deleted blobs are left in the filesystem,
+// since the transaction may be rolled back, and are deleted by CleanBlobs.
+func (s *blobStore) deleteUnreferenced(checksums []string) error {
+	for _, checksum := range checksums {
+		if checksum == "" {
+			continue
+		}
+
+		stmt := "DELETE FROM `blobs` WHERE `checksum` = ? AND " + unreferencedBlobsWhere()
+		if _, err := s.tx.Exec(stmt, checksum); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func queryChecksums(tx dbi, query string, args ...interface{}) ([]string, error) {
+	var ret []string
+	if err := tx.Select(&ret, query, args...); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// MigrateBlobs moves the stored blobs to the filesystem or the database,
+// depending on the current blob store options. Each blob is moved in its
+// own transaction. The progress function is called before each blob is
+// moved, and stops the migration if it returns false. Returns the number of
+// blobs moved.
+func MigrateBlobs(progress func(done int, total int) bool) (int, error) {
+	options := getBlobStoreOptions()
+	fs, err := options.filesystem()
+	if err != nil {
+		return 0, err
+	}
+
+	query := "SELECT `checksum` FROM `blobs` WHERE `blob` IS NULL"
+	if options.UseFilesystem {
+		query = "SELECT `checksum` FROM `blobs` WHERE `blob` IS NOT NULL"
+	}
+
+	checksums, err := queryChecksums(database.DB, query)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for i, checksum := range checksums {
+		if !progress(i, len(checksums)) {
+			break
+		}
+
+		if options.UseFilesystem {
+			err = moveBlobToFilesystem(fs, checksum)
+		} else {
+			err = moveBlobToDatabase(fs, checksum)
+		}
+
+		if err != nil {
+			logger.Warnf("error moving blob %s: %s", checksum, err.Error())
+			continue
+		}
+		moved++
+	}
+
+	return moved, nil
+}
+
+func moveBlobToFilesystem(fs *blob.FilesystemStore, checksum string) error {
+	return database.WithTxn(func(tx *sqlx.Tx) error {
+		data, err := getImage(tx, "SELECT `blob` FROM `blobs` WHERE `checksum` = ?", checksum)
+		if err != nil || data == nil {
+			return err
+		}
+
+		if err := fs.Write(checksum, data); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE `blobs` SET `blob` = NULL WHERE `checksum` = ?", checksum)
+		return err
+	})
+}
+
+func moveBlobToDatabase(fs *blob.FilesystemStore, checksum string) error {
+	data, err := fs.Read(checksum)
+	if err != nil {
+		return err
+	}
+
+	if err := database.WithTxn(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("UPDATE `blobs` SET `blob` = ? WHERE `checksum` = ?", data, checksum)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return fs.Delete(checksum)
+}
+
+// CleanBlobs deletes the blobs that are no longer referenced, and the files
+// in the blobs directory that are not needed, either because their blob was
+// deleted or because it is stored in the database. Nothing is deleted if
+// dryRun is true. Returns the number of blobs and files deleted, or that
+// would be deleted.
+func CleanBlobs(dryRun bool) (int, int, error) {
+	unreferenced, err := queryChecksums(database.DB, "SELECT `checksum` FROM `blobs` WHERE "+unreferencedBlobsWhere())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !dryRun && len(unreferenced) > 0 {
+		if err := database.WithTxn(func(tx *sqlx.Tx) error {
+			return (&blobStore{tx: tx}).deleteUnreferenced(unreferenced)
+		}); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	options := getBlobStoreOptions()
+	if options.Path == "" {
+		return len(unreferenced), 0, nil
+	}
+	fs := blob.NewFilesystemStore(options.Path)
+
+	files := 0
+	err = fs.Walk(func(checksum string) error {
+		stored, err := queryChecksums(database.DB, "SELECT `checksum` FROM `blobs` WHERE `checksum` = ? AND `blob` IS NULL", checksum)
+		if err != nil {
+			return err
+		}
+
+		if len(stored) > 0 {
+			return nil
+		}
+
+		files++
+		if dryRun {
+			return nil
+		}
+		return fs.Delete(checksum)
+	})
+
+	return len(unreferenced), files, err
+}
@@ -0,0 +1,144 @@
+// +build integration
+
+package sqlite_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/database"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sqlite"
+	"github.com/stashapp/stash/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func countBlobs(t *testing.T, where string, args ...interface{}) int {
+	var ret int
+	if err := database.DB.Get(&ret, "SELECT COUNT(*) FROM blobs WHERE "+where, args...); err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func TestBlobStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sqlite.SetBlobStoreOptions(sqlite.BlobStoreOptions{UseFilesystem: true, Path: dir})
+	defer sqlite.SetBlobStoreOptions(sqlite.BlobStoreOptions{})
+
+	image := []byte("TestBlobStore image")
+	checksum := utils.MD5FromBytes(image)
+	path := filepath.Join(dir, checksum[0:2], checksum[2:4], checksum)
+
+	const name = "TestBlobStore"
+	var studioID, tagID int
+	if err := withTxn(func(r models.Repository) error {
+		studio, err := createStudio(r.Studio(), name, nil)
+		if err != nil {
+			return err
+		}
+		studioID = studio.ID
+
+		tag, err := r.Tag().Create(models.Tag{Name: name})
+		if err != nil {
+			return err
+		}
+		tagID = tag.ID
+
+		// the same image is stored once
+		if err := r.Studio().UpdateImage(studioID, image); err != nil {
+			return err
+		}
+		return r.Tag().UpdateImage(tagID, image)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.FileExists(t, path)
+	assert.Equal(t, 1, countBlobs(t, "checksum = ? AND blob IS NULL", checksum))
+
+	withTxn(func(r models.Repository) error {
+		stored, err := r.Studio().GetImageChecksum(studioID)
+		assert.Nil(t, err)
+		assert.Equal(t, checksum, stored)
+
+		stored, err = r.Tag().GetImageChecksum(tagID)
+		assert.Nil(t, err)
+		assert.Equal(t, checksum, stored)
+
+		data, err := r.Studio().GetImage(studioID)
+		assert.Nil(t, err)
+		assert.Equal(t, image, data)
+		return nil
+	})
+
+	// the blob is kept while it is referenced
+	withTxn(func(r models.Repository) error {
+		return r.Studio().DestroyImage(studioID)
+	})
+	assert.Equal(t, 1, countBlobs(t, "checksum = ?", checksum))
+
+	withTxn(func(r models.Repository) error {
+		return r.Tag().DestroyImage(tagID)
+	})
+	assert.Equal(t, 0, countBlobs(t, "checksum = ?", checksum))
+
+	// files of deleted blobs are deleted by cleaning
+	assert.FileExists(t, path)
+
+	_, files, err := sqlite.CleanBlobs(true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, files)
+	assert.FileExists(t, path)
+
+	_, files, err = sqlite.CleanBlobs(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, files)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// move the blob to the database and back
+	withTxn(func(r models.Repository) error {
+		return r.Studio().UpdateImage(studioID, image)
+	})
+
+	migrate := func() int {
+		moved, err := sqlite.MigrateBlobs(func(done int, total int) bool {
+			return true
+		})
+		assert.Nil(t, err)
+		return moved
+	}
+
+	sqlite.SetBlobStoreOptions(sqlite.BlobStoreOptions{Path: dir})
+	assert.Equal(t, 1, migrate())
+	assert.Equal(t, 1, countBlobs(t, "checksum = ? AND blob = ?", checksum, image))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// blobs of other tests are moved as well
+	sqlite.SetBlobStoreOptions(sqlite.BlobStoreOptions{UseFilesystem: true, Path: dir})
+	assert.True(t, migrate() >= 1)
+	assert.Equal(t, 1, countBlobs(t, "checksum = ? AND blob IS NULL", checksum))
+	assert.FileExists(t, path)
+
+	withTxn(func(r models.Repository) error {
+		data, err := r.Studio().GetImage(studioID)
+		assert.Nil(t, err)
+		assert.Equal(t, image, data)
+
+		return r.Studio().Destroy(studioID)
+	})
+
+	// move the blobs back for the other tests
+	sqlite.SetBlobStoreOptions(sqlite.BlobStoreOptions{Path: dir})
+	migrate()
+	assert.Equal(t, 0, countBlobs(t, "blob IS NULL"))
+}
@@ -0,0 +1,251 @@
+// +build integration
+
+package sqlite_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCustomFieldsReaderWriter(t *testing.T, r models.CustomFieldsReaderWriter, id int) {
+	// ensure no custom fields to begin with
+	testCustomFields(t, r, id, models.CustomFieldMap{})
+
+	// ensure GetCustomFields with non-existing also returns none
+	testCustomFields(t, r, -1, models.CustomFieldMap{})
+
+	fields := models.CustomFieldMap{
+		"string": "value",
+		"int":    2,
+		"date":   "2020-01-02",
+		"bool":   true,
+	}
+
+	// update custom fields and ensure was updated
+	if err := r.UpdateCustomFields(id, fields); err != nil {
+		t.Error(err.Error())
+	}
+
+	testCustomFields(t, r, id, models.CustomFieldMap{
+		"string": "value",
+		"int":    int64(2),
+		"date":   "2020-01-02",
+		"bool":   true,
+	})
+
+	// update non-existing id - should return error
+	if err := r.UpdateCustomFields(-1, fields); err == nil {
+		t.Error("expected error when updating non-existing id")
+	}
+
+	// invalid values should return an error and leave the fields unchanged
+	if err := r.UpdateCustomFields(id, models.CustomFieldMap{"float": 1.5}); err == nil {
+		t.Error("expected error when setting a non-integer number")
+	}
+
+	testCustomFields(t, r, id, models.CustomFieldMap{
+		"string": "value",
+		"int":    int64(2),
+		"date":   "2020-01-02",
+		"bool":   true,
+	})
+
+	// explicitly typed values keep their type when stored again
+	if err := r.UpdateCustomFields(id, models.CustomFieldMap{
+		"string": map[string]interface{}{"type": "string", "value": "2020-01-02"},
+	}); err != nil {
+		t.Error(err.Error())
+	}
+
+	typedString := models.CustomFieldTypedValue{Type: models.CustomFieldTypeString, Value: "2020-01-02"}
+	testCustomFields(t, r, id, models.CustomFieldMap{"string": typedString})
+
+	existing, _ := r.GetCustomFields(id)
+	if err := r.UpdateCustomFields(id, existing.Merge(map[string]interface{}{"int": 3})); err != nil {
+		t.Error(err.Error())
+	}
+
+	testCustomFields(t, r, id, models.CustomFieldMap{
+		"string": typedString,
+		"int":    int64(3),
+	})
+
+	// values must match their type
+	if err := r.UpdateCustomFields(id, models.CustomFieldMap{
+		"date": map[string]interface{}{"type": "date", "value": "value"},
+	}); err == nil {
+		t.Error("expected error when setting an invalid date")
+	}
+
+	// remove custom fields and ensure was updated
+	if err := r.UpdateCustomFields(id, models.CustomFieldMap{}); err != nil {
+		t.Error(err.Error())
+	}
+
+	testCustomFields(t, r, id, models.CustomFieldMap{})
+}
+
+func testCustomFields(t *testing.T, r models.CustomFieldsReaderWriter, id int, expected models.CustomFieldMap) {
+	t.Helper()
+	fields, err := r.GetCustomFields(id)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+
+	assert.Equal(t, expected, fields)
+}
+
+func TestStudioCustomFields(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Studio()
+
+		// create studio to test against
+		const name = "TestStudioCustomFields"
+		created, err := createStudio(r.Studio(), name, nil)
+		if err != nil {
+			return fmt.Errorf("Error creating studio: %s", err.Error())
+		}
+
+		testCustomFieldsReaderWriter(t, qb, created.ID)
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneQueryCustomFields(t *testing.T) {
+	const scene1Idx = 1
+	const scene2Idx = 2
+
+	if err := withTxn(func(r models.Repository) error {
+		sqb := r.Scene()
+
+		scene1ID := sceneIDs[scene1Idx]
+		scene2ID := sceneIDs[scene2Idx]
+
+		if err := sqb.UpdateCustomFields(scene1ID, models.CustomFieldMap{
+			"source":    "Disc One",
+			"disc":      1,
+			"bought_on": "2020-01-02",
+			"watched":   true,
+		}); err != nil {
+			return err
+		}
+		if err := sqb.UpdateCustomFields(scene2ID, models.CustomFieldMap{
+			"source": "download",
+			"disc":   2,
+		}); err != nil {
+			return err
+		}
+
+		// remove the custom fields afterwards so that other tests are unaffected
+		defer func() {
+			_ = sqb.UpdateCustomFields(scene1ID, models.CustomFieldMap{})
+			_ = sqb.UpdateCustomFields(scene2ID, models.CustomFieldMap{})
+		}()
+
+		testCases := []struct {
+			name     string
+			criteria []*models.CustomFieldCriterionInput
+			included []int
+			excluded []int
+		}{
+			{
+				"string equals",
+				[]*models.CustomFieldCriterionInput{{Field: "source", Value: "disc one", Modifier: models.CriterionModifierEquals}},
+				[]int{scene1ID},
+				[]int{scene2ID},
+			},
+			{
+				"string not equals",
+				[]*models.CustomFieldCriterionInput{{Field: "source", Value: "disc one", Modifier: models.CriterionModifierNotEquals}},
+				[]int{scene2ID},
+				[]int{scene1ID},
+			},
+			{
+				"string includes",
+				[]*models.CustomFieldCriterionInput{{Field: "source", Value: "load", Modifier: models.CriterionModifierIncludes}},
+				[]int{scene2ID},
+				[]int{scene1ID},
+			},
+			{
+				"string matches regex",
+				[]*models.CustomFieldCriterionInput{{Field: "source", Value: "^Disc", Modifier: models.CriterionModifierMatchesRegex}},
+				[]int{scene1ID},
+				[]int{scene2ID},
+			},
+			{
+				"int greater than",
+				[]*models.CustomFieldCriterionInput{{Field: "disc", Value: float64(1), Modifier: models.CriterionModifierGreaterThan}},
+				[]int{scene2ID},
+				[]int{scene1ID},
+			},
+			{
+				"date less than",
+				[]*models.CustomFieldCriterionInput{{Field: "bought_on", Value: "2020-02-01", Modifier: models.CriterionModifierLessThan}},
+				[]int{scene1ID},
+				[]int{scene2ID},
+			},
+			{
+				"bool equals",
+				[]*models.CustomFieldCriterionInput{{Field: "watched", Value: true, Modifier: models.CriterionModifierEquals}},
+				[]int{scene1ID},
+				[]int{scene2ID},
+			},
+			{
+				"is null",
+				[]*models.CustomFieldCriterionInput{{Field: "watched", Modifier: models.CriterionModifierIsNull}},
+				[]int{scene2ID},
+				[]int{scene1ID},
+			},
+			{
+				"not null and int equals",
+				[]*models.CustomFieldCriterionInput{
+					{Field: "watched", Modifier: models.CriterionModifierNotNull},
+					{Field: "disc", Value: 1, Modifier: models.CriterionModifierEquals},
+				},
+				[]int{scene1ID},
+				[]int{scene2ID},
+			},
+		}
+
+		for _, tc := range testCases {
+			sceneFilter := models.SceneFilterType{
+				CustomFields: tc.criteria,
+			}
+
+			scenes := queryScene(t, sqb, &sceneFilter, nil)
+			var ids []int
+			for _, s := range scenes {
+				ids = append(ids, s.ID)
+			}
+
+			for _, id := range tc.included {
+				assert.Contains(t, ids, id, tc.name)
+			}
+			for _, id := range tc.excluded {
+				assert.NotContains(t, ids, id, tc.name)
+			}
+		}
+
+		// invalid criteria should return an error
+		for _, c := range []*models.CustomFieldCriterionInput{
+			{Field: "disc", Value: 1, Modifier: models.CriterionModifierIncludes},
+			{Field: "source", Value: "(", Modifier: models.CriterionModifierMatchesRegex},
+			{Field: "source", Modifier: models.CriterionModifierEquals},
+		} {
+			_, _, err := sqb.Query(&models.SceneFilterType{
+				CustomFields: []*models.CustomFieldCriterionInput{c},
+			}, nil)
+			assert.NotNil(t, err)
+		}
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -5,8 +5,15 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+const (
+	dateFormat      = "2006-01-02"
+	timestampFormat = "2006-01-02 15:04:05"
 )
 
 type sqlClause struct {
@@ -136,6 +143,33 @@ func (f *filterBuilder) not(n *filterBuilder) {
 	f.subFilterOp = notOp
 }
 
+func illegalFilterCombination(type1, type2 string) error {
+	return fmt.Errorf("cannot have %s and %s in the same filter", type1, type2)
+}
+
+// validateFilterCombination returns an error if more than one of the AND, OR
+// and NOT sub-filters of a filter is set.
+func validateFilterCombination(hasAnd, hasOr, hasNot bool) error {
+	const and = "AND"
+	const or = "OR"
+	const not = "NOT"
+
+	if hasAnd {
+		if hasOr {
+			return illegalFilterCombination(and, or)
+		}
+		if hasNot {
+			return illegalFilterCombination(and, not)
+		}
+	}
+
+	if hasOr && hasNot {
+		return illegalFilterCombination(or, not)
+	}
+
+	return nil
+}
+
 // addJoin adds a join to the filter. The join is expressed in SQL as:
 // LEFT JOIN <table> [AS <as>] ON <onClause>
 // The AS is omitted if as is empty.
@@ -216,7 +250,7 @@ func (f *filterBuilder) generateHavingClauses() (string, []interface{}) {
 	if f.subFilter != nil {
 		c, a := f.subFilter.generateHavingClauses()
 		if c != "" {
-			clause += " " + f.subFilterOp + " " + c
+			clause = f.getSubFilterClause(clause, c)
 			if len(a) > 0 {
 				args = append(args, a...)
 			}
@@ -346,6 +380,194 @@ func intCriterionHandler(c *models.IntCriterionInput, column string) criterionHa
 	}
 }
 
+func int64CriterionHandler(c *models.Int64CriterionInput, column string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if c != nil {
+			binding, count := getCriterionModifierBinding(c.Modifier, c.Value)
+
+			if count == 1 {
+				f.addWhere(column+" "+binding, c.Value)
+			} else {
+				f.addWhere(column + " " + binding)
+			}
+		}
+	}
+}
+
+func floatCriterionHandler(c *models.FloatCriterionInput, column string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if c != nil {
+			binding, count := getCriterionModifierBinding(c.Modifier, c.Value)
+
+			if count == 1 {
+				f.addWhere(column+" "+binding, c.Value)
+			} else {
+				f.addWhere(column + " " + binding)
+			}
+		}
+	}
+}
+
+// dateCriterionHandler returns a handler for date columns stored in
+// YYYY-MM-DD format. Empty dates are treated as null.
+func dateCriterionHandler(c *models.DateCriterionInput, column string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if c != nil {
+			switch c.Modifier {
+			case models.CriterionModifierIsNull:
+				f.addWhere(fmt.Sprintf("(%[1]s IS NULL OR %[1]s = '')", column))
+			case models.CriterionModifierNotNull:
+				f.addWhere(fmt.Sprintf("(%[1]s IS NOT NULL AND %[1]s != '')", column))
+			case models.CriterionModifierEquals, models.CriterionModifierNotEquals, models.CriterionModifierGreaterThan, models.CriterionModifierLessThan:
+				date, err := utils.ParseDateStringAsFormat(c.Value, dateFormat)
+				if err != nil {
+					f.setError(fmt.Errorf("invalid date value %q", c.Value))
+					return
+				}
+
+				binding, _ := getCriterionModifierBinding(c.Modifier, date)
+				f.addWhere(fmt.Sprintf("(%[1]s != '' AND %[1]s %[2]s)", column, binding), date)
+			default:
+				f.setError(fmt.Errorf("unsupported date modifier %s", c.Modifier))
+			}
+		}
+	}
+}
+
+// timestampCriterionHandler returns a handler for timestamp columns. If the
+// criterion value is a date only, then only the date portion of the column
+// is compared. Comparisons are made in UTC.
+func timestampCriterionHandler(c *models.TimestampCriterionInput, column string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if c != nil {
+			switch c.Modifier {
+			case models.CriterionModifierIsNull, models.CriterionModifierNotNull:
+				binding, _ := getCriterionModifierBinding(c.Modifier, nil)
+				f.addWhere(column + " " + binding)
+			case models.CriterionModifierEquals, models.CriterionModifierNotEquals, models.CriterionModifierGreaterThan, models.CriterionModifierLessThan:
+				if t, err := time.Parse(dateFormat, c.Value); err == nil {
+					binding, _ := getCriterionModifierBinding(c.Modifier, nil)
+					f.addWhere("date("+column+") "+binding, t.Format(dateFormat))
+					return
+				}
+
+				t, err := utils.ParseDateStringAsTime(c.Value)
+				if err != nil {
+					f.setError(fmt.Errorf("invalid timestamp value %q", c.Value))
+					return
+				}
+
+				binding, _ := getCriterionModifierBinding(c.Modifier, nil)
+				f.addWhere("datetime("+column+") "+binding, t.UTC().Format(timestampFormat))
+			default:
+				f.setError(fmt.Errorf("unsupported timestamp modifier %s", c.Modifier))
+			}
+		}
+	}
+}
+
+// countCriterionHandler returns a handler that filters on the number of rows
+// in joinTable referencing the primary table row using the primaryFK column.
+func countCriterionHandler(c *models.IntCriterionInput, primaryTable, joinTable, primaryFK string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if c != nil {
+			column := fmt.Sprintf("(SELECT COUNT(*) FROM %[1]s WHERE %[1]s.%[2]s = %[3]s.id)", joinTable, primaryFK, primaryTable)
+			intCriterionHandler(c, column)(f)
+		}
+	}
+}
+
+// customFieldsCriterionHandler returns a handler that filters on the custom
+// fields in cfTable referencing the primary table row using the primaryFK
+// column. All criteria must match.
+func customFieldsCriterionHandler(criteria []*models.CustomFieldCriterionInput, primaryTable, cfTable, primaryFK string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		for _, c := range criteria {
+			if c == nil {
+				continue
+			}
+
+			exists := fmt.Sprintf("EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.%[2]s = %[3]s.id AND %[1]s.field = ?", cfTable, primaryFK, primaryTable)
+
+			switch c.Modifier {
+			case models.CriterionModifierIsNull:
+				f.addWhere("NOT "+exists+")", c.Field)
+				continue
+			case models.CriterionModifierNotNull:
+				f.addWhere(exists+")", c.Field)
+				continue
+			}
+
+			if c.Value == nil {
+				f.setError(fmt.Errorf("custom field %s: value is required for modifier %s", c.Field, c.Modifier))
+				return
+			}
+
+			value := c.Value
+			if c.Type != nil && *c.Type != "" {
+				value = models.CustomFieldTypedValue{Type: *c.Type, Value: value}
+			}
+
+			v, err := models.NewCustomFieldValue(c.Field, value)
+			if err != nil {
+				f.setError(err)
+				return
+			}
+
+			isString := v.Type == models.CustomFieldTypeString || v.Type == models.CustomFieldTypeDate
+			column := cfTable + ".value"
+
+			switch c.Modifier {
+			case models.CriterionModifierEquals, models.CriterionModifierNotEquals:
+				// strings are compared case-insensitively, as in stringCriterionHandler
+				clause := column + " = ?"
+				if isString {
+					clause = column + " LIKE ?"
+				}
+				clause = fmt.Sprintf("%s.type = ? AND %s", cfTable, clause)
+				if c.Modifier == models.CriterionModifierNotEquals {
+					clause = "NOT (" + clause + ")"
+				}
+				f.addWhere(exists+" AND "+clause+")", c.Field, v.Type, v.Value)
+			case models.CriterionModifierGreaterThan, models.CriterionModifierLessThan:
+				if v.Type == models.CustomFieldTypeBool {
+					f.setError(fmt.Errorf("custom field %s: modifier %s is not supported for boolean values", c.Field, c.Modifier))
+					return
+				}
+				binding, _ := getSimpleCriterionClause(c.Modifier, "?")
+				f.addWhere(fmt.Sprintf("%s AND %s.type = ? AND %s %s)", exists, cfTable, column, binding), c.Field, v.Type, v.Value)
+			case models.CriterionModifierIncludes, models.CriterionModifierExcludes:
+				if !isString {
+					f.setError(fmt.Errorf("custom field %s: modifier %s is only supported for string values", c.Field, c.Modifier))
+					return
+				}
+				not := ""
+				if c.Modifier == models.CriterionModifierExcludes {
+					not = "NOT "
+				}
+				f.addWhere(fmt.Sprintf("%s AND %s.type IN (?, ?) AND %s %sLIKE ?)", exists, cfTable, column, not), c.Field, models.CustomFieldTypeString, models.CustomFieldTypeDate, "%"+v.Value.(string)+"%")
+			case models.CriterionModifierMatchesRegex, models.CriterionModifierNotMatchesRegex:
+				if !isString {
+					f.setError(fmt.Errorf("custom field %s: modifier %s is only supported for string values", c.Field, c.Modifier))
+					return
+				}
+				if _, err := regexp.Compile(v.Value.(string)); err != nil {
+					f.setError(err)
+					return
+				}
+				not := ""
+				if c.Modifier == models.CriterionModifierNotMatchesRegex {
+					not = "NOT "
+				}
+				f.addWhere(fmt.Sprintf("%s AND %s.type IN (?, ?) AND %s %sregexp ?)", exists, cfTable, column, not), c.Field, models.CustomFieldTypeString, models.CustomFieldTypeDate, v.Value)
+			default:
+				f.setError(fmt.Errorf("custom field %s: unsupported modifier %s", c.Field, c.Modifier))
+				return
+			}
+		}
+	}
+}
+
 func boolCriterionHandler(c *bool, column string) criterionHandlerFunc {
 	return func(f *filterBuilder) {
 		if c != nil {
@@ -601,3 +601,167 @@ func TestStringCriterionHandlerNotNull(t *testing.T) {
 	assert.Equal(fmt.Sprintf("%[1]s IS NOT NULL", column), f.whereClauses[0].sql)
 	assert.Len(f.whereClauses[0].args, 0)
 }
+
+func TestFloatCriterionHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	const column = "column"
+	const value1 = 29.97
+
+	f := &filterBuilder{}
+	f.handleCriterionFunc(floatCriterionHandler(&models.FloatCriterionInput{
+		Modifier: models.CriterionModifierGreaterThan,
+		Value:    value1,
+	}, column))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("%[1]s > ?", column), f.whereClauses[0].sql)
+	assert.Len(f.whereClauses[0].args, 1)
+	assert.Equal(value1, f.whereClauses[0].args[0])
+
+	f = &filterBuilder{}
+	f.handleCriterionFunc(floatCriterionHandler(&models.FloatCriterionInput{
+		Modifier: models.CriterionModifierIsNull,
+	}, column))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("%[1]s IS NULL", column), f.whereClauses[0].sql)
+	assert.Len(f.whereClauses[0].args, 0)
+}
+
+func TestDateCriterionHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	const column = "column"
+	const value1 = "2020-03-04"
+
+	f := &filterBuilder{}
+	f.handleCriterionFunc(dateCriterionHandler(&models.DateCriterionInput{
+		Modifier: models.CriterionModifierLessThan,
+		Value:    value1,
+	}, column))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("(%[1]s != '' AND %[1]s < ?)", column), f.whereClauses[0].sql)
+	assert.Len(f.whereClauses[0].args, 1)
+	assert.Equal(value1, f.whereClauses[0].args[0])
+
+	// empty dates are treated as null
+	f = &filterBuilder{}
+	f.handleCriterionFunc(dateCriterionHandler(&models.DateCriterionInput{
+		Modifier: models.CriterionModifierIsNull,
+	}, column))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("(%[1]s IS NULL OR %[1]s = '')", column), f.whereClauses[0].sql)
+	assert.Len(f.whereClauses[0].args, 0)
+
+	f = &filterBuilder{}
+	f.handleCriterionFunc(dateCriterionHandler(&models.DateCriterionInput{
+		Modifier: models.CriterionModifierNotNull,
+	}, column))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("(%[1]s IS NOT NULL AND %[1]s != '')", column), f.whereClauses[0].sql)
+
+	// ensure invalid date sets error state
+	f = &filterBuilder{}
+	f.handleCriterionFunc(dateCriterionHandler(&models.DateCriterionInput{
+		Modifier: models.CriterionModifierEquals,
+		Value:    "not a date",
+	}, column))
+
+	assert.NotNil(f.getError())
+
+	// ensure unsupported modifier sets error state
+	f = &filterBuilder{}
+	f.handleCriterionFunc(dateCriterionHandler(&models.DateCriterionInput{
+		Modifier: models.CriterionModifierIncludes,
+		Value:    value1,
+	}, column))
+
+	assert.NotNil(f.getError())
+}
+
+func TestTimestampCriterionHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	const column = "column"
+
+	// date only compares the date portion
+	f := &filterBuilder{}
+	f.handleCriterionFunc(timestampCriterionHandler(&models.TimestampCriterionInput{
+		Modifier: models.CriterionModifierEquals,
+		Value:    "2020-03-04",
+	}, column))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("date(%[1]s) = ?", column), f.whereClauses[0].sql)
+	assert.Len(f.whereClauses[0].args, 1)
+	assert.Equal("2020-03-04", f.whereClauses[0].args[0])
+
+	// timestamps are converted to UTC
+	f = &filterBuilder{}
+	f.handleCriterionFunc(timestampCriterionHandler(&models.TimestampCriterionInput{
+		Modifier: models.CriterionModifierGreaterThan,
+		Value:    "2020-03-04T10:00:00+10:00",
+	}, column))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("datetime(%[1]s) > ?", column), f.whereClauses[0].sql)
+	assert.Len(f.whereClauses[0].args, 1)
+	assert.Equal("2020-03-04 00:00:00", f.whereClauses[0].args[0])
+
+	f = &filterBuilder{}
+	f.handleCriterionFunc(timestampCriterionHandler(&models.TimestampCriterionInput{
+		Modifier: models.CriterionModifierNotNull,
+	}, column))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("%[1]s IS NOT NULL", column), f.whereClauses[0].sql)
+	assert.Len(f.whereClauses[0].args, 0)
+
+	// ensure invalid timestamp sets error state
+	f = &filterBuilder{}
+	f.handleCriterionFunc(timestampCriterionHandler(&models.TimestampCriterionInput{
+		Modifier: models.CriterionModifierEquals,
+		Value:    "not a timestamp",
+	}, column))
+
+	assert.NotNil(f.getError())
+
+	// ensure unsupported modifier sets error state
+	f = &filterBuilder{}
+	f.handleCriterionFunc(timestampCriterionHandler(&models.TimestampCriterionInput{
+		Modifier: models.CriterionModifierMatchesRegex,
+		Value:    "2020-03-04",
+	}, column))
+
+	assert.NotNil(f.getError())
+}
+
+func TestCountCriterionHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	const primaryTable = "primary"
+	const joinTable = "join_table"
+	const primaryFK = "primary_id"
+	const value1 = 2
+
+	f := &filterBuilder{}
+	f.handleCriterionFunc(countCriterionHandler(&models.IntCriterionInput{
+		Modifier: models.CriterionModifierGreaterThan,
+		Value:    value1,
+	}, primaryTable, joinTable, primaryFK))
+
+	assert.Len(f.whereClauses, 1)
+	assert.Equal(fmt.Sprintf("(SELECT COUNT(*) FROM %[2]s WHERE %[2]s.%[3]s = %[1]s.id) > ?", primaryTable, joinTable, primaryFK), f.whereClauses[0].sql)
+	assert.Len(f.whereClauses[0].args, 1)
+	assert.Equal(value1, f.whereClauses[0].args[0])
+
+	// nil criterion should not add a clause
+	f = &filterBuilder{}
+	f.handleCriterionFunc(countCriterionHandler(nil, primaryTable, joinTable, primaryFK))
+
+	assert.Len(f.whereClauses, 0)
+}
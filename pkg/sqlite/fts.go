@@ -0,0 +1,222 @@
+package sqlite
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ftsQuery is a search query parsed into full-text search phrases. Each
+// phrase is a quoted FTS5 string, optionally followed by * for prefix
+// matching.
+//
+// The query syntax is:
+//
+//	word      matches the word
+//	word*     matches words starting with word
+//	"a b"     matches the exact phrase
+//	+word     the word is required
+//	-word     excludes results matching the word
+//
+// Results match any of the optional phrases, unless required phrases are
+// present, in which case results must match all of the required phrases.
+// The last word of the query is also matched as a prefix, so that results
+// are found while the query is being typed.
+type ftsQuery struct {
+	required []string
+	optional []string
+	excluded []string
+}
+
+func parseFTSQuery(q string) ftsQuery {
+	var ret ftsQuery
+
+	runes := []rune(q)
+	// the phrases containing the last word of the query
+	var lastWord *[]string
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		// modifier
+		modifier := rune(0)
+		if runes[i] == '+' || runes[i] == '-' {
+			modifier = runes[i]
+			i++
+			if i >= len(runes) || unicode.IsSpace(runes[i]) {
+				continue
+			}
+		}
+
+		var term string
+		quoted := false
+		if runes[i] == '"' {
+			// phrase up to the closing quote or the end of the query
+			quoted = true
+			i++
+			start := i
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			term = string(runes[start:i])
+			if i < len(runes) {
+				i++
+			}
+		} else {
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			term = string(runes[start:i])
+		}
+
+		prefix := false
+		if i < len(runes) && runes[i] == '*' {
+			// prefix marker following a quoted phrase
+			prefix = true
+			i++
+		} else if strings.HasSuffix(term, "*") {
+			prefix = true
+			term = strings.TrimRight(term, "*")
+		}
+
+		if !hasFTSTokens(term) {
+			continue
+		}
+
+		phrase := quoteFTSPhrase(term)
+		if prefix {
+			phrase += "*"
+		}
+
+		switch modifier {
+		case '+':
+			ret.required = append(ret.required, phrase)
+			lastWord = &ret.required
+		case '-':
+			ret.excluded = append(ret.excluded, phrase)
+			lastWord = nil
+		default:
+			ret.optional = append(ret.optional, phrase)
+			lastWord = &ret.optional
+		}
+
+		if quoted || prefix {
+			lastWord = nil
+		}
+	}
+
+	if lastWord != nil {
+		(*lastWord)[len(*lastWord)-1] += "*"
+	}
+
+	return ret
+}
+
+// hasFTSTokens returns true if the term contains any characters which are
+// not separators for the unicode61 tokenizer.
+func hasFTSTokens(term string) bool {
+	for _, r := range term {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func quoteFTSPhrase(term string) string {
+	return `"` + strings.Replace(term, `"`, `""`, -1) + `"`
+}
+
+func (q ftsQuery) empty() bool {
+	return len(q.required) == 0 && len(q.optional) == 0 && len(q.excluded) == 0
+}
+
+// matchExpression returns the FTS5 expression matching the required and
+// optional phrases. Returns an empty string if there are none.
+func (q ftsQuery) matchExpression() string {
+	if len(q.required) == 0 {
+		return strings.Join(q.optional, " OR ")
+	}
+
+	ret := strings.Join(q.required, " AND ")
+	if len(q.optional) > 0 {
+		// the optional phrases do not change the results, since the first
+		// required phrase is always matched, but are included so that they
+		// affect the relevance of the results
+		optional := append([]string{}, q.optional...)
+		optional = append(optional, q.required[0])
+		ret = "(" + ret + ") AND (" + strings.Join(optional, " OR ") + ")"
+	}
+
+	return ret
+}
+
+// excludeExpression returns the FTS5 expression matching the excluded
+// phrases. Returns an empty string if there are none.
+func (q ftsQuery) excludeExpression() string {
+	return strings.Join(q.excluded, " OR ")
+}
+
+// getFTSSearchClause returns the where clause that filters the id column to
+// the rows of the full-text search table matching the query. Rows also match
+// if any of the alternative clauses are true. The alternative clauses must
+// each take the match expression as their only argument.
+func getFTSSearchClause(ftsTable string, idColumn string, q ftsQuery, alternatives ...string) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	if expr := q.matchExpression(); expr != "" {
+		matchClauses := []string{idColumn + " IN " + getFTSMatchQuery(ftsTable)}
+		args = append(args, expr)
+		for _, alternative := range alternatives {
+			matchClauses = append(matchClauses, alternative)
+			args = append(args, expr)
+		}
+
+		clauses = append(clauses, "("+strings.Join(matchClauses, " OR ")+")")
+	}
+
+	if expr := q.excludeExpression(); expr != "" {
+		clauses = append(clauses, idColumn+" NOT IN "+getFTSMatchQuery(ftsTable))
+		args = append(args, expr)
+	}
+
+	return "(" + strings.Join(clauses, " AND ") + ")", args
+}
+
+func getFTSMatchQuery(ftsTable string) string {
+	return "(SELECT rowid FROM " + ftsTable + " WHERE " + ftsTable + " MATCH ?)"
+}
+
+// relevanceSort is the sort value used to sort the results of a full-text
+// search by their relevance to the query.
+const relevanceSort = "relevance"
+
+// getFTSRelevanceSort returns the order by clause that sorts the results by
+// their relevance to the query. Results are sorted by most relevant first
+// when the direction is DESC. Returns an empty string if there is no query
+// to rank the results by.
+func getFTSRelevanceSort(ftsTable string, idColumn string, q *string, direction string) string {
+	if q == nil {
+		return ""
+	}
+
+	expr := parseFTSQuery(*q).matchExpression()
+	if expr == "" {
+		return ""
+	}
+
+	if direction != "ASC" && direction != "DESC" {
+		direction = "ASC"
+	}
+
+	// rank is lower for more relevant results, so it is negated. The
+	// expression is included as a literal since the sort clause does not
+	// take arguments. Rows that only matched an alternative clause have no
+	// rank.
+	literal := "'" + strings.Replace(expr, "'", "''", -1) + "'"
+	return " ORDER BY (SELECT -rank FROM " + ftsTable + " WHERE " + ftsTable + " MATCH " + literal + " AND rowid = " + idColumn + ") " + direction
+}
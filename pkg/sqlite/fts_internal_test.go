@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFTSQuery(t *testing.T) {
+	scenarios := []struct {
+		q       string
+		match   string
+		exclude string
+	}{
+		{"", "", ""},
+		{"one", `"one"*`, ""},
+		{"one two", `"one" OR "two"*`, ""},
+		{`"one two"`, `"one two"`, ""},
+		{`"one two"*`, `"one two"*`, ""},
+		{"one* two", `"one"* OR "two"*`, ""},
+		{"+one +two", `"one" AND "two"*`, ""},
+		{"+one two", `("one") AND ("two"* OR "one")`, ""},
+		{"one -two", `"one"`, `"two"`},
+		{`one -"two three"`, `"one"`, `"two three"`},
+		{"-one", "", `"one"`},
+		{`one "two`, `"one" OR "two"`, ""},
+		{`one "t"w"o"`, `"one" OR "t" OR "w""o"""*`, ""},
+		{"one - + - two", `"one" OR "two"*`, ""},
+		{"one's", `"one's"*`, ""},
+		{"... one", `"one"*`, ""},
+	}
+
+	for _, s := range scenarios {
+		q := parseFTSQuery(s.q)
+		assert.Equal(t, s.match, q.matchExpression(), s.q)
+		assert.Equal(t, s.exclude, q.excludeExpression(), s.q)
+	}
+}
+
+func TestGetFTSSearchClause(t *testing.T) {
+	q := parseFTSQuery("one -two")
+	clause, args := getFTSSearchClause("scenes_fts", "scenes.id", q, "alternative = ?")
+
+	assert.Equal(t, "((scenes.id IN (SELECT rowid FROM scenes_fts WHERE scenes_fts MATCH ?) OR alternative = ?) AND scenes.id NOT IN (SELECT rowid FROM scenes_fts WHERE scenes_fts MATCH ?))", clause)
+	assert.Equal(t, []interface{}{`"one"`, `"one"`, `"two"`}, args)
+}
+
+func TestGetFTSRelevanceSort(t *testing.T) {
+	q := "one's"
+	assert.Equal(t, ` ORDER BY (SELECT -rank FROM tags_fts WHERE tags_fts MATCH '"one''s"*' AND rowid = tags.id) DESC`, getFTSRelevanceSort("tags_fts", "tags.id", &q, "DESC"))
+
+	assert.Equal(t, "", getFTSRelevanceSort("tags_fts", "tags.id", nil, "DESC"))
+}
@@ -9,11 +9,13 @@ import (
 )
 
 const galleryTable = "galleries"
+const galleryCustomFieldsTable = "gallery_custom_fields"
 
 const performersGalleriesTable = "performers_galleries"
 const galleriesTagsTable = "galleries_tags"
 const galleriesImagesTable = "galleries_images"
 const galleriesScenesTable = "scenes_galleries"
+const galleriesChaptersTable = "galleries_chapters"
 const galleryIDColumn = "gallery_id"
 
 type galleryQueryBuilder struct {
@@ -159,105 +161,87 @@ func (qb *galleryQueryBuilder) All() ([]*models.Gallery, error) {
 	return qb.queryGalleries(selectAll("galleries")+qb.getGallerySort(nil), nil)
 }
 
-func (qb *galleryQueryBuilder) Query(galleryFilter *models.GalleryFilterType, findFilter *models.FindFilterType) ([]*models.Gallery, int, error) {
-	if galleryFilter == nil {
-		galleryFilter = &models.GalleryFilterType{}
+func (qb *galleryQueryBuilder) validateFilter(filter *models.GalleryFilterType) error {
+	if err := validateFilterCombination(filter.And != nil, filter.Or != nil, filter.Not != nil); err != nil {
+		return err
 	}
-	if findFilter == nil {
-		findFilter = &models.FindFilterType{}
+
+	switch {
+	case filter.And != nil:
+		return qb.validateFilter(filter.And)
+	case filter.Or != nil:
+		return qb.validateFilter(filter.Or)
+	case filter.Not != nil:
+		return qb.validateFilter(filter.Not)
 	}
 
-	query := qb.newQuery()
+	return nil
+}
 
-	query.body = selectDistinctIDs("galleries")
-	query.body += `
-		left join performers_galleries as performers_join on performers_join.gallery_id = galleries.id
-		left join scenes_galleries as scenes_join on scenes_join.gallery_id = galleries.id
-		left join studios as studio on studio.id = galleries.studio_id
-		left join galleries_tags as tags_join on tags_join.gallery_id = galleries.id
-		left join galleries_images as images_join on images_join.gallery_id = galleries.id
-		left join images on images_join.image_id = images.id
-	`
+func (qb *galleryQueryBuilder) makeFilter(filter *models.GalleryFilterType) *filterBuilder {
+	query := &filterBuilder{}
 
-	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"galleries.title", "galleries.path", "galleries.checksum"}
-		clause, thisArgs := getSearchBinding(searchColumns, *q, false)
-		query.addWhere(clause)
-		query.addArg(thisArgs...)
-	}
-
-	if zipFilter := galleryFilter.IsZip; zipFilter != nil {
-		var favStr string
-		if *zipFilter == true {
-			favStr = "1"
-		} else {
-			favStr = "0"
-		}
-		query.addWhere("galleries.zip = " + favStr)
+	if filter.And != nil {
+		query.and(qb.makeFilter(filter.And))
+	}
+	if filter.Or != nil {
+		query.or(qb.makeFilter(filter.Or))
+	}
+	if filter.Not != nil {
+		query.not(qb.makeFilter(filter.Not))
 	}
 
-	query.handleStringCriterionInput(galleryFilter.Path, "galleries.path")
-	query.handleIntCriterionInput(galleryFilter.Rating, "galleries.rating")
-	qb.handleAverageResolutionFilter(&query, galleryFilter.AverageResolution)
+	query.handleCriterionFunc(stringCriterionHandler(filter.Path, "galleries.path"))
+	query.handleCriterionFunc(intCriterionHandler(filter.Rating, "galleries.rating"))
+	query.handleCriterionFunc(boolCriterionHandler(filter.IsZip, "galleries.zip"))
+	query.handleCriterionFunc(boolCriterionHandler(filter.Organized, "galleries.organized"))
+	query.handleCriterionFunc(galleryIsMissingCriterionHandler(qb, filter.IsMissing))
+	query.handleCriterionFunc(galleryTagsCriterionHandler(qb, filter.Tags))
+	query.handleCriterionFunc(galleryPerformersCriterionHandler(qb, filter.Performers))
+	query.handleCriterionFunc(galleryStudioCriterionHandler(qb, filter.Studios))
+	query.handleCriterionFunc(countCriterionHandler(filter.ImageCount, galleryTable, galleriesImagesTable, galleryIDColumn))
+	query.handleCriterionFunc(galleryAverageResolutionCriterionHandler(qb, filter.AverageResolution))
 
-	if Organized := galleryFilter.Organized; Organized != nil {
-		var organized string
-		if *Organized == true {
-			organized = "1"
-		} else {
-			organized = "0"
-		}
-		query.addWhere("galleries.organized = " + organized)
-	}
-
-	if isMissingFilter := galleryFilter.IsMissing; isMissingFilter != nil && *isMissingFilter != "" {
-		switch *isMissingFilter {
-		case "scenes":
-			query.addWhere("scenes_join.gallery_id IS NULL")
-		case "studio":
-			query.addWhere("galleries.studio_id IS NULL")
-		case "performers":
-			query.addWhere("performers_join.gallery_id IS NULL")
-		case "date":
-			query.addWhere("galleries.date IS \"\" OR galleries.date IS \"0001-01-01\"")
-		case "tags":
-			query.addWhere("tags_join.gallery_id IS NULL")
-		default:
-			query.addWhere("galleries." + *isMissingFilter + " IS NULL")
-		}
-	}
+	query.handleCriterionFunc(dateCriterionHandler(filter.Date, "galleries.date"))
+	query.handleCriterionFunc(countCriterionHandler(filter.PerformerCount, galleryTable, performersGalleriesTable, galleryIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(filter.TagCount, galleryTable, galleriesTagsTable, galleryIDColumn))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.CreatedAt, "galleries.created_at"))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.UpdatedAt, "galleries.updated_at"))
+	query.handleCriterionFunc(customFieldsCriterionHandler(filter.CustomFields, galleryTable, galleryCustomFieldsTable, galleryIDColumn))
 
-	if tagsFilter := galleryFilter.Tags; tagsFilter != nil && len(tagsFilter.Value) > 0 {
-		for _, tagID := range tagsFilter.Value {
-			query.addArg(tagID)
-		}
+	return query
+}
 
-		query.body += " LEFT JOIN tags on tags_join.tag_id = tags.id"
-		whereClause, havingClause := getMultiCriterionClause("galleries", "tags", "galleries_tags", "gallery_id", "tag_id", tagsFilter)
-		query.addWhere(whereClause)
-		query.addHaving(havingClause)
+func (qb *galleryQueryBuilder) Query(galleryFilter *models.GalleryFilterType, findFilter *models.FindFilterType) ([]*models.Gallery, int, error) {
+	if galleryFilter == nil {
+		galleryFilter = &models.GalleryFilterType{}
+	}
+	if findFilter == nil {
+		findFilter = &models.FindFilterType{}
 	}
 
-	if performersFilter := galleryFilter.Performers; performersFilter != nil && len(performersFilter.Value) > 0 {
-		for _, performerID := range performersFilter.Value {
-			query.addArg(performerID)
-		}
+	query := qb.newQuery()
 
-		query.body += " LEFT JOIN performers ON performers_join.performer_id = performers.id"
-		whereClause, havingClause := getMultiCriterionClause("galleries", "performers", "performers_galleries", "gallery_id", "performer_id", performersFilter)
-		query.addWhere(whereClause)
-		query.addHaving(havingClause)
-	}
+	query.body = selectDistinctIDs(galleryTable)
 
-	if studiosFilter := galleryFilter.Studios; studiosFilter != nil && len(studiosFilter.Value) > 0 {
-		for _, studioID := range studiosFilter.Value {
-			query.addArg(studioID)
+	// required for sorting by image count
+	query.join(galleriesImagesTable, "images_join", "images_join.gallery_id = galleries.id")
+	query.join(imageTable, "", "images_join.image_id = images.id")
+
+	if q := findFilter.Q; q != nil && *q != "" {
+		if searchQuery := parseFTSQuery(*q); !searchQuery.empty() {
+			clause, thisArgs := getFTSSearchClause("galleries_fts", "galleries.id", searchQuery)
+			query.addWhere(clause)
+			query.addArg(thisArgs...)
 		}
+	}
 
-		whereClause, havingClause := getMultiCriterionClause("galleries", "studio", "", "", "studio_id", studiosFilter)
-		query.addWhere(whereClause)
-		query.addHaving(havingClause)
+	if err := qb.validateFilter(galleryFilter); err != nil {
+		return nil, 0, err
 	}
+	filter := qb.makeFilter(galleryFilter)
+
+	query.addFilter(filter)
 
 	query.sortAndPagination = qb.getGallerySort(findFilter) + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
@@ -278,68 +262,88 @@ func (qb *galleryQueryBuilder) Query(galleryFilter *models.GalleryFilterType, fi
 	return galleries, countResult, nil
 }
 
-func (qb *galleryQueryBuilder) handleAverageResolutionFilter(query *queryBuilder, resolutionFilter *models.ResolutionEnum) {
-	if resolutionFilter == nil {
-		return
-	}
-
-	if resolution := resolutionFilter.String(); resolutionFilter.IsValid() {
-		var low int
-		var high int
-
-		switch resolution {
-		case "VERY_LOW":
-			high = 240
-		case "LOW":
-			low = 240
-			high = 360
-		case "R360P":
-			low = 360
-			high = 480
-		case "STANDARD":
-			low = 480
-			high = 540
-		case "WEB_HD":
-			low = 540
-			high = 720
-		case "STANDARD_HD":
-			low = 720
-			high = 1080
-		case "FULL_HD":
-			low = 1080
-			high = 1440
-		case "QUAD_HD":
-			low = 1440
-			high = 1920
-		case "VR_HD":
-			low = 1920
-			high = 2160
-		case "FOUR_K":
-			low = 2160
-			high = 2880
-		case "FIVE_K":
-			low = 2880
-			high = 3384
-		case "SIX_K":
-			low = 3384
-			high = 4320
-		case "EIGHT_K":
-			low = 4320
+func galleryIsMissingCriterionHandler(qb *galleryQueryBuilder, isMissing *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if isMissing != nil && *isMissing != "" {
+			switch *isMissing {
+			case "scenes":
+				qb.scenesRepository().join(f, "scenes_join", "galleries.id")
+				f.addWhere("scenes_join.gallery_id IS NULL")
+			case "studio":
+				f.addWhere("galleries.studio_id IS NULL")
+			case "performers":
+				qb.performersRepository().join(f, "performers_join", "galleries.id")
+				f.addWhere("performers_join.gallery_id IS NULL")
+			case "date":
+				f.addWhere("galleries.date IS \"\" OR galleries.date IS \"0001-01-01\"")
+			case "tags":
+				qb.tagsRepository().join(f, "tags_join", "galleries.id")
+				f.addWhere("tags_join.gallery_id IS NULL")
+			default:
+				f.addWhere("galleries." + *isMissing + " IS NULL")
+			}
 		}
+	}
+}
 
-		havingClause := ""
-		if low != 0 {
-			havingClause = "avg(MIN(images.width, images.height)) >= " + strconv.Itoa(low)
-		}
-		if high != 0 {
-			if havingClause != "" {
-				havingClause += " AND "
+func (qb *galleryQueryBuilder) getMultiCriterionHandlerBuilder(foreignTable, joinTable, foreignFK string, addJoinsFunc func(f *filterBuilder)) multiCriterionHandlerBuilder {
+	return multiCriterionHandlerBuilder{
+		primaryTable: galleryTable,
+		foreignTable: foreignTable,
+		joinTable:    joinTable,
+		primaryFK:    galleryIDColumn,
+		foreignFK:    foreignFK,
+		addJoinsFunc: addJoinsFunc,
+	}
+}
+
+func galleryTagsCriterionHandler(qb *galleryQueryBuilder, tags *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		qb.tagsRepository().join(f, "tags_join", "galleries.id")
+		f.addJoin(tagTable, "", "tags_join.tag_id = tags.id")
+	}
+	h := qb.getMultiCriterionHandlerBuilder(tagTable, galleriesTagsTable, tagIDColumn, addJoinsFunc)
+
+	return h.handler(tags)
+}
+
+func galleryPerformersCriterionHandler(qb *galleryQueryBuilder, performers *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		qb.performersRepository().join(f, "performers_join", "galleries.id")
+		f.addJoin(performerTable, "", "performers_join.performer_id = performers.id")
+	}
+	h := qb.getMultiCriterionHandlerBuilder(performerTable, performersGalleriesTable, performerIDColumn, addJoinsFunc)
+
+	return h.handler(performers)
+}
+
+func galleryStudioCriterionHandler(qb *galleryQueryBuilder, studios *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		f.addJoin(studioTable, "studio", "studio.id = galleries.studio_id")
+	}
+	h := qb.getMultiCriterionHandlerBuilder("studio", "", studioIDColumn, addJoinsFunc)
+
+	return h.handler(studios)
+}
+
+func galleryAverageResolutionCriterionHandler(qb *galleryQueryBuilder, resolution *models.ResolutionEnum) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if resolution != nil && resolution.IsValid() {
+			qb.imagesRepository().join(f, "images_join", "galleries.id")
+			f.addJoin(imageTable, "", "images_join.image_id = images.id")
+
+			min := resolution.GetMinResolution()
+			max := resolution.GetMaxResolution()
+
+			const widthHeight = "avg(MIN(images.width, images.height))"
+
+			if min > 0 {
+				f.addHaving(widthHeight + " >= " + strconv.Itoa(min))
 			}
-			havingClause += "avg(MIN(images.width, images.height)) < " + strconv.Itoa(high)
-		}
 
-		if havingClause != "" {
-			query.addHaving(havingClause)
+			if max > 0 {
+				f.addHaving(widthHeight + " < " + strconv.Itoa(max))
+			}
 		}
 	}
 }
@@ -353,6 +357,13 @@ func (qb *galleryQueryBuilder) getGallerySort(findFilter *models.FindFilterType)
 	} else {
 		sort = findFilter.GetSort("path")
 		direction = findFilter.GetDirection()
+
+		if sort == relevanceSort {
+			if ret := getFTSRelevanceSort("galleries_fts", "galleries.id", findFilter.Q, direction); ret != "" {
+				return ret
+			}
+			sort = "path"
+		}
 	}
 	return getSort(sort, direction, "galleries")
 }
@@ -434,6 +445,30 @@ func (qb *galleryQueryBuilder) UpdateImages(galleryID int, imageIDs []int) error
 	return qb.imagesRepository().replace(galleryID, imageIDs)
 }
 
+func (qb *galleryQueryBuilder) GetImageOrder(galleryID int) ([]int, error) {
+	query := `SELECT image_id as id FROM galleries_images
+	WHERE gallery_id = ? AND position IS NOT NULL
+	ORDER BY position`
+	return qb.runIdsQuery(query, []interface{}{galleryID})
+}
+
+// UpdateImageOrder sets the position of each of the provided images in the
+// gallery to its index. The position of the other images in the gallery is
+// cleared, so that they follow the ordered images.
+func (qb *galleryQueryBuilder) UpdateImageOrder(galleryID int, imageIDs []int) error {
+	if _, err := qb.tx.Exec("UPDATE galleries_images SET position = NULL WHERE gallery_id = ?", galleryID); err != nil {
+		return err
+	}
+
+	for i, imageID := range imageIDs {
+		if _, err := qb.tx.Exec("UPDATE galleries_images SET position = ? WHERE gallery_id = ? AND image_id = ?", i, galleryID, imageID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (qb *galleryQueryBuilder) scenesRepository() *joinRepository {
 	return &joinRepository{
 		repository: repository{
@@ -453,3 +488,60 @@ func (qb *galleryQueryBuilder) UpdateScenes(galleryID int, sceneIDs []int) error
 	// Delete the existing joins and then create new ones
 	return qb.scenesRepository().replace(galleryID, sceneIDs)
 }
+
+func (qb *galleryQueryBuilder) customFieldsRepository() *customFieldsRepository {
+	return &customFieldsRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: galleryCustomFieldsTable,
+			idColumn:  galleryIDColumn,
+		},
+	}
+}
+
+func (qb *galleryQueryBuilder) GetCustomFields(galleryID int) (models.CustomFieldMap, error) {
+	return qb.customFieldsRepository().get(galleryID)
+}
+
+func (qb *galleryQueryBuilder) UpdateCustomFields(galleryID int, fields models.CustomFieldMap) error {
+	return qb.customFieldsRepository().replace(galleryID, fields)
+}
+
+type galleryChapters []*models.GalleryChapter
+
+func (c *galleryChapters) Append(o interface{}) {
+	*c = append(*c, o.(*models.GalleryChapter))
+}
+
+func (c *galleryChapters) New() interface{} {
+	return &models.GalleryChapter{}
+}
+
+func (qb *galleryQueryBuilder) chaptersRepository() *repository {
+	return &repository{
+		tx:        qb.tx,
+		tableName: galleriesChaptersTable,
+		idColumn:  galleryIDColumn,
+	}
+}
+
+func (qb *galleryQueryBuilder) GetChapters(galleryID int) ([]*models.GalleryChapter, error) {
+	query := "SELECT title, image_index FROM galleries_chapters WHERE gallery_id = ? ORDER BY image_index, rowid"
+	var ret galleryChapters
+	err := qb.chaptersRepository().query(query, []interface{}{galleryID}, &ret)
+	return []*models.GalleryChapter(ret), err
+}
+
+func (qb *galleryQueryBuilder) UpdateChapters(galleryID int, chapters []models.GalleryChapter) error {
+	r := qb.chaptersRepository()
+	if err := r.destroy([]int{galleryID}); err != nil {
+		return err
+	}
+
+	for _, c := range chapters {
+		if _, err := qb.tx.Exec("INSERT INTO galleries_chapters (gallery_id, title, image_index) VALUES (?, ?, ?)", galleryID, c.Title, c.ImageIndex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
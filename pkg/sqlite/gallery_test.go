@@ -3,6 +3,7 @@
 package sqlite_test
 
 import (
+	"fmt"
 	"strconv"
 	"testing"
 
@@ -193,6 +194,106 @@ func verifyGalleriesPath(t *testing.T, sqb models.GalleryReader, pathCriterion m
 	}
 }
 
+func TestGalleryQueryPathOr(t *testing.T) {
+	const gallery1Idx = 1
+	const gallery2Idx = 2
+
+	gallery1Path := getGalleryStringValue(gallery1Idx, "Path")
+	gallery2Path := getGalleryStringValue(gallery2Idx, "Path")
+
+	galleryFilter := models.GalleryFilterType{
+		Path: &models.StringCriterionInput{
+			Value:    gallery1Path,
+			Modifier: models.CriterionModifierEquals,
+		},
+		Or: &models.GalleryFilterType{
+			Path: &models.StringCriterionInput{
+				Value:    gallery2Path,
+				Modifier: models.CriterionModifierEquals,
+			},
+		},
+	}
+
+	withTxn(func(r models.Repository) error {
+		sqb := r.Gallery()
+
+		galleries := queryGallery(t, sqb, &galleryFilter, nil)
+
+		assert.Len(t, galleries, 2)
+		assert.Equal(t, gallery1Path, galleries[0].Path.String)
+		assert.Equal(t, gallery2Path, galleries[1].Path.String)
+
+		return nil
+	})
+}
+
+func TestGalleryQueryTagsOrPerformers(t *testing.T) {
+	galleryFilter := models.GalleryFilterType{
+		Tags: &models.MultiCriterionInput{
+			Value:    []string{strconv.Itoa(tagIDs[tagIdxWithGallery])},
+			Modifier: models.CriterionModifierIncludes,
+		},
+		Or: &models.GalleryFilterType{
+			Performers: &models.MultiCriterionInput{
+				Value:    []string{strconv.Itoa(performerIDs[performerIdxWithGallery])},
+				Modifier: models.CriterionModifierIncludes,
+			},
+		},
+	}
+
+	withTxn(func(r models.Repository) error {
+		sqb := r.Gallery()
+
+		galleries := queryGallery(t, sqb, &galleryFilter, nil)
+
+		var ids []int
+		for _, g := range galleries {
+			ids = append(ids, g.ID)
+		}
+
+		assert.Contains(t, ids, galleryIDs[galleryIdxWithTag])
+		assert.Contains(t, ids, galleryIDs[galleryIdxWithPerformer])
+
+		return nil
+	})
+}
+
+func TestGalleryIllegalQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	const galleryIdx = 1
+	subFilter := models.GalleryFilterType{
+		Path: &models.StringCriterionInput{
+			Value:    getGalleryStringValue(galleryIdx, "Path"),
+			Modifier: models.CriterionModifierEquals,
+		},
+	}
+
+	galleryFilter := &models.GalleryFilterType{
+		And: &subFilter,
+		Or:  &subFilter,
+	}
+
+	withTxn(func(r models.Repository) error {
+		sqb := r.Gallery()
+
+		_, _, err := sqb.Query(galleryFilter, nil)
+		assert.NotNil(err)
+
+		galleryFilter.Or = nil
+		galleryFilter.Not = &subFilter
+		_, _, err = sqb.Query(galleryFilter, nil)
+		assert.NotNil(err)
+
+		galleryFilter.And = nil
+		galleryFilter.Or = &subFilter
+		_, _, err = sqb.Query(galleryFilter, nil)
+		assert.NotNil(err)
+
+		return nil
+	})
+}
+
 func TestGalleryQueryRating(t *testing.T) {
 	const rating = 3
 	ratingCriterion := models.IntCriterionInput{
@@ -519,6 +620,120 @@ func TestGalleryQueryStudio(t *testing.T) {
 	})
 }
 
+func getImageIDs(images []*models.Image) []int {
+	var ret []int
+	for _, image := range images {
+		ret = append(ret, image.ID)
+	}
+	return ret
+}
+
+func TestGalleryImageOrder(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Gallery()
+		galleryID := galleryIDs[galleryIdxWithTag]
+		image1 := imageIDs[imageIdxWithPerformer]
+		image2 := imageIDs[imageIdxWithTag]
+		image3 := imageIDs[imageIdxWithStudio]
+
+		if err := qb.UpdateImages(galleryID, []int{image1, image2}); err != nil {
+			return fmt.Errorf("Error updating images: %s", err.Error())
+		}
+
+		if err := qb.UpdateImageOrder(galleryID, []int{image2, image1}); err != nil {
+			return fmt.Errorf("Error updating image order: %s", err.Error())
+		}
+
+		order, err := qb.GetImageOrder(galleryID)
+		if err != nil {
+			return fmt.Errorf("Error getting image order: %s", err.Error())
+		}
+		assert.Equal(t, []int{image2, image1}, order)
+
+		images, err := r.Image().FindByGalleryID(galleryID)
+		if err != nil {
+			return fmt.Errorf("Error finding images: %s", err.Error())
+		}
+		assert.Equal(t, []int{image2, image1}, getImageIDs(images))
+
+		// the order of the existing images is kept when images are added,
+		// and the new images follow
+		if err := qb.UpdateImages(galleryID, []int{image3, image1, image2}); err != nil {
+			return fmt.Errorf("Error updating images: %s", err.Error())
+		}
+
+		images, err = r.Image().FindByGalleryID(galleryID)
+		if err != nil {
+			return fmt.Errorf("Error finding images: %s", err.Error())
+		}
+		assert.Equal(t, []int{image2, image1, image3}, getImageIDs(images))
+
+		// images filtered by the gallery can be sorted by their position
+		sort := "position"
+		images, _, err = r.Image().Query(&models.ImageFilterType{
+			Galleries: &models.MultiCriterionInput{
+				Value:    []string{strconv.Itoa(galleryID)},
+				Modifier: models.CriterionModifierIncludes,
+			},
+		}, &models.FindFilterType{Sort: &sort})
+		if err != nil {
+			return fmt.Errorf("Error querying images: %s", err.Error())
+		}
+		assert.Equal(t, []int{image2, image1, image3}, getImageIDs(images))
+
+		// resetting the order orders the images by path
+		if err := qb.UpdateImageOrder(galleryID, nil); err != nil {
+			return fmt.Errorf("Error updating image order: %s", err.Error())
+		}
+
+		images, err = r.Image().FindByGalleryID(galleryID)
+		if err != nil {
+			return fmt.Errorf("Error finding images: %s", err.Error())
+		}
+		assert.Equal(t, []int{image1, image2, image3}, getImageIDs(images))
+
+		// restore the gallery images
+		return qb.UpdateImages(galleryID, nil)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestGalleryUpdateChapters(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Gallery()
+		galleryID := galleryIDs[galleryIdxWithImage]
+
+		chapters := []models.GalleryChapter{
+			{Title: "Second", ImageIndex: 10},
+			{Title: "First", ImageIndex: 0},
+		}
+		if err := qb.UpdateChapters(galleryID, chapters); err != nil {
+			return fmt.Errorf("Error updating chapters: %s", err.Error())
+		}
+
+		got, err := qb.GetChapters(galleryID)
+		if err != nil {
+			return fmt.Errorf("Error getting chapters: %s", err.Error())
+		}
+		assert.Equal(t, []*models.GalleryChapter{&chapters[1], &chapters[0]}, got)
+
+		if err := qb.UpdateChapters(galleryID, nil); err != nil {
+			return fmt.Errorf("Error updating chapters: %s", err.Error())
+		}
+
+		got, err = qb.GetChapters(galleryID)
+		if err != nil {
+			return fmt.Errorf("Error getting chapters: %s", err.Error())
+		}
+		assert.Len(t, got, 0)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 // TODO Count
 // TODO All
 // TODO Query
@@ -3,14 +3,25 @@ package sqlite
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
 
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 const imageTable = "images"
+const imageCustomFieldsTable = "image_custom_fields"
 const imageIDColumn = "image_id"
 const performersImagesTable = "performers_images"
 const imagesTagsTable = "images_tags"
+const imagesKeywordsTable = "images_keywords"
+const imagesMergedChecksumsTable = "images_merged_checksums"
+
+// imagePositionSort is the sort value used to sort images by their position
+// in the gallery of the galleries filter.
+const imagePositionSort = "position"
 
 var imagesForPerformerQuery = selectAll(imageTable) + `
 LEFT JOIN performers_images as performers_join on performers_join.image_id = images.id
@@ -188,6 +199,14 @@ func (qb *imageQueryBuilder) FindByChecksum(checksum string) (*models.Image, err
 	return qb.queryImage(query, args)
 }
 
+func (qb *imageQueryBuilder) FindByMergedChecksum(checksum string) (*models.Image, error) {
+	query := selectAll(imageTable) + `
+	INNER JOIN images_merged_checksums ON images_merged_checksums.image_id = images.id
+	WHERE images_merged_checksums.checksum = ? LIMIT 1`
+	args := []interface{}{checksum}
+	return qb.queryImage(query, args)
+}
+
 func (qb *imageQueryBuilder) FindByPath(path string) (*models.Image, error) {
 	query := selectAll(imageTable) + "WHERE path = ? LIMIT 1"
 	args := []interface{}{path}
@@ -196,7 +215,8 @@ func (qb *imageQueryBuilder) FindByPath(path string) (*models.Image, error) {
 
 func (qb *imageQueryBuilder) FindByGalleryID(galleryID int) ([]*models.Image, error) {
 	args := []interface{}{galleryID}
-	return qb.queryImages(imagesForGalleryQuery+qb.getImageSort(nil), args)
+	// images with an explicit position come first
+	return qb.queryImages(imagesForGalleryQuery+" ORDER BY galleries_join.position IS NULL, galleries_join.position, images.path ASC ", args)
 }
 
 func (qb *imageQueryBuilder) CountByGalleryID(galleryID int) (int, error) {
@@ -213,154 +233,128 @@ func (qb *imageQueryBuilder) Size() (float64, error) {
 }
 
 func (qb *imageQueryBuilder) All() ([]*models.Image, error) {
-	return qb.queryImages(selectAll(imageTable)+qb.getImageSort(nil), nil)
+	return qb.queryImages(selectAll(imageTable)+qb.getImageSort(nil, nil), nil)
 }
 
-func (qb *imageQueryBuilder) Query(imageFilter *models.ImageFilterType, findFilter *models.FindFilterType) ([]*models.Image, int, error) {
-	if imageFilter == nil {
-		imageFilter = &models.ImageFilterType{}
-	}
-	if findFilter == nil {
-		findFilter = &models.FindFilterType{}
+func (qb *imageQueryBuilder) FindDuplicates(distance int) ([][]*models.Image, error) {
+	if distance < 0 || distance > utils.MaxHammingDistance {
+		return nil, fmt.Errorf("distance must be between 0 and %d", utils.MaxHammingDistance)
 	}
 
-	query := qb.newQuery()
-
-	query.body = selectDistinctIDs(imageTable)
-	query.body += `
-		left join performers_images as performers_join on performers_join.image_id = images.id
-		left join studios as studio on studio.id = images.studio_id
-		left join images_tags as tags_join on tags_join.image_id = images.id
-		left join galleries_images as galleries_join on galleries_join.image_id = images.id
-	`
+	var ids []int
+	var hashes []uint64
+	query := "SELECT id, phash FROM images WHERE phash IS NOT NULL ORDER BY id"
+	if err := qb.queryFunc(query, nil, func(rows *sqlx.Rows) error {
+		var id int
+		var phash int64
+		if err := rows.Scan(&id, &phash); err != nil {
+			return err
+		}
 
-	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"images.title", "images.path", "images.checksum"}
-		clause, thisArgs := getSearchBinding(searchColumns, *q, false)
-		query.addWhere(clause)
-		query.addArg(thisArgs...)
+		ids = append(ids, id)
+		hashes = append(hashes, uint64(phash))
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	query.handleStringCriterionInput(imageFilter.Path, "images.path")
-
-	if rating := imageFilter.Rating; rating != nil {
-		clause, count := getIntCriterionWhereClause("images.rating", *imageFilter.Rating)
-		query.addWhere(clause)
-		if count == 1 {
-			query.addArg(imageFilter.Rating.Value)
+	var ret [][]*models.Image
+	for _, group := range utils.GroupSimilarHashes(hashes, distance) {
+		var groupIDs []int
+		for _, i := range group {
+			groupIDs = append(groupIDs, ids[i])
 		}
-	}
 
-	if oCounter := imageFilter.OCounter; oCounter != nil {
-		clause, count := getIntCriterionWhereClause("images.o_counter", *imageFilter.OCounter)
-		query.addWhere(clause)
-		if count == 1 {
-			query.addArg(imageFilter.OCounter.Value)
+		images, err := qb.FindMany(groupIDs)
+		if err != nil {
+			return nil, err
 		}
+		ret = append(ret, images)
 	}
 
-	if Organized := imageFilter.Organized; Organized != nil {
-		var organized string
-		if *Organized == true {
-			organized = "1"
-		} else {
-			organized = "0"
-		}
-		query.addWhere("images.organized = " + organized)
-	}
-
-	if resolutionFilter := imageFilter.Resolution; resolutionFilter != nil {
-		if resolution := resolutionFilter.String(); resolutionFilter.IsValid() {
-			switch resolution {
-			case "VERY_LOW":
-				query.addWhere("MIN(images.height, images.width) < 240")
-			case "LOW":
-				query.addWhere("(MIN(images.height, images.width) >= 240 AND MIN(images.height, images.width) < 360)")
-			case "R360P":
-				query.addWhere("(MIN(images.height, images.width) >= 360 AND MIN(images.height, images.width) < 480)")
-			case "STANDARD":
-				query.addWhere("(MIN(images.height, images.width) >= 480 AND MIN(images.height, images.width) < 540)")
-			case "WEB_HD":
-				query.addWhere("(MIN(images.height, images.width) >= 540 AND MIN(images.height, images.width) < 720)")
-			case "STANDARD_HD":
-				query.addWhere("(MIN(images.height, images.width) >= 720 AND MIN(images.height, images.width) < 1080)")
-			case "FULL_HD":
-				query.addWhere("(MIN(images.height, images.width) >= 1080 AND MIN(images.height, images.width) < 1440)")
-			case "QUAD_HD":
-				query.addWhere("(MIN(images.height, images.width) >= 1440 AND MIN(images.height, images.width) < 1920)")
-			case "VR_HD":
-				query.addWhere("(MIN(images.height, images.width) >= 1920 AND MIN(images.height, images.width) < 2160)")
-			case "FOUR_K":
-				query.addWhere("(MIN(images.height, images.width) >= 2160 AND MIN(images.height, images.width) < 2880)")
-			case "FIVE_K":
-				query.addWhere("(MIN(images.height, images.width) >= 2880 AND MIN(images.height, images.width) < 3384)")
-			case "SIX_K":
-				query.addWhere("(MIN(images.height, images.width) >= 3384 AND MIN(images.height, images.width) < 4320)")
-			case "EIGHT_K":
-				query.addWhere("MIN(images.height, images.width) >= 4320")
-			}
-		}
+	return ret, nil
+}
+
+func (qb *imageQueryBuilder) validateFilter(filter *models.ImageFilterType) error {
+	if err := validateFilterCombination(filter.And != nil, filter.Or != nil, filter.Not != nil); err != nil {
+		return err
 	}
 
-	if isMissingFilter := imageFilter.IsMissing; isMissingFilter != nil && *isMissingFilter != "" {
-		switch *isMissingFilter {
-		case "studio":
-			query.addWhere("images.studio_id IS NULL")
-		case "performers":
-			query.addWhere("performers_join.image_id IS NULL")
-		case "galleries":
-			query.addWhere("galleries_join.image_id IS NULL")
-		case "tags":
-			query.addWhere("tags_join.image_id IS NULL")
-		default:
-			query.addWhere("(images." + *isMissingFilter + " IS NULL OR TRIM(images." + *isMissingFilter + ") = '')")
-		}
+	switch {
+	case filter.And != nil:
+		return qb.validateFilter(filter.And)
+	case filter.Or != nil:
+		return qb.validateFilter(filter.Or)
+	case filter.Not != nil:
+		return qb.validateFilter(filter.Not)
 	}
 
-	if tagsFilter := imageFilter.Tags; tagsFilter != nil && len(tagsFilter.Value) > 0 {
-		for _, tagID := range tagsFilter.Value {
-			query.addArg(tagID)
-		}
+	return nil
+}
 
-		query.body += " LEFT JOIN tags on tags_join.tag_id = tags.id"
-		whereClause, havingClause := getMultiCriterionClause("images", "tags", "images_tags", "image_id", "tag_id", tagsFilter)
-		query.addWhere(whereClause)
-		query.addHaving(havingClause)
+func (qb *imageQueryBuilder) makeFilter(filter *models.ImageFilterType) *filterBuilder {
+	query := &filterBuilder{}
+
+	if filter.And != nil {
+		query.and(qb.makeFilter(filter.And))
+	}
+	if filter.Or != nil {
+		query.or(qb.makeFilter(filter.Or))
+	}
+	if filter.Not != nil {
+		query.not(qb.makeFilter(filter.Not))
 	}
 
-	if galleriesFilter := imageFilter.Galleries; galleriesFilter != nil && len(galleriesFilter.Value) > 0 {
-		for _, galleryID := range galleriesFilter.Value {
-			query.addArg(galleryID)
-		}
+	query.handleCriterionFunc(stringCriterionHandler(filter.Path, "images.path"))
+	query.handleCriterionFunc(intCriterionHandler(filter.Rating, "images.rating"))
+	query.handleCriterionFunc(intCriterionHandler(filter.OCounter, "images.o_counter"))
+	query.handleCriterionFunc(boolCriterionHandler(filter.Organized, "images.organized"))
+	query.handleCriterionFunc(resolutionCriterionHandler(filter.Resolution, "images.height", "images.width"))
+	query.handleCriterionFunc(imageIsMissingCriterionHandler(qb, filter.IsMissing))
 
-		query.body += " LEFT JOIN galleries ON galleries_join.gallery_id = galleries.id"
-		whereClause, havingClause := getMultiCriterionClause("images", "galleries", "galleries_images", "image_id", "gallery_id", galleriesFilter)
-		query.addWhere(whereClause)
-		query.addHaving(havingClause)
-	}
+	query.handleCriterionFunc(imageTagsCriterionHandler(qb, filter.Tags))
+	query.handleCriterionFunc(imageGalleriesCriterionHandler(qb, filter.Galleries))
+	query.handleCriterionFunc(imagePerformersCriterionHandler(qb, filter.Performers))
+	query.handleCriterionFunc(imageStudioCriterionHandler(qb, filter.Studios))
 
-	if performersFilter := imageFilter.Performers; performersFilter != nil && len(performersFilter.Value) > 0 {
-		for _, performerID := range performersFilter.Value {
-			query.addArg(performerID)
-		}
+	query.handleCriterionFunc(int64CriterionHandler(filter.FileSize, "images.size"))
+	query.handleCriterionFunc(countCriterionHandler(filter.PerformerCount, imageTable, performersImagesTable, imageIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(filter.TagCount, imageTable, imagesTagsTable, imageIDColumn))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.CreatedAt, "images.created_at"))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.UpdatedAt, "images.updated_at"))
+	query.handleCriterionFunc(customFieldsCriterionHandler(filter.CustomFields, imageTable, imageCustomFieldsTable, imageIDColumn))
+
+	return query
+}
 
-		query.body += " LEFT JOIN performers ON performers_join.performer_id = performers.id"
-		whereClause, havingClause := getMultiCriterionClause("images", "performers", "performers_images", "image_id", "performer_id", performersFilter)
-		query.addWhere(whereClause)
-		query.addHaving(havingClause)
+func (qb *imageQueryBuilder) Query(imageFilter *models.ImageFilterType, findFilter *models.FindFilterType) ([]*models.Image, int, error) {
+	if imageFilter == nil {
+		imageFilter = &models.ImageFilterType{}
 	}
+	if findFilter == nil {
+		findFilter = &models.FindFilterType{}
+	}
+
+	query := qb.newQuery()
+
+	query.body = selectDistinctIDs(imageTable)
 
-	if studiosFilter := imageFilter.Studios; studiosFilter != nil && len(studiosFilter.Value) > 0 {
-		for _, studioID := range studiosFilter.Value {
-			query.addArg(studioID)
+	if q := findFilter.Q; q != nil && *q != "" {
+		if searchQuery := parseFTSQuery(*q); !searchQuery.empty() {
+			clause, thisArgs := getFTSSearchClause("images_fts", "images.id", searchQuery)
+			query.addWhere(clause)
+			query.addArg(thisArgs...)
 		}
+	}
 
-		whereClause, havingClause := getMultiCriterionClause("images", "studio", "", "", "studio_id", studiosFilter)
-		query.addWhere(whereClause)
-		query.addHaving(havingClause)
+	if err := qb.validateFilter(imageFilter); err != nil {
+		return nil, 0, err
 	}
+	filter := qb.makeFilter(imageFilter)
 
-	query.sortAndPagination = qb.getImageSort(findFilter) + getPagination(findFilter)
+	query.addFilter(filter)
+
+	query.sortAndPagination = qb.getImageSort(imageFilter, findFilter) + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
@@ -379,12 +373,118 @@ func (qb *imageQueryBuilder) Query(imageFilter *models.ImageFilterType, findFilt
 	return images, countResult, nil
 }
 
-func (qb *imageQueryBuilder) getImageSort(findFilter *models.FindFilterType) string {
+func imageIsMissingCriterionHandler(qb *imageQueryBuilder, isMissing *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if isMissing != nil && *isMissing != "" {
+			switch *isMissing {
+			case "studio":
+				f.addWhere("images.studio_id IS NULL")
+			case "performers":
+				qb.performersRepository().join(f, "performers_join", "images.id")
+				f.addWhere("performers_join.image_id IS NULL")
+			case "galleries":
+				qb.galleriesRepository().join(f, "galleries_join", "images.id")
+				f.addWhere("galleries_join.image_id IS NULL")
+			case "tags":
+				qb.tagsRepository().join(f, "tags_join", "images.id")
+				f.addWhere("tags_join.image_id IS NULL")
+			default:
+				f.addWhere("(images." + *isMissing + " IS NULL OR TRIM(images." + *isMissing + ") = '')")
+			}
+		}
+	}
+}
+
+func (qb *imageQueryBuilder) getMultiCriterionHandlerBuilder(foreignTable, joinTable, foreignFK string, addJoinsFunc func(f *filterBuilder)) multiCriterionHandlerBuilder {
+	return multiCriterionHandlerBuilder{
+		primaryTable: imageTable,
+		foreignTable: foreignTable,
+		joinTable:    joinTable,
+		primaryFK:    imageIDColumn,
+		foreignFK:    foreignFK,
+		addJoinsFunc: addJoinsFunc,
+	}
+}
+
+func imageTagsCriterionHandler(qb *imageQueryBuilder, tags *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		qb.tagsRepository().join(f, "tags_join", "images.id")
+		f.addJoin(tagTable, "", "tags_join.tag_id = tags.id")
+	}
+	h := qb.getMultiCriterionHandlerBuilder(tagTable, imagesTagsTable, tagIDColumn, addJoinsFunc)
+
+	return h.handler(tags)
+}
+
+func imageGalleriesCriterionHandler(qb *imageQueryBuilder, galleries *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		qb.galleriesRepository().join(f, "galleries_join", "images.id")
+		f.addJoin(galleryTable, "", "galleries_join.gallery_id = galleries.id")
+	}
+	h := qb.getMultiCriterionHandlerBuilder(galleryTable, galleriesImagesTable, galleryIDColumn, addJoinsFunc)
+
+	return h.handler(galleries)
+}
+
+func imagePerformersCriterionHandler(qb *imageQueryBuilder, performers *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		qb.performersRepository().join(f, "performers_join", "images.id")
+		f.addJoin(performerTable, "", "performers_join.performer_id = performers.id")
+	}
+	h := qb.getMultiCriterionHandlerBuilder(performerTable, performersImagesTable, performerIDColumn, addJoinsFunc)
+
+	return h.handler(performers)
+}
+
+func imageStudioCriterionHandler(qb *imageQueryBuilder, studios *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		f.addJoin(studioTable, "studio", "studio.id = images.studio_id")
+	}
+	h := qb.getMultiCriterionHandlerBuilder("studio", "", studioIDColumn, addJoinsFunc)
+
+	return h.handler(studios)
+}
+
+// filterGalleryID returns the ID of the gallery that the galleries filter
+// includes, if it includes a single gallery.
+func filterGalleryID(imageFilter *models.ImageFilterType) (int, bool) {
+	if imageFilter == nil || imageFilter.Galleries == nil {
+		return 0, false
+	}
+
+	galleries := imageFilter.Galleries
+	if len(galleries.Value) != 1 || (galleries.Modifier != models.CriterionModifierIncludes && galleries.Modifier != models.CriterionModifierIncludesAll) {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(galleries.Value[0])
+	return id, err == nil
+}
+
+func (qb *imageQueryBuilder) getImageSort(imageFilter *models.ImageFilterType, findFilter *models.FindFilterType) string {
 	if findFilter == nil {
 		return " ORDER BY images.path ASC "
 	}
 	sort := findFilter.GetSort("title")
 	direction := findFilter.GetDirection()
+	if sort == relevanceSort {
+		if ret := getFTSRelevanceSort("images_fts", "images.id", findFilter.Q, direction); ret != "" {
+			return ret
+		}
+		sort = "title"
+	}
+	if sort == imagePositionSort {
+		// images are ordered by position if the filter includes a single
+		// gallery, with images without a position last, as in
+		// FindByGalleryID
+		galleryID, ok := filterGalleryID(imageFilter)
+		if !ok {
+			return getSort("path", direction, "images")
+		}
+
+		position := fmt.Sprintf("(SELECT position FROM %s WHERE %s.image_id = images.id AND %s.gallery_id = %d)", galleriesImagesTable, galleriesImagesTable, galleriesImagesTable, galleryID)
+		return fmt.Sprintf(" ORDER BY %[1]s IS NULL, %[1]s %[2]s, images.path %[2]s ", position, direction)
+	}
 	return getSort(sort, direction, "images")
 }
 
@@ -464,3 +564,60 @@ func (qb *imageQueryBuilder) UpdateTags(imageID int, tagIDs []int) error {
 	// Delete the existing joins and then create new ones
 	return qb.tagsRepository().replace(imageID, tagIDs)
 }
+
+func (qb *imageQueryBuilder) customFieldsRepository() *customFieldsRepository {
+	return &customFieldsRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: imageCustomFieldsTable,
+			idColumn:  imageIDColumn,
+		},
+	}
+}
+
+func (qb *imageQueryBuilder) GetCustomFields(imageID int) (models.CustomFieldMap, error) {
+	return qb.customFieldsRepository().get(imageID)
+}
+
+func (qb *imageQueryBuilder) UpdateCustomFields(imageID int, fields models.CustomFieldMap) error {
+	return qb.customFieldsRepository().replace(imageID, fields)
+}
+
+func (qb *imageQueryBuilder) keywordsRepository() *stringListRepository {
+	return &stringListRepository{
+		repository: repository{
+			tx:        qb.tx,
+			tableName: imagesKeywordsTable,
+			idColumn:  imageIDColumn,
+		},
+		stringColumn: "keyword",
+	}
+}
+
+func (qb *imageQueryBuilder) GetKeywords(imageID int) ([]string, error) {
+	return qb.keywordsRepository().get(imageID)
+}
+
+func (qb *imageQueryBuilder) UpdateKeywords(imageID int, keywords []string) error {
+	// Delete the existing keywords and then create new ones
+	return qb.keywordsRepository().replace(imageID, keywords)
+}
+
+func (qb *imageQueryBuilder) mergedChecksumsRepository() *stringListRepository {
+	return &stringListRepository{
+		repository: repository{
+			tx:        qb.tx,
+			tableName: imagesMergedChecksumsTable,
+			idColumn:  imageIDColumn,
+		},
+		stringColumn: "checksum",
+	}
+}
+
+func (qb *imageQueryBuilder) GetMergedChecksums(imageID int) ([]string, error) {
+	return qb.mergedChecksumsRepository().get(imageID)
+}
+
+func (qb *imageQueryBuilder) UpdateMergedChecksums(imageID int, checksums []string) error {
+	return qb.mergedChecksumsRepository().replace(imageID, checksums)
+}
@@ -4,12 +4,14 @@ package sqlite_test
 
 import (
 	"database/sql"
+	"fmt"
 	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 func TestImageFind(t *testing.T) {
@@ -149,6 +151,82 @@ func verifyImagePath(t *testing.T, pathCriterion models.StringCriterionInput, ex
 	})
 }
 
+func TestImageQueryPathOr(t *testing.T) {
+	const image1Idx = 1
+	const image2Idx = 2
+
+	image1Path := getImageStringValue(image1Idx, "Path")
+	image2Path := getImageStringValue(image2Idx, "Path")
+
+	imageFilter := models.ImageFilterType{
+		Path: &models.StringCriterionInput{
+			Value:    image1Path,
+			Modifier: models.CriterionModifierEquals,
+		},
+		Or: &models.ImageFilterType{
+			Path: &models.StringCriterionInput{
+				Value:    image2Path,
+				Modifier: models.CriterionModifierEquals,
+			},
+		},
+	}
+
+	withTxn(func(r models.Repository) error {
+		sqb := r.Image()
+
+		images, _, err := sqb.Query(&imageFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying image: %s", err.Error())
+		}
+
+		assert.Len(t, images, 2)
+		var paths []string
+		for _, image := range images {
+			paths = append(paths, image.Path)
+		}
+		assert.Contains(t, paths, image1Path)
+		assert.Contains(t, paths, image2Path)
+
+		return nil
+	})
+}
+
+func TestImageQueryPathNotTags(t *testing.T) {
+	imagePath := getImageStringValue(imageIdxWithTag, "Path")
+
+	imageFilter := models.ImageFilterType{
+		Path: &models.StringCriterionInput{
+			Value:    imagePath,
+			Modifier: models.CriterionModifierEquals,
+		},
+	}
+
+	withTxn(func(r models.Repository) error {
+		sqb := r.Image()
+
+		images, _, err := sqb.Query(&imageFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying image: %s", err.Error())
+		}
+		assert.Len(t, images, 1)
+
+		imageFilter.Not = &models.ImageFilterType{
+			Tags: &models.MultiCriterionInput{
+				Value:    []string{strconv.Itoa(tagIDs[tagIdxWithImage])},
+				Modifier: models.CriterionModifierIncludes,
+			},
+		}
+
+		images, _, err = sqb.Query(&imageFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying image: %s", err.Error())
+		}
+		assert.Len(t, images, 0)
+
+		return nil
+	})
+}
+
 func TestImageQueryRating(t *testing.T) {
 	const rating = 3
 	ratingCriterion := models.IntCriterionInput{
@@ -701,6 +779,142 @@ func TestImageQueryPagination(t *testing.T) {
 	})
 }
 
+func TestImageUpdateKeywords(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Image()
+		imageID := imageIDs[imageIdxWithGallery]
+
+		keywords, err := qb.GetKeywords(imageID)
+		if err != nil {
+			return fmt.Errorf("Error getting keywords: %s", err.Error())
+		}
+		assert.Len(t, keywords, 0)
+
+		if err := qb.UpdateKeywords(imageID, []string{"sunset", "beach"}); err != nil {
+			return fmt.Errorf("Error updating keywords: %s", err.Error())
+		}
+
+		keywords, err = qb.GetKeywords(imageID)
+		if err != nil {
+			return fmt.Errorf("Error getting keywords: %s", err.Error())
+		}
+		assert.Equal(t, []string{"beach", "sunset"}, keywords)
+
+		if err := qb.UpdateKeywords(imageID, nil); err != nil {
+			return fmt.Errorf("Error updating keywords: %s", err.Error())
+		}
+
+		keywords, err = qb.GetKeywords(imageID)
+		if err != nil {
+			return fmt.Errorf("Error getting keywords: %s", err.Error())
+		}
+		assert.Len(t, keywords, 0)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestImageMergedChecksums(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Image()
+
+		const checksum = "TestImageMergedChecksums"
+		imageID := imageIDs[imageIdxWithGallery]
+		if err := qb.UpdateMergedChecksums(imageID, []string{checksum}); err != nil {
+			return fmt.Errorf("Error updating merged checksums: %s", err.Error())
+		}
+
+		checksums, err := qb.GetMergedChecksums(imageID)
+		if err != nil {
+			return fmt.Errorf("Error getting merged checksums: %s", err.Error())
+		}
+		assert.Equal(t, []string{checksum}, checksums)
+
+		image, err := qb.FindByMergedChecksum(checksum)
+		if err != nil {
+			return fmt.Errorf("Error finding image by merged checksum: %s", err.Error())
+		}
+		if assert.NotNil(t, image) {
+			assert.Equal(t, imageID, image.ID)
+		}
+
+		if err := qb.UpdateMergedChecksums(imageID, nil); err != nil {
+			return fmt.Errorf("Error resetting merged checksums: %s", err.Error())
+		}
+
+		image, err = qb.FindByMergedChecksum(checksum)
+		if err != nil {
+			return fmt.Errorf("Error finding image by merged checksum: %s", err.Error())
+		}
+		assert.Nil(t, image)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestImageFindDuplicates(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Image()
+
+		setPhash := func(idx int, phash sql.NullInt64) error {
+			_, err := qb.Update(models.ImagePartial{
+				ID:    imageIDs[idx],
+				Phash: &phash,
+			})
+			return err
+		}
+
+		phashes := map[int]int64{
+			imageIdxWithPerformer: 0x0f0f0f0f0f0f0f0f,
+			imageIdxWithTag:       0x0f0f0f0f0f0f0f0e,
+			imageIdxWithStudio:    0x7070707070707070,
+		}
+		for idx, phash := range phashes {
+			if err := setPhash(idx, sql.NullInt64{Int64: phash, Valid: true}); err != nil {
+				return fmt.Errorf("Error setting phash: %s", err.Error())
+			}
+		}
+
+		duplicates, err := qb.FindDuplicates(0)
+		if err != nil {
+			return fmt.Errorf("Error finding duplicates: %s", err.Error())
+		}
+		assert.Len(t, duplicates, 0)
+
+		duplicates, err = qb.FindDuplicates(1)
+		if err != nil {
+			return fmt.Errorf("Error finding duplicates: %s", err.Error())
+		}
+		assert.Len(t, duplicates, 1)
+		if len(duplicates) == 1 {
+			var ids []int
+			for _, i := range duplicates[0] {
+				ids = append(ids, i.ID)
+			}
+			assert.ElementsMatch(t, []int{imageIDs[imageIdxWithPerformer], imageIDs[imageIdxWithTag]}, ids)
+		}
+
+		_, err = qb.FindDuplicates(-1)
+		assert.NotNil(t, err)
+		_, err = qb.FindDuplicates(utils.MaxHammingDistance + 1)
+		assert.NotNil(t, err)
+
+		for idx := range phashes {
+			if err := setPhash(idx, sql.NullInt64{}); err != nil {
+				return fmt.Errorf("Error resetting phash: %s", err.Error())
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 // TODO Update
 // TODO IncrementOCounter
 // TODO DecrementOCounter
@@ -8,6 +8,7 @@ import (
 )
 
 const movieTable = "movies"
+const movieCustomFieldsTable = "movie_custom_fields"
 
 type movieQueryBuilder struct {
 	repository
@@ -118,6 +119,48 @@ func (qb *movieQueryBuilder) AllSlim() ([]*models.Movie, error) {
 	return qb.queryMovies("SELECT movies.id, movies.name FROM movies "+qb.getMovieSort(nil), nil)
 }
 
+func (qb *movieQueryBuilder) validateFilter(filter *models.MovieFilterType) error {
+	if err := validateFilterCombination(filter.And != nil, filter.Or != nil, filter.Not != nil); err != nil {
+		return err
+	}
+
+	switch {
+	case filter.And != nil:
+		return qb.validateFilter(filter.And)
+	case filter.Or != nil:
+		return qb.validateFilter(filter.Or)
+	case filter.Not != nil:
+		return qb.validateFilter(filter.Not)
+	}
+
+	return nil
+}
+
+func (qb *movieQueryBuilder) makeFilter(filter *models.MovieFilterType) *filterBuilder {
+	query := &filterBuilder{}
+
+	if filter.And != nil {
+		query.and(qb.makeFilter(filter.And))
+	}
+	if filter.Or != nil {
+		query.or(qb.makeFilter(filter.Or))
+	}
+	if filter.Not != nil {
+		query.not(qb.makeFilter(filter.Not))
+	}
+
+	query.handleCriterionFunc(movieStudioCriterionHandler(filter.Studios))
+	query.handleCriterionFunc(movieIsMissingCriterionHandler(filter.IsMissing))
+
+	query.handleCriterionFunc(dateCriterionHandler(filter.Date, "movies.date"))
+	query.handleCriterionFunc(countCriterionHandler(filter.SceneCount, movieTable, moviesScenesTable, "movie_id"))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.CreatedAt, "movies.created_at"))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.UpdatedAt, "movies.updated_at"))
+	query.handleCriterionFunc(customFieldsCriterionHandler(filter.CustomFields, movieTable, movieCustomFieldsTable, "movie_id"))
+
+	return query
+}
+
 func (qb *movieQueryBuilder) Query(movieFilter *models.MovieFilterType, findFilter *models.FindFilterType) ([]*models.Movie, int, error) {
 	if findFilter == nil {
 		findFilter = &models.FindFilterType{}
@@ -126,54 +169,30 @@ func (qb *movieQueryBuilder) Query(movieFilter *models.MovieFilterType, findFilt
 		movieFilter = &models.MovieFilterType{}
 	}
 
-	var whereClauses []string
-	var havingClauses []string
-	var args []interface{}
-	body := selectDistinctIDs("movies")
-	body += `
-	left join movies_scenes as scenes_join on scenes_join.movie_id = movies.id
-	left join scenes on scenes_join.scene_id = scenes.id
-	left join studios as studio on studio.id = movies.studio_id
-`
+	query := qb.newQuery()
+
+	query.body = selectDistinctIDs(movieTable)
+
+	// required for sorting by scene count
+	query.join(moviesScenesTable, "scenes_join", "scenes_join.movie_id = movies.id")
+	query.join(sceneTable, "", "scenes_join.scene_id = scenes.id")
 
 	if q := findFilter.Q; q != nil && *q != "" {
 		searchColumns := []string{"movies.name"}
 		clause, thisArgs := getSearchBinding(searchColumns, *q, false)
-		whereClauses = append(whereClauses, clause)
-		args = append(args, thisArgs...)
+		query.addWhere(clause)
+		query.addArg(thisArgs...)
 	}
 
-	if studiosFilter := movieFilter.Studios; studiosFilter != nil && len(studiosFilter.Value) > 0 {
-		for _, studioID := range studiosFilter.Value {
-			args = append(args, studioID)
-		}
-
-		whereClause, havingClause := getMultiCriterionClause("movies", "studio", "", "", "studio_id", studiosFilter)
-		whereClauses = appendClause(whereClauses, whereClause)
-		havingClauses = appendClause(havingClauses, havingClause)
-	}
-
-	if isMissingFilter := movieFilter.IsMissing; isMissingFilter != nil && *isMissingFilter != "" {
-		switch *isMissingFilter {
-		case "front_image":
-			body += `left join movies_images on movies_images.movie_id = movies.id
-			`
-			whereClauses = appendClause(whereClauses, "movies_images.front_image IS NULL")
-		case "back_image":
-			body += `left join movies_images on movies_images.movie_id = movies.id
-			`
-			whereClauses = appendClause(whereClauses, "movies_images.back_image IS NULL")
-		case "scenes":
-			body += `left join movies_scenes on movies_scenes.movie_id = movies.id
-			`
-			whereClauses = appendClause(whereClauses, "movies_scenes.scene_id IS NULL")
-		default:
-			whereClauses = appendClause(whereClauses, "movies."+*isMissingFilter+" IS NULL")
-		}
+	if err := qb.validateFilter(movieFilter); err != nil {
+		return nil, 0, err
 	}
+	filter := qb.makeFilter(movieFilter)
 
-	sortAndPagination := qb.getMovieSort(findFilter) + getPagination(findFilter)
-	idsResult, countResult, err := qb.executeFindQuery(body, args, sortAndPagination, whereClauses, havingClauses)
+	query.addFilter(filter)
+
+	query.sortAndPagination = qb.getMovieSort(findFilter) + getPagination(findFilter)
+	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
 	}
@@ -191,6 +210,42 @@ func (qb *movieQueryBuilder) Query(movieFilter *models.MovieFilterType, findFilt
 	return movies, countResult, nil
 }
 
+func movieStudioCriterionHandler(studios *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		f.addJoin(studioTable, "studio", "studio.id = movies.studio_id")
+	}
+	h := multiCriterionHandlerBuilder{
+		primaryTable: movieTable,
+		foreignTable: "studio",
+		joinTable:    "",
+		primaryFK:    "movie_id",
+		foreignFK:    studioIDColumn,
+		addJoinsFunc: addJoinsFunc,
+	}
+
+	return h.handler(studios)
+}
+
+func movieIsMissingCriterionHandler(isMissing *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if isMissing != nil && *isMissing != "" {
+			switch *isMissing {
+			case "front_image":
+				f.addJoin("movies_images", "", "movies_images.movie_id = movies.id")
+				f.addWhere("movies_images.front_image_blob IS NULL")
+			case "back_image":
+				f.addJoin("movies_images", "", "movies_images.movie_id = movies.id")
+				f.addWhere("movies_images.back_image_blob IS NULL")
+			case "scenes":
+				f.addJoin(moviesScenesTable, "scenes_join", "scenes_join.movie_id = movies.id")
+				f.addWhere("scenes_join.scene_id IS NULL")
+			default:
+				f.addWhere("movies." + *isMissing + " IS NULL")
+			}
+		}
+	}
+}
+
 func (qb *movieQueryBuilder) getMovieSort(findFilter *models.FindFilterType) string {
 	var sort string
 	var direction string
@@ -227,37 +282,131 @@ func (qb *movieQueryBuilder) queryMovies(query string, args []interface{}) ([]*m
 	return []*models.Movie(ret), nil
 }
 
+func (qb *movieQueryBuilder) blobs() *blobStore {
+	return &blobStore{tx: qb.tx}
+}
+
+func (qb *movieQueryBuilder) getImageChecksums(movieID int) ([]string, error) {
+	query := `SELECT front_image_blob, COALESCE(back_image_blob, '') from movies_images WHERE movie_id = ?`
+	rows, err := qb.tx.Queryx(query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var front, back string
+	if rows.Next() {
+		if err := rows.Scan(&front, &back); err != nil {
+			return nil, err
+		}
+	}
+
+	return []string{front, back}, rows.Err()
+}
+
+// writeImage stores the image and returns its checksum, or NULL if the image
+// is nil.
+func (qb *movieQueryBuilder) writeImage(image []byte) (sql.NullString, error) {
+	if image == nil {
+		return sql.NullString{}, nil
+	}
+
+	checksum, err := qb.blobs().write(image)
+	return sql.NullString{String: checksum, Valid: err == nil}, err
+}
+
 func (qb *movieQueryBuilder) UpdateImages(movieID int, frontImage []byte, backImage []byte) error {
+	existing, err := qb.getImageChecksums(movieID)
+	if err != nil {
+		return err
+	}
+
+	front, err := qb.writeImage(frontImage)
+	if err != nil {
+		return err
+	}
+
+	back, err := qb.writeImage(backImage)
+	if err != nil {
+		return err
+	}
+
 	// Delete the existing cover and then create new
-	if err := qb.DestroyImages(movieID); err != nil {
+	if _, err := qb.tx.Exec("DELETE FROM movies_images WHERE movie_id = ?", movieID); err != nil {
 		return err
 	}
 
-	_, err := qb.tx.Exec(
-		`INSERT INTO movies_images (movie_id, front_image, back_image) VALUES (?, ?, ?)`,
+	if _, err := qb.tx.Exec(
+		`INSERT INTO movies_images (movie_id, front_image_blob, back_image_blob) VALUES (?, ?, ?)`,
 		movieID,
-		frontImage,
-		backImage,
-	)
+		front,
+		back,
+	); err != nil {
+		return err
+	}
 
-	return err
+	return qb.blobs().deleteUnreferenced(existing)
 }
 
 func (qb *movieQueryBuilder) DestroyImages(movieID int) error {
-	// Delete the existing joins
-	_, err := qb.tx.Exec("DELETE FROM movies_images WHERE movie_id = ?", movieID)
+	existing, err := qb.getImageChecksums(movieID)
 	if err != nil {
 		return err
 	}
-	return err
+
+	// Delete the existing joins
+	if _, err := qb.tx.Exec("DELETE FROM movies_images WHERE movie_id = ?", movieID); err != nil {
+		return err
+	}
+
+	return qb.blobs().deleteUnreferenced(existing)
+}
+
+func (qb *movieQueryBuilder) GetFrontImageChecksum(movieID int) (string, error) {
+	checksums, err := qb.getImageChecksums(movieID)
+	if err != nil {
+		return "", err
+	}
+	return checksums[0], nil
+}
+
+func (qb *movieQueryBuilder) GetBackImageChecksum(movieID int) (string, error) {
+	checksums, err := qb.getImageChecksums(movieID)
+	if err != nil {
+		return "", err
+	}
+	return checksums[1], nil
+}
+
+func (qb *movieQueryBuilder) readImage(checksum string, err error) ([]byte, error) {
+	if err != nil || checksum == "" {
+		return nil, err
+	}
+	return qb.blobs().read(checksum)
 }
 
 func (qb *movieQueryBuilder) GetFrontImage(movieID int) ([]byte, error) {
-	query := `SELECT front_image from movies_images WHERE movie_id = ?`
-	return getImage(qb.tx, query, movieID)
+	return qb.readImage(qb.GetFrontImageChecksum(movieID))
 }
 
 func (qb *movieQueryBuilder) GetBackImage(movieID int) ([]byte, error) {
-	query := `SELECT back_image from movies_images WHERE movie_id = ?`
-	return getImage(qb.tx, query, movieID)
+	return qb.readImage(qb.GetBackImageChecksum(movieID))
+}
+
+func (qb *movieQueryBuilder) customFieldsRepository() *customFieldsRepository {
+	return &customFieldsRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: movieCustomFieldsTable,
+			idColumn:  "movie_id",
+		},
+	}
+}
+
+func (qb *movieQueryBuilder) GetCustomFields(movieID int) (models.CustomFieldMap, error) {
+	return qb.customFieldsRepository().get(movieID)
+}
+
+func (qb *movieQueryBuilder) UpdateCustomFields(movieID int, fields models.CustomFieldMap) error {
+	return qb.customFieldsRepository().replace(movieID, fields)
 }
@@ -10,6 +10,7 @@ import (
 )
 
 const performerTable = "performers"
+const performerCustomFieldsTable = "performer_custom_fields"
 const performerIDColumn = "performer_id"
 
 type performerQueryBuilder struct {
@@ -165,6 +166,69 @@ func (qb *performerQueryBuilder) AllSlim() ([]*models.Performer, error) {
 	return qb.queryPerformers("SELECT performers.id, performers.name, performers.gender FROM performers "+qb.getPerformerSort(nil), nil)
 }
 
+func (qb *performerQueryBuilder) validateFilter(filter *models.PerformerFilterType) error {
+	if err := validateFilterCombination(filter.And != nil, filter.Or != nil, filter.Not != nil); err != nil {
+		return err
+	}
+
+	switch {
+	case filter.And != nil:
+		return qb.validateFilter(filter.And)
+	case filter.Or != nil:
+		return qb.validateFilter(filter.Or)
+	case filter.Not != nil:
+		return qb.validateFilter(filter.Not)
+	}
+
+	return nil
+}
+
+func (qb *performerQueryBuilder) makeFilter(filter *models.PerformerFilterType) *filterBuilder {
+	query := &filterBuilder{}
+
+	if filter.And != nil {
+		query.and(qb.makeFilter(filter.And))
+	}
+	if filter.Or != nil {
+		query.or(qb.makeFilter(filter.Or))
+	}
+	if filter.Not != nil {
+		query.not(qb.makeFilter(filter.Not))
+	}
+
+	const tableName = performerTable
+	query.handleCriterionFunc(boolCriterionHandler(filter.FilterFavorites, tableName+".favorite"))
+	query.handleCriterionFunc(performerBirthYearCriterionHandler(filter.BirthYear))
+	query.handleCriterionFunc(performerAgeCriterionHandler(filter.Age))
+	query.handleCriterionFunc(performerGenderCriterionHandler(filter.Gender))
+	query.handleCriterionFunc(performerIsMissingCriterionHandler(qb, filter.IsMissing))
+	query.handleCriterionFunc(performerStashIDsHandler(qb, filter.StashID))
+
+	query.handleCriterionFunc(stringCriterionHandler(filter.Ethnicity, tableName+".ethnicity"))
+	query.handleCriterionFunc(stringCriterionHandler(filter.Country, tableName+".country"))
+	query.handleCriterionFunc(stringCriterionHandler(filter.EyeColor, tableName+".eye_color"))
+	query.handleCriterionFunc(stringCriterionHandler(filter.Height, tableName+".height"))
+	query.handleCriterionFunc(stringCriterionHandler(filter.Measurements, tableName+".measurements"))
+	query.handleCriterionFunc(stringCriterionHandler(filter.FakeTits, tableName+".fake_tits"))
+	query.handleCriterionFunc(stringCriterionHandler(filter.CareerLength, tableName+".career_length"))
+	query.handleCriterionFunc(stringCriterionHandler(filter.Tattoos, tableName+".tattoos"))
+	query.handleCriterionFunc(stringCriterionHandler(filter.Piercings, tableName+".piercings"))
+
+	// TODO - need better handling of aliases
+	query.handleCriterionFunc(stringCriterionHandler(filter.Aliases, tableName+".aliases"))
+
+	query.handleCriterionFunc(dateCriterionHandler(filter.Birthdate, tableName+".birthdate"))
+	query.handleCriterionFunc(countCriterionHandler(filter.SceneCount, tableName, performersScenesTable, performerIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(filter.ImageCount, tableName, performersImagesTable, performerIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(filter.GalleryCount, tableName, performersGalleriesTable, performerIDColumn))
+	query.handleCriterionFunc(performerSceneTagsCriterionHandler(filter.Tags))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.CreatedAt, tableName+".created_at"))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.UpdatedAt, tableName+".updated_at"))
+	query.handleCriterionFunc(customFieldsCriterionHandler(filter.CustomFields, tableName, performerCustomFieldsTable, performerIDColumn))
+
+	return query
+}
+
 func (qb *performerQueryBuilder) Query(performerFilter *models.PerformerFilterType, findFilter *models.FindFilterType) ([]*models.Performer, int, error) {
 	if performerFilter == nil {
 		performerFilter = &models.PerformerFilterType{}
@@ -173,82 +237,28 @@ func (qb *performerQueryBuilder) Query(performerFilter *models.PerformerFilterTy
 		findFilter = &models.FindFilterType{}
 	}
 
-	tableName := "performers"
 	query := qb.newQuery()
 
-	query.body = selectDistinctIDs(tableName)
-	query.body += `
-		left join performers_scenes as scenes_join on scenes_join.performer_id = performers.id
-		left join scenes on scenes_join.scene_id = scenes.id
-		left join performer_stash_ids on performer_stash_ids.performer_id = performers.id
-	`
-
-	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"performers.name", "performers.checksum", "performers.birthdate", "performers.ethnicity"}
-		clause, thisArgs := getSearchBinding(searchColumns, *q, false)
-		query.addWhere(clause)
-		query.addArg(thisArgs...)
-	}
-
-	if favoritesFilter := performerFilter.FilterFavorites; favoritesFilter != nil {
-		var favStr string
-		if *favoritesFilter == true {
-			favStr = "1"
-		} else {
-			favStr = "0"
-		}
-		query.addWhere("performers.favorite = " + favStr)
-	}
-
-	if birthYear := performerFilter.BirthYear; birthYear != nil {
-		clauses, thisArgs := getBirthYearFilterClause(birthYear.Modifier, birthYear.Value)
-		query.addWhere(clauses...)
-		query.addArg(thisArgs...)
-	}
-
-	if age := performerFilter.Age; age != nil {
-		clauses, thisArgs := getAgeFilterClause(age.Modifier, age.Value)
-		query.addWhere(clauses...)
-		query.addArg(thisArgs...)
-	}
+	query.body = selectDistinctIDs(performerTable)
 
-	if gender := performerFilter.Gender; gender != nil {
-		query.addWhere("performers.gender = ?")
-		query.addArg(gender.Value.String())
-	}
+	// required for sorting by scene count
+	query.join(performersScenesTable, "scenes_join", "scenes_join.performer_id = performers.id")
+	query.join(sceneTable, "", "scenes_join.scene_id = scenes.id")
 
-	if isMissingFilter := performerFilter.IsMissing; isMissingFilter != nil && *isMissingFilter != "" {
-		switch *isMissingFilter {
-		case "scenes":
-			query.addWhere("scenes_join.scene_id IS NULL")
-		case "image":
-			query.body += `left join performers_image on performers_image.performer_id = performers.id
-			`
-			query.addWhere("performers_image.performer_id IS NULL")
-		case "stash_id":
-			query.addWhere("performer_stash_ids.performer_id IS NULL")
-		default:
-			query.addWhere("(performers." + *isMissingFilter + " IS NULL OR TRIM(performers." + *isMissingFilter + ") = '')")
+	if q := findFilter.Q; q != nil && *q != "" {
+		if searchQuery := parseFTSQuery(*q); !searchQuery.empty() {
+			clause, thisArgs := getFTSSearchClause("performers_fts", "performers.id", searchQuery)
+			query.addWhere(clause)
+			query.addArg(thisArgs...)
 		}
 	}
 
-	if stashIDFilter := performerFilter.StashID; stashIDFilter != nil {
-		query.addWhere("performer_stash_ids.stash_id = ?")
-		query.addArg(stashIDFilter)
+	if err := qb.validateFilter(performerFilter); err != nil {
+		return nil, 0, err
 	}
+	filter := qb.makeFilter(performerFilter)
 
-	query.handleStringCriterionInput(performerFilter.Ethnicity, tableName+".ethnicity")
-	query.handleStringCriterionInput(performerFilter.Country, tableName+".country")
-	query.handleStringCriterionInput(performerFilter.EyeColor, tableName+".eye_color")
-	query.handleStringCriterionInput(performerFilter.Height, tableName+".height")
-	query.handleStringCriterionInput(performerFilter.Measurements, tableName+".measurements")
-	query.handleStringCriterionInput(performerFilter.FakeTits, tableName+".fake_tits")
-	query.handleStringCriterionInput(performerFilter.CareerLength, tableName+".career_length")
-	query.handleStringCriterionInput(performerFilter.Tattoos, tableName+".tattoos")
-	query.handleStringCriterionInput(performerFilter.Piercings, tableName+".piercings")
-
-	// TODO - need better handling of aliases
-	query.handleStringCriterionInput(performerFilter.Aliases, tableName+".aliases")
+	query.addFilter(filter)
 
 	query.sortAndPagination = qb.getPerformerSort(findFilter) + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
@@ -268,8 +278,90 @@ func (qb *performerQueryBuilder) Query(performerFilter *models.PerformerFilterTy
 	return performers, countResult, nil
 }
 
-func getBirthYearFilterClause(criterionModifier models.CriterionModifier, value int) ([]string, []interface{}) {
-	var clauses []string
+func performerBirthYearCriterionHandler(birthYear *models.IntCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if birthYear != nil {
+			clause, args := getBirthYearFilterClause(birthYear.Modifier, birthYear.Value)
+			f.addWhere(clause, args...)
+		}
+	}
+}
+
+func performerAgeCriterionHandler(age *models.IntCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if age != nil {
+			clause, args := getAgeFilterClause(age.Modifier, age.Value)
+			f.addWhere(clause, args...)
+		}
+	}
+}
+
+func performerGenderCriterionHandler(gender *models.GenderCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if gender != nil && gender.Value != nil {
+			f.addWhere("performers.gender = ?", gender.Value.String())
+		}
+	}
+}
+
+func performerIsMissingCriterionHandler(qb *performerQueryBuilder, isMissing *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if isMissing != nil && *isMissing != "" {
+			switch *isMissing {
+			case "scenes":
+				f.addJoin(performersScenesTable, "scenes_join", "scenes_join.performer_id = performers.id")
+				f.addWhere("scenes_join.scene_id IS NULL")
+			case "image":
+				f.addJoin("performers_image", "", "performers_image.performer_id = performers.id")
+				f.addWhere("performers_image.performer_id IS NULL")
+			case "stash_id":
+				qb.stashIDRepository().join(f, "performer_stash_ids", "performers.id")
+				f.addWhere("performer_stash_ids.performer_id IS NULL")
+			default:
+				f.addWhere("(performers." + *isMissing + " IS NULL OR TRIM(performers." + *isMissing + ") = '')")
+			}
+		}
+	}
+}
+
+func performerStashIDsHandler(qb *performerQueryBuilder, stashID *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if stashID != nil && *stashID != "" {
+			qb.stashIDRepository().join(f, "performer_stash_ids", "performers.id")
+			stringLiteralCriterionHandler(stashID, "performer_stash_ids.stash_id")(f)
+		}
+	}
+}
+
+// performerSceneTagsCriterionHandler filters performers by the tags of the
+// scenes they appear in.
+func performerSceneTagsCriterionHandler(tags *models.MultiCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if tags == nil || len(tags.Value) == 0 {
+			return
+		}
+
+		var args []interface{}
+		for _, tagID := range tags.Value {
+			args = append(args, tagID)
+		}
+
+		subQuery := "SELECT performers_scenes.performer_id FROM performers_scenes JOIN scenes_tags ON scenes_tags.scene_id = performers_scenes.scene_id WHERE scenes_tags.tag_id IN " + getInBinding(len(tags.Value))
+
+		switch tags.Modifier {
+		case models.CriterionModifierIncludes:
+			f.addWhere("performers.id IN ("+subQuery+")", args...)
+		case models.CriterionModifierIncludesAll:
+			subQuery += " GROUP BY performers_scenes.performer_id HAVING COUNT(DISTINCT scenes_tags.tag_id) = " + strconv.Itoa(len(tags.Value))
+			f.addWhere("performers.id IN ("+subQuery+")", args...)
+		case models.CriterionModifierExcludes:
+			f.addWhere("performers.id NOT IN ("+subQuery+")", args...)
+		}
+	}
+}
+
+func getBirthYearFilterClause(criterionModifier models.CriterionModifier, value int) (string, []interface{}) {
+	var clause string
 	var args []interface{}
 
 	yearStr := strconv.Itoa(value)
@@ -280,31 +372,30 @@ func getBirthYearFilterClause(criterionModifier models.CriterionModifier, value
 		switch modifier {
 		case "EQUALS":
 			// between yyyy-01-01 and yyyy-12-31
-			clauses = append(clauses, "performers.birthdate >= ?")
-			clauses = append(clauses, "performers.birthdate <= ?")
+			clause = "(performers.birthdate >= ? AND performers.birthdate <= ?)"
 			args = append(args, startOfYear)
 			args = append(args, endOfYear)
 		case "NOT_EQUALS":
 			// outside of yyyy-01-01 to yyyy-12-31
-			clauses = append(clauses, "performers.birthdate < ? OR performers.birthdate > ?")
+			clause = "(performers.birthdate < ? OR performers.birthdate > ?)"
 			args = append(args, startOfYear)
 			args = append(args, endOfYear)
 		case "GREATER_THAN":
 			// > yyyy-12-31
-			clauses = append(clauses, "performers.birthdate > ?")
+			clause = "performers.birthdate > ?"
 			args = append(args, endOfYear)
 		case "LESS_THAN":
 			// < yyyy-01-01
-			clauses = append(clauses, "performers.birthdate < ?")
+			clause = "performers.birthdate < ?"
 			args = append(args, startOfYear)
 		}
 	}
 
-	return clauses, args
+	return clause, args
 }
 
-func getAgeFilterClause(criterionModifier models.CriterionModifier, value int) ([]string, []interface{}) {
-	var clauses []string
+func getAgeFilterClause(criterionModifier models.CriterionModifier, value int) (string, []interface{}) {
+	var clause string
 	var args []interface{}
 
 	// get the date at which performer would turn the age specified
@@ -316,27 +407,26 @@ func getAgeFilterClause(criterionModifier models.CriterionModifier, value int) (
 		switch modifier {
 		case "EQUALS":
 			// between birthDate and yearAfter
-			clauses = append(clauses, "performers.birthdate >= ?")
-			clauses = append(clauses, "performers.birthdate < ?")
+			clause = "(performers.birthdate >= ? AND performers.birthdate < ?)"
 			args = append(args, birthDate)
 			args = append(args, yearAfter)
 		case "NOT_EQUALS":
 			// outside of birthDate and yearAfter
-			clauses = append(clauses, "performers.birthdate < ? OR performers.birthdate >= ?")
+			clause = "(performers.birthdate < ? OR performers.birthdate >= ?)"
 			args = append(args, birthDate)
 			args = append(args, yearAfter)
 		case "GREATER_THAN":
 			// < birthDate
-			clauses = append(clauses, "performers.birthdate < ?")
+			clause = "performers.birthdate < ?"
 			args = append(args, birthDate)
 		case "LESS_THAN":
 			// > yearAfter
-			clauses = append(clauses, "performers.birthdate >= ?")
+			clause = "performers.birthdate >= ?"
 			args = append(args, yearAfter)
 		}
 	}
 
-	return clauses, args
+	return clause, args
 }
 
 func (qb *performerQueryBuilder) getPerformerSort(findFilter *models.FindFilterType) string {
@@ -348,6 +438,13 @@ func (qb *performerQueryBuilder) getPerformerSort(findFilter *models.FindFilterT
 	} else {
 		sort = findFilter.GetSort("name")
 		direction = findFilter.GetDirection()
+
+		if sort == relevanceSort {
+			if ret := getFTSRelevanceSort("performers_fts", "performers.id", findFilter.Q, direction); ret != "" {
+				return ret
+			}
+			sort = "name"
+		}
 	}
 	return getSort(sort, direction, "performers")
 }
@@ -361,27 +458,59 @@ func (qb *performerQueryBuilder) queryPerformers(query string, args []interface{
 	return []*models.Performer(ret), nil
 }
 
-func (qb *performerQueryBuilder) imageRepository() *imageRepository {
-	return &imageRepository{
+func (qb *performerQueryBuilder) imageRepository() *orderedImageRepository {
+	return &orderedImageRepository{
 		repository: repository{
 			tx:        qb.tx,
 			tableName: "performers_image",
 			idColumn:  performerIDColumn,
 		},
-		imageColumn: "image",
+		blobColumn:     "image_blob",
+		positionColumn: "position",
 	}
 }
 
 func (qb *performerQueryBuilder) GetImage(performerID int) ([]byte, error) {
-	return qb.imageRepository().get(performerID)
+	return qb.imageRepository().get(performerID, 0)
+}
+
+func (qb *performerQueryBuilder) GetImageByIndex(performerID int, index int) ([]byte, error) {
+	return qb.imageRepository().get(performerID, index)
+}
+
+func (qb *performerQueryBuilder) GetImageChecksumByIndex(performerID int, index int) (string, error) {
+	return qb.imageRepository().getChecksum(performerID, index)
+}
+
+func (qb *performerQueryBuilder) GetImages(performerID int) ([][]byte, error) {
+	return qb.imageRepository().getImages(performerID)
+}
+
+func (qb *performerQueryBuilder) GetImageCount(performerID int) (int, error) {
+	return qb.imageRepository().count(performerID)
 }
 
 func (qb *performerQueryBuilder) UpdateImage(performerID int, image []byte) error {
-	return qb.imageRepository().replace(performerID, image)
+	return qb.imageRepository().set(performerID, 0, image)
 }
 
+func (qb *performerQueryBuilder) UpdateImages(performerID int, images [][]byte) error {
+	return qb.imageRepository().replace(performerID, images)
+}
+
+// DestroyImage removes the primary image of the performer. The next image,
+// if present, becomes the primary image.
 func (qb *performerQueryBuilder) DestroyImage(performerID int) error {
-	return qb.imageRepository().destroy([]int{performerID})
+	images, err := qb.GetImages(performerID)
+	if err != nil {
+		return err
+	}
+
+	if len(images) > 0 {
+		images = images[1:]
+	}
+
+	return qb.UpdateImages(performerID, images)
 }
 
 func (qb *performerQueryBuilder) stashIDRepository() *stashIDRepository {
@@ -401,3 +530,21 @@ func (qb *performerQueryBuilder) GetStashIDs(performerID int) ([]*models.StashID
 func (qb *performerQueryBuilder) UpdateStashIDs(performerID int, stashIDs []models.StashID) error {
 	return qb.stashIDRepository().replace(performerID, stashIDs)
 }
+
+func (qb *performerQueryBuilder) customFieldsRepository() *customFieldsRepository {
+	return &customFieldsRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: performerCustomFieldsTable,
+			idColumn:  performerIDColumn,
+		},
+	}
+}
+
+func (qb *performerQueryBuilder) GetCustomFields(performerID int) (models.CustomFieldMap, error) {
+	return qb.customFieldsRepository().get(performerID)
+}
+
+func (qb *performerQueryBuilder) UpdateCustomFields(performerID int, fields models.CustomFieldMap) error {
+	return qb.customFieldsRepository().replace(performerID, fields)
+}
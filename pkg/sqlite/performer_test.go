@@ -5,6 +5,7 @@ package sqlite_test
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -180,6 +181,81 @@ func TestPerformerDestroyPerformerImage(t *testing.T) {
 	}
 }
 
+func TestPerformerUpdatePerformerImages(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Performer()
+
+		// create performer to test against
+		const name = "TestPerformerUpdatePerformerImages"
+		performer := models.Performer{
+			Name:     sql.NullString{String: name, Valid: true},
+			Checksum: utils.MD5FromString(name),
+			Favorite: sql.NullBool{Bool: false, Valid: true},
+		}
+		created, err := qb.Create(performer)
+		if err != nil {
+			return fmt.Errorf("Error creating performer: %s", err.Error())
+		}
+
+		images := [][]byte{
+			[]byte("image1"),
+			[]byte("image2"),
+			[]byte("image3"),
+		}
+		err = qb.UpdateImages(created.ID, images)
+		if err != nil {
+			return fmt.Errorf("Error updating performer images: %s", err.Error())
+		}
+
+		storedImages, err := qb.GetImages(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting images: %s", err.Error())
+		}
+		assert.Equal(t, images, storedImages)
+
+		count, err := qb.GetImageCount(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting image count: %s", err.Error())
+		}
+		assert.Equal(t, len(images), count)
+
+		storedImage, err := qb.GetImageByIndex(created.ID, 1)
+		if err != nil {
+			return fmt.Errorf("Error getting image: %s", err.Error())
+		}
+		assert.Equal(t, images[1], storedImage)
+
+		// replacing the primary image should not affect the others
+		primary := []byte("primary")
+		err = qb.UpdateImage(created.ID, primary)
+		if err != nil {
+			return fmt.Errorf("Error updating performer image: %s", err.Error())
+		}
+
+		storedImages, err = qb.GetImages(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting images: %s", err.Error())
+		}
+		assert.Equal(t, [][]byte{primary, images[1], images[2]}, storedImages)
+
+		// destroying the primary image should make the next image primary
+		err = qb.DestroyImage(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error destroying performer image: %s", err.Error())
+		}
+
+		storedImage, err = qb.GetImage(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting image: %s", err.Error())
+		}
+		assert.Equal(t, images[1], storedImage)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 func TestPerformerQueryAge(t *testing.T) {
 	const age = 19
 	ageCriterion := models.IntCriterionInput{
@@ -227,6 +303,203 @@ func verifyPerformerAge(t *testing.T, ageCriterion models.IntCriterionInput) {
 	})
 }
 
+func TestPerformerQuerySceneCount(t *testing.T) {
+	countCriterion := models.IntCriterionInput{
+		Value:    0,
+		Modifier: models.CriterionModifierGreaterThan,
+	}
+
+	verifyPerformerSceneCount(t, countCriterion)
+
+	countCriterion.Modifier = models.CriterionModifierEquals
+	verifyPerformerSceneCount(t, countCriterion)
+
+	countCriterion.Value = 1
+	verifyPerformerSceneCount(t, countCriterion)
+}
+
+func verifyPerformerSceneCount(t *testing.T, sceneCountCriterion models.IntCriterionInput) {
+	withTxn(func(r models.Repository) error {
+		qb := r.Performer()
+		performerFilter := models.PerformerFilterType{
+			SceneCount: &sceneCountCriterion,
+		}
+
+		performers, _, err := qb.Query(&performerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying performer: %s", err.Error())
+		}
+
+		assert.Greater(t, len(performers), 0)
+
+		for _, performer := range performers {
+			count, err := r.Scene().CountByPerformerID(performer.ID)
+			if err != nil {
+				t.Errorf("Error counting scenes: %s", err.Error())
+			}
+
+			verifyInt64(t, sql.NullInt64{
+				Int64: int64(count),
+				Valid: true,
+			}, sceneCountCriterion)
+		}
+
+		return nil
+	})
+}
+
+func TestPerformerQuerySceneTags(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		qb := r.Performer()
+
+		tagCriterion := models.MultiCriterionInput{
+			Value:    []string{strconv.Itoa(tagIDs[tagIdxWithScene])},
+			Modifier: models.CriterionModifierIncludes,
+		}
+
+		performerFilter := models.PerformerFilterType{
+			Tags: &tagCriterion,
+		}
+
+		// no performers are in tagged scenes
+		performers, _, err := qb.Query(&performerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying performer: %s", err.Error())
+		}
+
+		assert.Len(t, performers, 0)
+
+		count, err := qb.Count()
+		if err != nil {
+			t.Errorf("Error counting performers: %s", err.Error())
+		}
+
+		tagCriterion.Modifier = models.CriterionModifierExcludes
+		performers, _, err = qb.Query(&performerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying performer: %s", err.Error())
+		}
+
+		assert.Len(t, performers, count)
+
+		return nil
+	})
+}
+
+func TestPerformerQueryCreatedAt(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		qb := r.Performer()
+
+		count, err := qb.Count()
+		if err != nil {
+			t.Errorf("Error counting performers: %s", err.Error())
+		}
+
+		performerFilter := models.PerformerFilterType{
+			CreatedAt: &models.TimestampCriterionInput{
+				Value:    "3000-01-01",
+				Modifier: models.CriterionModifierLessThan,
+			},
+		}
+
+		performers, _, err := qb.Query(&performerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying performer: %s", err.Error())
+		}
+
+		assert.Len(t, performers, count)
+
+		performerFilter.CreatedAt.Modifier = models.CriterionModifierGreaterThan
+		performers, _, err = qb.Query(&performerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying performer: %s", err.Error())
+		}
+
+		assert.Len(t, performers, 0)
+
+		performerFilter.CreatedAt.Value = "invalid"
+		_, _, err = qb.Query(&performerFilter, nil)
+		assert.NotNil(t, err)
+
+		return nil
+	})
+}
+
+func TestPerformerQueryFavoriteOr(t *testing.T) {
+	favorite := true
+	notFavorite := false
+
+	performerFilter := models.PerformerFilterType{
+		FilterFavorites: &favorite,
+		Or: &models.PerformerFilterType{
+			FilterFavorites: &notFavorite,
+		},
+	}
+
+	withTxn(func(r models.Repository) error {
+		qb := r.Performer()
+
+		count, err := qb.Count()
+		if err != nil {
+			t.Errorf("Error counting performers: %s", err.Error())
+		}
+
+		performers, _, err := qb.Query(&performerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying performer: %s", err.Error())
+		}
+
+		assert.Len(t, performers, count)
+
+		performerFilter.Or = nil
+		performerFilter.Not = &models.PerformerFilterType{
+			FilterFavorites: &favorite,
+		}
+
+		performers, _, err = qb.Query(&performerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying performer: %s", err.Error())
+		}
+
+		assert.Len(t, performers, 0)
+
+		return nil
+	})
+}
+
+func TestPerformerIllegalQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	favorite := true
+	subFilter := models.PerformerFilterType{
+		FilterFavorites: &favorite,
+	}
+
+	performerFilter := &models.PerformerFilterType{
+		And: &subFilter,
+		Or:  &subFilter,
+	}
+
+	withTxn(func(r models.Repository) error {
+		qb := r.Performer()
+
+		_, _, err := qb.Query(performerFilter, nil)
+		assert.NotNil(err)
+
+		performerFilter.Or = nil
+		performerFilter.Not = &subFilter
+		_, _, err = qb.Query(performerFilter, nil)
+		assert.NotNil(err)
+
+		performerFilter.And = nil
+		performerFilter.Or = &subFilter
+		_, _, err = qb.Query(performerFilter, nil)
+		assert.NotNil(err)
+
+		return nil
+	})
+}
+
 func TestPerformerStashIDs(t *testing.T) {
 	if err := withTxn(func(r models.Repository) error {
 		qb := r.Performer()
@@ -1,11 +1,5 @@
 package sqlite
 
-import (
-	"regexp"
-
-	"github.com/stashapp/stash/pkg/models"
-)
-
 type queryBuilder struct {
 	repository *repository
 
@@ -93,60 +87,3 @@ func (qb *queryBuilder) addFilter(f *filterBuilder) {
 
 	qb.addJoins(f.getAllJoins()...)
 }
-
-func (qb *queryBuilder) handleIntCriterionInput(c *models.IntCriterionInput, column string) {
-	if c != nil {
-		clause, count := getIntCriterionWhereClause(column, *c)
-		qb.addWhere(clause)
-		if count == 1 {
-			qb.addArg(c.Value)
-		}
-	}
-}
-
-func (qb *queryBuilder) handleStringCriterionInput(c *models.StringCriterionInput, column string) {
-	if c != nil {
-		if modifier := c.Modifier; c.Modifier.IsValid() {
-			switch modifier {
-			case models.CriterionModifierIncludes:
-				clause, thisArgs := getSearchBinding([]string{column}, c.Value, false)
-				qb.addWhere(clause)
-				qb.addArg(thisArgs...)
-			case models.CriterionModifierExcludes:
-				clause, thisArgs := getSearchBinding([]string{column}, c.Value, true)
-				qb.addWhere(clause)
-				qb.addArg(thisArgs...)
-			case models.CriterionModifierEquals:
-				qb.addWhere(column + " LIKE ?")
-				qb.addArg(c.Value)
-			case models.CriterionModifierNotEquals:
-				qb.addWhere(column + " NOT LIKE ?")
-				qb.addArg(c.Value)
-			case models.CriterionModifierMatchesRegex:
-				if _, err := regexp.Compile(c.Value); err != nil {
-					qb.err = err
-					return
-				}
-				qb.addWhere(column + " regexp ?")
-				qb.addArg(c.Value)
-			case models.CriterionModifierNotMatchesRegex:
-				if _, err := regexp.Compile(c.Value); err != nil {
-					qb.err = err
-					return
-				}
-				qb.addWhere(column + " NOT regexp ?")
-				qb.addArg(c.Value)
-			case models.CriterionModifierIsNull:
-				qb.addWhere("(" + column + " IS NULL OR TRIM(" + column + ") = '')")
-			case models.CriterionModifierNotNull:
-				qb.addWhere("(" + column + " IS NOT NULL AND TRIM(" + column + ") != '')")
-			default:
-				clause, count := getSimpleCriterionClause(modifier, "?")
-				qb.addWhere(column + " " + clause)
-				if count == 1 {
-					qb.addArg(c.Value)
-				}
-			}
-		}
-	}
-}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 const idColumn = "id"
@@ -300,43 +301,209 @@ func (r *joinRepository) insert(id, foreignID int) (sql.Result, error) {
 	return r.tx.Exec(stmt, id, foreignID)
 }
 
+// replace removes the joins that are not in foreignIDs and adds the missing
+// ones. Joins that are retained are not rewritten, so that any other columns
+// of the join table are kept.
 func (r *joinRepository) replace(id int, foreignIDs []int) error {
-	if err := r.destroy([]int{id}); err != nil {
+	existing, err := r.getIDs(id)
+	if err != nil {
 		return err
 	}
 
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s = ? AND %s = ?", r.tableName, r.idColumn, r.fkColumn)
+	for _, fk := range existing {
+		if !utils.IntInclude(foreignIDs, fk) {
+			if _, err := r.tx.Exec(stmt, id, fk); err != nil {
+				return err
+			}
+		}
+	}
+
 	for _, fk := range foreignIDs {
-		if _, err := r.insert(id, fk); err != nil {
-			return err
+		if !utils.IntInclude(existing, fk) {
+			if _, err := r.insert(id, fk); err != nil {
+				return err
+			}
+			existing = append(existing, fk)
 		}
 	}
 
 	return nil
 }
 
+// imageRepository stores an image for each object as a reference to a blob.
 type imageRepository struct {
 	repository
-	imageColumn string
+	blobColumn string
 }
 
-func (r *imageRepository) get(id int) ([]byte, error) {
-	query := fmt.Sprintf("SELECT %s from %s WHERE %s = ?", r.imageColumn, r.tableName, r.idColumn)
-	var ret []byte
+func (r *imageRepository) blobs() *blobStore {
+	return &blobStore{tx: r.tx}
+}
+
+// getChecksum returns the checksum of the image of the object, or an empty
+// string if it has no image.
+func (r *imageRepository) getChecksum(id int) (string, error) {
+	query := fmt.Sprintf("SELECT %s from %s WHERE %s = ?", r.blobColumn, r.tableName, r.idColumn)
+	var ret string
 	err := r.querySimple(query, []interface{}{id}, &ret)
 	return ret, err
 }
 
+func (r *imageRepository) get(id int) ([]byte, error) {
+	checksum, err := r.getChecksum(id)
+	if err != nil || checksum == "" {
+		return nil, err
+	}
+
+	return r.blobs().read(checksum)
+}
+
 func (r *imageRepository) replace(id int, image []byte) error {
-	if err := r.destroy([]int{id}); err != nil {
+	existing, err := r.getChecksum(id)
+	if err != nil {
+		return err
+	}
+
+	checksum, err := r.blobs().write(image)
+	if err != nil {
+		return err
+	}
+
+	if err := r.repository.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (?, ?)", r.tableName, r.idColumn, r.blobColumn)
+	if _, err := r.tx.Exec(stmt, id, checksum); err != nil {
+		return err
+	}
+
+	return r.blobs().deleteUnreferenced([]string{existing})
+}
+
+// destroy deletes the images of the objects, and their blobs if they are no
+// longer referenced.
+func (r *imageRepository) destroy(ids []int) error {
+	var checksums []string
+	for _, id := range ids {
+		checksum, err := r.getChecksum(id)
+		if err != nil {
+			return err
+		}
+		checksums = append(checksums, checksum)
+	}
+
+	if err := r.repository.destroy(ids); err != nil {
+		return err
+	}
+
+	return r.blobs().deleteUnreferenced(checksums)
+}
+
+// orderedImageRepository stores an ordered list of images for each object.
+// The image at position 0 is the primary image.
+type orderedImageRepository struct {
+	repository
+	blobColumn     string
+	positionColumn string
+}
+
+func (r *orderedImageRepository) blobs() *blobStore {
+	return &blobStore{tx: r.tx}
+}
+
+// getChecksum returns the checksum of the image of the object at the
+// provided position, or an empty string if there is no image.
+func (r *orderedImageRepository) getChecksum(id int, index int) (string, error) {
+	query := fmt.Sprintf("SELECT %s from %s WHERE %s = ? AND %s = ?", r.blobColumn, r.tableName, r.idColumn, r.positionColumn)
+	var ret string
+	err := r.querySimple(query, []interface{}{id, index}, &ret)
+	return ret, err
+}
+
+func (r *orderedImageRepository) getChecksums(id int) ([]string, error) {
+	query := fmt.Sprintf("SELECT %s from %s WHERE %s = ? ORDER BY %s", r.blobColumn, r.tableName, r.idColumn, r.positionColumn)
+	return queryChecksums(r.tx, query, id)
+}
+
+func (r *orderedImageRepository) get(id int, index int) ([]byte, error) {
+	checksum, err := r.getChecksum(id, index)
+	if err != nil || checksum == "" {
+		return nil, err
+	}
+
+	return r.blobs().read(checksum)
+}
+
+func (r *orderedImageRepository) getImages(id int) ([][]byte, error) {
+	checksums, err := r.getChecksums(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret [][]byte
+	for _, checksum := range checksums {
+		image, err := r.blobs().read(checksum)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, image)
+	}
+
+	return ret, nil
+}
+
+func (r *orderedImageRepository) count(id int) (int, error) {
+	query := fmt.Sprintf("SELECT %s from %s WHERE %s = ?", r.positionColumn, r.tableName, r.idColumn)
+	return r.runCountQuery(r.buildCountQuery(query), []interface{}{id})
+}
+
+func (r *orderedImageRepository) set(id int, index int, image []byte) error {
+	existing, err := r.getChecksum(id, index)
+	if err != nil {
 		return err
 	}
 
-	stmt := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (?, ?)", r.tableName, r.idColumn, r.imageColumn)
-	_, err := r.tx.Exec(stmt, id, image)
+	if err := r.insert(id, index, image); err != nil {
+		return err
+	}
+
+	return r.blobs().deleteUnreferenced([]string{existing})
+}
+
+func (r *orderedImageRepository) insert(id int, index int, image []byte) error {
+	checksum, err := r.blobs().write(image)
+	if err != nil {
+		return err
+	}
+
+	stmt := fmt.Sprintf("INSERT OR REPLACE INTO %s (%s, %s, %s) VALUES (?, ?, ?)", r.tableName, r.idColumn, r.positionColumn, r.blobColumn)
+	_, err = r.tx.Exec(stmt, id, index, checksum)
 
 	return err
 }
 
+func (r *orderedImageRepository) replace(id int, images [][]byte) error {
+	existing, err := r.getChecksums(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.repository.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	for i, image := range images {
+		if err := r.insert(id, i, image); err != nil {
+			return err
+		}
+	}
+
+	return r.blobs().deleteUnreferenced(existing)
+}
+
 type stashIDRepository struct {
 	repository
 }
@@ -373,6 +540,88 @@ func (r *stashIDRepository) replace(id int, newIDs []models.StashID) error {
 	return nil
 }
 
+type customFieldsRepository struct {
+	repository
+}
+
+type customFieldValues []*models.CustomFieldValue
+
+func (s *customFieldValues) Append(o interface{}) {
+	*s = append(*s, o.(*models.CustomFieldValue))
+}
+
+func (s *customFieldValues) New() interface{} {
+	return &models.CustomFieldValue{}
+}
+
+func (r *customFieldsRepository) get(id int) (models.CustomFieldMap, error) {
+	query := fmt.Sprintf("SELECT field, type, value from %s WHERE %s = ?", r.tableName, r.idColumn)
+	var values customFieldValues
+	if err := r.query(query, []interface{}{id}, &values); err != nil {
+		return nil, err
+	}
+
+	var ret []models.CustomFieldValue
+	for _, v := range values {
+		ret = append(ret, *v)
+	}
+	return models.CustomFieldMapFromValues(ret), nil
+}
+
+func (r *customFieldsRepository) replace(id int, fields models.CustomFieldMap) error {
+	values, err := fields.Values()
+	if err != nil {
+		return err
+	}
+
+	if err := r.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, field, type, value) VALUES (?, ?, ?, ?)", r.tableName, r.idColumn)
+	for _, v := range values {
+		_, err := r.tx.Exec(query, id, v.Field, v.Type, v.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type stringListRepository struct {
+	repository
+	stringColumn string
+}
+
+func (r *stringListRepository) get(id int) ([]string, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ? ORDER BY %s", r.stringColumn, r.tableName, r.idColumn, r.stringColumn)
+	var ret []string
+	err := r.queryFunc(query, []interface{}{id}, func(rows *sqlx.Rows) error {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return err
+		}
+		ret = append(ret, s)
+		return nil
+	})
+	return ret, err
+}
+
+func (r *stringListRepository) replace(id int, newStrings []string) error {
+	if err := r.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (?, ?)", r.tableName, r.idColumn, r.stringColumn)
+	for _, s := range newStrings {
+		_, err := r.tx.Exec(query, id, s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func listKeys(i interface{}, addPrefix bool) string {
 	var query []string
 	v := reflect.ValueOf(i)
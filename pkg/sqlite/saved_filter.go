@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const savedFilterTable = "saved_filters"
+
+type savedFilterQueryBuilder struct {
+	repository
+}
+
+func NewSavedFilterReaderWriter(tx dbi) *savedFilterQueryBuilder {
+	return &savedFilterQueryBuilder{
+		repository{
+			tx:        tx,
+			tableName: savedFilterTable,
+			idColumn:  idColumn,
+		},
+	}
+}
+
+func (qb *savedFilterQueryBuilder) Create(newObject models.SavedFilter) (*models.SavedFilter, error) {
+	var ret models.SavedFilter
+	if err := qb.insertObject(newObject, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *savedFilterQueryBuilder) Update(updatedObject models.SavedFilter) (*models.SavedFilter, error) {
+	const partial = false
+	if err := qb.update(updatedObject.ID, updatedObject, partial); err != nil {
+		return nil, err
+	}
+
+	return qb.find(updatedObject.ID)
+}
+
+// SetDefault sets the default filter for the mode of the provided filter.
+// The name of the filter is ignored.
+func (qb *savedFilterQueryBuilder) SetDefault(obj models.SavedFilter) (*models.SavedFilter, error) {
+	existing, err := qb.FindDefault(obj.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	obj.Name = models.SavedFilterDefaultName
+
+	if existing == nil {
+		obj.ID = 0
+		return qb.Create(obj)
+	}
+
+	obj.ID = existing.ID
+	return qb.Update(obj)
+}
+
+func (qb *savedFilterQueryBuilder) Destroy(id int) error {
+	return qb.destroyExisting([]int{id})
+}
+
+func (qb *savedFilterQueryBuilder) Find(id int) (*models.SavedFilter, error) {
+	return qb.find(id)
+}
+
+func (qb *savedFilterQueryBuilder) find(id int) (*models.SavedFilter, error) {
+	var ret models.SavedFilter
+	if err := qb.get(id, &ret); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// FindByMode returns the saved filters for the mode, ordered by name. The
+// default filter for the mode is not included.
+func (qb *savedFilterQueryBuilder) FindByMode(mode models.FilterMode) ([]*models.SavedFilter, error) {
+	query := "SELECT * FROM " + savedFilterTable + " WHERE mode = ? AND name != ? ORDER BY name COLLATE NOCASE ASC"
+	return qb.querySavedFilters(query, []interface{}{mode.String(), models.SavedFilterDefaultName})
+}
+
+func (qb *savedFilterQueryBuilder) FindDefault(mode models.FilterMode) (*models.SavedFilter, error) {
+	query := "SELECT * FROM " + savedFilterTable + " WHERE mode = ? AND name = ? LIMIT 1"
+	results, err := qb.querySavedFilters(query, []interface{}{mode.String(), models.SavedFilterDefaultName})
+	if err != nil || len(results) < 1 {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+func (qb *savedFilterQueryBuilder) querySavedFilters(query string, args []interface{}) ([]*models.SavedFilter, error) {
+	var ret models.SavedFilters
+	if err := qb.query(query, args, &ret); err != nil {
+		return nil, err
+	}
+
+	return []*models.SavedFilter(ret), nil
+}
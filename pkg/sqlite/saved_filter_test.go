@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedFilterFindByMode(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.SavedFilter()
+
+		const name = "TestSavedFilterFindByMode"
+		created, err := qb.Create(models.SavedFilter{
+			Mode:         models.FilterModeScenes,
+			Name:         name,
+			ObjectFilter: sql.NullString{String: `{"organized":false}`, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("Error creating saved filter: %s", err.Error())
+		}
+
+		filters, err := qb.FindByMode(models.FilterModeScenes)
+		if err != nil {
+			return fmt.Errorf("Error finding saved filters: %s", err.Error())
+		}
+
+		assert.Len(t, filters, 1)
+		assert.Equal(t, *created, *filters[0])
+
+		filters, err = qb.FindByMode(models.FilterModeImages)
+		if err != nil {
+			return fmt.Errorf("Error finding saved filters: %s", err.Error())
+		}
+
+		assert.Len(t, filters, 0)
+
+		return qb.Destroy(created.ID)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSavedFilterSetDefault(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.SavedFilter()
+
+		filter := models.SavedFilter{
+			Mode:       models.FilterModePerformers,
+			FindFilter: sql.NullString{String: `{"sort":"name"}`, Valid: true},
+		}
+		created, err := qb.SetDefault(filter)
+		if err != nil {
+			return fmt.Errorf("Error setting default filter: %s", err.Error())
+		}
+
+		// setting the default again should replace the existing filter
+		filter.FindFilter.String = `{"sort":"birthdate"}`
+		updated, err := qb.SetDefault(filter)
+		if err != nil {
+			return fmt.Errorf("Error setting default filter: %s", err.Error())
+		}
+
+		assert.Equal(t, created.ID, updated.ID)
+
+		found, err := qb.FindDefault(models.FilterModePerformers)
+		if err != nil {
+			return fmt.Errorf("Error finding default filter: %s", err.Error())
+		}
+
+		assert.Equal(t, filter.FindFilter, found.FindFilter)
+
+		// default filter should not be returned with the saved filters
+		filters, err := qb.FindByMode(models.FilterModePerformers)
+		if err != nil {
+			return fmt.Errorf("Error finding saved filters: %s", err.Error())
+		}
+
+		assert.Len(t, filters, 0)
+
+		return qb.Destroy(found.ID)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
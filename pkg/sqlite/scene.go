@@ -10,6 +10,7 @@ import (
 )
 
 const sceneTable = "scenes"
+const sceneCustomFieldsTable = "scene_custom_fields"
 const sceneIDColumn = "scene_id"
 const performersScenesTable = "performers_scenes"
 const scenesTagsTable = "scenes_tags"
@@ -289,35 +290,17 @@ func (qb *sceneQueryBuilder) All() ([]*models.Scene, error) {
 	return qb.queryScenes(selectAll(sceneTable)+qb.getSceneSort(nil), nil)
 }
 
-func illegalFilterCombination(type1, type2 string) error {
-	return fmt.Errorf("cannot have %s and %s in the same filter", type1, type2)
-}
-
 func (qb *sceneQueryBuilder) validateFilter(sceneFilter *models.SceneFilterType) error {
-	const and = "AND"
-	const or = "OR"
-	const not = "NOT"
-
-	if sceneFilter.And != nil {
-		if sceneFilter.Or != nil {
-			return illegalFilterCombination(and, or)
-		}
-		if sceneFilter.Not != nil {
-			return illegalFilterCombination(and, not)
-		}
-
-		return qb.validateFilter(sceneFilter.And)
+	if err := validateFilterCombination(sceneFilter.And != nil, sceneFilter.Or != nil, sceneFilter.Not != nil); err != nil {
+		return err
 	}
 
-	if sceneFilter.Or != nil {
-		if sceneFilter.Not != nil {
-			return illegalFilterCombination(or, not)
-		}
-
+	switch {
+	case sceneFilter.And != nil:
+		return qb.validateFilter(sceneFilter.And)
+	case sceneFilter.Or != nil:
 		return qb.validateFilter(sceneFilter.Or)
-	}
-
-	if sceneFilter.Not != nil {
+	case sceneFilter.Not != nil:
 		return qb.validateFilter(sceneFilter.Not)
 	}
 
@@ -352,6 +335,20 @@ func (qb *sceneQueryBuilder) makeFilter(sceneFilter *models.SceneFilterType) *fi
 	query.handleCriterionFunc(sceneMoviesCriterionHandler(qb, sceneFilter.Movies))
 	query.handleCriterionFunc(sceneStashIDsHandler(qb, sceneFilter.StashID))
 
+	query.handleCriterionFunc(dateCriterionHandler(sceneFilter.Date, "scenes.date"))
+	query.handleCriterionFunc(intCriterionHandler(sceneFilter.Bitrate, "scenes.bitrate"))
+	query.handleCriterionFunc(floatCriterionHandler(sceneFilter.Framerate, "scenes.framerate"))
+	query.handleCriterionFunc(stringCriterionHandler(sceneFilter.VideoCodec, "scenes.video_codec"))
+	query.handleCriterionFunc(stringCriterionHandler(sceneFilter.AudioCodec, "scenes.audio_codec"))
+	query.handleCriterionFunc(int64CriterionHandler(sceneFilter.FileSize, "CAST(scenes.size AS INTEGER)"))
+	query.handleCriterionFunc(countCriterionHandler(sceneFilter.PerformerCount, sceneTable, performersScenesTable, sceneIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(sceneFilter.TagCount, sceneTable, scenesTagsTable, sceneIDColumn))
+	query.handleCriterionFunc(scenePerformerGenderCriterionHandler(sceneFilter.PerformerGender))
+	query.handleCriterionFunc(scenePerformerAgeCriterionHandler(sceneFilter.PerformerAge))
+	query.handleCriterionFunc(timestampCriterionHandler(sceneFilter.CreatedAt, "scenes.created_at"))
+	query.handleCriterionFunc(timestampCriterionHandler(sceneFilter.UpdatedAt, "scenes.updated_at"))
+	query.handleCriterionFunc(customFieldsCriterionHandler(sceneFilter.CustomFields, sceneTable, sceneCustomFieldsTable, sceneIDColumn))
+
 	return query
 }
 
@@ -368,11 +365,13 @@ func (qb *sceneQueryBuilder) Query(sceneFilter *models.SceneFilterType, findFilt
 	query.body = selectDistinctIDs(sceneTable)
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		query.join("scene_markers", "", "scene_markers.scene_id = scenes.id")
-		searchColumns := []string{"scenes.title", "scenes.details", "scenes.path", "scenes.oshash", "scenes.checksum", "scene_markers.title"}
-		clause, thisArgs := getSearchBinding(searchColumns, *q, false)
-		query.addWhere(clause)
-		query.addArg(thisArgs...)
+		if searchQuery := parseFTSQuery(*q); !searchQuery.empty() {
+			// scenes also match on the titles of their markers
+			markersClause := "scenes.id IN (SELECT scene_id FROM scene_markers WHERE id IN " + getFTSMatchQuery("scene_markers_fts") + ")"
+			clause, thisArgs := getFTSSearchClause("scenes_fts", "scenes.id", searchQuery, markersClause)
+			query.addWhere(clause)
+			query.addArg(thisArgs...)
+		}
 	}
 
 	if err := qb.validateFilter(sceneFilter); err != nil {
@@ -401,14 +400,6 @@ func (qb *sceneQueryBuilder) Query(sceneFilter *models.SceneFilterType, findFilt
 	return scenes, countResult, nil
 }
 
-func appendClause(clauses []string, clause string) []string {
-	if clause != "" {
-		return append(clauses, clause)
-	}
-
-	return clauses
-}
-
 func durationCriterionHandler(durationFilter *models.IntCriterionInput, column string) criterionHandlerFunc {
 	return func(f *filterBuilder) {
 		if durationFilter != nil {
@@ -565,12 +556,67 @@ func sceneStashIDsHandler(qb *sceneQueryBuilder, stashID *string) criterionHandl
 	}
 }
 
+// scenePerformerExistsClause returns a clause matching scenes that have a
+// performer matching the provided performer clause. The performer table is
+// aliased as p in the subquery.
+func scenePerformerExistsClause(performerClause string) string {
+	return "EXISTS (SELECT 1 FROM performers_scenes AS ps JOIN performers AS p ON p.id = ps.performer_id WHERE ps.scene_id = scenes.id AND " + performerClause + ")"
+}
+
+func scenePerformerGenderCriterionHandler(gender *models.GenderCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if gender == nil {
+			return
+		}
+
+		switch gender.Modifier {
+		case models.CriterionModifierIsNull:
+			f.addWhere(scenePerformerExistsClause("p.gender IS NULL"))
+		case models.CriterionModifierNotNull:
+			f.addWhere(scenePerformerExistsClause("p.gender IS NOT NULL"))
+		case models.CriterionModifierEquals:
+			if gender.Value != nil {
+				f.addWhere(scenePerformerExistsClause("p.gender = ?"), gender.Value.String())
+			}
+		case models.CriterionModifierNotEquals:
+			if gender.Value != nil {
+				f.addWhere("NOT "+scenePerformerExistsClause("p.gender = ?"), gender.Value.String())
+			}
+		}
+	}
+}
+
+func scenePerformerAgeCriterionHandler(age *models.IntCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if age == nil {
+			return
+		}
+
+		// age of the performer in whole years as at the scene date
+		const ageColumn = "cast(strftime('%Y.%m%d', scenes.date) - strftime('%Y.%m%d', p.birthdate) as int)"
+		clause, count := getIntCriterionWhereClause(ageColumn, *age)
+		clause = scenePerformerExistsClause("scenes.date != '' AND p.birthdate != '' AND " + clause)
+
+		if count == 1 {
+			f.addWhere(clause, age.Value)
+		} else {
+			f.addWhere(clause)
+		}
+	}
+}
+
 func (qb *sceneQueryBuilder) getSceneSort(findFilter *models.FindFilterType) string {
 	if findFilter == nil {
 		return " ORDER BY scenes.path, scenes.date ASC "
 	}
 	sort := findFilter.GetSort("title")
 	direction := findFilter.GetDirection()
+	if sort == relevanceSort {
+		if ret := getFTSRelevanceSort("scenes_fts", "scenes.id", findFilter.Q, direction); ret != "" {
+			return ret
+		}
+		sort = "title"
+	}
 	return getSort(sort, direction, "scenes")
 }
 
@@ -598,7 +644,7 @@ func (qb *sceneQueryBuilder) imageRepository() *imageRepository {
 			tableName: "scenes_cover",
 			idColumn:  sceneIDColumn,
 		},
-		imageColumn: "cover",
+		blobColumn: "cover_blob",
 	}
 }
 
@@ -606,6 +652,10 @@ func (qb *sceneQueryBuilder) GetCover(sceneID int) ([]byte, error) {
 	return qb.imageRepository().get(sceneID)
 }
 
+func (qb *sceneQueryBuilder) GetCoverChecksum(sceneID int) (string, error) {
+	return qb.imageRepository().getChecksum(sceneID)
+}
+
 func (qb *sceneQueryBuilder) UpdateCover(sceneID int, image []byte) error {
 	return qb.imageRepository().replace(sceneID, image)
 }
@@ -732,3 +782,21 @@ func (qb *sceneQueryBuilder) GetStashIDs(sceneID int) ([]*models.StashID, error)
 func (qb *sceneQueryBuilder) UpdateStashIDs(sceneID int, stashIDs []models.StashID) error {
 	return qb.stashIDRepository().replace(sceneID, stashIDs)
 }
+
+func (qb *sceneQueryBuilder) customFieldsRepository() *customFieldsRepository {
+	return &customFieldsRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: sceneCustomFieldsTable,
+			idColumn:  sceneIDColumn,
+		},
+	}
+}
+
+func (qb *sceneQueryBuilder) GetCustomFields(sceneID int) (models.CustomFieldMap, error) {
+	return qb.customFieldsRepository().get(sceneID)
+}
+
+func (qb *sceneQueryBuilder) UpdateCustomFields(sceneID int, fields models.CustomFieldMap) error {
+	return qb.customFieldsRepository().replace(sceneID, fields)
+}
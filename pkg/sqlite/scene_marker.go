@@ -127,129 +127,80 @@ func (qb *sceneMarkerQueryBuilder) Wall(q *string) ([]*models.SceneMarker, error
 	return qb.querySceneMarkers(query, nil)
 }
 
-func (qb *sceneMarkerQueryBuilder) Query(sceneMarkerFilter *models.SceneMarkerFilterType, findFilter *models.FindFilterType) ([]*models.SceneMarker, int, error) {
-	if sceneMarkerFilter == nil {
-		sceneMarkerFilter = &models.SceneMarkerFilterType{}
-	}
-	if findFilter == nil {
-		findFilter = &models.FindFilterType{}
+func (qb *sceneMarkerQueryBuilder) validateFilter(filter *models.SceneMarkerFilterType) error {
+	if err := validateFilterCombination(filter.And != nil, filter.Or != nil, filter.Not != nil); err != nil {
+		return err
 	}
 
-	var whereClauses []string
-	var havingClauses []string
-	var args []interface{}
-	body := selectDistinctIDs("scene_markers")
-	body = body + `
-		left join tags as primary_tag on primary_tag.id = scene_markers.primary_tag_id
-		left join scenes as scene on scene.id = scene_markers.scene_id
-		left join scene_markers_tags as tags_join on tags_join.scene_marker_id = scene_markers.id
-		left join tags on tags_join.tag_id = tags.id
-	`
-
-	if tagsFilter := sceneMarkerFilter.Tags; tagsFilter != nil && len(tagsFilter.Value) > 0 {
-		//select `scene_markers`.* from `scene_markers`
-		//left join `tags` as `primary_tags_join`
-		//  on `primary_tags_join`.`id` = `scene_markers`.`primary_tag_id`
-		//  and `primary_tags_join`.`id` in ('3', '37', '9', '89')
-		//left join `scene_markers_tags` as `tags_join`
-		//  on `tags_join`.`scene_marker_id` = `scene_markers`.`id`
-		//  and `tags_join`.`tag_id` in ('3', '37', '9', '89')
-		//group by `scene_markers`.`id`
-		//having ((count(distinct `primary_tags_join`.`id`) + count(distinct `tags_join`.`tag_id`)) = 4)
-
-		length := len(tagsFilter.Value)
-
-		if tagsFilter.Modifier == models.CriterionModifierIncludes || tagsFilter.Modifier == models.CriterionModifierIncludesAll {
-			body += " LEFT JOIN tags AS ptj ON ptj.id = scene_markers.primary_tag_id AND ptj.id IN " + getInBinding(length)
-			body += " LEFT JOIN scene_markers_tags AS tj ON tj.scene_marker_id = scene_markers.id AND tj.tag_id IN " + getInBinding(length)
-
-			// only one required for include any
-			requiredCount := 1
-
-			// all required for include all
-			if tagsFilter.Modifier == models.CriterionModifierIncludesAll {
-				requiredCount = length
-			}
-
-			havingClauses = append(havingClauses, "((COUNT(DISTINCT ptj.id) + COUNT(DISTINCT tj.tag_id)) >= "+strconv.Itoa(requiredCount)+")")
-		} else if tagsFilter.Modifier == models.CriterionModifierExcludes {
-			// excludes all of the provided ids
-			whereClauses = append(whereClauses, "scene_markers.primary_tag_id not in "+getInBinding(length))
-			whereClauses = append(whereClauses, "not exists (select smt.scene_marker_id from scene_markers_tags as smt where smt.scene_marker_id = scene_markers.id and smt.tag_id in "+getInBinding(length)+")")
-		}
-
-		for _, tagID := range tagsFilter.Value {
-			args = append(args, tagID)
-		}
-		for _, tagID := range tagsFilter.Value {
-			args = append(args, tagID)
-		}
+	switch {
+	case filter.And != nil:
+		return qb.validateFilter(filter.And)
+	case filter.Or != nil:
+		return qb.validateFilter(filter.Or)
+	case filter.Not != nil:
+		return qb.validateFilter(filter.Not)
 	}
 
-	if sceneTagsFilter := sceneMarkerFilter.SceneTags; sceneTagsFilter != nil && len(sceneTagsFilter.Value) > 0 {
-		length := len(sceneTagsFilter.Value)
+	return nil
+}
 
-		if sceneTagsFilter.Modifier == models.CriterionModifierIncludes || sceneTagsFilter.Modifier == models.CriterionModifierIncludesAll {
-			body += " LEFT JOIN scenes_tags AS scene_tags_join ON scene_tags_join.scene_id = scene.id AND scene_tags_join.tag_id IN " + getInBinding(length)
+func (qb *sceneMarkerQueryBuilder) makeFilter(filter *models.SceneMarkerFilterType) *filterBuilder {
+	query := &filterBuilder{}
 
-			// only one required for include any
-			requiredCount := 1
+	if filter.And != nil {
+		query.and(qb.makeFilter(filter.And))
+	}
+	if filter.Or != nil {
+		query.or(qb.makeFilter(filter.Or))
+	}
+	if filter.Not != nil {
+		query.not(qb.makeFilter(filter.Not))
+	}
 
-			// all required for include all
-			if sceneTagsFilter.Modifier == models.CriterionModifierIncludesAll {
-				requiredCount = length
-			}
+	query.handleCriterionFunc(sceneMarkerTagIDCriterionHandler(filter.TagID))
+	query.handleCriterionFunc(sceneMarkerTagsCriterionHandler(filter.Tags))
+	query.handleCriterionFunc(sceneMarkerSceneCriterionHandler(filter.SceneTags, scenesTagsTable, tagIDColumn))
+	query.handleCriterionFunc(sceneMarkerSceneCriterionHandler(filter.Performers, performersScenesTable, performerIDColumn))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.CreatedAt, "scene_markers.created_at"))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.UpdatedAt, "scene_markers.updated_at"))
 
-			havingClauses = append(havingClauses, "COUNT(DISTINCT scene_tags_join.tag_id) >= "+strconv.Itoa(requiredCount))
-		} else if sceneTagsFilter.Modifier == models.CriterionModifierExcludes {
-			// excludes all of the provided ids
-			whereClauses = append(whereClauses, "not exists (select st.scene_id from scenes_tags as st where st.scene_id = scene.id AND st.tag_id IN "+getInBinding(length)+")")
-		}
+	return query
+}
 
-		for _, tagID := range sceneTagsFilter.Value {
-			args = append(args, tagID)
-		}
+func (qb *sceneMarkerQueryBuilder) Query(sceneMarkerFilter *models.SceneMarkerFilterType, findFilter *models.FindFilterType) ([]*models.SceneMarker, int, error) {
+	if sceneMarkerFilter == nil {
+		sceneMarkerFilter = &models.SceneMarkerFilterType{}
+	}
+	if findFilter == nil {
+		findFilter = &models.FindFilterType{}
 	}
 
-	if performersFilter := sceneMarkerFilter.Performers; performersFilter != nil && len(performersFilter.Value) > 0 {
-		length := len(performersFilter.Value)
-
-		if performersFilter.Modifier == models.CriterionModifierIncludes || performersFilter.Modifier == models.CriterionModifierIncludesAll {
-			body += " LEFT JOIN performers_scenes as scene_performers ON scene.id = scene_performers.scene_id"
-			whereClauses = append(whereClauses, "scene_performers.performer_id IN "+getInBinding(length))
-
-			// only one required for include any
-			requiredCount := 1
+	query := qb.newQuery()
 
-			// all required for include all
-			if performersFilter.Modifier == models.CriterionModifierIncludesAll {
-				requiredCount = length
-			}
+	query.body = selectDistinctIDs(sceneMarkerTable)
 
-			havingClauses = append(havingClauses, "COUNT(DISTINCT scene_performers.performer_id) >= "+strconv.Itoa(requiredCount))
-		} else if performersFilter.Modifier == models.CriterionModifierExcludes {
-			// excludes all of the provided ids
-			whereClauses = append(whereClauses, "not exists (select sp.scene_id from performers_scenes as sp where sp.scene_id = scene.id AND sp.performer_id IN "+getInBinding(length)+")")
-		}
+	// required for sorting by scene updated at
+	query.join(sceneTable, "scene", "scene.id = scene_markers.scene_id")
 
-		for _, performerID := range performersFilter.Value {
-			args = append(args, performerID)
+	if q := findFilter.Q; q != nil && *q != "" {
+		if searchQuery := parseFTSQuery(*q); !searchQuery.empty() {
+			// markers also match on the title of their scene
+			sceneClause := "scene_markers.scene_id IN (SELECT rowid FROM scenes_fts WHERE scenes_fts MATCH '{title} : (' || ? || ')')"
+			clause, thisArgs := getFTSSearchClause("scene_markers_fts", "scene_markers.id", searchQuery, sceneClause)
+			query.addWhere(clause)
+			query.addArg(thisArgs...)
 		}
 	}
 
-	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"scene_markers.title", "scene.title"}
-		clause, thisArgs := getSearchBinding(searchColumns, *q, false)
-		whereClauses = append(whereClauses, clause)
-		args = append(args, thisArgs...)
+	if err := qb.validateFilter(sceneMarkerFilter); err != nil {
+		return nil, 0, err
 	}
+	filter := qb.makeFilter(sceneMarkerFilter)
 
-	if tagID := sceneMarkerFilter.TagID; tagID != nil {
-		whereClauses = append(whereClauses, "(scene_markers.primary_tag_id = "+*tagID+" OR tags.id = "+*tagID+")")
-	}
+	query.addFilter(filter)
 
-	sortAndPagination := qb.getSceneMarkerSort(findFilter) + getPagination(findFilter)
-	idsResult, countResult, err := qb.executeFindQuery(body, args, sortAndPagination, whereClauses, havingClauses)
+	query.sortAndPagination = qb.getSceneMarkerSort(findFilter) + getPagination(findFilter)
+	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
 	}
@@ -267,9 +218,84 @@ func (qb *sceneMarkerQueryBuilder) Query(sceneMarkerFilter *models.SceneMarkerFi
 	return sceneMarkers, countResult, nil
 }
 
+func sceneMarkerTagIDCriterionHandler(tagID *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if tagID != nil {
+			f.addWhere("(scene_markers.primary_tag_id = ? OR EXISTS (SELECT smt.scene_marker_id FROM scene_markers_tags AS smt WHERE smt.scene_marker_id = scene_markers.id AND smt.tag_id = ?))", *tagID, *tagID)
+		}
+	}
+}
+
+// getMultiCriterionRequiredCount returns the number of values that must be
+// matched for an includes or includes all criterion.
+func getMultiCriterionRequiredCount(criterion *models.MultiCriterionInput) int {
+	// all required for include all
+	if criterion.Modifier == models.CriterionModifierIncludesAll {
+		return len(criterion.Value)
+	}
+
+	// only one required for include any
+	return 1
+}
+
+func sceneMarkerTagsCriterionHandler(tags *models.MultiCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if tags != nil && len(tags.Value) > 0 {
+			length := len(tags.Value)
+			var args []interface{}
+			for _, tagID := range tags.Value {
+				args = append(args, tagID)
+			}
+
+			switch tags.Modifier {
+			case models.CriterionModifierIncludes, models.CriterionModifierIncludesAll:
+				// the primary tag is not counted twice if it is also in the marker tags
+				primaryCount := "(CASE WHEN scene_markers.primary_tag_id IN " + getInBinding(length) + " THEN 1 ELSE 0 END)"
+				tagsCount := "(SELECT COUNT(DISTINCT smt.tag_id) FROM scene_markers_tags AS smt WHERE smt.scene_marker_id = scene_markers.id AND smt.tag_id IN " + getInBinding(length) + " AND smt.tag_id != scene_markers.primary_tag_id)"
+				requiredCount := getMultiCriterionRequiredCount(tags)
+
+				f.addWhere("("+primaryCount+" + "+tagsCount+") >= "+strconv.Itoa(requiredCount), append(args, args...)...)
+			case models.CriterionModifierExcludes:
+				// excludes all of the provided ids
+				f.addWhere("scene_markers.primary_tag_id NOT IN "+getInBinding(length), args...)
+				f.addWhere("NOT EXISTS (SELECT smt.scene_marker_id FROM scene_markers_tags AS smt WHERE smt.scene_marker_id = scene_markers.id AND smt.tag_id IN "+getInBinding(length)+")", args...)
+			}
+		}
+	}
+}
+
+// sceneMarkerSceneCriterionHandler returns a handler that filters markers by
+// the values joined to their scene in the join table.
+func sceneMarkerSceneCriterionHandler(criterion *models.MultiCriterionInput, joinTable string, fkColumn string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if criterion != nil && len(criterion.Value) > 0 {
+			var args []interface{}
+			for _, id := range criterion.Value {
+				args = append(args, id)
+			}
+
+			subQuery := "SELECT COUNT(DISTINCT j." + fkColumn + ") FROM " + joinTable + " AS j WHERE j.scene_id = scene_markers.scene_id AND j." + fkColumn + " IN " + getInBinding(len(criterion.Value))
+
+			switch criterion.Modifier {
+			case models.CriterionModifierIncludes, models.CriterionModifierIncludesAll:
+				f.addWhere("("+subQuery+") >= "+strconv.Itoa(getMultiCriterionRequiredCount(criterion)), args...)
+			case models.CriterionModifierExcludes:
+				// excludes all of the provided ids
+				f.addWhere("("+subQuery+") = 0", args...)
+			}
+		}
+	}
+}
+
 func (qb *sceneMarkerQueryBuilder) getSceneMarkerSort(findFilter *models.FindFilterType) string {
 	sort := findFilter.GetSort("title")
 	direction := findFilter.GetDirection()
+	if sort == relevanceSort {
+		if ret := getFTSRelevanceSort("scene_markers_fts", "scene_markers.id", findFilter.Q, direction); ret != "" {
+			return ret
+		}
+		sort = "title"
+	}
 	tableName := "scene_markers"
 	if sort == "scenes_updated_at" {
 		sort = "updated_at"
@@ -3,6 +3,7 @@
 package sqlite_test
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stashapp/stash/pkg/models"
@@ -67,6 +68,52 @@ func TestMarkerCountByTagID(t *testing.T) {
 	})
 }
 
+func TestMarkerQueryTags(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		mqb := r.SceneMarker()
+
+		markerFilter := models.SceneMarkerFilterType{
+			Tags: &models.MultiCriterionInput{
+				Value:    []string{strconv.Itoa(tagIDs[tagIdxWithMarker])},
+				Modifier: models.CriterionModifierIncludes,
+			},
+		}
+
+		markers, _, err := mqb.Query(&markerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying markers: %s", err.Error())
+		}
+
+		assert.Len(t, markers, 1)
+		assert.Equal(t, markerIDs[markerIdxWithScene], markers[0].ID)
+
+		// primary tag should match as well
+		markerFilter.Tags.Value = []string{strconv.Itoa(tagIDs[tagIdxWithPrimaryMarker])}
+		markers, _, err = mqb.Query(&markerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying markers: %s", err.Error())
+		}
+
+		assert.Len(t, markers, 1)
+
+		markerFilter.Not = &models.SceneMarkerFilterType{
+			Tags: &models.MultiCriterionInput{
+				Value:    []string{strconv.Itoa(tagIDs[tagIdxWithMarker])},
+				Modifier: models.CriterionModifierIncludes,
+			},
+		}
+
+		markers, _, err = mqb.Query(&markerFilter, nil)
+		if err != nil {
+			t.Errorf("Error querying markers: %s", err.Error())
+		}
+
+		assert.Len(t, markers, 0)
+
+		return nil
+	})
+}
+
 // TODO Update
 // TODO Destroy
 // TODO Find
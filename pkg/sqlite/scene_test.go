@@ -4,6 +4,7 @@ package sqlite_test
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -138,6 +139,105 @@ func TestSceneQueryQ(t *testing.T) {
 	})
 }
 
+func TestSceneQueryQFullText(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		sqb := r.Scene()
+
+		verify := func(q string, expectedIdxs ...int) {
+			t.Helper()
+			scenes := queryScene(t, sqb, nil, &models.FindFilterType{
+				Q: &q,
+			})
+
+			var ids []int
+			for _, scene := range scenes {
+				ids = append(ids, scene.ID)
+			}
+
+			var expected []int
+			for _, idx := range expectedIdxs {
+				expected = append(expected, sceneIDs[idx])
+			}
+
+			assert.ElementsMatch(t, expected, ids, q)
+		}
+
+		// any of the phrases
+		verify(`"scene_0002_Title" "scene_0003_Title"`, 2, 3)
+		// required terms
+		verify(`+scene_0002 +Title`, 2)
+		verify(`scene_0002_Title +scene_0003_Details`, 3)
+		// excluded terms
+		verify(`"scene_0002_Title" "scene_0003_Title" -scene_0003`, 2)
+		// prefix matching
+		verify(`"scene_0002_Ti"*`, 2)
+		verify(`scene_0002_Ti`, 2)
+		verify(`"scene_0002_Ti"`)
+
+		return nil
+	})
+}
+
+func TestSceneQueryQRelevance(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		sqb := r.Scene()
+
+		q := `"scene_0002_Details" Title`
+		sort := "relevance"
+		direction := models.SortDirectionEnumDesc
+		scenes := queryScene(t, sqb, nil, &models.FindFilterType{
+			Q:         &q,
+			Sort:      &sort,
+			Direction: &direction,
+		})
+
+		// all scenes match the title, but only one matches the details
+		assert.Len(t, scenes, totalScenes)
+		assert.Equal(t, sceneIDs[2], scenes[0].ID)
+
+		return nil
+	})
+}
+
+func TestSceneQueryQMarkerTitle(t *testing.T) {
+	errRollback := errors.New("rollback")
+
+	err := withTxn(func(r models.Repository) error {
+		mqb := r.SceneMarker()
+
+		marker, err := mqb.Find(markerIDs[0])
+		if err != nil {
+			return err
+		}
+
+		const title = "TestSceneQueryQMarkerTitle"
+		marker.Title = title
+		if _, err := mqb.Update(*marker); err != nil {
+			return err
+		}
+
+		sceneQueryQ(t, r.Scene(), title, sceneIdxWithMarker)
+
+		// markers should match the title of their scene
+		q := getSceneStringValue(sceneIdxWithMarker, titleField)
+		markers, _, err := mqb.Query(nil, &models.FindFilterType{
+			Q: &q,
+		})
+		if err != nil {
+			return err
+		}
+
+		assert.Len(t, markers, 1)
+
+		// roll back the marker change
+		return errRollback
+	})
+
+	if err != errRollback {
+		t.Error(err.Error())
+	}
+}
+
 func queryScene(t *testing.T, sqb models.SceneReader, sceneFilter *models.SceneFilterType, findFilter *models.FindFilterType) []*models.Scene {
 	t.Helper()
 	scenes, _, err := sqb.Query(sceneFilter, findFilter)
@@ -220,6 +320,107 @@ func TestSceneQueryPathOr(t *testing.T) {
 	})
 }
 
+func TestSceneQueryPerformerCount(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		sqb := r.Scene()
+
+		sceneFilter := models.SceneFilterType{
+			PerformerCount: &models.IntCriterionInput{
+				Value:    1,
+				Modifier: models.CriterionModifierGreaterThan,
+			},
+		}
+
+		scenes := queryScene(t, sqb, &sceneFilter, nil)
+
+		assert.Len(t, scenes, 1)
+		assert.Equal(t, sceneIDs[sceneIdxWithTwoPerformers], scenes[0].ID)
+
+		sceneFilter.PerformerCount.Modifier = models.CriterionModifierEquals
+		scenes = queryScene(t, sqb, &sceneFilter, nil)
+
+		assert.Len(t, scenes, 1)
+		assert.Equal(t, sceneIDs[sceneIdxWithPerformer], scenes[0].ID)
+
+		return nil
+	})
+}
+
+func TestSceneQueryTagCountOrPerformerCount(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		sqb := r.Scene()
+
+		sceneFilter := models.SceneFilterType{
+			TagCount: &models.IntCriterionInput{
+				Value:    1,
+				Modifier: models.CriterionModifierGreaterThan,
+			},
+			Or: &models.SceneFilterType{
+				PerformerCount: &models.IntCriterionInput{
+					Value:    1,
+					Modifier: models.CriterionModifierGreaterThan,
+				},
+			},
+		}
+
+		scenes := queryScene(t, sqb, &sceneFilter, nil)
+
+		var ids []int
+		for _, s := range scenes {
+			ids = append(ids, s.ID)
+		}
+
+		assert.Len(t, ids, 2)
+		assert.Contains(t, ids, sceneIDs[sceneIdxWithTwoTags])
+		assert.Contains(t, ids, sceneIDs[sceneIdxWithTwoPerformers])
+
+		return nil
+	})
+}
+
+func TestSceneQueryFileSize(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		sqb := r.Scene()
+
+		// sizes greater than 32 bits must be supported
+		const size = int64(1) << 33
+		sceneID := sceneIDs[sceneIdxWithGallery]
+
+		if _, err := sqb.Update(models.ScenePartial{
+			ID:   sceneID,
+			Size: &sql.NullString{String: strconv.FormatInt(size+1, 10), Valid: true},
+		}); err != nil {
+			return err
+		}
+
+		defer func() {
+			_, _ = sqb.Update(models.ScenePartial{
+				ID:   sceneID,
+				Size: &sql.NullString{},
+			})
+		}()
+
+		sceneFilter := models.SceneFilterType{
+			FileSize: &models.Int64CriterionInput{
+				Value:    size,
+				Modifier: models.CriterionModifierGreaterThan,
+			},
+		}
+
+		scenes := queryScene(t, sqb, &sceneFilter, nil)
+		assert.Len(t, scenes, 1)
+		assert.Equal(t, sceneID, scenes[0].ID)
+
+		sceneFilter.FileSize.Modifier = models.CriterionModifierLessThan
+		scenes = queryScene(t, sqb, &sceneFilter, nil)
+		for _, s := range scenes {
+			assert.NotEqual(t, sceneID, s.ID)
+		}
+
+		return nil
+	})
+}
+
 func TestSceneQueryPathAndRating(t *testing.T) {
 	const sceneIdx = 1
 	scenePath := getSceneStringValue(sceneIdx, "Path")
@@ -8,6 +8,7 @@ import (
 )
 
 const studioTable = "studios"
+const studioCustomFieldsTable = "studio_custom_fields"
 const studioIDColumn = "studio_id"
 
 type studioQueryBuilder struct {
@@ -125,6 +126,50 @@ func (qb *studioQueryBuilder) AllSlim() ([]*models.Studio, error) {
 	return qb.queryStudios("SELECT studios.id, studios.name, studios.parent_id FROM studios "+qb.getStudioSort(nil), nil)
 }
 
+func (qb *studioQueryBuilder) validateFilter(filter *models.StudioFilterType) error {
+	if err := validateFilterCombination(filter.And != nil, filter.Or != nil, filter.Not != nil); err != nil {
+		return err
+	}
+
+	switch {
+	case filter.And != nil:
+		return qb.validateFilter(filter.And)
+	case filter.Or != nil:
+		return qb.validateFilter(filter.Or)
+	case filter.Not != nil:
+		return qb.validateFilter(filter.Not)
+	}
+
+	return nil
+}
+
+func (qb *studioQueryBuilder) makeFilter(filter *models.StudioFilterType) *filterBuilder {
+	query := &filterBuilder{}
+
+	if filter.And != nil {
+		query.and(qb.makeFilter(filter.And))
+	}
+	if filter.Or != nil {
+		query.or(qb.makeFilter(filter.Or))
+	}
+	if filter.Not != nil {
+		query.not(qb.makeFilter(filter.Not))
+	}
+
+	query.handleCriterionFunc(studioParentsCriterionHandler(filter.Parents))
+	query.handleCriterionFunc(studioStashIDsHandler(qb, filter.StashID))
+	query.handleCriterionFunc(studioIsMissingCriterionHandler(qb, filter.IsMissing))
+
+	query.handleCriterionFunc(countCriterionHandler(filter.SceneCount, studioTable, sceneTable, studioIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(filter.ImageCount, studioTable, imageTable, studioIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(filter.GalleryCount, studioTable, galleryTable, studioIDColumn))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.CreatedAt, "studios.created_at"))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.UpdatedAt, "studios.updated_at"))
+	query.handleCriterionFunc(customFieldsCriterionHandler(filter.CustomFields, studioTable, studioCustomFieldsTable, studioIDColumn))
+
+	return query
+}
+
 func (qb *studioQueryBuilder) Query(studioFilter *models.StudioFilterType, findFilter *models.FindFilterType) ([]*models.Studio, int, error) {
 	if studioFilter == nil {
 		studioFilter = &models.StudioFilterType{}
@@ -133,57 +178,30 @@ func (qb *studioQueryBuilder) Query(studioFilter *models.StudioFilterType, findF
 		findFilter = &models.FindFilterType{}
 	}
 
-	var whereClauses []string
-	var havingClauses []string
-	var args []interface{}
-	body := selectDistinctIDs("studios")
-	body += `
-		left join scenes on studios.id = scenes.studio_id		
-		left join studio_stash_ids on studio_stash_ids.studio_id = studios.id
-	`
+	query := qb.newQuery()
+
+	query.body = selectDistinctIDs(studioTable)
+
+	// required for sorting by scene count
+	query.join(sceneTable, "", "studios.id = scenes.studio_id")
 
 	if q := findFilter.Q; q != nil && *q != "" {
 		searchColumns := []string{"studios.name"}
 
 		clause, thisArgs := getSearchBinding(searchColumns, *q, false)
-		whereClauses = append(whereClauses, clause)
-		args = append(args, thisArgs...)
+		query.addWhere(clause)
+		query.addArg(thisArgs...)
 	}
 
-	if parentsFilter := studioFilter.Parents; parentsFilter != nil && len(parentsFilter.Value) > 0 {
-		body += `
-			left join studios as parent_studio on parent_studio.id = studios.parent_id
-		`
-
-		for _, studioID := range parentsFilter.Value {
-			args = append(args, studioID)
-		}
-
-		whereClause, havingClause := getMultiCriterionClause("studios", "parent_studio", "", "", "parent_id", parentsFilter)
-		whereClauses = appendClause(whereClauses, whereClause)
-		havingClauses = appendClause(havingClauses, havingClause)
-	}
-
-	if stashIDFilter := studioFilter.StashID; stashIDFilter != nil {
-		whereClauses = append(whereClauses, "studio_stash_ids.stash_id = ?")
-		args = append(args, stashIDFilter)
+	if err := qb.validateFilter(studioFilter); err != nil {
+		return nil, 0, err
 	}
+	filter := qb.makeFilter(studioFilter)
 
-	if isMissingFilter := studioFilter.IsMissing; isMissingFilter != nil && *isMissingFilter != "" {
-		switch *isMissingFilter {
-		case "image":
-			body += `left join studios_image on studios_image.studio_id = studios.id
-			`
-			whereClauses = appendClause(whereClauses, "studios_image.studio_id IS NULL")
-		case "stash_id":
-			whereClauses = appendClause(whereClauses, "studio_stash_ids.studio_id IS NULL")
-		default:
-			whereClauses = appendClause(whereClauses, "studios."+*isMissingFilter+" IS NULL")
-		}
-	}
+	query.addFilter(filter)
 
-	sortAndPagination := qb.getStudioSort(findFilter) + getPagination(findFilter)
-	idsResult, countResult, err := qb.executeFindQuery(body, args, sortAndPagination, whereClauses, havingClauses)
+	query.sortAndPagination = qb.getStudioSort(findFilter) + getPagination(findFilter)
+	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
 	}
@@ -201,6 +219,48 @@ func (qb *studioQueryBuilder) Query(studioFilter *models.StudioFilterType, findF
 	return studios, countResult, nil
 }
 
+func studioParentsCriterionHandler(parents *models.MultiCriterionInput) criterionHandlerFunc {
+	addJoinsFunc := func(f *filterBuilder) {
+		f.addJoin(studioTable, "parent_studio", "parent_studio.id = studios.parent_id")
+	}
+	h := multiCriterionHandlerBuilder{
+		primaryTable: studioTable,
+		foreignTable: "parent_studio",
+		joinTable:    "",
+		primaryFK:    studioIDColumn,
+		foreignFK:    "parent_id",
+		addJoinsFunc: addJoinsFunc,
+	}
+
+	return h.handler(parents)
+}
+
+func studioStashIDsHandler(qb *studioQueryBuilder, stashID *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if stashID != nil && *stashID != "" {
+			qb.stashIDRepository().join(f, "studio_stash_ids", "studios.id")
+			stringLiteralCriterionHandler(stashID, "studio_stash_ids.stash_id")(f)
+		}
+	}
+}
+
+func studioIsMissingCriterionHandler(qb *studioQueryBuilder, isMissing *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if isMissing != nil && *isMissing != "" {
+			switch *isMissing {
+			case "image":
+				f.addJoin("studios_image", "", "studios_image.studio_id = studios.id")
+				f.addWhere("studios_image.studio_id IS NULL")
+			case "stash_id":
+				qb.stashIDRepository().join(f, "studio_stash_ids", "studios.id")
+				f.addWhere("studio_stash_ids.studio_id IS NULL")
+			default:
+				f.addWhere("studios." + *isMissing + " IS NULL")
+			}
+		}
+	}
+}
+
 func (qb *studioQueryBuilder) getStudioSort(findFilter *models.FindFilterType) string {
 	var sort string
 	var direction string
@@ -238,7 +298,7 @@ func (qb *studioQueryBuilder) imageRepository() *imageRepository {
 			tableName: "studios_image",
 			idColumn:  studioIDColumn,
 		},
-		imageColumn: "image",
+		blobColumn: "image_blob",
 	}
 }
 
@@ -246,6 +306,10 @@ func (qb *studioQueryBuilder) GetImage(studioID int) ([]byte, error) {
 	return qb.imageRepository().get(studioID)
 }
 
+func (qb *studioQueryBuilder) GetImageChecksum(studioID int) (string, error) {
+	return qb.imageRepository().getChecksum(studioID)
+}
+
 func (qb *studioQueryBuilder) HasImage(studioID int) (bool, error) {
 	return qb.imageRepository().exists(studioID)
 }
@@ -275,3 +339,21 @@ func (qb *studioQueryBuilder) GetStashIDs(studioID int) ([]*models.StashID, erro
 func (qb *studioQueryBuilder) UpdateStashIDs(studioID int, stashIDs []models.StashID) error {
 	return qb.stashIDRepository().replace(studioID, stashIDs)
 }
+
+func (qb *studioQueryBuilder) customFieldsRepository() *customFieldsRepository {
+	return &customFieldsRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: studioCustomFieldsTable,
+			idColumn:  studioIDColumn,
+		},
+	}
+}
+
+func (qb *studioQueryBuilder) GetCustomFields(studioID int) (models.CustomFieldMap, error) {
+	return qb.customFieldsRepository().get(studioID)
+}
+
+func (qb *studioQueryBuilder) UpdateCustomFields(studioID int, fields models.CustomFieldMap) error {
+	return qb.customFieldsRepository().replace(studioID, fields)
+}
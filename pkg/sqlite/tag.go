@@ -184,6 +184,47 @@ func (qb *tagQueryBuilder) AllSlim() ([]*models.Tag, error) {
 	return qb.queryTags("SELECT tags.id, tags.name FROM tags "+qb.getTagSort(nil), nil)
 }
 
+func (qb *tagQueryBuilder) validateFilter(filter *models.TagFilterType) error {
+	if err := validateFilterCombination(filter.And != nil, filter.Or != nil, filter.Not != nil); err != nil {
+		return err
+	}
+
+	switch {
+	case filter.And != nil:
+		return qb.validateFilter(filter.And)
+	case filter.Or != nil:
+		return qb.validateFilter(filter.Or)
+	case filter.Not != nil:
+		return qb.validateFilter(filter.Not)
+	}
+
+	return nil
+}
+
+func (qb *tagQueryBuilder) makeFilter(filter *models.TagFilterType) *filterBuilder {
+	query := &filterBuilder{}
+
+	if filter.And != nil {
+		query.and(qb.makeFilter(filter.And))
+	}
+	if filter.Or != nil {
+		query.or(qb.makeFilter(filter.Or))
+	}
+	if filter.Not != nil {
+		query.not(qb.makeFilter(filter.Not))
+	}
+
+	query.handleCriterionFunc(tagIsMissingCriterionHandler(filter.IsMissing))
+	query.handleCriterionFunc(countCriterionHandler(filter.SceneCount, tagTable, scenesTagsTable, tagIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(filter.ImageCount, tagTable, imagesTagsTable, tagIDColumn))
+	query.handleCriterionFunc(countCriterionHandler(filter.GalleryCount, tagTable, galleriesTagsTable, tagIDColumn))
+	query.handleCriterionFunc(tagMarkerCountCriterionHandler(filter.MarkerCount))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.CreatedAt, "tags.created_at"))
+	query.handleCriterionFunc(timestampCriterionHandler(filter.UpdatedAt, "tags.updated_at"))
+
+	return query
+}
+
 func (qb *tagQueryBuilder) Query(tagFilter *models.TagFilterType, findFilter *models.FindFilterType) ([]*models.Tag, int, error) {
 	if tagFilter == nil {
 		tagFilter = &models.TagFilterType{}
@@ -196,55 +237,24 @@ func (qb *tagQueryBuilder) Query(tagFilter *models.TagFilterType, findFilter *mo
 
 	query.body = selectDistinctIDs(tagTable)
 
-	/*
-		query.body += `
-		left join tags_image on tags_image.tag_id = tags.id
-		left join scenes_tags on scenes_tags.tag_id = tags.id
-		left join scene_markers_tags on scene_markers_tags.tag_id = tags.id
-		left join scene_markers on scene_markers.primary_tag_id = tags.id OR scene_markers.id = scene_markers_tags.scene_marker_id
-		left join scenes on scenes_tags.scene_id = scenes.id`
-	*/
-
-	// the presence of joining on scene_markers.primary_tag_id and scene_markers_tags.tag_id
-	// appears to confuse sqlite and causes serious performance issues.
-	// Disabling querying/sorting on marker count for now.
-
-	query.body += ` 
-	left join tags_image on tags_image.tag_id = tags.id
-	left join scenes_tags on scenes_tags.tag_id = tags.id
-	left join scenes on scenes_tags.scene_id = scenes.id`
+	// required for sorting by scene count
+	query.join(scenesTagsTable, "", "scenes_tags.tag_id = tags.id")
+	query.join(sceneTable, "", "scenes_tags.scene_id = scenes.id")
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"tags.name"}
-		clause, thisArgs := getSearchBinding(searchColumns, *q, false)
-		query.addWhere(clause)
-		query.addArg(thisArgs...)
-	}
-
-	if isMissingFilter := tagFilter.IsMissing; isMissingFilter != nil && *isMissingFilter != "" {
-		switch *isMissingFilter {
-		case "image":
-			query.addWhere("tags_image.tag_id IS NULL")
-		default:
-			query.addWhere("tags." + *isMissingFilter + " IS NULL")
+		if searchQuery := parseFTSQuery(*q); !searchQuery.empty() {
+			clause, thisArgs := getFTSSearchClause("tags_fts", "tags.id", searchQuery)
+			query.addWhere(clause)
+			query.addArg(thisArgs...)
 		}
 	}
 
-	if sceneCount := tagFilter.SceneCount; sceneCount != nil {
-		clause, count := getIntCriterionWhereClause("count(distinct scenes_tags.scene_id)", *sceneCount)
-		query.addHaving(clause)
-		if count == 1 {
-			query.addArg(sceneCount.Value)
-		}
+	if err := qb.validateFilter(tagFilter); err != nil {
+		return nil, 0, err
 	}
+	filter := qb.makeFilter(tagFilter)
 
-	// if markerCount := tagFilter.MarkerCount; markerCount != nil {
-	// 	clause, count := getIntCriterionWhereClause("count(distinct scene_markers.id)", *markerCount)
-	// 	query.addHaving(clause)
-	// 	if count == 1 {
-	// 		query.addArg(markerCount.Value)
-	// 	}
-	// }
+	query.addFilter(filter)
 
 	query.sortAndPagination = qb.getTagSort(findFilter) + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
@@ -264,6 +274,32 @@ func (qb *tagQueryBuilder) Query(tagFilter *models.TagFilterType, findFilter *mo
 	return tags, countResult, nil
 }
 
+func tagIsMissingCriterionHandler(isMissing *string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if isMissing != nil && *isMissing != "" {
+			switch *isMissing {
+			case "image":
+				f.addJoin("tags_image", "", "tags_image.tag_id = tags.id")
+				f.addWhere("tags_image.tag_id IS NULL")
+			default:
+				f.addWhere("tags." + *isMissing + " IS NULL")
+			}
+		}
+	}
+}
+
+func tagMarkerCountCriterionHandler(markerCount *models.IntCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if markerCount != nil {
+			// joining on scene_markers.primary_tag_id and scene_markers_tags.tag_id
+			// causes serious performance issues in sqlite, so use a correlated
+			// subquery instead.
+			const column = "(SELECT COUNT(*) FROM scene_markers WHERE scene_markers.primary_tag_id = tags.id OR scene_markers.id IN (SELECT scene_marker_id FROM scene_markers_tags WHERE scene_markers_tags.tag_id = tags.id))"
+			intCriterionHandler(markerCount, column)(f)
+		}
+	}
+}
+
 func (qb *tagQueryBuilder) getTagSort(findFilter *models.FindFilterType) string {
 	var sort string
 	var direction string
@@ -273,6 +309,13 @@ func (qb *tagQueryBuilder) getTagSort(findFilter *models.FindFilterType) string
 	} else {
 		sort = findFilter.GetSort("name")
 		direction = findFilter.GetDirection()
+
+		if sort == relevanceSort {
+			if ret := getFTSRelevanceSort("tags_fts", "tags.id", findFilter.Q, direction); ret != "" {
+				return ret
+			}
+			sort = "name"
+		}
 	}
 	return getSort(sort, direction, "tags")
 }
@@ -301,7 +344,7 @@ func (qb *tagQueryBuilder) imageRepository() *imageRepository {
 			tableName: "tags_image",
 			idColumn:  tagIDColumn,
 		},
-		imageColumn: "image",
+		blobColumn: "image_blob",
 	}
 }
 
@@ -309,6 +352,10 @@ func (qb *tagQueryBuilder) GetImage(tagID int) ([]byte, error) {
 	return qb.imageRepository().get(tagID)
 }
 
+func (qb *tagQueryBuilder) GetImageChecksum(tagID int) (string, error) {
+	return qb.imageRepository().getChecksum(tagID)
+}
+
 func (qb *tagQueryBuilder) HasImage(tagID int) (bool, error) {
 	return qb.imageRepository().exists(tagID)
 }
@@ -194,26 +194,24 @@ func verifyTagSceneCount(t *testing.T, sceneCountCriterion models.IntCriterionIn
 	})
 }
 
-// disabled due to performance issues
-
-// func TestTagQueryMarkerCount(t *testing.T) {
-// 	countCriterion := models.IntCriterionInput{
-// 		Value:    1,
-// 		Modifier: models.CriterionModifierEquals,
-// 	}
+func TestTagQueryMarkerCount(t *testing.T) {
+	countCriterion := models.IntCriterionInput{
+		Value:    1,
+		Modifier: models.CriterionModifierEquals,
+	}
 
-// 	verifyTagMarkerCount(t, countCriterion)
+	verifyTagMarkerCount(t, countCriterion)
 
-// 	countCriterion.Modifier = models.CriterionModifierNotEquals
-// 	verifyTagMarkerCount(t, countCriterion)
+	countCriterion.Modifier = models.CriterionModifierNotEquals
+	verifyTagMarkerCount(t, countCriterion)
 
-// 	countCriterion.Modifier = models.CriterionModifierLessThan
-// 	verifyTagMarkerCount(t, countCriterion)
+	countCriterion.Modifier = models.CriterionModifierLessThan
+	verifyTagMarkerCount(t, countCriterion)
 
-// 	countCriterion.Value = 0
-// 	countCriterion.Modifier = models.CriterionModifierGreaterThan
-// 	verifyTagMarkerCount(t, countCriterion)
-// }
+	countCriterion.Value = 0
+	countCriterion.Modifier = models.CriterionModifierGreaterThan
+	verifyTagMarkerCount(t, countCriterion)
+}
 
 func verifyTagMarkerCount(t *testing.T, markerCountCriterion models.IntCriterionInput) {
 	withTxn(func(r models.Repository) error {
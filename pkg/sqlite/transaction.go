@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/stashapp/stash/pkg/database"
@@ -76,6 +77,11 @@ func (t *transaction) ensureTx() {
 	}
 }
 
+func (t *transaction) AuditLog() models.AuditLogReaderWriter {
+	t.ensureTx()
+	return NewAuditLogReaderWriter(t.tx)
+}
+
 func (t *transaction) Gallery() models.GalleryReaderWriter {
 	t.ensureTx()
 	return NewGalleryReaderWriter(t.tx)
@@ -106,6 +112,11 @@ func (t *transaction) Scene() models.SceneReaderWriter {
 	return NewSceneReaderWriter(t.tx)
 }
 
+func (t *transaction) SavedFilter() models.SavedFilterReaderWriter {
+	t.ensureTx()
+	return NewSavedFilterReaderWriter(t.tx)
+}
+
 func (t *transaction) ScrapedItem() models.ScrapedItemReaderWriter {
 	t.ensureTx()
 	return NewScrapedItemReaderWriter(t.tx)
@@ -139,6 +150,10 @@ func (t *ReadTransaction) Repository() models.ReaderRepository {
 	return t
 }
 
+func (t *ReadTransaction) AuditLog() models.AuditLogReader {
+	return NewAuditLogReaderWriter(database.DB)
+}
+
 func (t *ReadTransaction) Gallery() models.GalleryReader {
 	return NewGalleryReaderWriter(database.DB)
 }
@@ -163,6 +178,10 @@ func (t *ReadTransaction) Scene() models.SceneReader {
 	return NewSceneReaderWriter(database.DB)
 }
 
+func (t *ReadTransaction) SavedFilter() models.SavedFilterReader {
+	return NewSavedFilterReaderWriter(database.DB)
+}
+
 func (t *ReadTransaction) ScrapedItem() models.ScrapedItemReader {
 	return NewScrapedItemReaderWriter(database.DB)
 }
@@ -175,21 +194,93 @@ func (t *ReadTransaction) Tag() models.TagReader {
 	return NewTagReaderWriter(database.DB)
 }
 
+// ErrDatabaseLocked is returned when starting a transaction while the
+// database is locked.
+var ErrDatabaseLocked = errors.New("the database is locked")
+
 type TransactionManager struct {
 	// only allow one write transaction at a time
 	c chan struct{}
+
+	// guards the database from being closed while transactions are running
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	active int
+	locked bool
 }
 
 func NewTransactionManager() *TransactionManager {
-	return &TransactionManager{
+	ret := &TransactionManager{
 		c: make(chan struct{}, 1),
 	}
+	ret.cond = sync.NewCond(&ret.mutex)
+	return ret
 }
 
 func (t *TransactionManager) WithTxn(ctx context.Context, fn func(r models.Repository) error) error {
+	if err := t.acquire(); err != nil {
+		return err
+	}
+	defer t.release()
+
 	return models.WithTxn(&transaction{Ctx: ctx}, fn)
 }
 
 func (t *TransactionManager) WithReadTxn(ctx context.Context, fn func(r models.ReaderRepository) error) error {
+	if err := t.acquire(); err != nil {
+		return err
+	}
+	defer t.release()
+
 	return models.WithROTxn(&ReadTransaction{}, fn)
 }
+
+// Lock waits for running transactions to finish, and causes transactions
+// started before Unlock is called to fail with ErrDatabaseLocked. New
+// transactions fail rather than wait, so that transactions nested in a
+// running transaction cannot deadlock. The database may be closed and
+// replaced while it is locked. Returns ErrDatabaseLocked if the database is
+// already locked.
+func (t *TransactionManager) Lock() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.locked {
+		return ErrDatabaseLocked
+	}
+
+	t.locked = true
+	for t.active > 0 {
+		t.cond.Wait()
+	}
+
+	return nil
+}
+
+// Unlock allows transactions to run again after Lock.
+func (t *TransactionManager) Unlock() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.locked = false
+}
+
+func (t *TransactionManager) acquire() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.locked {
+		return ErrDatabaseLocked
+	}
+
+	t.active++
+	return nil
+}
+
+func (t *TransactionManager) release() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.active--
+	t.cond.Broadcast()
+}
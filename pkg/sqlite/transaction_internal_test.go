@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionManagerLock(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewTransactionManager()
+
+	// lock should wait for running transactions to finish
+	assert.Nil(m.acquire())
+
+	locked := make(chan error)
+	go func() {
+		locked <- m.Lock()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock did not wait for the running transaction")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.release()
+	assert.Nil(<-locked)
+
+	// transactions should fail while locked
+	assert.Equal(ErrDatabaseLocked, m.WithTxn(context.TODO(), func(r models.Repository) error {
+		t.Error("transaction ran while locked")
+		return nil
+	}))
+	assert.Equal(ErrDatabaseLocked, m.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		t.Error("read transaction ran while locked")
+		return nil
+	}))
+
+	// locking again should fail
+	assert.Equal(ErrDatabaseLocked, m.Lock())
+
+	m.Unlock()
+	assert.Nil(m.acquire())
+	m.release()
+}
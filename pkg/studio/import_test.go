@@ -156,6 +156,35 @@ func TestImporterPostImport(t *testing.T) {
 	readerWriter.AssertExpectations(t)
 }
 
+func TestImporterPostImportCustomFields(t *testing.T) {
+	readerWriter := &mocks.StudioReaderWriter{}
+
+	customFields := map[string]interface{}{
+		"source": "disc",
+		"count":  float64(2),
+	}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		Input: jsonschema.Studio{
+			CustomFields: customFields,
+		},
+	}
+
+	updateErr := errors.New("UpdateCustomFields error")
+
+	readerWriter.On("UpdateCustomFields", studioID, models.CustomFieldMap(customFields)).Return(nil).Once()
+	readerWriter.On("UpdateCustomFields", errStudioID, models.CustomFieldMap(customFields)).Return(updateErr).Once()
+
+	err := i.PostImport(studioID)
+	assert.Nil(t, err)
+
+	err = i.PostImport(errStudioID)
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
 func TestImporterFindExistingID(t *testing.T) {
 	readerWriter := &mocks.StudioReaderWriter{}
 
@@ -49,22 +49,30 @@ func GetBase64StringFromData(data []byte) string {
 	//return result
 }
 
+// MatchesETag returns true if the If-None-Match header of the request
+// contains the provided ETag.
+func MatchesETag(r *http.Request, etag string) bool {
+	match := r.Header.Get("If-None-Match")
+	return match != "" && strings.Contains(match, etag)
+}
+
 func ServeImage(image []byte, w http.ResponseWriter, r *http.Request) error {
 	etag := fmt.Sprintf("%x", md5.Sum(image))
 
-	if match := r.Header.Get("If-None-Match"); match != "" {
-		if strings.Contains(match, etag) {
-			w.WriteHeader(http.StatusNotModified)
-			return nil
-		}
+	if MatchesETag(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
 	}
 
-	contentType := http.DetectContentType(image)
-	if contentType == "text/xml; charset=utf-8" || contentType == "text/plain; charset=utf-8" {
-		contentType = "image/svg+xml"
-	}
+	// the content type is detected if it has not been set
+	if w.Header().Get("Content-Type") == "" {
+		contentType := http.DetectContentType(image)
+		if contentType == "text/xml; charset=utf-8" || contentType == "text/plain; charset=utf-8" {
+			contentType = "image/svg+xml"
+		}
 
-	w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Type", contentType)
+	}
 	w.Header().Add("Etag", etag)
 	_, err := w.Write(image)
 	return err
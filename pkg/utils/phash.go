@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// MaxHammingDistance is the largest hamming distance between two 64 bit
+// hashes.
+const MaxHammingDistance = 64
+
+// HammingDistance returns the number of bits that differ between the
+// provided hashes.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// GroupSimilarHashes returns groups of the indexes of hashes that are within
+// the provided hamming distance of another hash in the group. Only groups
+// with more than one hash are returned. The indexes in each group are
+// sorted, and the groups are sorted by their first index.
+func GroupSimilarHashes(hashes []uint64, distance int) [][]int {
+	// identical hashes are compared once
+	var unique []uint64
+	indexes := make(map[uint64][]int)
+	for i, h := range hashes {
+		if _, found := indexes[h]; !found {
+			unique = append(unique, h)
+		}
+		indexes[h] = append(indexes[h], i)
+	}
+
+	parents := make([]int, len(unique))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	if distance > 0 {
+		// if hashes differ by at most distance bits, then at least one of
+		// distance+1 blocks of the bits is the same in both hashes, so only
+		// hashes with a block in common are compared
+		blocks := distance + 1
+		if blocks > 64 {
+			blocks = 64
+		}
+
+		for b := 0; b < blocks; b++ {
+			start := uint(b * 64 / blocks)
+			end := uint((b + 1) * 64 / blocks)
+			mask := (^uint64(0) >> (64 - (end - start))) << start
+
+			buckets := make(map[uint64][]int)
+			for i, h := range unique {
+				buckets[h&mask] = append(buckets[h&mask], i)
+			}
+
+			for _, bucket := range buckets {
+				for x := 0; x < len(bucket); x++ {
+					for y := x + 1; y < len(bucket); y++ {
+						if HammingDistance(unique[bucket[x]], unique[bucket[y]]) <= distance {
+							parents[find(bucket[x])] = find(bucket[y])
+						}
+					}
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i, h := range unique {
+		root := find(i)
+		groups[root] = append(groups[root], indexes[h]...)
+	}
+
+	var ret [][]int
+	for _, g := range groups {
+		if len(g) > 1 {
+			sort.Ints(g)
+			ret = append(ret, g)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i][0] < ret[j][0]
+	})
+
+	return ret
+}
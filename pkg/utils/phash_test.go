@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHammingDistance(t *testing.T) {
+	assert.Equal(t, 0, HammingDistance(0xf0f0, 0xf0f0))
+	assert.Equal(t, 2, HammingDistance(0xf0f0, 0xf0f3))
+	assert.Equal(t, 64, HammingDistance(0, ^uint64(0)))
+}
+
+func TestGroupSimilarHashes(t *testing.T) {
+	hashes := []uint64{
+		0x0000000000000000,
+		0xffffffffffffffff,
+		0x0000000000000003, // distance 2 from 0
+		0xfffffffffffffffe, // distance 1 from 1
+		0x0000000000000000, // identical to 0
+		0x00000000ffff0000,
+		0x000000000000000f, // distance 2 from 2, 4 from 0
+	}
+
+	tests := []struct {
+		distance int
+		want     [][]int
+	}{
+		{0, [][]int{{0, 4}}},
+		{1, [][]int{{0, 4}, {1, 3}}},
+		{2, [][]int{{0, 2, 4, 6}, {1, 3}}},
+		{16, [][]int{{0, 2, 4, 5, 6}, {1, 3}}},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, GroupSimilarHashes(hashes, tc.distance), "distance %d", tc.distance)
+	}
+
+	assert.Len(t, GroupSimilarHashes(nil, 4), 0)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+
+	"github.com/stashapp/stash/pkg/scraper"
+)
+
+const scraperUsage = `usage: stash scraper test <scraper.yml> [flags]
+
+Runs an xpath or json scraper against a URL or a saved html/json document,
+printing the values found for each field and the results of the scrape.
+`
+
+// runScraperCommand runs the scraper subcommand with the provided arguments.
+// Returns the exit code of the command.
+func runScraperCommand(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprint(os.Stderr, scraperUsage)
+		return 2
+	}
+
+	flags := pflag.NewFlagSet("scraper test", pflag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, scraperUsage)
+		flags.PrintDefaults()
+	}
+
+	var options scraper.HarnessOptions
+	var expectedPath string
+	flags.StringVar(&options.URL, "url", "", "url to scrape")
+	flags.StringVar(&options.File, "html", "", "saved html document to scrape instead of the url")
+	flags.StringVar(&options.File, "json", "", "saved json document to scrape instead of the url")
+	flags.StringVar(&options.Scraper, "scraper", "", "name of the xpath or json scraper to run")
+	flags.StringVar(&options.GlobalConfig.UserAgent, "user-agent", "", "user agent to use when loading urls")
+	flags.StringVar(&options.GlobalConfig.CDPPath, "cdp-path", "", "path to chrome or address of a remote chrome instance")
+	flags.StringVar(&expectedPath, "expected", "", "yaml file of expected results to compare the results with")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	options.ConfigPath = flags.Arg(0)
+	options.Out = os.Stdout
+
+	results, err := scraper.RunHarness(options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error running scraper: %s\n", err.Error())
+		return 1
+	}
+
+	out, err := yaml.Marshal(results)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing results: %s\n", err.Error())
+		return 1
+	}
+	fmt.Printf("== results ==\n%s", out)
+
+	if expectedPath == "" {
+		return 0
+	}
+
+	expected, err := scraper.LoadHarnessResults(expectedPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		return 1
+	}
+
+	diff := results.Diff(expected)
+	if len(diff) > 0 {
+		fmt.Println("== differences ==")
+		for _, d := range diff {
+			fmt.Println(d)
+		}
+		return 1
+	}
+
+	fmt.Println("results match expected results")
+	return 0
+}
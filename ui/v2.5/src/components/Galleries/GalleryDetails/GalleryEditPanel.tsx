@@ -8,6 +8,7 @@ import {
   useGalleryCreate,
   useGalleryUpdate,
   useListGalleryScrapers,
+  changeSourceContext,
 } from "src/core/StashService";
 import {
   PerformerSelect,
@@ -75,6 +76,7 @@ export const GalleryEditPanel: React.FC<
 
   const [createGallery] = useGalleryCreate();
   const [updateGallery] = useGalleryUpdate();
+  const [scraped, setScraped] = useState(false);
 
   useEffect(() => {
     if (isVisible) {
@@ -141,6 +143,7 @@ export const GalleryEditPanel: React.FC<
           variables: {
             input: getGalleryInput(),
           },
+          context: changeSourceContext(scraped),
         });
         if (result.data?.galleryCreate) {
           history.push(`/galleries/${result.data.galleryCreate.id}`);
@@ -151,8 +154,10 @@ export const GalleryEditPanel: React.FC<
           variables: {
             input: getGalleryInput() as GQL.GalleryUpdateInput,
           },
+          context: changeSourceContext(scraped),
         });
         if (result.data?.galleryUpdate) {
+          setScraped(false);
           Toast.success({ content: "Updated gallery" });
         }
       }
@@ -196,6 +201,7 @@ export const GalleryEditPanel: React.FC<
   function updateGalleryFromScrapedGallery(
     galleryData: GQL.ScrapedGalleryDataFragment
   ) {
+    setScraped(true);
     if (galleryData.title) {
       setTitle(galleryData.title);
     }
@@ -6,6 +6,7 @@ import AutoTagging from "src/docs/en/AutoTagging.md";
 import JSONSpec from "src/docs/en/JSONSpec.md";
 import Configuration from "src/docs/en/Configuration.md";
 import Interface from "src/docs/en/Interface.md";
+import Searching from "src/docs/en/Searching.md";
 import Galleries from "src/docs/en/Galleries.md";
 import Scraping from "src/docs/en/Scraping.md";
 import Plugins from "src/docs/en/Plugins.md";
@@ -43,6 +44,11 @@ export const Manual: React.FC<IManualProps> = ({
       title: "Interface Options",
       content: Interface,
     },
+    {
+      key: "Searching.md",
+      title: "Searching",
+      content: Searching,
+    },
     {
       key: "Tasks.md",
       title: "Tasks",
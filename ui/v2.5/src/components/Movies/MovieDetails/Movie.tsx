@@ -9,6 +9,7 @@ import {
   useMovieDestroy,
   queryScrapeMovieURL,
   useListMovieScrapers,
+  changeSourceContext,
 } from "src/core/StashService";
 import { useParams, useHistory } from "react-router-dom";
 import {
@@ -80,6 +81,7 @@ export const Movie: React.FC = () => {
   const { data, error, loading } = useFindMovie(id);
   const [isLoading, setIsLoading] = useState(false);
   const [updateMovie] = useMovieUpdate();
+  const [scraped, setScraped] = useState(false);
   const [createMovie] = useMovieCreate(getMovieInput() as GQL.MovieCreateInput);
   const [deleteMovie] = useMovieDestroy(
     getMovieInput() as GQL.MovieDestroyInput
@@ -223,13 +225,17 @@ export const Movie: React.FC = () => {
           variables: {
             input: getMovieInput() as GQL.MovieUpdateInput,
           },
+          context: changeSourceContext(scraped),
         });
         if (result.data?.movieUpdate) {
+          setScraped(false);
           updateMovieData(result.data.movieUpdate);
           setIsEditing(false);
         }
       } else {
-        const result = await createMovie();
+        const result = await createMovie({
+          context: changeSourceContext(scraped),
+        });
         if (result.data?.movieCreate?.id) {
           history.push(`/movies/${result.data.movieCreate.id}`);
           setIsEditing(false);
@@ -317,6 +323,7 @@ export const Movie: React.FC = () => {
   function updateMovieEditStateFromScraper(
     state: Partial<GQL.ScrapedMovieDataFragment>
   ) {
+    setScraped(true);
     if (state.name) {
       setName(state.name);
     }
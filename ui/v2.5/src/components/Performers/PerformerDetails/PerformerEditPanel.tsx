@@ -21,6 +21,9 @@ import {
   usePerformerUpdate,
   usePerformerCreate,
   queryScrapePerformerURL,
+  queryStashBoxPerformer,
+  useConfiguration,
+  changeSourceContext,
 } from "src/core/StashService";
 import {
   Icon,
@@ -68,11 +71,13 @@ export const PerformerEditPanel: React.FC<IPerformerDetails> = ({
 
   // Network state
   const [isLoading, setIsLoading] = useState(false);
+  const [scraped, setScraped] = useState(false);
 
   const [updatePerformer] = usePerformerUpdate();
   const [createPerformer] = usePerformerCreate();
 
   const Scrapers = useListPerformerScrapers();
+  const stashConfig = useConfiguration();
   const [queryableScrapers, setQueryableScrapers] = useState<GQL.Scraper[]>([]);
 
   const [scrapedPerformer, setScrapedPerformer] = useState<
@@ -157,6 +162,7 @@ export const PerformerEditPanel: React.FC<IPerformerDetails> = ({
   function updatePerformerEditStateFromScraper(
     state: Partial<GQL.ScrapedPerformerDataFragment>
   ) {
+    setScraped(true);
     if (state.name) {
       formik.setFieldValue("name", state.name);
     }
@@ -246,6 +252,7 @@ export const PerformerEditPanel: React.FC<IPerformerDetails> = ({
               })),
             } as GQL.PerformerUpdateInput,
           },
+          context: changeSourceContext(scraped),
         });
         if (performerInput.image) {
           // Refetch image to bust browser cache
@@ -256,6 +263,7 @@ export const PerformerEditPanel: React.FC<IPerformerDetails> = ({
       } else {
         const result = await createPerformer({
           variables: performerInput as GQL.PerformerCreateInput,
+          context: changeSourceContext(scraped),
         });
         if (result.data?.performerCreate) {
           history.push(`/performers/${result.data.performerCreate.id}`);
@@ -382,6 +390,38 @@ export const PerformerEditPanel: React.FC<IPerformerDetails> = ({
     }
   }
 
+  async function onScrapeStashBoxClicked(stashBoxIndex: number) {
+    const { name } = formik.values;
+    if (!name) return;
+    setIsLoading(true);
+    try {
+      const result = await queryStashBoxPerformer(stashBoxIndex, name);
+      if (!result.data || !result.data.queryStashBoxPerformer) {
+        return;
+      }
+
+      if (result.data.queryStashBoxPerformer.length === 0) {
+        Toast.success({
+          content: "No performers found",
+        });
+        return;
+      }
+
+      // if this is a new performer, just dump the data
+      if (isNew) {
+        updatePerformerEditStateFromScraper(
+          result.data.queryStashBoxPerformer[0]
+        );
+      } else {
+        setScrapedPerformer(result.data.queryStashBoxPerformer[0]);
+      }
+    } catch (e) {
+      Toast.error(e);
+    } finally {
+      setIsLoading(false);
+    }
+  }
+
   async function onScrapePerformerURL() {
     const { url } = formik.values;
     if (!url) return;
@@ -410,10 +450,22 @@ export const PerformerEditPanel: React.FC<IPerformerDetails> = ({
       return;
     }
 
+    const stashBoxes = stashConfig.data?.configuration.general.stashBoxes ?? [];
+
     const popover = (
       <Popover id="performer-scraper-popover">
         <Popover.Content>
           <>
+            {stashBoxes.map((s, index) => (
+              <div key={s.endpoint}>
+                <Button
+                  className="minimal"
+                  onClick={() => onScrapeStashBoxClicked(index)}
+                >
+                  stash-box
+                </Button>
+              </div>
+            ))}
             {queryableScrapers
               ? queryableScrapers.map((s) => (
                   <div key={s.name}>
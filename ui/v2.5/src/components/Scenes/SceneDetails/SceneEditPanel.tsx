@@ -17,6 +17,7 @@ import {
   mutateReloadScrapers,
   useConfiguration,
   queryStashBoxScene,
+  changeSourceContext,
 } from "src/core/StashService";
 import {
   PerformerSelect,
@@ -82,6 +83,7 @@ export const SceneEditPanel: React.FC<IProps> = ({
   const [queryableScrapers, setQueryableScrapers] = useState<GQL.Scraper[]>([]);
 
   const [scrapedScene, setScrapedScene] = useState<GQL.ScrapedScene | null>();
+  const [scraped, setScraped] = useState(false);
 
   const [coverImagePreview, setCoverImagePreview] = useState<
     string | undefined
@@ -223,8 +225,10 @@ export const SceneEditPanel: React.FC<IProps> = ({
         variables: {
           input: getSceneInput(),
         },
+        context: changeSourceContext(scraped),
       });
       if (result.data?.sceneUpdate) {
+        setScraped(false);
         Toast.success({ content: "Updated scene" });
       }
     } catch (e) {
@@ -426,6 +430,7 @@ export const SceneEditPanel: React.FC<IProps> = ({
   function updateSceneFromScrapedScene(
     updatedScene: GQL.ScrapedSceneDataFragment
   ) {
+    setScraped(true);
     if (updatedScene.title) {
       setTitle(updatedScene.title);
     }
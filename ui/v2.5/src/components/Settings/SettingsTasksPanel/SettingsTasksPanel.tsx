@@ -82,6 +82,14 @@ export const SettingsTasksPanel: React.FC = () => {
         return "Running Plugin Operation";
       case "Migrate":
         return "Migrating";
+      case "Check Integrity":
+        return "Checking database integrity";
+      case "Migrate Blobs":
+        return "Moving images to the blobs storage";
+      case "Apply Gallery Folder Rules":
+        return "Applying gallery folder rules";
+      case "Restore Database":
+        return "Restoring the database";
       default:
         return "Idle";
     }
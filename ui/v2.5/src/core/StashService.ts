@@ -14,6 +14,15 @@ const { client } = createClient();
 
 export const getClient = () => client;
 
+const changeSourceHeader = "X-Stash-Change-Source";
+
+// Returns the context of a mutation, attributing its changes to scrapers if
+// the data was scraped.
+export const changeSourceContext = (scraped: boolean) =>
+  scraped
+    ? { headers: { [changeSourceHeader]: GQL.AuditSourceEnum.Scraper } }
+    : undefined;
+
 const getQueryNames = (queries: DocumentNode[]): string[] =>
   queries.map((q) => getOperationName(q)).filter((n) => n !== null) as string[];
 
@@ -765,6 +774,21 @@ export const queryStashBoxScene = (stashBoxIndex: number, sceneID: string) =>
     },
   });
 
+export const queryStashBoxPerformer = (
+  stashBoxIndex: number,
+  performerName: string
+) =>
+  client.query<GQL.QueryStashBoxPerformerQuery>({
+    query: GQL.QueryStashBoxPerformerDocument,
+    variables: {
+      input: {
+        stash_box_index: stashBoxIndex,
+        q: performerName,
+      },
+    },
+    fetchPolicy: "network-only",
+  });
+
 export const queryScrapeGallery = (
   scraperId: string,
   scene: GQL.GalleryUpdateInput
@@ -128,6 +128,7 @@ export class ListFilterModel {
           "framerate",
           "bitrate",
           "random",
+          "relevance",
         ];
         this.displayModeOptions = [
           DisplayMode.Grid,
@@ -156,11 +157,13 @@ export class ListFilterModel {
         this.sortByOptions = [
           "title",
           "path",
+          "position",
           "rating",
           "o_counter",
           "filesize",
           "file_mod_time",
           "random",
+          "relevance",
         ];
         this.displayModeOptions = [DisplayMode.Grid, DisplayMode.Wall];
         this.criterionOptions = [
@@ -184,6 +187,7 @@ export class ListFilterModel {
           "birthdate",
           "scenes_count",
           "random",
+          "relevance",
         ];
         this.displayModeOptions = [DisplayMode.Grid, DisplayMode.List];
 
@@ -235,7 +239,12 @@ export class ListFilterModel {
         break;
       case FilterMode.Galleries:
         this.sortBy = "path";
-        this.sortByOptions = ["path", "file_mod_time", "images_count"];
+        this.sortByOptions = [
+          "path",
+          "file_mod_time",
+          "images_count",
+          "relevance",
+        ];
         this.displayModeOptions = [DisplayMode.Grid, DisplayMode.List];
         this.criterionOptions = [
           new NoneCriterionOption(),
@@ -262,6 +271,7 @@ export class ListFilterModel {
           "scene_id",
           "random",
           "scenes_updated_at",
+          "relevance",
         ];
         this.displayModeOptions = [DisplayMode.Wall];
         this.criterionOptions = [
@@ -278,6 +288,7 @@ export class ListFilterModel {
         this.sortByOptions = [
           "name",
           "scenes_count" /* , "scene_markers_count"*/,
+          "relevance",
         ];
         this.displayModeOptions = [DisplayMode.Grid, DisplayMode.List];
         this.criterionOptions = [